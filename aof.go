@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sparrow_blog_server/cache/aof"
+	"sparrow_blog_server/pkg/logger"
+)
+
+// runAofCommand 执行缓存 AOF 文件的检查与修复命令: aof check|repair
+// 修复会将 AOF 截断在第一条损坏的记录处，执行前需要先停止服务
+// @return int 进程退出码
+func runAofCommand() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if err := logger.InitLogger(ctx); err != nil {
+		fmt.Printf("❗ 日志模块初始化失败: %v\n", err)
+		return 1
+	}
+
+	var report *aof.RepairReport
+	var err error
+	switch Args["aof"] {
+	case "check":
+		report, err = aof.Check(ctx)
+	case "repair":
+		report, err = aof.Repair(ctx)
+	default:
+		fmt.Println("用法: sparrow_blog_server aof check|repair")
+		return 1
+	}
+	if err != nil {
+		fmt.Printf("❗ 读取 AOF 文件失败: %v\n", err)
+		return 1
+	}
+
+	if report.Corruption == nil {
+		fmt.Printf("✅ 检查了 %d 个 AOF 文件，共 %d 条命令，没有发现损坏的记录\n", report.Files, report.KeptCommands)
+		return 0
+	}
+
+	fmt.Printf("❗ %v\n", report.Corruption)
+	fmt.Printf("   • 损坏位置之前的 %d 条命令可以保留\n", report.KeptCommands)
+	fmt.Printf("   • 损坏位置之后的 %d 字节将被丢弃\n", report.Corruption.LostBytes())
+	for _, f := range report.RemovedFiles {
+		fmt.Printf("   • 之后的文件 %s 将被删除\n", f)
+	}
+	if len(report.RemovedFiles) > 0 {
+		fmt.Printf("   • 被删除的文件中共有 %d 条命令\n", report.RemovedCommands)
+	}
+
+	if Args["aof"] == "check" {
+		fmt.Println("ℹ️ 停止服务后运行 aof repair 截断损坏的记录")
+		return 1
+	}
+	fmt.Println("✅ 已截断损坏的记录")
+	return 0
+}
@@ -3,6 +3,7 @@ package aof
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,6 +31,13 @@ import (
 type Aof struct {
 	file *FileOp      // 处理文件底层操作，包括轮转和压缩
 	mu   sync.RWMutex // 确保文件操作的线程安全访问
+
+	size       int64    // 上次加载或重写以来 AOF 的总大小（字节），包括已轮转的文件
+	baseSize   int64    // 上次加载或重写完成时 AOF 的大小，用于计算增长比例
+	rewriting  bool     // 是否正在重写
+	rewriteBuf [][]byte // 重写期间写入的命令，重写完成后追加到新文件中
+	closed     bool     // 是否已经关闭，关闭后不再完成重写
+	rewrites   int64    // 启动以来完成重写（包括生成快照）的次数
 }
 
 // NewAof 创建并返回一个新的 AOF 实例。
@@ -101,28 +109,11 @@ func (aof *Aof) LoadFile(ctx context.Context) ([][]string, error) {
 			}
 		}()
 
-		var files []string
-		var err error
-
-		// 如果启用了压缩功能，获取所有压缩文件并按时间戳排序。
-		if aof.file.needCompress {
-			files, err = filepath.Glob(filepath.Join(dir, prefix+"_*.aof.tar.gz"))
-			if err != nil {
-				return nil, fmt.Errorf("无法列出压缩的 AOF 文件: %w", err)
-			}
-			// 按文件名中的时间戳对压缩文件进行排序。
-			sort.Slice(files, func(i, j int) bool {
-				tsI := extractTimestamp(files[i])
-				tsJ := extractTimestamp(files[j])
-				return tsI < tsJ
-			})
+		files, err := aof.file.listFiles()
+		if err != nil {
+			return nil, err
 		}
-
-		// 检查当前的 AOF 文件是否存在，并将其加入文件列表。
 		currentFile := filepath.Join(dir, prefix+".aof")
-		if filetool.IsExist(currentFile) {
-			files = append(files, currentFile)
-		}
 
 		var allCommands [][]string
 		logger.Info("正在处理 %d 个文件", len(files))
@@ -130,8 +121,14 @@ func (aof *Aof) LoadFile(ctx context.Context) ([][]string, error) {
 		// 按顺序处理每个文件。
 		for i, f := range files {
 			logger.Info("正在处理文件 %d/%d: %s", i+1, len(files), f)
-			commands, err := processFile(f, tempDir)
-			if err != nil {
+			commands, err := processFile(f, tempDir, false)
+			var corruption *CorruptionError
+			if errors.As(err, &corruption) && corruption.Torn && f == currentFile {
+				// 当前文件末尾的不完整记录是写入时崩溃留下的，丢弃后继续加载
+				logger.Warn("%v，丢弃末尾不完整的 %d 字节", corruption, corruption.LostBytes())
+			} else if corruption != nil {
+				return nil, fmt.Errorf("%w，可以运行 aof repair 截断损坏的记录后重新启动", err)
+			} else if err != nil {
 				return nil, fmt.Errorf("无法处理文件 %s: %w", f, err)
 			}
 			logger.Info("文件 %s: 已加载 %d 条命令", f, len(commands))
@@ -149,6 +146,8 @@ func (aof *Aof) LoadFile(ctx context.Context) ([][]string, error) {
 		if err := aof.file.ready(); err != nil {
 			return nil, fmt.Errorf("无法创建新的 AOF 文件: %w", err)
 		}
+		aof.size = 0
+		aof.baseSize = 0
 
 		// 记录加载完成的日志。
 		logger.Info("AOF 文件加载完成: 处理了 %d 个文件，%d 条命令",
@@ -163,8 +162,12 @@ func (aof *Aof) LoadFile(ctx context.Context) ([][]string, error) {
 // 支持的命令:
 // - SET: 需要 4 个参数（键、值、类型、过期时间）
 // - DELETE: 需要 1 个参数（键）
+// - EVICT: 需要 1 个参数（键），表示因容量限制被淘汰
 // - INCR: 需要 2 个参数（键、类型）
 // - CLEANUP: 不需要参数
+// - EXPIRE、LPUSH: 需要 2 个参数
+// - HSET、LTRIM、ZADD: 需要 3 个参数
+// - SNAPSHOT: 需要 1 个参数（快照标识），表示之前的数据都已包含在快照中
 //
 // 线程安全:
 // - 使用互斥锁防止并发写入
@@ -215,20 +218,52 @@ func (aof *Aof) Close() error {
 	aof.mu.Lock()
 	defer aof.mu.Unlock()
 
+	aof.closed = true
 	// 关闭底层文件
 	return aof.file.Close()
 }
 
 // 内部辅助函数
 
-// storeCommand 处理实际的命令存储逻辑。
-// 它验证命令参数并格式化命令字符串。
+// storeCommand 格式化命令并写入 AOF 文件，重写期间同时缓存命令，重写完成后追加到新文件中。
 //
-// 命令格式:
-// - SET: SET;;key;;value;;type;;expiry
-// - DELETE: DELETE;;key
-// - INCR: INCR;;key;;type
-// - CLEANUP: CLEANUP
+// 参数：
+// - cmd: 表示要执行的命令类型，例如 SET、DELETE、INCR 或 CLEANUP。
+// - args: 表示命令的参数列表，具体内容取决于命令类型。
+//
+// 返回值：
+// - error: 如果命令参数不合法或写入文件失败，则返回错误；否则返回 nil。
+func (aof *Aof) storeCommand(cmd string, args ...string) error {
+	record, err := formatCommand(cmd, args...)
+	if err != nil {
+		return err
+	}
+	if err := aof.file.WriteRecord(record); err != nil {
+		return err
+	}
+
+	aof.size += int64(len(record))
+	if aof.rewriting {
+		aof.rewriteBuf = append(aof.rewriteBuf, record)
+	}
+	return nil
+}
+
+// formatCommand 验证命令参数并编码为写入 AOF 文件的一条记录，记录格式见 record.go。
+//
+// 命令参数:
+// - SET: key, value, type, expiry
+// - DELETE: key
+// - EVICT: key
+// - INCR: key, type
+// - CLEANUP: 无
+// - BASE: time
+// - SNAPSHOT: id
+// - EXPIRE: key, expiry
+// - HSET: key, field, value
+// - LPUSH: key, value
+// - LTRIM: key, start, stop
+// - ZADD: key, member, score
 //
 // 验证:
 // - 检查每种命令类型的参数数量
@@ -236,66 +271,89 @@ func (aof *Aof) Close() error {
 //
 // 如果出现以下情况则返回错误:
 // - 参数数量无效
-// - 写入操作失败
+// - 不支持的命令类型
 //
 // 参数：
 // - cmd: 表示要执行的命令类型，例如 SET、DELETE、INCR 或 CLEANUP。
 // - args: 表示命令的参数列表，具体内容取决于命令类型。
 //
 // 返回值：
-// - error: 如果命令参数不合法或写入文件失败，则返回错误；否则返回 nil。
-func (aof *Aof) storeCommand(cmd string, args ...string) error {
+// - []byte: 编码后的记录
+// - error: 如果命令参数不合法，则返回错误；否则返回 nil。
+func formatCommand(cmd string, args ...string) ([]byte, error) {
 	switch cmd {
 	case common.SET:
 		// 检查 SET 命令的参数数量是否正确，SET 命令需要 4 个参数：key、value、type 和 expired。
 		if len(args) != 4 {
-			return fmt.Errorf("SET command requires 4 args (key=%s, value=%s, type=%s, expired=%s), got %d",
+			return nil, fmt.Errorf("SET command requires 4 args (key=%s, value=%s, type=%s, expired=%s), got %d",
 				safeGet(args, 0), safeGet(args, 1), safeGet(args, 2), safeGet(args, 3), len(args))
 		}
-		// 将 SET 命令及其参数格式化为字符串并写入 AOF 文件。
-		return aof.file.Write([]byte(fmt.Sprintf("%s;;%s;;%s;;%s;;%s", cmd, args[0], args[1], args[2], args[3])))
+		return encodeRecord(append([]string{cmd}, args...)), nil
 
-	case common.DELETE:
-		// 检查 DELETE 命令的参数数量是否正确，DELETE 命令需要 1 个参数：key。
+	case common.DELETE, common.EVICT:
+		// 检查 DELETE 与 EVICT 命令的参数数量是否正确，它们都需要 1 个参数：key。
 		if len(args) != 1 {
-			return fmt.Errorf("DELETE command requires 1 arg (key=%s), got %d",
-				safeGet(args, 0), len(args))
+			return nil, fmt.Errorf("%s command requires 1 arg (key=%s), got %d",
+				cmd, safeGet(args, 0), len(args))
 		}
-		// 将 DELETE 命令及其参数格式化为字符串并写入 AOF 文件。
-		return aof.file.Write([]byte(fmt.Sprintf("%s;;%s", cmd, args[0])))
+		return encodeRecord(append([]string{cmd}, args...)), nil
 
 	case common.INCR:
 		// 检查 INCR 命令的参数数量是否正确，INCR 命令需要 2 个参数：key 和 type。
 		if len(args) != 2 {
-			return fmt.Errorf("INCR command requires 2 args (key=%s, type=%s), got %d",
+			return nil, fmt.Errorf("INCR command requires 2 args (key=%s, type=%s), got %d",
 				safeGet(args, 0), safeGet(args, 1), len(args))
 		}
-		// 将 INCR 命令及其参数格式化为字符串并写入 AOF 文件。
-		return aof.file.Write([]byte(fmt.Sprintf("%s;;%s;;%s", cmd, args[0], args[1])))
+		return encodeRecord(append([]string{cmd}, args...)), nil
 
 	case common.CLEANUP:
 		// 检查 CLEANUP 命令的参数数量是否正确，CLEANUP 命令不需要任何参数。
 		if len(args) != 0 {
-			return fmt.Errorf("CLEANUP command requires no args, got %d", len(args))
+			return nil, fmt.Errorf("CLEANUP command requires no args, got %d", len(args))
 		}
-		// 将 CLEANUP 命令直接写入 AOF 文件。
-		return aof.file.Write([]byte(cmd))
+		return encodeRecord([]string{cmd}), nil
+
+	case common.BASE, common.SNAPSHOT:
+		// 检查 BASE 与 SNAPSHOT 命令的参数数量是否正确，它们都需要 1 个参数：重写时间或快照标识。
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s command requires 1 arg (id=%s), got %d", cmd, safeGet(args, 0), len(args))
+		}
+		return encodeRecord(append([]string{cmd}, args...)), nil
+
+	case common.EXPIRE, common.LPUSH:
+		// 检查 EXPIRE 与 LPUSH 命令的参数数量是否正确，它们都需要 2 个参数：key 与过期时间或元素。
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s command requires 2 args (key=%s, value=%s), got %d",
+				cmd, safeGet(args, 0), safeGet(args, 1), len(args))
+		}
+		return encodeRecord(append([]string{cmd}, args...)), nil
+
+	case common.HSET, common.ZADD, common.LTRIM:
+		// 检查 HSET、ZADD 与 LTRIM 命令的参数数量是否正确，它们都需要 3 个参数：
+		// key 与字段和值、成员和分数或起止位置。
+		if len(args) != 3 {
+			return nil, fmt.Errorf("%s command requires 3 args (key=%s, arg1=%s, arg2=%s), got %d",
+				cmd, safeGet(args, 0), safeGet(args, 1), safeGet(args, 2), len(args))
+		}
+		return encodeRecord(append([]string{cmd}, args...)), nil
 
 	default:
 		// 如果命令类型不被支持，则返回错误。
-		return fmt.Errorf("unsupported command type: %s", cmd)
+		return nil, fmt.Errorf("unsupported command type: %s", cmd)
 	}
 }
 
-// processFile 处理指定路径的文件，支持常规文件和 .tar.gz 压缩文件。
+// processFile 处理指定路径的文件，支持常规文件和 .tar.gz 压缩文件，以及新旧两种文件格式。
+// 文件损坏时返回损坏位置之前的命令与 *CorruptionError。
 // 参数:
 // - path: 文件路径，可以是常规文件或 .tar.gz 压缩文件。
 // - tempDir: 临时目录路径，用于解压 .tar.gz 文件。
+// - strict: 为 true 时旧格式文件中无法解析的行也视为损坏，为 false 时跳过这些行。
 //
 // 返回值:
 // - [][]string: 处理后的文件内容，以二维字符串切片形式返回。
 // - error: 如果处理过程中发生错误，则返回具体的错误信息。
-func processFile(path string, tempDir string) ([][]string, error) {
+func processFile(path string, tempDir string, strict bool) ([][]string, error) {
 	var file *os.File
 	var err error
 
@@ -331,9 +389,28 @@ func processFile(path string, tempDir string) ([][]string, error) {
 		}
 	}()
 
-	// 使用 bufio.Scanner 逐行读取文件内容
-	scanner := bufio.NewScanner(file)
-	return processAOFFile(scanner)
+	// 没有文件头的是旧格式的文件，使用 bufio.Scanner 逐行读取
+	reader := bufio.NewReader(file)
+	isRecordFormat, err := hasFileHeader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+	var commands [][]string
+	if isRecordFormat {
+		_, _ = reader.Discard(len(fileHeader))
+		commands, err = readRecords(reader)
+	} else {
+		commands, err = processAOFFile(bufio.NewScanner(reader), strict)
+	}
+
+	var corruption *CorruptionError
+	if errors.As(err, &corruption) {
+		corruption.Path = path
+		if info, statErr := file.Stat(); statErr == nil {
+			corruption.Size = info.Size()
+		}
+	}
+	return commands, err
 }
 
 // extractTimestamp 从 AOF 文件名中提取时间戳。
@@ -366,15 +443,15 @@ func extractTimestamp(filename string) int64 {
 	return timestamp
 }
 
-// processAOFFile 处理 AOF 文件的内容，逐行读取文件并根据格式解析每个命令。
+// processAOFFile 处理旧格式（按行分隔）的 AOF 文件内容，逐行读取文件并根据格式解析每个命令。
 //
 // 命令验证:
 // - 检查命令格式和参数数量
 // - 删除所有字段的空格
-// - 记录无效命令的警告但继续处理
 //
 // 错误处理:
-// - 跳过无效命令并发出警告
+// - strict 为 false 时跳过无效命令并发出警告
+// - strict 为 true 时在第一条无效命令处停止，返回之前的命令与 *CorruptionError，偏移量为该行的起始位置
 // - 如果扫描器遇到读取错误则返回错误
 //
 // 返回:
@@ -384,65 +461,54 @@ func extractTimestamp(filename string) int64 {
 // 函数逻辑:
 // 1. 逐行读取文件内容，并根据 ";;" 分隔符将每行拆分为命令和参数。
 // 2. 根据命令类型（SET、DELETE、INCR、CLEANUP）验证参数数量和格式。
-// 3. 对于无效命令或格式错误的命令，记录警告日志并跳过该行。
+// 3. 对于无效命令或格式错误的命令，根据 strict 跳过该行或返回损坏错误。
 // 4. 如果扫描器在读取过程中遇到错误，则返回错误信息。
-func processAOFFile(scanner *bufio.Scanner) ([][]string, error) {
+func processAOFFile(scanner *bufio.Scanner, strict bool) ([][]string, error) {
 	var commands [][]string
 	lineNum := 0
 
+	// 记录已读取的字节数，用于计算每一行的起始偏移量
+	var consumed, lineStart int64
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
+
 	// 逐行扫描文件内容
 	for scanner.Scan() {
 		lineNum++
 		command := strings.Split(scanner.Text(), ";;")
 
-		// 根据命令类型处理不同的逻辑
-		switch strings.TrimSpace(command[0]) {
-		case common.SET:
-			// 验证 SET 命令的参数数量是否为 5
-			if len(command) != 5 {
-				logger.Warn("第 %d 行: SET 命令格式无效，跳过: %v", lineNum, command)
-				continue
+		// 根据命令类型验证参数数量，SET 为 5 个，DELETE、EVICT 与 BASE 为 2 个，INCR 为 3 个，CLEANUP 不需要参数
+		var invalid string
+		switch name := strings.TrimSpace(command[0]); name {
+		case common.SET, common.DELETE, common.EVICT, common.INCR, common.BASE, common.CLEANUP:
+			if len(command) != commandArgc[name] {
+				invalid = fmt.Sprintf("%s 命令格式无效", name)
 			}
-			// 将 SET 命令及其参数添加到结果中
-			commands = append(commands, []string{
-				strings.TrimSpace(command[0]),
-				strings.TrimSpace(command[1]), // key
-				strings.TrimSpace(command[2]), // value
-				strings.TrimSpace(command[3]), // type
-				strings.TrimSpace(command[4]), // expiry
-			})
-		case common.DELETE:
-			// 验证 DELETE 命令的参数数量是否为 2
-			if len(command) != 2 {
-				logger.Warn("第 %d 行: DELETE 命令格式无效，跳过: %v", lineNum, command)
-				continue
+		default:
+			invalid = fmt.Sprintf("未知命令 %s", name)
+		}
+
+		if invalid != "" {
+			if strict {
+				return commands, &CorruptionError{
+					Offset: lineStart,
+					Reason: fmt.Sprintf("第 %d 行: %s", lineNum, invalid),
+				}
 			}
-			// 将 DELETE 命令及其参数添加到结果中
-			commands = append(commands, []string{
-				strings.TrimSpace(command[0]),
-				strings.TrimSpace(command[1]), // key
-			})
-		case common.INCR:
-			// 验证 INCR 命令的参数数量是否为 3
-			if len(command) != 3 {
-				logger.Warn("第 %d 行: INCR 命令格式无效，跳过: %v", lineNum, command)
-				continue
+			// 记录无效命令的警告日志并跳过
+			logger.Warn("第 %d 行: %s，跳过: %v", lineNum, invalid, command)
+		} else {
+			// 将命令及其参数添加到结果中
+			fields := make([]string, len(command))
+			for i, field := range command {
+				fields[i] = strings.TrimSpace(field)
 			}
-			// 将 INCR 命令及其参数添加到结果中
-			commands = append(commands, []string{
-				strings.TrimSpace(command[0]),
-				strings.TrimSpace(command[1]), // key
-				strings.TrimSpace(command[2]), // type
-			})
-		case common.CLEANUP:
-			// CLEANUP 命令不需要参数，直接添加到结果中
-			commands = append(commands, []string{
-				strings.TrimSpace(command[0]),
-			})
-		default:
-			// 记录未知命令的警告日志并跳过
-			logger.Warn("第 %d 行: 未知命令，跳过: %v", lineNum, command[0])
+			commands = append(commands, fields)
 		}
+		lineStart = consumed
 	}
 
 	// 检查扫描器是否在读取过程中遇到错误
@@ -454,6 +520,32 @@ func processAOFFile(scanner *bufio.Scanner) ([][]string, error) {
 	return commands, nil
 }
 
+// listFiles 按时间顺序列出所有 AOF 文件：已轮转的压缩文件在前，当前文件在最后
+func (fop *FileOp) listFiles() ([]string, error) {
+	dir := filepath.Dir(fop.path)
+
+	var files []string
+	// 如果启用了压缩功能，获取所有压缩文件并按时间戳排序。
+	if fop.needCompress {
+		var err error
+		files, err = filepath.Glob(filepath.Join(dir, fop.filePrefixName+"_*.aof.tar.gz"))
+		if err != nil {
+			return nil, fmt.Errorf("无法列出压缩的 AOF 文件: %w", err)
+		}
+		// 按文件名中的时间戳对压缩文件进行排序。
+		sort.Slice(files, func(i, j int) bool {
+			return extractTimestamp(files[i]) < extractTimestamp(files[j])
+		})
+	}
+
+	// 检查当前的 AOF 文件是否存在，并将其加入文件列表。
+	currentFile := filepath.Join(dir, fop.filePrefixName+".aof")
+	if filetool.IsExist(currentFile) {
+		files = append(files, currentFile)
+	}
+	return files, nil
+}
+
 // safeGet 安全地从切片中检索元素。
 // 通过对无效索引返回 "<nil>" 来防止索引超出范围的 panic。
 //
@@ -20,6 +20,7 @@ func init() {
 }
 
 func TestAof_Store(t *testing.T) {
+	useTempAof(t)
 	aof := NewAof()
 	ctx := context.Background()
 
@@ -90,6 +91,7 @@ func TestAof_Store(t *testing.T) {
 }
 
 func TestAof_LoadFile(t *testing.T) {
+	useTempAof(t)
 	aof := NewAof()
 	ctx := context.Background()
 
@@ -144,6 +146,7 @@ func TestAof_LoadFile(t *testing.T) {
 
 func TestAOFWriteAndLoad(t *testing.T) {
 	// Initialize AOF with test configuration
+	useTempAof(t)
 	config.Cache.Aof.MaxSize = 10 // Set to 10MB for testing
 	aof := NewAof()
 	ctx := context.Background()
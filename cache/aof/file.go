@@ -2,6 +2,7 @@ package aof
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -31,11 +32,21 @@ type FileOp struct {
 	path           string        // 当前活动文件的绝对路径
 	filePrefixName string        // 不带扩展名的基本文件名
 	fileSuffixName string        // 不带点的文件扩展名
+	header         []byte        // 新建文件时写入的文件头，为空时不写入
+	fsync          string        // 刷盘策略，见 config.FsyncAlways 等
+	syncInterval   time.Duration // everysec 策略下后台刷盘的间隔
+	written        int64         // 当前文件已写入操作系统的大小
+	synced         int64         // 当前文件已刷到磁盘的大小
+	stopFlusher    chan struct{} // 关闭文件时通知后台刷盘协程退出
+	rotations      int64         // 启动以来轮转的次数
 }
 
+// defaultSyncInterval everysec 策略下后台刷盘的间隔
+const defaultSyncInterval = time.Second
+
 // CreateFileOp 使用给定的配置初始化一个新的 FileOp 实例。
 // 它验证配置并设置文件操作结构。
-// 在第一次写入操作之前，实际文件不会被打开。
+// 在第一次写入操作之前，实际文件不会被打开；新建的文件会先写入文件头，只能通过 WriteRecord 追加记录。
 //
 // 返回值：
 //   - *FileOp: 初始化完成的 FileOp 实例，包含文件路径、前缀、后缀、压缩需求等信息。
@@ -58,6 +69,9 @@ func CreateFileOp() (*FileOp, error) {
 		path:           config.Cache.Aof.Path,
 		needCompress:   config.Cache.Aof.Compress,
 		maxSize:        config.Cache.Aof.MaxSize,
+		header:         fileHeader,
+		fsync:          config.Cache.Aof.Fsync,
+		syncInterval:   defaultSyncInterval,
 	}, nil
 }
 
@@ -85,16 +99,106 @@ func (fop *FileOp) ready() error {
 	if err != nil {
 		return fmt.Errorf("failed to open/create file: %w", err)
 	}
+	if err := fop.checkHeader(); err != nil {
+		_ = fop.file.Close()
+		fop.file = nil
+		return err
+	}
+
+	// 打开时文件中已有的内容视为已经刷盘
+	info, err := fop.file.Stat()
+	if err != nil {
+		_ = fop.file.Close()
+		fop.file = nil
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	fop.written = info.Size()
+	fop.synced = fop.written
 
 	// 使用 32KB 缓冲区初始化缓冲写入器
 	fop.writer = bufio.NewWriterSize(fop.file, 32*1024)
 	fop.isOpen = true
+	fop.startFlusher()
+	return nil
+}
+
+// startFlusher 在 everysec 策略下启动后台刷盘协程
+// 每个间隔内的所有写入共用一次 fsync（组提交），写入本身只需写到操作系统的页缓存，不必等待磁盘。
+// 协程在持有锁时检查文件状态，文件关闭后即使晚一些退出也不会访问已关闭的文件。
+func (fop *FileOp) startFlusher() {
+	if fop.fsync != "" && fop.fsync != config.FsyncEverySec {
+		return
+	}
+
+	stop := make(chan struct{})
+	fop.stopFlusher = stop
+	go func() {
+		ticker := time.NewTicker(fop.syncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fop.rwMu.Lock()
+				if fop.isOpen {
+					if err := fop.syncLocked(); err != nil {
+						logger.Error("AOF 后台刷盘失败: %v", err)
+					}
+				}
+				fop.rwMu.Unlock()
+			}
+		}
+	}()
+}
+
+// syncLocked 将写入操作系统的数据刷到磁盘，没有新的写入时跳过，调用方需持有锁
+func (fop *FileOp) syncLocked() error {
+	if fop.synced == fop.written {
+		return nil
+	}
+	if err := fop.file.Sync(); err != nil {
+		return fmt.Errorf("sync failed: %w", err)
+	}
+	fop.synced = fop.written
+	return nil
+}
+
+// checkHeader 为新建的空文件写入文件头；已有内容的文件必须以相同的文件头开始，
+// 避免在旧格式的文件后追加新格式的记录。
+func (fop *FileOp) checkHeader() error {
+	if len(fop.header) == 0 {
+		return nil
+	}
+
+	info, err := fop.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.Size() == 0 {
+		if _, err := fop.file.Write(fop.header); err != nil {
+			return fmt.Errorf("failed to write file header: %w", err)
+		}
+		return nil
+	}
+
+	head := make([]byte, len(fop.header))
+	if _, err := fop.file.ReadAt(head, 0); err != nil || !bytes.Equal(head, fop.header) {
+		return fmt.Errorf("file %s has a different format, it must be loaded before appending", fop.path)
+	}
 	return nil
 }
 
 // Close 刷新所有缓冲数据并关闭文件。
 // 多次调用 Close 是安全的。
 func (fop *FileOp) Close() error {
+	fop.rwMu.Lock()
+	defer fop.rwMu.Unlock()
+	return fop.closeLocked()
+}
+
+// closeLocked 关闭文件，调用方需持有锁
+func (fop *FileOp) closeLocked() error {
 	if !fop.isOpen {
 		return nil
 	}
@@ -116,6 +220,12 @@ func (fop *FileOp) Close() error {
 		return fmt.Errorf("close failed: %w", err)
 	}
 
+	// 通知后台刷盘协程退出
+	if fop.stopFlusher != nil {
+		close(fop.stopFlusher)
+		fop.stopFlusher = nil
+	}
+
 	// 重置文件操作状态
 	fop.isOpen = false
 	fop.writer = nil
@@ -142,17 +252,21 @@ func (fop *FileOp) needSplit() bool {
 	return uint64(fileInfo.Size()) > uint64(fop.maxSize)*1024*1024
 }
 
-// Write 在文件中追加数据，并带有换行符。
-// 如果达到大小限制，它会自动处理文件轮转。
-// 该操作是线程安全的，并且使用缓冲提高性能。
-func (fop *FileOp) Write(context []byte) error {
+// WriteRecord 在文件中原样追加一条自带长度的记录，不添加换行符。
+// 达到大小限制时会先轮转文件，记录不会被拆分到两个文件中。
+func (fop *FileOp) WriteRecord(record []byte) error {
 	if fop == nil {
 		return fmt.Errorf("FileOp is nil")
 	}
-	if len(context) == 0 {
+	if len(record) == 0 {
 		return nil
 	}
 
+	return fop.write(record)
+}
+
+// write 写入数据并刷新缓冲区，必要时先轮转文件
+func (fop *FileOp) write(buf []byte) error {
 	fop.rwMu.Lock()
 	defer fop.rwMu.Unlock()
 
@@ -170,21 +284,21 @@ func (fop *FileOp) Write(context []byte) error {
 		}
 	}
 
-	// 准备带有换行符的数据
-	buf := make([]byte, len(context)+1)
-	copy(buf, context)
-	buf[len(context)] = '\n'
-
 	// 写入数据
 	if _, err := fop.writer.Write(buf); err != nil {
 		return fmt.Errorf("write failed: %w", err)
 	}
 
-	// 写入后始终刷新以确保数据被写入
+	// 写入后始终刷新以确保数据被写入操作系统，进程崩溃时不会丢失
 	if err := fop.writer.Flush(); err != nil {
 		return fmt.Errorf("flush failed: %w", err)
 	}
+	fop.written += int64(len(buf))
 
+	// always 策略下等待数据写入磁盘后才返回，系统崩溃或断电时也不会丢失
+	if fop.fsync == config.FsyncAlways {
+		return fop.syncLocked()
+	}
 	return nil
 }
 
@@ -205,7 +319,7 @@ func (fop *FileOp) checkAndRotate() error {
 	}
 
 	// 关闭当前文件
-	if err := fop.Close(); err != nil {
+	if err := fop.closeLocked(); err != nil {
 		return err
 	}
 
@@ -243,6 +357,8 @@ func (fop *FileOp) checkAndRotate() error {
 		}
 	}
 
+	fop.rotations++
+
 	// 创建新的写入文件
 	return fop.ready()
 }
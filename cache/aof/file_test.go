@@ -3,9 +3,13 @@ package aof
 import (
 	"bytes"
 	"context"
+	"os"
+	"slices"
+	"sparrow_blog_server/cache/common"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -13,20 +17,133 @@ func init() {
 	_ = logger.InitLogger(context.Background())
 }
 
-func TestFileOp_Write(t *testing.T) {
+func TestFileOp_WriteRecord(t *testing.T) {
+	useTempAof(t)
+	config.Cache.Aof.MaxSize = 1
+
 	// 初始化FileOp配置（1MB分割）
-	fo, _ := CreateFileOp()
+	fo, err := CreateFileOp()
+	if err != nil {
+		t.Fatalf("创建文件失败: %v", err)
+	}
 
-	// 写法 1
-	for i := 0; i < 10; i++ {
-		err := fo.Write(bytes.Repeat([]byte("a"), 1024*1024+512))
-		if err != nil {
+	for i := 0; i < 3; i++ {
+		record := encodeRecord([]string{common.SET, "key", string(bytes.Repeat([]byte("a"), 1024*1024+512)), "4", "0"})
+		if err := fo.WriteRecord(record); err != nil {
 			t.Fatalf("写入日志发生错误: %v", err)
 		}
 	}
+	if fo.rotations == 0 {
+		t.Error("期望超过大小限制后轮转文件")
+	}
 
 	// 强制关闭并添加延迟确保文件释放
 	if err := fo.Close(); err != nil {
 		t.Fatalf("关闭文件失败: %v", err)
 	}
+
+	// 新建的文件带有文件头，追加的记录可以被正常加载
+	aof := NewAof()
+	defer func() { _ = aof.Close() }()
+	if _, err := aof.LoadFile(context.Background()); err != nil {
+		t.Errorf("加载写入的文件失败: %v", err)
+	}
+}
+
+// simulateCrash 模拟系统崩溃或断电：不执行正常的关闭流程，丢弃所有未刷到磁盘的数据
+func simulateCrash(fop *FileOp) {
+	fop.rwMu.Lock()
+	defer fop.rwMu.Unlock()
+
+	if fop.stopFlusher != nil {
+		close(fop.stopFlusher)
+		fop.stopFlusher = nil
+	}
+	_ = fop.file.Close()
+	_ = os.Truncate(fop.path, fop.synced)
+	fop.file = nil
+	fop.writer = nil
+	fop.isOpen = false
+}
+
+func TestFileOp_Fsync(t *testing.T) {
+	ctx := context.Background()
+
+	store := func(aof *Aof, keys ...string) {
+		for _, key := range keys {
+			if err := aof.Store(ctx, common.SET, key, "val", "4", "0"); err != nil {
+				t.Fatalf("写入失败: %v", err)
+			}
+		}
+	}
+	recovered := func(t *testing.T) []string {
+		aof := NewAof()
+		defer func() { _ = aof.Close() }()
+		commands, err := aof.LoadFile(ctx)
+		if err != nil {
+			t.Fatalf("加载失败: %v", err)
+		}
+		keys := make([]string, 0, len(commands))
+		for _, command := range commands {
+			keys = append(keys, command[1])
+		}
+		return keys
+	}
+
+	t.Run("always 每次写入都刷盘", func(t *testing.T) {
+		useTempAof(t)
+		config.Cache.Aof.Fsync = config.FsyncAlways
+		aof := NewAof()
+		store(aof, "a", "b", "c")
+		simulateCrash(aof.file)
+
+		if keys := recovered(t); !slices.Equal(keys, []string{"a", "b", "c"}) {
+			t.Errorf("期望所有写入都保留，实际得到 %v", keys)
+		}
+	})
+
+	t.Run("everysec 只丢失最近一次刷盘之后的写入", func(t *testing.T) {
+		useTempAof(t)
+		config.Cache.Aof.Fsync = config.FsyncEverySec
+		aof := NewAof()
+		aof.file.syncInterval = 20 * time.Millisecond
+		store(aof, "a", "b")
+		time.Sleep(100 * time.Millisecond)
+
+		// 停止后台刷盘，模拟在下一次刷盘之前崩溃
+		aof.file.rwMu.Lock()
+		if aof.file.synced != aof.file.written {
+			t.Error("期望后台协程已经将之前的写入刷盘")
+		}
+		close(aof.file.stopFlusher)
+		aof.file.stopFlusher = nil
+		aof.file.rwMu.Unlock()
+
+		store(aof, "c")
+		simulateCrash(aof.file)
+
+		if keys := recovered(t); !slices.Equal(keys, []string{"a", "b"}) {
+			t.Errorf("期望保留刷盘前的写入，实际得到 %v", keys)
+		}
+	})
+
+	t.Run("no 不主动刷盘", func(t *testing.T) {
+		useTempAof(t)
+		config.Cache.Aof.Fsync = config.FsyncNo
+		aof := NewAof()
+		store(aof, "a", "b", "c")
+		simulateCrash(aof.file)
+
+		if keys := recovered(t); len(keys) != 0 {
+			t.Errorf("期望未刷盘的写入全部丢失，实际得到 %v", keys)
+		}
+
+		// 正常关闭时会刷盘
+		aof = NewAof()
+		store(aof, "d")
+		_ = aof.Close()
+		if keys := recovered(t); !slices.Equal(keys, []string{"d"}) {
+			t.Errorf("期望正常关闭后写入保留，实际得到 %v", keys)
+		}
+	})
 }
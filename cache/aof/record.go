@@ -0,0 +1,188 @@
+package aof
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sparrow_blog_server/cache/common"
+)
+
+// AOF 文件格式（版本 2）:
+//
+//	文件头: "SBAOF" + 版本号(1 字节) + '\n'
+//	记录:   长度(uint32, 大端) + CRC32-C 校验和(uint32, 大端) + 内容
+//	内容:   参数个数(uvarint) + 依次每个参数的长度(uvarint)与字节，第一个参数为命令名
+//
+// 参数按长度写入，值中包含 ";;" 或换行符也不会影响重放；每条记录都带有校验和，
+// 写入时崩溃留下的不完整记录或磁盘损坏都能被发现，而不是被当作普通命令重放。
+// 没有文件头的文件为旧的按行分隔格式（版本 1），仍然可以加载。
+
+const (
+	formatMagic   = "SBAOF"
+	formatVersion = 2
+
+	recordHeaderSize = 8
+	maxRecordSize    = 512 * 1024 * 1024 // 单条记录的最大长度，超过时视为损坏
+)
+
+// fileHeader 新格式文件的文件头
+var fileHeader = []byte{'S', 'B', 'A', 'O', 'F', formatVersion, '\n'}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// commandArgc 每种命令包括命令名在内的参数个数
+var commandArgc = map[string]int{
+	common.SET:      5,
+	common.DELETE:   2,
+	common.EVICT:    2,
+	common.INCR:     3,
+	common.CLEANUP:  1,
+	common.BASE:     2,
+	common.SNAPSHOT: 2,
+	common.EXPIRE:   3,
+	common.HSET:     4,
+	common.LPUSH:    3,
+	common.LTRIM:    4,
+	common.ZADD:     4,
+}
+
+// CorruptionError 表示 AOF 文件中出现了不完整或校验失败的记录
+type CorruptionError struct {
+	Path   string // 文件路径
+	Offset int64  // 第一条损坏记录的偏移量，之前的记录都是完整的
+	Size   int64  // 文件大小
+	Torn   bool   // 文件在记录中间结束，通常是写入时崩溃导致的
+	Reason string // 损坏原因
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("AOF 文件 %s 在偏移量 %d 处损坏（文件大小 %d）: %s", e.Path, e.Offset, e.Size, e.Reason)
+}
+
+// LostBytes 从损坏位置到文件末尾的字节数
+func (e *CorruptionError) LostBytes() int64 {
+	return e.Size - e.Offset
+}
+
+// encodeRecord 将命令及其参数编码为一条记录
+func encodeRecord(fields []string) []byte {
+	size := binary.MaxVarintLen64
+	for _, f := range fields {
+		size += binary.MaxVarintLen64 + len(f)
+	}
+
+	buf := make([]byte, recordHeaderSize, recordHeaderSize+size)
+	buf = binary.AppendUvarint(buf, uint64(len(fields)))
+	for _, f := range fields {
+		buf = binary.AppendUvarint(buf, uint64(len(f)))
+		buf = append(buf, f...)
+	}
+
+	payload := buf[recordHeaderSize:]
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	return buf
+}
+
+// decodePayload 解析记录的内容，内容已经通过校验，解析失败说明写入时的数据本身有误
+func decodePayload(payload []byte) ([]string, error) {
+	r := bytes.NewReader(payload)
+	argc, err := binary.ReadUvarint(r)
+	if err != nil || argc == 0 || argc > uint64(len(payload)) {
+		return nil, errors.New("参数个数无效")
+	}
+
+	fields := make([]string, 0, argc)
+	for i := uint64(0); i < argc; i++ {
+		n, err := binary.ReadUvarint(r)
+		if err != nil || n > uint64(r.Len()) {
+			return nil, fmt.Errorf("第 %d 个参数长度无效", i+1)
+		}
+		field := make([]byte, n)
+		_, _ = r.Read(field)
+		fields = append(fields, string(field))
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("记录末尾有多余的数据")
+	}
+	return fields, nil
+}
+
+// hasFileHeader 判断文件是否以新格式的文件头开始
+// 以 "SBAOF" 开始但版本号不支持时返回错误
+func hasFileHeader(r *bufio.Reader) (bool, error) {
+	head, err := r.Peek(len(fileHeader))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	if !bytes.HasPrefix(head, []byte(formatMagic)) {
+		return false, nil
+	}
+	if !bytes.Equal(head, fileHeader) {
+		return false, fmt.Errorf("不支持的 AOF 文件版本: %q", head)
+	}
+	return true, nil
+}
+
+// readRecords 读取文件头之后的全部记录。
+// 遇到不完整或校验失败的记录时停止，返回之前读取到的命令与 *CorruptionError，
+// 错误中的 Path 与 Size 由调用方填写。
+//
+// 参数:
+// - r: 位于文件头之后的读取器
+//
+// 返回:
+// - [][]string: 损坏位置之前的全部命令
+// - error: 读取失败或文件损坏时返回错误
+func readRecords(r *bufio.Reader) ([][]string, error) {
+	var commands [][]string
+	offset := int64(len(fileHeader))
+	header := make([]byte, recordHeaderSize)
+
+	for {
+		n, err := io.ReadFull(r, header)
+		if errors.Is(err, io.EOF) {
+			return commands, nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return commands, &CorruptionError{Offset: offset, Torn: true,
+				Reason: fmt.Sprintf("记录头不完整，只有 %d 字节", n)}
+		}
+		if err != nil {
+			return commands, err
+		}
+
+		length := binary.BigEndian.Uint32(header[0:4])
+		checksum := binary.BigEndian.Uint32(header[4:8])
+		if length == 0 || length > maxRecordSize {
+			return commands, &CorruptionError{Offset: offset, Reason: fmt.Sprintf("记录长度 %d 无效", length)}
+		}
+
+		payload := make([]byte, length)
+		if n, err := io.ReadFull(r, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return commands, &CorruptionError{Offset: offset, Torn: true,
+					Reason: fmt.Sprintf("记录内容不完整，期望 %d 字节，只有 %d 字节", length, n)}
+			}
+			return commands, err
+		}
+		if crc32.Checksum(payload, crcTable) != checksum {
+			return commands, &CorruptionError{Offset: offset, Reason: "校验和不一致"}
+		}
+
+		fields, err := decodePayload(payload)
+		if err != nil {
+			return commands, &CorruptionError{Offset: offset, Reason: err.Error()}
+		}
+		if argc, ok := commandArgc[fields[0]]; !ok || argc != len(fields) {
+			return commands, &CorruptionError{Offset: offset, Reason: fmt.Sprintf("命令 %s 的参数个数 %d 无效", fields[0], len(fields))}
+		}
+
+		commands = append(commands, fields)
+		offset += int64(recordHeaderSize) + int64(length)
+	}
+}
@@ -0,0 +1,188 @@
+package aof
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/filetool"
+	"testing"
+)
+
+// useTempAof 将 AOF 路径指向临时目录，测试结束后恢复
+func useTempAof(t *testing.T) string {
+	dir := t.TempDir()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Path = filepath.Join(dir, "record.aof")
+	config.Cache.Aof.Compress = true
+	t.Cleanup(func() { config.Cache.Aof = oldAof })
+	return dir
+}
+
+func TestAof_RecordFormat(t *testing.T) {
+	ctx := context.Background()
+	useTempAof(t)
+
+	aof := NewAof()
+	// 值中包含分隔符与换行符时，旧格式会解析出错
+	value := `{"content":"a;;b","lines":"1\n2"}`
+	_ = aof.Store(ctx, common.SET, "obj", value, "6", "0")
+	_ = aof.Store(ctx, common.SET, "empty", "", "4", "0")
+	_ = aof.Store(ctx, common.CLEANUP)
+
+	commands, err := aof.LoadFile(ctx)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+	want := [][]string{
+		{common.SET, "obj", value, "6", "0"},
+		{common.SET, "empty", "", "4", "0"},
+		{common.CLEANUP},
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("期望加载 %d 条命令，实际得到 %q", len(want), commands)
+	}
+	for i := range want {
+		if !slices.Equal(commands[i], want[i]) {
+			t.Errorf("第 %d 条命令期望 %q，实际得到 %q", i+1, want[i], commands[i])
+		}
+	}
+}
+
+func TestAof_LegacyFormat(t *testing.T) {
+	ctx := context.Background()
+	useTempAof(t)
+
+	legacy := "SET;;key1;;value1;;4;;0\nDELETE;;key2\nBROKEN;;line\nINCR;;key3;;1\n"
+	if err := os.WriteFile(config.Cache.Aof.Path, []byte(legacy), 0600); err != nil {
+		t.Fatalf("写入旧格式文件失败: %v", err)
+	}
+
+	aof := NewAof()
+	defer func() { _ = aof.Close() }()
+	commands, err := aof.LoadFile(ctx)
+	if err != nil {
+		t.Fatalf("加载旧格式文件失败: %v", err)
+	}
+	if len(commands) != 3 || commands[0][2] != "value1" || commands[2][0] != common.INCR {
+		t.Errorf("旧格式文件加载结果不符合预期: %q", commands)
+	}
+
+	// 加载后新写入的记录使用新格式
+	_ = aof.Store(ctx, common.DELETE, "key1")
+	_ = aof.file.Close()
+	head := make([]byte, len(fileHeader))
+	f, _ := os.Open(config.Cache.Aof.Path)
+	defer func() { _ = f.Close() }()
+	if _, err := f.Read(head); err != nil || string(head) != string(fileHeader) {
+		t.Errorf("期望加载后的文件以新格式的文件头开始，实际得到 %q", head)
+	}
+}
+
+func TestAof_CheckLegacyFormat(t *testing.T) {
+	ctx := context.Background()
+	useTempAof(t)
+
+	valid := "SET;;key1;;value1;;4;;0\r\nDELETE;;key2\n"
+	legacy := valid + "BROKEN;;line\nINCR;;key3;;1\n"
+	if err := os.WriteFile(config.Cache.Aof.Path, []byte(legacy), 0600); err != nil {
+		t.Fatalf("写入旧格式文件失败: %v", err)
+	}
+
+	// 加载时跳过的行在检查时视为损坏
+	report, err := Check(ctx)
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if report.Corruption == nil || report.Corruption.Offset != int64(len(valid)) || report.KeptCommands != 2 {
+		t.Fatalf("期望检查出第 3 行损坏，实际得到 %+v, %+v", report, report.Corruption)
+	}
+
+	if _, err := Repair(ctx); err != nil {
+		t.Fatalf("修复失败: %v", err)
+	}
+	if data, _ := os.ReadFile(config.Cache.Aof.Path); string(data) != valid {
+		t.Errorf("期望文件被截断在损坏的行之前，实际得到 %q", data)
+	}
+	report, err = Check(ctx)
+	if err != nil || report.Corruption != nil || report.KeptCommands != 2 {
+		t.Errorf("期望修复后的文件是完整的，实际得到 %+v, %v", report, err)
+	}
+}
+
+func TestAof_Corruption(t *testing.T) {
+	ctx := context.Background()
+
+	writeAof := func(t *testing.T) (path string, offsets []int64) {
+		aof := NewAof()
+		for _, key := range []string{"a", "b", "c"} {
+			_ = aof.Store(ctx, common.SET, key, "val", "4", "0")
+			info, _ := os.Stat(aof.file.path)
+			offsets = append(offsets, info.Size())
+		}
+		_ = aof.Close()
+		return aof.file.path, offsets
+	}
+
+	t.Run("末尾不完整的记录", func(t *testing.T) {
+		useTempAof(t)
+		path, offsets := writeAof(t)
+		if err := os.Truncate(path, offsets[2]-3); err != nil {
+			t.Fatalf("截断文件失败: %v", err)
+		}
+
+		commands, err := NewAof().LoadFile(ctx)
+		if err != nil {
+			t.Fatalf("期望丢弃末尾不完整的记录后继续加载，实际得到 %v", err)
+		}
+		if len(commands) != 2 {
+			t.Errorf("期望加载 2 条完整的命令，实际得到 %q", commands)
+		}
+	})
+
+	t.Run("校验失败的记录", func(t *testing.T) {
+		dir := useTempAof(t)
+		path, offsets := writeAof(t)
+		data, _ := os.ReadFile(path)
+		data[offsets[0]+recordHeaderSize+2] ^= 0xff
+
+		// 将损坏的文件作为已轮转的压缩文件，之后的当前文件在修复时会被删除
+		rotated := filepath.Join(dir, "record_1000.aof")
+		_ = os.WriteFile(rotated, data, 0600)
+		if err := filetool.CompressFileToTarGz(rotated, rotated+".tar.gz"); err != nil {
+			t.Fatalf("压缩文件失败: %v", err)
+		}
+		_ = os.Remove(rotated)
+		_ = os.Remove(path)
+		writeAof(t)
+
+		var corruption *CorruptionError
+		if _, err := NewAof().LoadFile(ctx); !errors.As(err, &corruption) {
+			t.Fatalf("期望加载时返回 CorruptionError，实际得到 %v", err)
+		}
+		if corruption.Offset != offsets[0] || corruption.Torn {
+			t.Errorf("损坏位置不符合预期: %+v", corruption)
+		}
+
+		report, err := Repair(ctx)
+		if err != nil {
+			t.Fatalf("修复失败: %v", err)
+		}
+		if report.KeptCommands != 1 || report.Corruption.LostBytes() != offsets[2]-offsets[0] ||
+			len(report.RemovedFiles) != 1 || report.RemovedCommands != 3 {
+			t.Errorf("修复结果不符合预期: %+v, %+v", report, report.Corruption)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("期望损坏位置之后的文件被删除")
+		}
+
+		report, err = Check(ctx)
+		if err != nil || report.Corruption != nil || report.KeptCommands != 1 {
+			t.Errorf("期望修复后的文件是完整的，实际得到 %+v, %v", report, err)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package aof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sparrow_blog_server/pkg/filetool"
+	"strings"
+)
+
+// RepairReport AOF 检查或修复的结果
+type RepairReport struct {
+	Files           int              // 检查的文件数量
+	Corruption      *CorruptionError // 第一处损坏，为 nil 时所有文件都是完整的
+	KeptCommands    int              // 损坏位置之前可以重放的命令数量
+	RemovedFiles    []string         // 位于损坏文件之后、修复时需要删除的文件
+	RemovedCommands int              // 被删除的文件中的命令数量
+}
+
+// Check 按时间顺序检查所有 AOF 文件，找出第一条不完整或校验失败的记录，不修改任何文件。
+// 旧格式的文件没有校验和，只能检查出无法解析的行；加载时会跳过这些行，检查与修复时将第一条无法解析的行视为损坏。
+//
+// 参数:
+// - ctx: 上下文
+//
+// 返回:
+// - *RepairReport: 检查结果
+// - error: 读取文件失败时返回错误
+func Check(ctx context.Context) (*RepairReport, error) {
+	return scanFiles(ctx, false)
+}
+
+// Repair 参考 redis-check-aof --fix，将 AOF 截断在第一条损坏的记录处：
+// 保留损坏位置之前的记录，丢弃损坏位置之后的内容，并删除之后轮转出的所有文件，
+// 避免跳过中间的记录后重放出与实际不一致的数据。
+// 修复前需要先停止服务。
+//
+// 参数:
+// - ctx: 上下文
+//
+// 返回:
+// - *RepairReport: 修复结果，记录了丢弃的内容
+// - error: 读取或修改文件失败时返回错误
+func Repair(ctx context.Context) (*RepairReport, error) {
+	return scanFiles(ctx, true)
+}
+
+func scanFiles(ctx context.Context, fix bool) (*RepairReport, error) {
+	fop, err := CreateFileOp()
+	if err != nil {
+		return nil, err
+	}
+	files, err := fop.listFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir := filepath.Join(filepath.Dir(fop.path), "temp_aof_repair")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("无法创建临时目录: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	report := &RepairReport{Files: len(files)}
+	for i, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		commands, err := processFile(f, tempDir, true)
+		report.KeptCommands += len(commands)
+		var corruption *CorruptionError
+		if !errors.As(err, &corruption) {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		report.Corruption = corruption
+		for _, later := range files[i+1:] {
+			laterCommands, err := processFile(later, tempDir, true)
+			if err != nil && !errors.As(err, new(*CorruptionError)) {
+				return nil, err
+			}
+			report.RemovedFiles = append(report.RemovedFiles, later)
+			report.RemovedCommands += len(laterCommands)
+		}
+
+		if fix {
+			if err := truncateFile(f, corruption.Offset, tempDir); err != nil {
+				return nil, err
+			}
+			for _, later := range report.RemovedFiles {
+				if err := os.Remove(later); err != nil {
+					return nil, fmt.Errorf("无法删除文件 %s: %w", later, err)
+				}
+			}
+		}
+		return report, nil
+	}
+
+	return report, nil
+}
+
+// truncateFile 将文件截断到指定长度，压缩文件先解压，截断后重新压缩并替换原文件
+func truncateFile(path string, size int64, tempDir string) error {
+	if !strings.HasSuffix(path, ".tar.gz") {
+		if err := os.Truncate(path, size); err != nil {
+			return fmt.Errorf("无法截断文件 %s: %w", path, err)
+		}
+		return nil
+	}
+
+	decompressed := filepath.Join(tempDir, strings.TrimSuffix(filepath.Base(path), ".tar.gz"))
+	if err := filetool.DecompressTarGz(path, decompressed); err != nil {
+		return fmt.Errorf("无法解压文件 %s: %w", path, err)
+	}
+	if err := os.Truncate(decompressed, size); err != nil {
+		return fmt.Errorf("无法截断文件 %s: %w", path, err)
+	}
+	compressed := decompressed + ".tar.gz"
+	if err := filetool.CompressFileToTarGz(decompressed, compressed); err != nil {
+		return fmt.Errorf("无法压缩文件 %s: %w", path, err)
+	}
+	if err := os.Rename(compressed, path); err != nil {
+		return fmt.Errorf("无法替换文件 %s: %w", path, err)
+	}
+	return nil
+}
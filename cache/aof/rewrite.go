@@ -0,0 +1,251 @@
+package aof
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// ErrRewriteInProgress 已有重写正在进行
+var ErrRewriteInProgress = errors.New("AOF rewrite already in progress")
+
+// ErrAofClosed AOF 已经关闭
+var ErrAofClosed = errors.New("AOF already closed")
+
+// BeginRewrite 开始重写 AOF，此后写入的命令在写入当前文件的同时被缓存。
+// 调用方需要保证生成快照与调用 BeginRewrite 之间没有其他写入，
+// 例如在持有缓存写锁（或阻塞写入的读锁）期间生成快照并调用本方法。
+//
+// 返回:
+// - error: 已有重写正在进行或 AOF 已关闭时返回错误
+func (aof *Aof) BeginRewrite() error {
+	aof.mu.Lock()
+	defer aof.mu.Unlock()
+
+	if aof.closed {
+		return ErrAofClosed
+	}
+	if aof.rewriting {
+		return ErrRewriteInProgress
+	}
+	aof.rewriting = true
+	aof.rewriteBuf = nil
+	return nil
+}
+
+// FinishRewrite 将快照写入新的基础文件，并用它替换当前的 AOF。
+// 处理过程:
+// 1. 不持有锁，将 BASE 标记与快照中的命令写入临时文件，期间新的写入照常进行
+// 2. 持有锁，将重写期间缓存的命令追加到临时文件并同步到磁盘
+// 3. 将临时文件原子地重命名为当前 AOF 文件，并删除已轮转的旧文件
+//
+// BASE 标记在重放时会清空之前的数据，因此即使在删除旧文件前崩溃，重放结果也是正确的。
+// 任何一步失败时放弃本次重写，当前的 AOF 不受影响。
+//
+// 参数:
+// - snapshot: 开始重写时的全部有效数据，每项为一条命令，第一个元素为命令名（标量为 SET，集合类型为 HSET、LPUSH、ZADD 与 EXPIRE）
+//
+// 返回:
+// - error: 重写过程中遇到的任何错误
+func (aof *Aof) FinishRewrite(snapshot [][]string) error {
+	base := make([][]string, 0, len(snapshot)+1)
+	base = append(base, []string{common.BASE, strconv.FormatInt(time.Now().Unix(), 10)})
+	return aof.finishRewrite(append(base, snapshot...))
+}
+
+// FinishSnapshot 在快照文件写入完成后替换当前的 AOF，过程与 FinishRewrite 相同，
+// 新文件以 SNAPSHOT 标记开头，之后只有开始生成快照以来的写入。
+// 重放时 SNAPSHOT 标记会清空之前的数据并加载对应的快照文件，因此快照文件必须先于此调用持久化。
+//
+// 参数:
+// - id: 快照的标识
+//
+// 返回:
+// - error: 替换过程中遇到的任何错误
+func (aof *Aof) FinishSnapshot(id string) error {
+	return aof.finishRewrite([][]string{{common.SNAPSHOT, id}})
+}
+
+// finishRewrite 将 base 中的命令写入新文件，追加重写期间的命令后替换当前文件
+func (aof *Aof) finishRewrite(base [][]string) error {
+	tmpPath := aof.file.path + ".rewrite"
+	start := time.Now()
+
+	file, size, err := writeBaseFile(tmpPath, base)
+	if err != nil {
+		aof.abortRewrite(tmpPath, file)
+		return err
+	}
+
+	aof.mu.Lock()
+	defer aof.mu.Unlock()
+
+	if aof.closed {
+		aof.abortRewriteLocked(tmpPath, file)
+		return ErrAofClosed
+	}
+
+	// 追加重写期间的命令
+	writer := bufio.NewWriterSize(file, 32*1024)
+	for _, record := range aof.rewriteBuf {
+		if _, err := writer.Write(record); err != nil {
+			aof.abortRewriteLocked(tmpPath, file)
+			return fmt.Errorf("write rewrite buffer failed: %w", err)
+		}
+		size += int64(len(record))
+	}
+	if err := writer.Flush(); err != nil {
+		aof.abortRewriteLocked(tmpPath, file)
+		return fmt.Errorf("flush rewrite file failed: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		aof.abortRewriteLocked(tmpPath, file)
+		return fmt.Errorf("sync rewrite file failed: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		aof.abortRewriteLocked(tmpPath, nil)
+		return fmt.Errorf("close rewrite file failed: %w", err)
+	}
+
+	if err := aof.file.replace(tmpPath); err != nil {
+		aof.abortRewriteLocked(tmpPath, nil)
+		return err
+	}
+
+	logger.Info("AOF 重写完成: 基础部分 %d 条命令，重写期间新增 %d 条命令，大小 %d -> %d 字节，耗时 %v",
+		len(base), len(aof.rewriteBuf), aof.size, size, time.Since(start))
+
+	aof.size = size
+	aof.baseSize = size
+	aof.rewrites++
+	aof.rewriting = false
+	aof.rewriteBuf = nil
+	return nil
+}
+
+// NeedRewrite 判断 AOF 是否需要重写
+// 当前大小不小于 minSize，且相对上次加载或重写后的大小增长超过 percentage% 时需要重写。
+//
+// 参数:
+// - percentage: 触发重写的增长百分比，小于等于 0 时不重写
+// - minSize: 触发重写的最小大小（字节）
+//
+// 返回:
+// - bool: 是否需要重写
+func (aof *Aof) NeedRewrite(percentage int, minSize int64) bool {
+	aof.mu.RLock()
+	defer aof.mu.RUnlock()
+
+	if percentage <= 0 || aof.rewriting || aof.closed || aof.size < minSize {
+		return false
+	}
+	return aof.size >= aof.baseSize+aof.baseSize*int64(percentage)/100
+}
+
+// MarkBase 将当前大小记为增长比例的基准，在启动时加载并重新持久化数据后调用
+func (aof *Aof) MarkBase() {
+	aof.mu.Lock()
+	defer aof.mu.Unlock()
+	aof.baseSize = aof.size
+}
+
+// writeBaseFile 将文件头与基础部分的命令写入临时文件，返回未关闭的文件与已写入的大小
+func writeBaseFile(path string, base [][]string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, 0, fmt.Errorf("create rewrite file failed: %w", err)
+	}
+
+	writer := bufio.NewWriterSize(file, 64*1024)
+	size, _ := writer.Write(fileHeader)
+	writeRecord := func(cmd string, args ...string) error {
+		record, err := formatCommand(cmd, args...)
+		if err != nil {
+			return err
+		}
+		n, err := writer.Write(record)
+		size += n
+		return err
+	}
+
+	for _, command := range base {
+		if len(command) == 0 {
+			return file, 0, errors.New("write rewrite file failed: empty command in snapshot")
+		}
+		if err := writeRecord(command[0], command[1:]...); err != nil {
+			return file, 0, fmt.Errorf("write rewrite file failed: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return file, 0, fmt.Errorf("flush rewrite file failed: %w", err)
+	}
+
+	return file, int64(size), nil
+}
+
+// AbortRewrite 放弃 BeginRewrite 开始的重写，在调用方生成快照失败时调用
+func (aof *Aof) AbortRewrite() {
+	aof.mu.Lock()
+	defer aof.mu.Unlock()
+	aof.rewriting = false
+	aof.rewriteBuf = nil
+}
+
+// abortRewrite 放弃本次重写并删除临时文件
+func (aof *Aof) abortRewrite(tmpPath string, file *os.File) {
+	aof.mu.Lock()
+	defer aof.mu.Unlock()
+	aof.abortRewriteLocked(tmpPath, file)
+}
+
+func (aof *Aof) abortRewriteLocked(tmpPath string, file *os.File) {
+	if file != nil {
+		_ = file.Close()
+	}
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		logger.Warn("无法删除 AOF 重写临时文件 %s: %v", tmpPath, err)
+	}
+	aof.rewriting = false
+	aof.rewriteBuf = nil
+}
+
+// replace 用重写生成的文件替换当前文件，并删除已轮转的旧文件
+func (fop *FileOp) replace(src string) error {
+	fop.rwMu.Lock()
+	defer fop.rwMu.Unlock()
+
+	if err := fop.closeLocked(); err != nil {
+		return err
+	}
+	if err := os.Rename(src, fop.path); err != nil {
+		// 重命名失败时重新打开原文件，继续在其后追加
+		_ = fop.ready()
+		return fmt.Errorf("failed to rename rewrite file: %w", err)
+	}
+
+	// 同步目录，确保重命名在崩溃后依然有效
+	dir := filepath.Dir(fop.path)
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	// 已轮转文件中的数据都已包含在新文件中
+	segments, err := filepath.Glob(filepath.Join(dir, fop.filePrefixName+"_*.aof.tar.gz"))
+	if err != nil {
+		logger.Warn("无法列出已轮转的 AOF 文件: %v", err)
+	}
+	for _, segment := range segments {
+		if err := os.Remove(segment); err != nil {
+			logger.Warn("无法删除已轮转的 AOF 文件 %s: %v", segment, err)
+		}
+	}
+
+	return fop.ready()
+}
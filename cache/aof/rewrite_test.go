@@ -0,0 +1,97 @@
+package aof
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"testing"
+)
+
+func TestAof_Rewrite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	oldPath := config.Cache.Aof.Path
+	config.Cache.Aof.Path = filepath.Join(dir, "rewrite.aof")
+	defer func() { config.Cache.Aof.Path = oldPath }()
+
+	aof := NewAof()
+	defer func() { _ = aof.Close() }()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := aof.Store(ctx, common.SET, key, "old", "4", "0"); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	_ = aof.Store(ctx, common.DELETE, "c")
+
+	// 模拟已轮转的旧文件，重写后应被删除
+	segment := filepath.Join(dir, "rewrite_1700000000.aof.tar.gz")
+	if err := os.WriteFile(segment, []byte("stale"), 0600); err != nil {
+		t.Fatalf("创建轮转文件失败: %v", err)
+	}
+
+	if err := aof.BeginRewrite(); err != nil {
+		t.Fatalf("开始重写失败: %v", err)
+	}
+	if err := aof.BeginRewrite(); err != ErrRewriteInProgress {
+		t.Errorf("期望重复开始重写返回 ErrRewriteInProgress，实际得到 %v", err)
+	}
+	// 重写期间的写入需要追加到新文件中
+	_ = aof.Store(ctx, common.SET, "d", "new", "4", "0")
+	_ = aof.Store(ctx, common.DELETE, "a")
+
+	snapshot := [][]string{{common.SET, "a", "old", "4", "0"}, {common.SET, "b", "old", "4", "0"}}
+	if err := aof.FinishRewrite(snapshot); err != nil {
+		t.Fatalf("完成重写失败: %v", err)
+	}
+	_ = aof.Store(ctx, common.SET, "e", "after", "4", "0")
+
+	if _, err := os.Stat(segment); !os.IsNotExist(err) {
+		t.Error("期望已轮转的旧文件被删除")
+	}
+	if aof.NeedRewrite(100, 0) {
+		t.Error("期望刚重写完成时不需要再次重写")
+	}
+
+	if err := aof.file.Close(); err != nil {
+		t.Fatalf("关闭文件失败: %v", err)
+	}
+	commands, err := processFile(config.Cache.Aof.Path, dir, true)
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	want := [][]string{
+		{common.SET, "a", "old", "4", "0"},
+		{common.SET, "b", "old", "4", "0"},
+		{common.SET, "d", "new", "4", "0"},
+		{common.DELETE, "a"},
+		{common.SET, "e", "after", "4", "0"},
+	}
+	if len(commands) != len(want)+1 || commands[0][0] != common.BASE {
+		t.Fatalf("重写后的文件内容不符合预期: %q", commands)
+	}
+	for i, command := range want {
+		if !slices.Equal(commands[i+1], command) {
+			t.Errorf("第 %d 条命令期望 %q，实际得到 %q", i+2, command, commands[i+1])
+		}
+	}
+}
+
+func TestAof_NeedRewrite(t *testing.T) {
+	aof := &Aof{size: 300, baseSize: 100}
+	if !aof.NeedRewrite(100, 200) {
+		t.Error("期望增长超过 100% 时需要重写")
+	}
+	if aof.NeedRewrite(100, 400) {
+		t.Error("期望小于最小大小时不需要重写")
+	}
+	if aof.NeedRewrite(300, 0) {
+		t.Error("期望增长未超过 300% 时不需要重写")
+	}
+	if aof.NeedRewrite(-1, 0) {
+		t.Error("期望百分比小于等于 0 时不重写")
+	}
+}
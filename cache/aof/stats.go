@@ -0,0 +1,75 @@
+package aof
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileStat AOF 目录中的一个文件
+type FileStat struct {
+	Name string `json:"name"` // 文件名
+	Size int64  `json:"size"` // 文件大小（字节）
+}
+
+// Stats AOF 的统计信息
+//
+// 字段:
+// - Files: 按时间顺序排列的 AOF 文件，已轮转的压缩文件在前，当前文件在最后
+// - TotalSize: 所有文件在磁盘上的大小之和
+// - Size: 上次加载或重写以来写入的大小，与 BaseSize 一起决定是否自动重写
+// - BaseSize: 上次加载或重写完成时的大小
+// - Rotations: 启动以来按大小轮转的次数
+// - Rewrites: 启动以来完成重写（包括生成快照）的次数
+// - Rewriting: 是否正在重写
+// - Fsync: 刷盘策略
+type Stats struct {
+	Files     []FileStat `json:"files"`
+	TotalSize int64      `json:"total_size"`
+	Size      int64      `json:"size"`
+	BaseSize  int64      `json:"base_size"`
+	Rotations int64      `json:"rotations"`
+	Rewrites  int64      `json:"rewrites"`
+	Rewriting bool       `json:"rewriting"`
+	Fsync     string     `json:"fsync"`
+}
+
+// Stats 返回 AOF 文件与重写的统计信息
+// 文件大小直接从磁盘读取，每次写入后都会刷新缓冲区，因此包含所有已写入的命令。
+//
+// 返回:
+// - *Stats: 统计信息
+// - error: 列出或读取文件信息失败时返回错误
+func (aof *Aof) Stats() (*Stats, error) {
+	aof.mu.RLock()
+	stats := &Stats{
+		Size:      aof.size,
+		BaseSize:  aof.baseSize,
+		Rewrites:  aof.rewrites,
+		Rewriting: aof.rewriting,
+	}
+	aof.mu.RUnlock()
+
+	aof.file.rwMu.RLock()
+	defer aof.file.rwMu.RUnlock()
+
+	stats.Rotations = aof.file.rotations
+	stats.Fsync = aof.file.fsync
+	files, err := aof.file.listFiles()
+	if err != nil {
+		return nil, err
+	}
+	stats.Files = make([]FileStat, 0, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat AOF file: %w", err)
+		}
+		stats.Files = append(stats.Files, FileStat{Name: filepath.Base(path), Size: info.Size()})
+		stats.TotalSize += info.Size()
+	}
+	return stats, nil
+}
@@ -0,0 +1,58 @@
+package aof
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"testing"
+)
+
+func TestAof_Stats(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Path = filepath.Join(dir, "stats.aof")
+	config.Cache.Aof.Compress = true
+	defer func() { config.Cache.Aof = oldAof }()
+
+	// 已轮转的压缩文件排在当前文件之前
+	segment := filepath.Join(dir, "stats_1700000000.aof.tar.gz")
+	if err := os.WriteFile(segment, []byte("segment"), 0600); err != nil {
+		t.Fatalf("创建轮转文件失败: %v", err)
+	}
+
+	aof := NewAof()
+	defer func() { _ = aof.Close() }()
+	_ = aof.Store(ctx, common.SET, "a", "1", "0", "0")
+
+	stats, err := aof.Stats()
+	if err != nil {
+		t.Fatalf("获取统计信息失败: %v", err)
+	}
+	if len(stats.Files) != 2 || stats.Files[0].Name != filepath.Base(segment) || stats.Files[1].Name != "stats.aof" {
+		t.Fatalf("期望列出轮转文件与当前文件，实际得到 %+v", stats.Files)
+	}
+	current, _ := os.Stat(config.Cache.Aof.Path)
+	if stats.Files[1].Size != current.Size() || stats.TotalSize != current.Size()+int64(len("segment")) {
+		t.Errorf("文件大小不符合预期: %+v", stats)
+	}
+	if stats.Size == 0 || stats.Rewrites != 0 || stats.Rotations != 0 {
+		t.Errorf("统计信息不符合预期: %+v", stats)
+	}
+
+	if err := aof.BeginRewrite(); err != nil {
+		t.Fatalf("开始重写失败: %v", err)
+	}
+	if stats, _ := aof.Stats(); !stats.Rewriting {
+		t.Error("期望重写期间 Rewriting 为 true")
+	}
+	if err := aof.FinishRewrite([][]string{{common.SET, "a", "1", "0", "0"}}); err != nil {
+		t.Fatalf("完成重写失败: %v", err)
+	}
+	stats, _ = aof.Stats()
+	if stats.Rewrites != 1 || stats.Rewriting || len(stats.Files) != 1 {
+		t.Errorf("期望重写后只剩当前文件且重写次数为 1，实际得到 %+v", stats)
+	}
+}
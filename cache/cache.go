@@ -3,17 +3,18 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"sparrow_blog_server/cache/aof"
 	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/cache/snapshot"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,24 +23,54 @@ type cacheItem struct {
 	value    any              // 实际存储的值
 	vt       common.ValueType // 存储值的类型信息
 	expireAt time.Time        // 过期时间戳（零值表示永不过期）
+	size     int64            // 估算的内存占用（字节）
+
+	accessAt atomic.Int64  // 最近访问时间（UnixNano），读锁下也可以更新
+	freq     atomic.Uint32 // LFU 访问频率计数，按对数增长并随空闲时间衰减
+}
+
+// expired 判断条目在 now 时是否已经过期
+func (item *cacheItem) expired(now time.Time) bool {
+	return !item.expireAt.IsZero() && now.After(item.expireAt)
 }
 
 // Cache 实现了一个带分片锁的线程安全内存缓存系统
 // 用于提高并发性能。
+// 键按哈希值划分到固定数量的分片中，每个分片有独立的锁与映射，访问不同分片的键互不阻塞；
+// 对同一个键的修改与对应的 AOF 记录在同一把分片锁下完成，AOF 中同一个键的记录顺序与内存中的修改顺序一致。
 //
 // 字段:
-// - items: 存储缓存条目的映射，使用字符串键（推荐格式："type:id"）
-// - mu: 用于线程安全操作的读写锁
-// - aof: 用于持久化支持的追加文件
+// - shards: 缓存分片，键的推荐格式为 "type:id"
+// - aof: 用于持久化支持的追加文件，只在持有分片锁时读取，关闭时锁住所有分片后置空
+// - count: 所有分片的条目总数
+// - usedBytes: 所有条目估算的内存占用
+// - eviction: 容量限制与淘汰策略
+// - stop: 关闭时通知后台协程退出
+// - background: 后台清理、AOF 重写等协程，关闭时等待其退出
+// - snapshotMu: 保证同一时间只生成一个快照
+// - stats: 按键前缀统计的命中、未命中与淘汰次数，只使用原子操作，不需要持有锁
+// - noEvict: 不参与容量淘汰的键前缀，例如已撤销的 token 等安全相关的状态
 type Cache struct {
-	items map[string]cacheItem
-	mu    sync.RWMutex
-	aof   *aof.Aof
+	shards     []*shard
+	aof        *aof.Aof
+	count      atomic.Int64
+	usedBytes  atomic.Int64
+	eviction   config.EvictionConfig
+	stop       chan struct{}
+	background sync.WaitGroup
+	snapshotMu sync.Mutex
+	stats      atomic.Pointer[statsTable]
+	noEvict    atomic.Pointer[[]string]
 }
 
 // NewCache 创建并初始化一个新的缓存实例，使用给定的上下文
 // 如果在Cache-config.yaml中配置了AOF，则启用持久化
 func NewCache(ctx context.Context) (*Cache, error) {
+	return newCache(ctx, defaultShardCount)
+}
+
+// newCache 使用指定的分片数量创建缓存，分片数量必须是 2 的幂
+func newCache(ctx context.Context, shardCount int) (*Cache, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -47,8 +78,11 @@ func NewCache(ctx context.Context) (*Cache, error) {
 	}
 
 	c := &Cache{
-		items: make(map[string]cacheItem),
+		shards:   newShards(shardCount),
+		eviction: config.Cache.Eviction,
+		stop:     make(chan struct{}),
 	}
+	c.stats.Store(newStatsTable(nil, shardCount))
 
 	// 如果配置了AOF，则启用
 	if config.Cache.Aof.Enable {
@@ -60,11 +94,20 @@ func NewCache(ctx context.Context) (*Cache, error) {
 		}
 	}
 
+	// 启动过期键的后台清理与 AOF 的自动重写
+	c.startSweeper(config.Cache.Expire)
+	if c.aof != nil {
+		c.startRewriter(config.Cache.Aof)
+	}
+
 	return c, nil
 }
 
 // loadAof 从AOF文件加载并重放命令以恢复缓存状态
-// 它按时间顺序处理SET、DELETE和CLEANUP命令
+// 它按时间顺序处理SET、DELETE、集合类型与EXPIRE命令
+// AOF 以 SNAPSHOT 标记开头时先加载对应的快照，只重放标记之后的命令；
+// 没有任何AOF命令但存在快照文件时（例如从备份恢复），加载最新的快照
+// 只在创建缓存时调用，此时没有其他协程访问缓存，重放期间不加锁
 func (c *Cache) loadAof(ctx context.Context) error {
 	if c.aof == nil {
 		return nil
@@ -75,6 +118,25 @@ func (c *Cache) loadAof(ctx context.Context) error {
 		return err
 	}
 
+	// BASE 与 SNAPSHOT 标记会清空之前的数据，只需从最后一个标记开始重放
+	for i := len(commands) - 1; i > 0; i-- {
+		if commands[i][0] == common.BASE || commands[i][0] == common.SNAPSHOT {
+			commands = commands[i:]
+			break
+		}
+	}
+	if len(commands) == 0 {
+		infos, err := snapshot.List()
+		if err != nil {
+			return err
+		}
+		if len(infos) > 0 {
+			latest := infos[len(infos)-1]
+			logger.Info("AOF 为空，从最新的缓存快照恢复: %s", latest.Path)
+			commands = [][]string{{common.SNAPSHOT, strconv.FormatInt(latest.ID, 10)}}
+		}
+	}
+
 	// 处理命令前检查上下文
 	select {
 	case <-ctx.Done():
@@ -109,94 +171,90 @@ func (c *Cache) loadAof(ctx context.Context) error {
 					}
 
 					// 根据类型转换值
-					var value any = cmd[2] // 默认为字符串
-					switch common.ValueType(vt) {
-					case common.INT:
-						if intVal, err := strconv.Atoi(cmd[2]); err == nil {
-							value = intVal
-						} else {
-							continue // 转换失败，跳过该条目
-						}
-					case common.UINT:
-						if uintVal, err := strconv.ParseUint(cmd[2], 10, 64); err == nil {
-							value = uint(uintVal)
-						} else {
-							continue // 转换失败，跳过该条目
-						}
-					case common.FLOAT:
-						if floatVal, err := strconv.ParseFloat(cmd[2], 64); err == nil {
-							value = floatVal
-						} else {
-							continue // 转换失败，跳过该条目
-						}
-					case common.STRING:
-						value = cmd[2] // 保持字符串类型
-					case common.OBJ:
-						value = cmd[2] // 保持字符串类型（JSON格式）
+					value, err := parseValue(common.ValueType(vt), cmd[2])
+					if err != nil {
+						continue // 转换失败，跳过该条目
 					}
 
 					// 创建缓存项
-					item := cacheItem{
+					item := &cacheItem{
 						value:    value,                // 转换后的值
 						vt:       common.ValueType(vt), // 转换为ValueType
 						expireAt: expireAt,
 					}
-					c.items[cmd[1]] = item
+					c.storeItemLocked(c.shardFor(cmd[1]), cmd[1], item)
 
-				case common.DELETE:
+				case common.DELETE, common.EVICT:
 					if len(cmd) != 2 {
 						continue
 					}
-					delete(c.items, cmd[1])
+					c.removeItemLocked(c.shardFor(cmd[1]), cmd[1])
 
 				case common.CLEANUP:
-					c.Cleanup()
+					// 是否过期取决于重放时的时间，重放期间不删除，加载完成后统一删除过期的条目，
+					// 避免集合类型在过期前的修改因提前删除而被重放为新建的集合
+
+				case common.HSET, common.LPUSH, common.LTRIM, common.ZADD, common.EXPIRE:
+					c.replayCollectionLocked(cmd)
+
+				case common.BASE:
+					// 重写生成的基础快照包含了此前的全部数据，丢弃之前重放的结果
+					c.clearLocked()
+
+				case common.SNAPSHOT:
+					id, err := strconv.ParseInt(cmd[1], 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid snapshot id in AOF: %s", cmd[1])
+					}
+					if err := c.loadSnapshotLocked(id); err != nil {
+						return fmt.Errorf("failed to load snapshot %d referenced by AOF: %w", id, err)
+					}
 				}
 			}
 		}
 	}
 
-	// 加载完数据后，需要将当前内存中的数据持久化到磁盘，保证缓存启动时，磁盘与内存中的数据一致
-	for k, v := range c.items {
-		if err := c.aof.Store(
-			ctx,
-			common.SET,
-			k,
-			fmt.Sprint(v.value),
-			fmt.Sprint(v.vt),
-			fmt.Sprint(v.expireAt.Unix()),
-		); err != nil {
-			return fmt.Errorf("failed to store in AOF: %w", err)
+	// 丢弃已经过期的条目，并在容量限制调小后淘汰多出的条目，再持久化剩余的数据
+	c.removeExpiredLocked()
+	for c.overLimit() {
+		key, item := c.evictionCandidate("")
+		if item == nil {
+			break
 		}
+		c.removeItemLocked(c.shardFor(key), key)
 	}
 
+	// 加载完数据后，需要将当前内存中的数据持久化到磁盘，保证缓存启动时，磁盘与内存中的数据一致
+	// 数据保存为快照，新的AOF只记录快照标记，下次启动时不必重放全部命令
+	if err := c.persistSnapshotLocked(ctx); err != nil {
+		return err
+	}
+	// 以重新持久化后的大小作为自动重写的增长基准
+	c.aof.MarkBase()
+
 	return nil
 }
 
 // Set 在缓存中存储一个值，不设置过期时间
+// 如果键已存在且未过期，保留原有的 TTL，防止将原有的 TTL 覆盖
 func (c *Cache) Set(ctx context.Context, key string, value any) error {
-	if len(strings.TrimSpace(key)) == 0 {
-		return ErrEmptyKey
-	}
-
-	item, ok := c.items[key]
-	if !ok {
-		// 如果键不存在，则设置TTL为零，表示不设置过期时间
-		return c.SetWithExpired(ctx, key, value, 0)
-	}
-
-	// 如果键已存在，将原有的 TTL 值保留，防止将原有的 TTL 覆盖
-	if !item.expireAt.IsZero() {
-		// 计算剩余过期时间
-		return c.SetWithExpired(ctx, key, value, time.Until(item.expireAt))
-	}
-
-	return c.SetWithExpired(ctx, key, value, 0)
+	return c.set(ctx, key, value, 0, true)
 }
 
 // SetWithExpired 在缓存中存储一个带有可选TTL的值
 // 如果键已存在，将被覆盖，TTL将被重置
 func (c *Cache) SetWithExpired(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return c.set(ctx, key, value, ttl, false)
+}
+
+// set 写入条目并记录到AOF，超过容量限制时淘汰其他条目
+// 参数:
+// - ctx: 上下文
+// - key: 条目键
+// - value: 条目值
+// - ttl: 过期时间，小于等于 0 表示不过期
+// - keepTTL: 是否保留已有条目的过期时间，为 true 时忽略 ttl
+func (c *Cache) set(ctx context.Context, key string, value any, ttl time.Duration, keepTTL bool) error {
 	if len(strings.TrimSpace(key)) == 0 {
 		return ErrEmptyKey
 	}
@@ -205,67 +263,95 @@ func (c *Cache) SetWithExpired(ctx context.Context, key string, value any, ttl t
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		c.mu.Lock()
-		defer c.mu.Unlock()
-
-		// 类型安全检查：不允许存储指针、数组或切片类型
-		if reflect.TypeOf(value).Kind() == reflect.Ptr ||
-			reflect.TypeOf(value).Kind() == reflect.Array ||
-			reflect.TypeOf(value).Kind() == reflect.Slice {
-			return ErrPointerNotAllowed
-		}
+	}
 
-		item := cacheItem{
-			value: value,
-		}
+	item, err := newItem(value)
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
 
-		// 设置过期时间
-		var expireTs int64
-		if ttl > 0 {
-			item.expireAt = time.Now().Add(ttl)
-			expireTs = item.expireAt.Unix()
-		}
+	s := c.shardFor(key)
+	s.mu.Lock()
+	if old, ok := s.items[key]; ok && keepTTL && !old.expired(time.Now()) {
+		item.expireAt = old.expireAt
+	}
+	err = c.storeLocked(ctx, s, key, item)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
-		// 设置值类型
-		switch value.(type) {
-		case int, int8, int16, int32, int64:
-			item.vt = common.INT
-		case uint, uint8, uint16, uint32, uint64:
-			item.vt = common.UINT
-		case float32, float64:
-			item.vt = common.FLOAT
-		case string:
-			item.vt = common.STRING
-		default:
-			// 对于其他类型，序列化为JSON字符串
-			jsonStr, err := json.Marshal(item.value)
-			if err != nil {
-				return err
-			}
-			item.vt = common.OBJ
-			item.value = jsonStr
-		}
+	// 超过容量限制时淘汰其他条目
+	return c.evict(ctx, key)
+}
 
-		c.items[key] = item
+// storeLocked 写入条目并记录到AOF，调用方需持有分片的写锁
+func (c *Cache) storeLocked(ctx context.Context, s *shard, key string, item *cacheItem) error {
+	c.storeItemLocked(s, key, item)
 
-		// 记录到AOF
-		if c.aof != nil {
-			if err := c.aof.Store(
-				ctx,
-				common.SET,
-				key,
-				fmt.Sprint(item.value),
-				fmt.Sprint(item.vt),
-				fmt.Sprint(expireTs),
-			); err != nil {
-				return fmt.Errorf("failed to store in AOF: %w", err)
-			}
+	// 记录到AOF
+	if c.aof != nil {
+		if err := c.aof.Store(ctx, common.SET, itemArgs(key, item)...); err != nil {
+			return fmt.Errorf("failed to store in AOF: %w", err)
 		}
+	}
+	return nil
+}
 
-		return nil
+// parseValue 将AOF或快照中的字符串按类型转换为缓存中的值，对象保持为JSON字符串
+func parseValue(vt common.ValueType, s string) (any, error) {
+	switch vt {
+	case common.INT:
+		return strconv.Atoi(s)
+	case common.UINT:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return uint(v), err
+	case common.FLOAT:
+		return strconv.ParseFloat(s, 64)
+	default:
+		return s, nil
 	}
 }
 
+// newItem 检查值的类型并创建缓存条目
+func newItem(value any) (*cacheItem, error) {
+	// 类型安全检查：不允许存储指针、数组或切片类型
+	if reflect.TypeOf(value).Kind() == reflect.Ptr ||
+		reflect.TypeOf(value).Kind() == reflect.Array ||
+		reflect.TypeOf(value).Kind() == reflect.Slice {
+		return nil, ErrPointerNotAllowed
+	}
+
+	item := &cacheItem{
+		value: value,
+	}
+
+	// 设置值类型
+	switch value.(type) {
+	case int, int8, int16, int32, int64:
+		item.vt = common.INT
+	case uint, uint8, uint16, uint32, uint64:
+		item.vt = common.UINT
+	case float32, float64:
+		item.vt = common.FLOAT
+	case string:
+		item.vt = common.STRING
+	default:
+		// 对于其他类型，序列化为JSON字符串
+		jsonStr, err := json.Marshal(item.value)
+		if err != nil {
+			return nil, err
+		}
+		item.vt = common.OBJ
+		item.value = jsonStr
+	}
+
+	return item, nil
+}
+
 // Incr 原子递增一个整数值
 // ctx    用于取消操作的上下文
 // key    条目键
@@ -273,43 +359,34 @@ func (c *Cache) SetWithExpired(ctx context.Context, key string, value any, ttl t
 // 返回:
 // - int   操作后的新值
 // - error 可能的错误:
-//   - ErrNotFound 键不存在
 //   - ErrTypeMismatch 值类型不是整数
 //   - ErrOutOfRange 值溢出
 //
 // 注意:
-// - 如果键不存在，它将返回ErrNotFound
+// - 如果键不存在，将创建它并设置为 1
 // - 操作会保持原始TTL时间不变
 func (c *Cache) Incr(ctx context.Context, key string) (int, error) {
-	select {
-	case <-ctx.Done():
-		return 0, ctx.Err()
-	default:
-		// 获取现有值，如果不存在则默认为0
-		val, err := c.GetInt(ctx, key)
+	var result int
+	err := c.update(ctx, key, func(value any, exists bool) (any, error) {
+		if !exists {
+			result = 1
+			return result, nil
+		}
+		val, err := toInt(value)
 		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				// 如果键不存在，创建一个新的
-				if err = c.Set(ctx, key, 1); err != nil {
-					return 0, err
-				}
-			} else {
-				return 0, err
-			}
+			return nil, err
 		}
-
 		// 溢出检查
 		if val == math.MaxInt {
-			return 0, ErrOutOfRange
+			return nil, ErrOutOfRange
 		}
-
-		setErr := c.Set(ctx, key, val+1)
-		if setErr != nil {
-			return 0, setErr
-		}
-
-		return val + 1, nil
+		result = val + 1
+		return result, nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return result, nil
 }
 
 // IncrUint 原子递增一个无符号整数值
@@ -319,43 +396,81 @@ func (c *Cache) Incr(ctx context.Context, key string) (int, error) {
 // 返回:
 // - uint  操作后的新值
 // - error 可能的错误:
-//   - ErrNotFound 键不存在
 //   - ErrTypeMismatch 值类型不是无符号整数
 //   - ErrOutOfRange 值溢出
 //
 // 注意:
-// - 如果键不存在，它将返回ErrNotFound
+// - 如果键不存在，将创建它并设置为 1
 // - 操作会保持原始TTL时间不变
 func (c *Cache) IncrUint(ctx context.Context, key string) (uint, error) {
-	select {
-	case <-ctx.Done():
-		return 0, ctx.Err()
-	default:
-		val, err := c.GetUint(ctx, key)
+	var result uint
+	err := c.update(ctx, key, func(value any, exists bool) (any, error) {
+		if !exists {
+			result = 1
+			return result, nil
+		}
+		val, err := toUint(value)
 		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				// 如果键不存在，创建一个新的
-				if err = c.Set(ctx, key, 1); err != nil {
-					return 0, err
-				}
-			} else {
-				return 0, err
-			}
+			return nil, err
 		}
-
 		// 溢出检查
 		if val == math.MaxUint {
-			return 0, ErrOutOfRange
+			return nil, ErrOutOfRange
 		}
+		result = val + 1
+		return result, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
 
-		// 计算剩余过期时间
-		setErr := c.Set(ctx, key, val+1)
-		if setErr != nil {
-			return 0, setErr
-		}
+// update 在分片锁内读取条目、计算新值并写回，读取与写入之间不会插入其他修改
+// 已过期的条目视为不存在；新值沿用原条目的过期时间
+// 参数:
+// - ctx: 上下文
+// - key: 条目键
+// - fn: 根据当前值计算新值，返回错误时不修改条目
+func (c *Cache) update(ctx context.Context, key string, fn func(value any, exists bool) (any, error)) error {
+	if len(strings.TrimSpace(key)) == 0 {
+		return ErrEmptyKey
+	}
 
-		return val + 1, nil
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s := c.shardFor(key)
+	s.mu.Lock()
+	old, exists := s.items[key]
+	if exists && old.expired(time.Now()) {
+		exists = false
+	}
+	var value any
+	if exists {
+		value = old.value
 	}
+
+	newValue, err := fn(value, exists)
+	if err == nil {
+		var item *cacheItem
+		item, err = newItem(newValue)
+		if err == nil {
+			if exists {
+				item.expireAt = old.expireAt
+			}
+			err = c.storeLocked(ctx, s, key, item)
+		}
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return c.evict(ctx, key)
 }
 
 // Get 检索缓存条目的原始值
@@ -364,31 +479,41 @@ func (c *Cache) IncrUint(ctx context.Context, key string) (uint, error) {
 //
 // 返回:
 // - any    原始存储的值
-// - error  操作过程中遇到的错误
+// - error  操作过程中遇到的错误，集合类型的条目需要使用对应的方法读取，返回 ErrTypeMismatch
 func (c *Cache) Get(ctx context.Context, key string) (any, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 		// 尝试最小化锁定范围
-		c.mu.RLock()
-		item, exists := c.items[key]
-		c.mu.RUnlock()
+		s := c.shardFor(key)
+		s.mu.RLock()
+		item, exists := s.items[key]
+		if exists && !item.expired(time.Now()) {
+			c.touch(item)
+			c.recordHit(key)
+		} else {
+			c.recordMiss(key)
+		}
+		s.mu.RUnlock()
 
 		if !exists {
 			return nil, NewNotFoundError("键不存在：" + key)
 		}
 
-		if item.expireAt.IsZero() {
-			return item.value, nil
-		} else if time.Now().After(item.expireAt) {
-			c.mu.Lock()
-			delete(c.items, key)
-			c.mu.Unlock()
+		if item.expired(time.Now()) {
+			s.mu.Lock()
+			// 加锁期间键可能已被重新设置，只删除过期的同一个条目
+			if s.items[key] == item {
+				c.removeItemLocked(s, key)
+			}
+			s.mu.Unlock()
 			return nil, ErrNotFound
-		} else {
-			return item.value, nil
 		}
+		if isCollection(item.vt) {
+			return nil, NewTypeMismatchError("集合类型的条目需要使用对应的方法读取：" + key)
+		}
+		return item.value, nil
 	}
 }
 
@@ -398,7 +523,11 @@ func (c *Cache) GetInt(ctx context.Context, key string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	return toInt(val)
+}
 
+// toInt 将缓存中的值转换为整数
+func toInt(val any) (int, error) {
 	switch v := val.(type) {
 	case int: // 直接返回原生int类型
 		return v, nil
@@ -443,7 +572,11 @@ func (c *Cache) GetUint(ctx context.Context, key string) (uint, error) {
 	if err != nil {
 		return 0, err
 	}
+	return toUint(val)
+}
 
+// toUint 将缓存中的值转换为无符号整数
+func toUint(val any) (uint, error) {
 	switch v := val.(type) {
 	case uint:
 		return v, nil
@@ -562,9 +695,13 @@ func (c *Cache) GetString(ctx context.Context, key string) (string, error) {
 }
 
 // GetKeysLike 获取所有键名中包含指定字符串的键
+// 按子串匹配，不支持通配符，并且一次遍历所有分片
+//
+// Deprecated: 使用 Scan 按 glob 模式增量遍历
+//
 // 参数:
 // - ctx: 用于取消操作的上下文
-// - matchStr: 要匹配的字符串，支持通配符*
+// - matchStr: 要匹配的子串
 //
 // 返回:
 // - []string: 所有匹配的键名
@@ -575,10 +712,14 @@ func (c *Cache) GetKeysLike(ctx context.Context, matchStr string) ([]string, err
 		return nil, ctx.Err()
 	default:
 		keys := make([]string, 0)
-		for key := range c.items {
-			if strings.Contains(key, matchStr) {
-				keys = append(keys, key)
+		for _, s := range c.shards {
+			s.mu.RLock()
+			for key := range s.items {
+				if strings.Contains(key, matchStr) {
+					keys = append(keys, key)
+				}
 			}
+			s.mu.RUnlock()
 		}
 		return keys, nil
 	}
@@ -591,10 +732,11 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		c.mu.Lock()
-		defer c.mu.Unlock()
+		s := c.shardFor(key)
+		s.mu.Lock()
+		defer s.mu.Unlock()
 
-		delete(c.items, key)
+		c.removeItemLocked(s, key)
 
 		// 记录到AOF
 		if c.aof != nil {
@@ -609,17 +751,12 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 
 // Cleanup 从缓存中删除所有过期的条目
 // 此操作在运行时会阻塞所有读/写操作
-// 建议在低流量期间运行
+// 建议在低流量期间运行，日常的过期清理由后台抽样完成
 func (c *Cache) Cleanup() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lockAll()
+	defer c.unlockAll()
 
-	now := time.Now()
-	for key, item := range c.items {
-		if !item.expireAt.IsZero() && now.After(item.expireAt) {
-			delete(c.items, key)
-		}
-	}
+	c.removeExpiredLocked()
 
 	// 记录到AOF
 	if c.aof != nil {
@@ -633,14 +770,35 @@ func (c *Cache) Cleanup() {
 
 // CleanAll 从缓存中删除所有条目，无论其过期状态如何
 func (c *Cache) CleanAll() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lockAll()
+	defer c.unlockAll()
 
-	for key := range c.items {
-		delete(c.items, key) // map删除操作
+	c.clearLocked()
+}
+
+// removeExpiredLocked 删除所有分片中过期的条目，调用方需锁住所有分片
+func (c *Cache) removeExpiredLocked() {
+	now := time.Now()
+	for _, s := range c.shards {
+		for key := range s.expires {
+			if s.items[key].expired(now) {
+				c.removeItemLocked(s, key)
+			}
+		}
 	}
 }
 
+// clearLocked 清空所有分片，调用方需锁住所有分片
+func (c *Cache) clearLocked() {
+	for _, s := range c.shards {
+		clear(s.items)
+		clear(s.expires)
+		s.usedBytes = 0
+	}
+	c.count.Store(0)
+	c.usedBytes.Store(0)
+}
+
 // Close 安全地关闭缓存并确保所有数据都被持久化到磁盘。
 // 应该在应用程序关闭时调用此方法以防止数据丢失。
 //
@@ -654,8 +812,11 @@ func (c *Cache) Close() error {
 		return nil
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	// 先停止后台协程，避免其在关闭后继续访问缓存
+	c.stopBackground()
+
+	c.lockAll()
+	defer c.unlockAll()
 
 	// 如果启用了AOF，关闭它以将数据刷新到磁盘
 	if c.aof != nil {
@@ -665,8 +826,14 @@ func (c *Cache) Close() error {
 		c.aof = nil
 	}
 
-	// 清理items映射
-	c.items = nil
+	// 清理所有分片
+	for _, s := range c.shards {
+		s.items = nil
+		s.expires = nil
+		s.usedBytes = 0
+	}
+	c.count.Store(0)
+	c.usedBytes.Store(0)
 
 	return nil
 }
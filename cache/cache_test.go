@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
+	"strings"
 	"testing"
 	"time"
 )
@@ -248,3 +251,175 @@ func TestCore_Clean(t *testing.T) {
 		}
 	})
 }
+
+func TestCore_Sweeper(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < 100; i++ {
+		_ = c.SetWithExpired(ctx, fmt.Sprintf("test:sweep_%d", i), "val", 20*time.Millisecond)
+	}
+	_ = c.Set(ctx, "test:sweep_persistent", "val")
+
+	// 不访问过期的键，由后台清理删除
+	time.Sleep(50 * time.Millisecond)
+	c.sweep(defaultSweepSampleSize, time.Second)
+
+	c.rLockAll()
+	defer c.rUnlockAll()
+	if _, ok := c.shardFor("test:sweep_0").items["test:sweep_0"]; ok {
+		t.Error("期望过期条目已被后台清理删除")
+	}
+	if _, ok := c.shardFor("test:sweep_persistent").items["test:sweep_persistent"]; !ok {
+		t.Error("期望永久条目仍然存在")
+	}
+	for _, s := range c.shards {
+		for key := range s.expires {
+			if strings.HasPrefix(key, "test:sweep_") {
+				t.Errorf("过期键集合中仍有已删除的键 %s", key)
+				break
+			}
+		}
+	}
+}
+
+func TestCore_Eviction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("LRU 按数量淘汰", func(t *testing.T) {
+		c, _ := NewCache(ctx)
+		defer func() { _ = c.Close() }()
+		c.CleanAll()
+		c.eviction = config.EvictionConfig{MaxEntries: 3, Policy: config.EvictionLRU, Samples: 10}
+
+		_ = c.Set(ctx, "test:lru_1", "val1")
+		time.Sleep(time.Millisecond)
+		_ = c.Set(ctx, "test:lru_2", "val2")
+		time.Sleep(time.Millisecond)
+		_ = c.Set(ctx, "test:lru_3", "val3")
+		time.Sleep(time.Millisecond)
+
+		// 访问第一个键，第二个键成为最久未访问的
+		_, _ = c.Get(ctx, "test:lru_1")
+		_ = c.Set(ctx, "test:lru_4", "val4")
+
+		if _, err := c.Get(ctx, "test:lru_2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("期望最久未访问的 test:lru_2 被淘汰，实际得到 %v", err)
+		}
+		for _, key := range []string{"test:lru_1", "test:lru_3", "test:lru_4"} {
+			if _, err := c.Get(ctx, key); err != nil {
+				t.Errorf("期望 %s 仍然存在，实际得到 %v", key, err)
+			}
+		}
+	})
+
+	t.Run("LFU 按内存淘汰", func(t *testing.T) {
+		c, _ := NewCache(ctx)
+		defer func() { _ = c.Close() }()
+		c.CleanAll()
+		c.eviction = config.EvictionConfig{MaxBytes: 2*(itemOverhead+100) + 50, Policy: config.EvictionLFU, Samples: 10}
+
+		value := fmt.Sprintf("%090d", 0)
+		_ = c.Set(ctx, "test:lfu_1", value)
+		_ = c.Set(ctx, "test:lfu_2", value)
+		for i := 0; i < 1000; i++ {
+			_, _ = c.Get(ctx, "test:lfu_1")
+		}
+		_ = c.Set(ctx, "test:lfu_3", value)
+
+		if _, err := c.Get(ctx, "test:lfu_2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("期望访问频率最低的 test:lfu_2 被淘汰，实际得到 %v", err)
+		}
+		if _, err := c.Get(ctx, "test:lfu_1"); err != nil {
+			t.Errorf("期望频繁访问的 test:lfu_1 仍然存在，实际得到 %v", err)
+		}
+		if c.usedBytes.Load() > c.eviction.MaxBytes {
+			t.Errorf("内存占用 %d 超过限制 %d", c.usedBytes.Load(), c.eviction.MaxBytes)
+		}
+	})
+
+	t.Run("不参与淘汰的键", func(t *testing.T) {
+		c, _ := NewCache(ctx)
+		defer func() { _ = c.Close() }()
+		c.CleanAll()
+		c.eviction = config.EvictionConfig{MaxEntries: 3, Policy: config.EvictionLRU, Samples: 10}
+		c.SetNoEvictPrefixes("test:protected_")
+
+		_ = c.Set(ctx, "test:protected_1", "val")
+		_ = c.SetWithExpired(ctx, "test:protected_2", "val", time.Hour)
+		for i := 0; i < 100; i++ {
+			_ = c.Set(ctx, fmt.Sprintf("test:evictable_%d", i), "val")
+		}
+
+		for _, key := range []string{"test:protected_1", "test:protected_2"} {
+			if _, err := c.Get(ctx, key); err != nil {
+				t.Errorf("期望 %s 不被淘汰，实际得到 %v", key, err)
+			}
+		}
+		if c.count.Load() != 3 {
+			t.Errorf("期望只淘汰其它键并保持在限制内，实际有 %d 个键", c.count.Load())
+		}
+
+		// 只剩下不参与淘汰的键时允许超过限制
+		_ = c.Set(ctx, "test:protected_3", "val")
+		_ = c.Set(ctx, "test:protected_4", "val")
+		if _, err := c.Get(ctx, "test:protected_1"); err != nil {
+			t.Errorf("期望 test:protected_1 不被淘汰，实际得到 %v", err)
+		}
+	})
+}
+
+func TestCore_RewriteAof(t *testing.T) {
+	ctx := context.Background()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Enable = true
+	config.Cache.Aof.Path = filepath.Join(t.TempDir(), "rewrite.aof")
+	defer func() { config.Cache.Aof = oldAof }()
+
+	c, _ := NewCache(ctx)
+	for i := 0; i < 100; i++ {
+		_ = c.Set(ctx, "test:rewrite", i)
+		_ = c.Set(ctx, fmt.Sprintf("test:rewrite_tmp_%d", i), "val")
+		_ = c.Delete(ctx, fmt.Sprintf("test:rewrite_tmp_%d", i))
+	}
+	_ = c.SetWithExpired(ctx, "test:rewrite_ttl", "val", time.Hour)
+	_ = c.SetWithExpired(ctx, "test:rewrite_expired", "val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	before := fileSize(t, config.Cache.Aof.Path)
+	if err := c.RewriteAof(ctx); err != nil {
+		t.Fatalf("重写 AOF 失败: %v", err)
+	}
+	after := fileSize(t, config.Cache.Aof.Path)
+	if after >= before {
+		t.Errorf("期望重写后文件变小，重写前 %d 字节，重写后 %d 字节", before, after)
+	}
+	_ = c.Set(ctx, "test:rewrite_after", "val")
+	_ = c.Close()
+
+	// 重新加载，数据应与重写前一致
+	c, _ = NewCache(ctx)
+	defer func() { _ = c.Close() }()
+	if val, err := c.GetInt(ctx, "test:rewrite"); err != nil || val != 99 {
+		t.Errorf("期望 test:rewrite 为 99，实际得到 %v, %v", val, err)
+	}
+	for _, key := range []string{"test:rewrite_ttl", "test:rewrite_after"} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("期望 %s 仍然存在，实际得到 %v", key, err)
+		}
+	}
+	for _, key := range []string{"test:rewrite_tmp_0", "test:rewrite_expired"} {
+		if _, err := c.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("期望 %s 不存在，实际得到 %v", key, err)
+		}
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("读取文件信息失败: %v", err)
+	}
+	return info.Size()
+}
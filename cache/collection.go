@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sparrow_blog_server/cache/common"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// elementOverhead 集合中每个元素在内容之外的估算开销
+const elementOverhead = 16
+
+// 集合类型的 AOF 记录与重放:
+// - 修改记录为与当前状态无关的结果（HINCRBY 记录为 HSET，ZINCRBY 记录为 ZADD），重放时不依赖计算顺序
+// - 重放时不判断过期，过期的条目在加载完成后统一删除，因此重放结果不受重放时间的影响
+// - 运行时新建集合前先写入 DELETE，重放时即使旧的集合因未记录的过期删除而残留，也会从空集合开始
+
+// isCollection 判断值类型是否为集合类型
+func isCollection(vt common.ValueType) bool {
+	return vt == common.HASH || vt == common.LIST || vt == common.ZSET
+}
+
+// newCollection 创建指定类型的空集合
+// LIST 以切片逆序存储，切片末尾为表头，LPUSH 只需追加
+func newCollection(vt common.ValueType) any {
+	switch vt {
+	case common.HASH:
+		return make(map[string]string)
+	case common.ZSET:
+		return make(map[string]float64)
+	default:
+		return []string(nil)
+	}
+}
+
+// collectionLen 返回集合中的元素个数
+func collectionLen(item *cacheItem) int {
+	switch v := item.value.(type) {
+	case map[string]string:
+		return len(v)
+	case map[string]float64:
+		return len(v)
+	case []string:
+		return len(v)
+	}
+	return 0
+}
+
+// typeMismatch 返回键的类型与操作不符时的错误
+func typeMismatch(key string, vt common.ValueType) error {
+	return NewTypeMismatchError(fmt.Sprintf("键 %s 的类型与操作不符，期望类型 %d", key, vt))
+}
+
+// readCollection 以读锁查找集合条目并调用 fn，键不存在或已过期时 fn 的参数为 nil
+// 参数:
+// - ctx: 上下文
+// - key: 条目键
+// - vt: 期望的集合类型
+// - fn: 读取集合内容，调用期间持有分片的读锁
+//
+// 返回:
+// - error: 键为空、上下文取消或类型不符时返回错误
+func (c *Cache) readCollection(ctx context.Context, key string, vt common.ValueType, fn func(item *cacheItem) error) error {
+	if len(strings.TrimSpace(key)) == 0 {
+		return ErrEmptyKey
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s := c.shardFor(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, exists := s.items[key]
+	if !exists || item.expired(time.Now()) {
+		c.recordMiss(key)
+		return fn(nil)
+	}
+	if item.vt != vt {
+		return typeMismatch(key, vt)
+	}
+	c.touch(item)
+	c.recordHit(key)
+	return fn(item)
+}
+
+// modifyCollection 在分片锁内修改集合条目，修改后超过容量限制时淘汰其他条目
+// 已过期的条目视为不存在；修改保留原有的过期时间，新建的集合没有过期时间；修改后集合为空时删除该键
+// 参数:
+// - ctx: 上下文
+// - key: 条目键
+// - vt: 期望的集合类型
+// - create: 键不存在时是否新建空集合，为 false 时 fn 的参数可能为 nil
+// - fn: 修改集合内容并写入 AOF，返回错误时已完成的修改保留
+//
+// 返回:
+// - error: 键为空、上下文取消、类型不符、fn 或写入 AOF 失败时返回错误
+func (c *Cache) modifyCollection(ctx context.Context, key string, vt common.ValueType, create bool,
+	fn func(s *shard, item *cacheItem) error) error {
+	if len(strings.TrimSpace(key)) == 0 {
+		return ErrEmptyKey
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s := c.shardFor(key)
+	s.mu.Lock()
+	err := c.modifyCollectionLocked(ctx, s, key, vt, create, fn)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return c.evict(ctx, key)
+}
+
+func (c *Cache) modifyCollectionLocked(ctx context.Context, s *shard, key string, vt common.ValueType, create bool,
+	fn func(s *shard, item *cacheItem) error) error {
+	item, exists := s.items[key]
+	if exists && item.expired(time.Now()) {
+		c.removeItemLocked(s, key)
+		exists = false
+	}
+	if exists && item.vt != vt {
+		return typeMismatch(key, vt)
+	}
+
+	if !exists {
+		if !create {
+			return fn(s, nil)
+		}
+		if err := c.appendAof(ctx, common.DELETE, key); err != nil {
+			return err
+		}
+		item = &cacheItem{value: newCollection(vt), vt: vt}
+		c.storeItemLocked(s, key, item)
+	} else {
+		c.touch(item)
+	}
+
+	err := fn(s, item)
+	if s.items[key] == item && collectionLen(item) == 0 {
+		c.removeItemLocked(s, key)
+	}
+	return err
+}
+
+// resizeLocked 调整条目估算的内存占用，调用方需持有分片的写锁
+func (c *Cache) resizeLocked(s *shard, item *cacheItem, delta int64) {
+	item.size += delta
+	s.usedBytes += delta
+	c.usedBytes.Add(delta)
+}
+
+// setExpireLocked 设置条目的过期时间并维护过期键集合，零值表示永不过期，调用方需持有分片的写锁
+func (c *Cache) setExpireLocked(s *shard, key string, item *cacheItem, expireAt time.Time) {
+	item.expireAt = expireAt
+	if expireAt.IsZero() {
+		delete(s.expires, key)
+	} else {
+		s.expires[key] = struct{}{}
+	}
+}
+
+// appendAof 将命令记录到AOF，未启用AOF时不做任何事
+func (c *Cache) appendAof(ctx context.Context, cmd string, args ...string) error {
+	if c.aof == nil {
+		return nil
+	}
+	if err := c.aof.Store(ctx, cmd, args...); err != nil {
+		return fmt.Errorf("failed to store in AOF: %w", err)
+	}
+	return nil
+}
+
+// Expire 设置条目的过期时间，适用于所有类型的条目
+// ctx  用于取消操作的上下文
+// key  条目键
+// ttl  从现在起的过期时间，小于等于 0 表示取消过期时间
+//
+// 返回:
+// - error  键不存在或已过期时返回 ErrNotFound
+func (c *Cache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, exists := s.items[key]
+	if !exists || item.expired(time.Now()) {
+		return NewNotFoundError("键不存在：" + key)
+	}
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+	c.setExpireLocked(s, key, item, expireAt)
+	return c.appendAof(ctx, common.EXPIRE, key, expireArg(expireAt))
+}
+
+// expireArg 将过期时间转换为 AOF 中的参数，永不过期时为 0
+func expireArg(expireAt time.Time) string {
+	if expireAt.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(expireAt.Unix(), 10)
+}
+
+// itemCommands 将条目转换为重放后可以恢复该条目的 AOF 命令，第一个元素为命令名
+// 标量为一条 SET 命令；集合类型每个元素一条命令，设置了过期时间时再追加一条 EXPIRE 命令
+func itemCommands(key string, item *cacheItem) [][]string {
+	if !isCollection(item.vt) {
+		return [][]string{append([]string{common.SET}, itemArgs(key, item)...)}
+	}
+
+	var commands [][]string
+	switch v := item.value.(type) {
+	case map[string]string:
+		for field, value := range v {
+			commands = append(commands, []string{common.HSET, key, field, value})
+		}
+	case map[string]float64:
+		for member, score := range v {
+			commands = append(commands, []string{common.ZADD, key, member, formatScore(score)})
+		}
+	case []string:
+		// 从表尾到表头依次 LPUSH，重放后顺序不变
+		for _, value := range v {
+			commands = append(commands, []string{common.LPUSH, key, value})
+		}
+	}
+	if !item.expireAt.IsZero() {
+		commands = append(commands, []string{common.EXPIRE, key, expireArg(item.expireAt)})
+	}
+	return commands
+}
+
+// replayCollectionLocked 重放集合类型与 EXPIRE 命令，参数无效或类型不符的命令被跳过
+// 只在加载AOF时调用，不判断过期，也不写入AOF
+func (c *Cache) replayCollectionLocked(cmd []string) {
+	key := cmd[1]
+	s := c.shardFor(key)
+
+	if cmd[0] == common.EXPIRE {
+		item, exists := s.items[key]
+		ts, err := strconv.ParseInt(cmd[2], 10, 64)
+		if !exists || err != nil {
+			return
+		}
+		var expireAt time.Time
+		if ts != 0 {
+			expireAt = time.Unix(ts, 0)
+		}
+		c.setExpireLocked(s, key, item, expireAt)
+		return
+	}
+
+	vt := map[string]common.ValueType{
+		common.HSET:  common.HASH,
+		common.LPUSH: common.LIST,
+		common.LTRIM: common.LIST,
+		common.ZADD:  common.ZSET,
+	}[cmd[0]]
+
+	item, exists := s.items[key]
+	if exists && item.vt != vt {
+		return
+	}
+	if !exists {
+		if cmd[0] == common.LTRIM {
+			return
+		}
+		item = &cacheItem{value: newCollection(vt), vt: vt}
+		c.storeItemLocked(s, key, item)
+	}
+
+	switch cmd[0] {
+	case common.HSET:
+		c.hsetLocked(s, item, cmd[2], cmd[3])
+	case common.LPUSH:
+		c.lpushLocked(s, item, cmd[2])
+	case common.LTRIM:
+		start, err1 := strconv.Atoi(cmd[2])
+		stop, err2 := strconv.Atoi(cmd[3])
+		if err1 == nil && err2 == nil {
+			c.ltrimLocked(s, item, start, stop)
+		}
+	case common.ZADD:
+		if score, err := strconv.ParseFloat(cmd[3], 64); err == nil {
+			c.zaddLocked(s, item, cmd[2], score)
+		}
+	}
+
+	if collectionLen(item) == 0 {
+		c.removeItemLocked(s, key)
+	}
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestCollection_Hash(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+
+	_ = c.HSet(ctx, "test:hash", "title", "hello")
+	if val, err := c.HGet(ctx, "test:hash", "title"); err != nil || val != "hello" {
+		t.Errorf("期望 title 为 hello，实际得到 %q, %v", val, err)
+	}
+	if _, err := c.HGet(ctx, "test:hash", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望不存在的字段返回 ErrNotFound，实际得到 %v", err)
+	}
+	if _, err := c.HGet(ctx, "test:hash_missing", "title"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望不存在的键返回 ErrNotFound，实际得到 %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = c.HIncrBy(ctx, "test:hash", "2024-01-01", 2)
+	}
+	if val, err := c.HIncrBy(ctx, "test:hash", "2024-01-01", -1); err != nil || val != 5 {
+		t.Errorf("期望计数为 5，实际得到 %d, %v", val, err)
+	}
+	if _, err := c.HIncrBy(ctx, "test:hash", "title", 1); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("期望对非整数字段递增返回 ErrTypeMismatch，实际得到 %v", err)
+	}
+	_ = c.HSet(ctx, "test:hash", "max", "9223372036854775807")
+	if _, err := c.HIncrBy(ctx, "test:hash", "max", 1); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("期望溢出返回 ErrOutOfRange，实际得到 %v", err)
+	}
+
+	// 不同类型之间的操作
+	if _, err := c.Get(ctx, "test:hash"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("期望 Get 集合类型返回 ErrTypeMismatch，实际得到 %v", err)
+	}
+	_ = c.Set(ctx, "test:hash_string", "val")
+	if err := c.HSet(ctx, "test:hash_string", "f", "v"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("期望对字符串执行 HSet 返回 ErrTypeMismatch，实际得到 %v", err)
+	}
+	if _, err := c.LPush(ctx, "test:hash", "v"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("期望对哈希表执行 LPush 返回 ErrTypeMismatch，实际得到 %v", err)
+	}
+}
+
+func TestCollection_List(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+
+	if n, err := c.LPush(ctx, "test:list", "a", "b", "c"); err != nil || n != 3 {
+		t.Fatalf("期望列表长度为 3，实际得到 %d, %v", n, err)
+	}
+	_, _ = c.LPush(ctx, "test:list", "d")
+
+	tests := []struct {
+		start, stop int
+		want        []string
+	}{
+		{0, -1, []string{"d", "c", "b", "a"}},
+		{0, 1, []string{"d", "c"}},
+		{-2, -1, []string{"b", "a"}},
+		{1, 100, []string{"c", "b", "a"}},
+		{-100, 0, []string{"d"}},
+		{3, 1, []string{}},
+		{10, 20, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := c.LRange(ctx, "test:list", tt.start, tt.stop)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("LRange(%d, %d) 期望 %q，实际得到 %q, %v", tt.start, tt.stop, tt.want, got, err)
+		}
+	}
+
+	_ = c.LTrim(ctx, "test:list", 0, 1)
+	if got, _ := c.LRange(ctx, "test:list", 0, -1); !slices.Equal(got, []string{"d", "c"}) {
+		t.Errorf("期望裁剪后为 [d c]，实际得到 %q", got)
+	}
+	s := c.shardFor("test:list")
+	if size, expected := s.items["test:list"].size, estimateSize("test:list", []string{"c", "d"}); size != expected {
+		t.Errorf("期望内存占用为 %d，实际得到 %d", expected, size)
+	}
+
+	// 裁剪为空时删除该键
+	_ = c.LTrim(ctx, "test:list", 5, 10)
+	if got, err := c.LRange(ctx, "test:list", 0, -1); err != nil || len(got) != 0 {
+		t.Errorf("期望裁剪为空后返回空列表，实际得到 %q, %v", got, err)
+	}
+	if _, ok := s.items["test:list"]; ok {
+		t.Error("期望裁剪为空后键被删除")
+	}
+}
+
+func TestCollection_ZSet(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+
+	_, _ = c.ZIncrBy(ctx, "test:zset", "post:1", 3)
+	_, _ = c.ZIncrBy(ctx, "test:zset", "post:2", 5)
+	_, _ = c.ZIncrBy(ctx, "test:zset", "post:3", 1)
+	if score, err := c.ZIncrBy(ctx, "test:zset", "post:3", 2.5); err != nil || score != 3.5 {
+		t.Errorf("期望分数为 3.5，实际得到 %v, %v", score, err)
+	}
+	_, _ = c.ZIncrBy(ctx, "test:zset", "post:0", 3)
+
+	got, err := c.ZRange(ctx, "test:zset", 0, -1, false)
+	want := []ZMember{{"post:0", 3}, {"post:1", 3}, {"post:3", 3.5}, {"post:2", 5}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("期望按分数升序为 %v，实际得到 %v, %v", want, got, err)
+	}
+	got, _ = c.ZRange(ctx, "test:zset", 0, 1, true)
+	want = []ZMember{{"post:2", 5}, {"post:3", 3.5}}
+	if !slices.Equal(got, want) {
+		t.Errorf("期望分数最高的两个为 %v，实际得到 %v", want, got)
+	}
+
+	if _, err := c.ZIncrBy(ctx, "test:zset", "post:1", math.NaN()); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("期望 NaN 增量返回 ErrOutOfRange，实际得到 %v", err)
+	}
+	if got, err := c.ZRange(ctx, "test:zset_missing", 0, -1, false); err != nil || len(got) != 0 {
+		t.Errorf("期望不存在的键返回空切片，实际得到 %v, %v", got, err)
+	}
+}
+
+func TestCollection_Expire(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+
+	if err := c.Expire(ctx, "test:expire_missing", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望对不存在的键设置过期时间返回 ErrNotFound，实际得到 %v", err)
+	}
+
+	// 修改保留原有的过期时间
+	_ = c.HSet(ctx, "test:expire", "f", "1")
+	_ = c.Expire(ctx, "test:expire", 50*time.Millisecond)
+	_, _ = c.HIncrBy(ctx, "test:expire", "f", 1)
+	time.Sleep(80 * time.Millisecond)
+	if _, err := c.HGet(ctx, "test:expire", "f"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望修改后仍按原有的过期时间过期，实际得到 %v", err)
+	}
+
+	// 过期后重新写入的集合从空集合开始，且没有过期时间
+	if val, err := c.HIncrBy(ctx, "test:expire", "f", 1); err != nil || val != 1 {
+		t.Errorf("期望过期后重新计数为 1，实际得到 %d, %v", val, err)
+	}
+	s := c.shardFor("test:expire")
+	if !s.items["test:expire"].expireAt.IsZero() {
+		t.Error("期望重新创建的集合没有过期时间")
+	}
+
+	// 取消过期时间
+	_, _ = c.LPush(ctx, "test:expire_list", "a")
+	_ = c.Expire(ctx, "test:expire_list", time.Millisecond)
+	_ = c.Expire(ctx, "test:expire_list", 0)
+	time.Sleep(5 * time.Millisecond)
+	if got, _ := c.LRange(ctx, "test:expire_list", 0, -1); len(got) != 1 {
+		t.Errorf("期望取消过期时间后列表仍然存在，实际得到 %q", got)
+	}
+}
+
+func TestCollection_Aof(t *testing.T) {
+	ctx := context.Background()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Enable = true
+	config.Cache.Aof.Path = filepath.Join(t.TempDir(), "collection.aof")
+	defer func() { config.Cache.Aof = oldAof }()
+
+	c, _ := NewCache(ctx)
+	_ = c.HSet(ctx, "test:aof_hash", "title", "含有;;分隔符\n的值")
+	_, _ = c.HIncrBy(ctx, "test:aof_hash", "views", 10)
+	_, _ = c.LPush(ctx, "test:aof_list", "a", "b", "c", "d")
+	_ = c.LTrim(ctx, "test:aof_list", 0, 2)
+	first, second := 0.1, 0.2
+	_, _ = c.ZIncrBy(ctx, "test:aof_zset", "post:1", first)
+	_, _ = c.ZIncrBy(ctx, "test:aof_zset", "post:1", second)
+	_, _ = c.ZIncrBy(ctx, "test:aof_zset", "post:2", 1)
+	_ = c.Expire(ctx, "test:aof_zset", time.Hour)
+
+	// 过期前修改、过期后重新创建，重放结果都应与运行时一致
+	_ = c.HSet(ctx, "test:aof_expired", "old", "1")
+	_ = c.Expire(ctx, "test:aof_expired", time.Second)
+	_ = c.HSet(ctx, "test:aof_expired", "old", "2")
+	_, _ = c.LPush(ctx, "test:aof_recreated", "old")
+	_ = c.Expire(ctx, "test:aof_recreated", time.Second)
+	time.Sleep(1100 * time.Millisecond)
+	_, _ = c.LPush(ctx, "test:aof_recreated", "new")
+	c.Cleanup()
+	_ = c.Close()
+
+	check := func(c *Cache) {
+		t.Helper()
+		if val, err := c.HGet(ctx, "test:aof_hash", "title"); err != nil || val != "含有;;分隔符\n的值" {
+			t.Errorf("期望 title 被恢复，实际得到 %q, %v", val, err)
+		}
+		if val, err := c.HIncrBy(ctx, "test:aof_hash", "views", 0); err != nil || val != 10 {
+			t.Errorf("期望 views 为 10，实际得到 %d, %v", val, err)
+		}
+		if got, _ := c.LRange(ctx, "test:aof_list", 0, -1); !slices.Equal(got, []string{"d", "c", "b"}) {
+			t.Errorf("期望列表为 [d c b]，实际得到 %q", got)
+		}
+		got, _ := c.ZRange(ctx, "test:aof_zset", 0, -1, true)
+		if want := []ZMember{{"post:2", 1}, {"post:1", first + second}}; !slices.Equal(got, want) {
+			t.Errorf("期望有序集合为 %v，实际得到 %v", want, got)
+		}
+		if s := c.shardFor("test:aof_zset"); s.items["test:aof_zset"].expireAt.IsZero() {
+			t.Error("期望有序集合的过期时间被恢复")
+		}
+		if _, err := c.HGet(ctx, "test:aof_expired", "old"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("期望过期的哈希表不被恢复，实际得到 %v", err)
+		}
+		if got, _ := c.LRange(ctx, "test:aof_recreated", 0, -1); !slices.Equal(got, []string{"new"}) {
+			t.Errorf("期望重新创建的列表只有 [new]，实际得到 %q", got)
+		}
+	}
+
+	c, _ = NewCache(ctx)
+	check(c)
+	// 重写后再次加载，结果不变
+	if err := c.RewriteAof(ctx); err != nil {
+		t.Fatalf("重写 AOF 失败: %v", err)
+	}
+	_ = c.Close()
+	c, _ = NewCache(ctx)
+	defer func() { _ = c.Close() }()
+	check(c)
+}
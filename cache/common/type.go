@@ -8,11 +8,46 @@ const (
 	FLOAT
 	STRING
 	OBJ
+	HASH // 字段到字符串值的映射
+	LIST // 字符串列表
+	ZSET // 按分数排序的成员集合
 )
 
+var valueTypeNames = [...]string{
+	INT:    "int",
+	UINT:   "uint",
+	FLOAT:  "float",
+	STRING: "string",
+	OBJ:    "object",
+	HASH:   "hash",
+	LIST:   "list",
+	ZSET:   "zset",
+}
+
+// Name 返回值类型的名称，用于统计与调试输出
+// 不实现 fmt.Stringer，AOF 中的类型按数字记录
+func (vt ValueType) Name() string {
+	if int(vt) < len(valueTypeNames) {
+		return valueTypeNames[vt]
+	}
+	return "unknown"
+}
+
 const (
 	SET     = "SET"
 	DELETE  = "DELETE"
 	INCR    = "INCR"
 	CLEANUP = "CLEANUP"
+	EVICT   = "EVICT"  // 因容量限制被淘汰，重放时与 DELETE 相同
+	BASE    = "BASE"   // 重写生成的基础快照的开头，重放时先清空之前的所有数据
+	EXPIRE  = "EXPIRE" // 设置过期时间，过期时间为 0 表示取消过期
+
+	// SNAPSHOT 生成快照后新 AOF 的开头，参数为快照的标识，重放时先清空之前的所有数据再加载对应的快照
+	SNAPSHOT = "SNAPSHOT"
+
+	// 集合类型的命令，HINCRBY 与 ZINCRBY 按计算后的结果记录为 HSET 与 ZADD，重放时不依赖之前的值
+	HSET  = "HSET"
+	LPUSH = "LPUSH"
+	LTRIM = "LTRIM"
+	ZADD  = "ZADD"
 )
@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEvictionSamples = 5 // 默认每次淘汰时抽样比较的键数量
+
+	itemOverhead = 64 // 每个条目在键和值之外的估算开销（map 桶、条目结构体等）
+
+	lfuInitVal   = 5           // 新条目的访问频率计数，避免刚写入就被淘汰
+	lfuLogFactor = 10          // 计数越大增长越慢，255 约对应百万次访问
+	lfuDecayTime = time.Minute // 每空闲这么久计数减一
+)
+
+// storeItemLocked 写入条目并维护过期键集合与内存占用，调用方需持有分片的写锁
+func (c *Cache) storeItemLocked(s *shard, key string, item *cacheItem) {
+	item.size = estimateSize(key, item.value)
+	if old, ok := s.items[key]; ok {
+		// 覆盖写入视为一次访问，沿用原条目的访问频率
+		s.usedBytes -= old.size
+		c.usedBytes.Add(-old.size)
+		item.accessAt.Store(old.accessAt.Load())
+		item.freq.Store(old.freq.Load())
+		c.touch(item)
+	} else {
+		item.accessAt.Store(time.Now().UnixNano())
+		item.freq.Store(lfuInitVal)
+		c.count.Add(1)
+	}
+
+	s.items[key] = item
+	s.usedBytes += item.size
+	c.usedBytes.Add(item.size)
+	if item.expireAt.IsZero() {
+		delete(s.expires, key)
+	} else {
+		s.expires[key] = struct{}{}
+	}
+}
+
+// removeItemLocked 删除条目并维护过期键集合与内存占用，调用方需持有分片的写锁
+func (c *Cache) removeItemLocked(s *shard, key string) {
+	item, ok := s.items[key]
+	if !ok {
+		return
+	}
+	s.usedBytes -= item.size
+	c.usedBytes.Add(-item.size)
+	c.count.Add(-1)
+	delete(s.items, key)
+	delete(s.expires, key)
+}
+
+// touch 记录一次访问，更新最近访问时间与 LFU 计数
+// 只使用原子操作，持有读锁时即可调用；并发访问时计数可能少记，对淘汰的近似比较没有影响
+func (c *Cache) touch(item *cacheItem) {
+	now := time.Now().UnixNano()
+	if c.eviction.Policy == config.EvictionLFU {
+		item.freq.Store(lfuIncr(lfuDecay(item.freq.Load(), item.accessAt.Load(), now)))
+	}
+	item.accessAt.Store(now)
+}
+
+// overLimit 判断是否超过容量限制
+func (c *Cache) overLimit() bool {
+	return (c.eviction.MaxEntries > 0 && c.count.Load() > int64(c.eviction.MaxEntries)) ||
+		(c.eviction.MaxBytes > 0 && c.usedBytes.Load() > c.eviction.MaxBytes)
+}
+
+// evict 超过容量限制时按淘汰策略删除条目，并将淘汰记录到AOF
+// 调用方不能持有任何分片的锁：先逐个分片抽样选出候选，再锁住候选所在的分片删除，
+// 若候选在此期间已被修改或删除则重新选择
+// 参数:
+// - ctx: 上下文
+// - keep: 刚写入的键，不参与淘汰
+//
+// 返回:
+// - error: 写入AOF失败时返回错误
+func (c *Cache) evict(ctx context.Context, keep string) error {
+	for c.overLimit() {
+		key, item := c.evictionCandidate(keep)
+		if item == nil {
+			return nil
+		}
+
+		s := c.shardFor(key)
+		s.mu.Lock()
+		if s.items[key] != item {
+			s.mu.Unlock()
+			continue
+		}
+		c.removeItemLocked(s, key)
+		c.recordEviction(key)
+		var err error
+		if c.aof != nil {
+			err = c.aof.Store(ctx, common.EVICT, key)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			return fmt.Errorf("failed to store in AOF: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetNoEvictPrefixes 设置不参与容量淘汰的键前缀，匹配的键只会因过期或删除而移除。
+// 用于已撤销的 token、验证码等安全相关的状态：这些键被淘汰后，已注销的 token 会重新生效。
+// 超过容量限制而只剩下这些键时不再淘汰，缓存可能超过限制。
+//
+// 参数:
+// - prefixes: 键前缀，例如 "user_invoked_token_"
+func (c *Cache) SetNoEvictPrefixes(prefixes ...string) {
+	prefixes = append([]string(nil), prefixes...)
+	c.noEvict.Store(&prefixes)
+}
+
+// evictable 判断键是否可以因容量限制被淘汰
+func (c *Cache) evictable(key string) bool {
+	if prefixes := c.noEvict.Load(); prefixes != nil {
+		for _, prefix := range *prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// evictionCandidate 参考 Redis 的近似 LRU/LFU：从随机的分片开始依次抽样若干个键，选出其中最应该淘汰的
+// 每个分片只在抽样期间持有读锁；已过期的键优先淘汰；不参与淘汰的键不计入抽样；没有可淘汰的键时返回 nil
+func (c *Cache) evictionCandidate(keep string) (string, *cacheItem) {
+	samples := c.eviction.Samples
+	if samples <= 0 {
+		samples = defaultEvictionSamples
+	}
+	lfu := c.eviction.Policy == config.EvictionLFU
+	now := time.Now()
+
+	var candidate string
+	var candidateItem *cacheItem
+	var bestFreq uint32
+	var bestAccess int64
+	sampled := 0
+	start := rand.IntN(len(c.shards))
+	for i := 0; i < len(c.shards) && sampled < samples; i++ {
+		s := c.shards[(start+i)%len(c.shards)]
+		s.mu.RLock()
+		for key, item := range s.items {
+			if key == keep {
+				continue
+			}
+			if item.expired(now) {
+				s.mu.RUnlock()
+				return key, item
+			}
+			if !c.evictable(key) {
+				continue
+			}
+
+			access := item.accessAt.Load()
+			freq := uint32(0)
+			if lfu {
+				freq = lfuDecay(item.freq.Load(), access, now.UnixNano())
+			}
+			if candidateItem == nil || freq < bestFreq || (freq == bestFreq && access < bestAccess) {
+				candidate, candidateItem, bestFreq, bestAccess = key, item, freq, access
+			}
+
+			sampled++
+			if sampled >= samples {
+				break
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return candidate, candidateItem
+}
+
+// estimateSize 估算条目的内存占用，只用于容量限制，不追求精确
+func estimateSize(key string, value any) int64 {
+	size := int64(itemOverhead + len(key))
+	switch v := value.(type) {
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	case map[string]string:
+		for field, value := range v {
+			size += int64(elementOverhead + len(field) + len(value))
+		}
+	case map[string]float64:
+		for member := range v {
+			size += int64(elementOverhead + len(member) + 8)
+		}
+	case []string:
+		for _, value := range v {
+			size += int64(elementOverhead + len(value))
+		}
+	default:
+		size += 8
+	}
+	return size
+}
+
+// lfuIncr 按对数概率增加访问频率计数，计数越大越难增长，最大为 255
+func lfuIncr(counter uint32) uint32 {
+	if counter >= 255 {
+		return 255
+	}
+	base := float64(counter) - lfuInitVal
+	if base < 0 {
+		base = 0
+	}
+	if rand.Float64() < 1/(base*lfuLogFactor+1) {
+		counter++
+	}
+	return counter
+}
+
+// lfuDecay 按空闲时间衰减访问频率计数
+func lfuDecay(counter uint32, accessAt int64, now int64) uint32 {
+	periods := uint32((now - accessAt) / int64(lfuDecayTime))
+	if periods >= counter {
+		return 0
+	}
+	return counter - periods
+}
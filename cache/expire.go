@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"time"
+)
+
+const (
+	defaultSweepInterval   = 100 * time.Millisecond // 默认的后台清理间隔
+	defaultSweepSampleSize = 20                     // 默认每批抽样检查的键数量
+)
+
+// startSweeper 启动过期键的后台清理协程
+// 参考 Redis 的主动过期策略：每个间隔从设置了过期时间的键中抽样一批，删除其中过期的键；
+// 若过期比例超过 1/4，说明过期键较多，继续抽样下一批，单轮耗时不超过间隔的 1/4。
+// 每批只锁住一个分片，不会像 Cleanup 那样在遍历整个缓存期间阻塞读写。
+func (c *Cache) startSweeper(conf config.ExpireConfig) {
+	if conf.Interval < 0 {
+		return
+	}
+
+	interval := defaultSweepInterval
+	if conf.Interval > 0 {
+		interval = time.Duration(conf.Interval) * time.Millisecond
+	}
+	sampleSize := conf.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultSweepSampleSize
+	}
+
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.sweep(sampleSize, interval/4)
+			}
+		}
+	}()
+}
+
+// sweep 执行一轮抽样清理，依次处理每个分片
+// 参数:
+// - sampleSize: 每批抽样检查的键数量
+// - budget: 单轮清理的最长耗时
+//
+// 返回:
+// - int: 本轮删除的过期键数量
+func (c *Cache) sweep(sampleSize int, budget time.Duration) int {
+	start := time.Now()
+	removed := 0
+	for _, s := range c.shards {
+		for {
+			sampled, expired := c.sweepBatch(s, sampleSize)
+			removed += expired
+			if time.Since(start) > budget {
+				return removed
+			}
+			if sampled == 0 || expired*4 <= sampled {
+				break
+			}
+		}
+	}
+	return removed
+}
+
+// sweepBatch 从分片中设置了过期时间的键里抽样检查一批，删除其中过期的键
+// Go 的 map 遍历起点是随机的，取遍历的前 sampleSize 个键即为一次随机抽样
+func (c *Cache) sweepBatch(s *shard, sampleSize int) (sampled int, expired int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key := range s.expires {
+		if sampled >= sampleSize {
+			break
+		}
+		sampled++
+
+		if !s.items[key].expired(now) {
+			continue
+		}
+		c.removeItemLocked(s, key)
+		expired++
+
+		// 过期删除同样记录到AOF，重放时与内存中的状态保持一致
+		if c.aof != nil {
+			if err := c.aof.Store(context.Background(), common.DELETE, key); err != nil {
+				logger.Error("failed to store expired key in AOF: %v", err)
+			}
+		}
+	}
+
+	return sampled, expired
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"sparrow_blog_server/cache/common"
+	"strconv"
+)
+
+// HSet 设置哈希表中字段的值，键不存在时创建新的哈希表
+// ctx    用于取消操作的上下文
+// key    条目键
+// field  字段名
+// value  字段值
+//
+// 返回:
+// - error  键的类型不是哈希表时返回 ErrTypeMismatch
+//
+// 注意:
+// - 新建的哈希表没有过期时间，修改已有的哈希表保留原有的过期时间
+func (c *Cache) HSet(ctx context.Context, key, field, value string) error {
+	return c.modifyCollection(ctx, key, common.HASH, true, func(s *shard, item *cacheItem) error {
+		c.hsetLocked(s, item, field, value)
+		return c.appendAof(ctx, common.HSET, key, field, value)
+	})
+}
+
+// HGet 获取哈希表中字段的值
+// ctx    用于取消操作的上下文
+// key    条目键
+// field  字段名
+//
+// 返回:
+// - string  字段值
+// - error   键或字段不存在时返回 ErrNotFound，键的类型不是哈希表时返回 ErrTypeMismatch
+func (c *Cache) HGet(ctx context.Context, key, field string) (string, error) {
+	var value string
+	err := c.readCollection(ctx, key, common.HASH, func(item *cacheItem) error {
+		if item == nil {
+			return NewNotFoundError("键不存在：" + key)
+		}
+		v, ok := item.value.(map[string]string)[field]
+		if !ok {
+			return NewNotFoundError("字段不存在：" + key + " " + field)
+		}
+		value = v
+		return nil
+	})
+	return value, err
+}
+
+// HIncrBy 原子地将哈希表中字段的整数值加上 delta，字段不存在时视为 0
+// ctx    用于取消操作的上下文
+// key    条目键
+// field  字段名
+// delta  增量，可以为负数
+//
+// 返回:
+// - int64  操作后的新值
+// - error  可能的错误:
+//   - ErrTypeMismatch 键的类型不是哈希表或字段的值不是整数
+//   - ErrOutOfRange 值溢出
+//
+// 注意:
+// - AOF 中记录为设置计算后结果的 HSET 命令
+func (c *Cache) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	var result int64
+	err := c.modifyCollection(ctx, key, common.HASH, true, func(s *shard, item *cacheItem) error {
+		var val int64
+		if old, ok := item.value.(map[string]string)[field]; ok {
+			var err error
+			if val, err = strconv.ParseInt(old, 10, 64); err != nil {
+				return NewTypeMismatchError("字段的值不是整数: " + err.Error())
+			}
+		}
+		if (delta > 0 && val > math.MaxInt64-delta) || (delta < 0 && val < math.MinInt64-delta) {
+			return NewOutOfRangeError("值超出int64范围")
+		}
+		result = val + delta
+
+		value := strconv.FormatInt(result, 10)
+		c.hsetLocked(s, item, field, value)
+		return c.appendAof(ctx, common.HSET, key, field, value)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// hsetLocked 设置哈希表字段的值并维护内存占用，调用方需持有分片的写锁
+func (c *Cache) hsetLocked(s *shard, item *cacheItem, field, value string) {
+	hash := item.value.(map[string]string)
+	delta := int64(len(value))
+	if old, ok := hash[field]; ok {
+		delta -= int64(len(old))
+	} else {
+		delta += int64(elementOverhead + len(field))
+	}
+	hash[field] = value
+	c.resizeLocked(s, item, delta)
+}
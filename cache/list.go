@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"strconv"
+)
+
+// LPush 将一个或多个值依次插入列表的表头，键不存在时创建新的列表
+// ctx     用于取消操作的上下文
+// key     条目键
+// values  依次插入的值，最后一个值位于表头
+//
+// 返回:
+// - int    操作后列表的长度
+// - error  键的类型不是列表时返回 ErrTypeMismatch
+//
+// 注意:
+// - 新建的列表没有过期时间，修改已有的列表保留原有的过期时间
+func (c *Cache) LPush(ctx context.Context, key string, values ...string) (int, error) {
+	var length int
+	err := c.modifyCollection(ctx, key, common.LIST, len(values) > 0, func(s *shard, item *cacheItem) error {
+		if item == nil {
+			return nil
+		}
+		for _, value := range values {
+			c.lpushLocked(s, item, value)
+			if err := c.appendAof(ctx, common.LPUSH, key, value); err != nil {
+				return err
+			}
+		}
+		length = collectionLen(item)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// LRange 返回列表中从 start 到 stop（包含）的元素，0 为表头
+// ctx    用于取消操作的上下文
+// key    条目键
+// start  起始位置，负数表示从表尾倒数，-1 为最后一个元素
+// stop   结束位置，负数表示从表尾倒数
+//
+// 返回:
+// - []string  范围内的元素，键不存在或范围为空时返回空切片
+// - error     键的类型不是列表时返回 ErrTypeMismatch
+func (c *Cache) LRange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	result := make([]string, 0)
+	err := c.readCollection(ctx, key, common.LIST, func(item *cacheItem) error {
+		if item == nil {
+			return nil
+		}
+		list := item.value.([]string)
+		start, stop, ok := listRange(len(list), start, stop)
+		if !ok {
+			return nil
+		}
+		// 列表逆序存储，第 i 个元素位于 len-1-i
+		for i := start; i <= stop; i++ {
+			result = append(result, list[len(list)-1-i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LTrim 只保留列表中从 start 到 stop（包含）的元素，位置的含义与 LRange 相同
+// 常用于在 LPush 后限制列表的长度，例如 LTrim(ctx, key, 0, 99) 只保留最新的 100 个元素
+// ctx    用于取消操作的上下文
+// key    条目键
+// start  起始位置
+// stop   结束位置
+//
+// 返回:
+// - error  键的类型不是列表时返回 ErrTypeMismatch
+//
+// 注意:
+// - 键不存在时不做任何事；保留的范围为空时删除该键
+func (c *Cache) LTrim(ctx context.Context, key string, start, stop int) error {
+	return c.modifyCollection(ctx, key, common.LIST, false, func(s *shard, item *cacheItem) error {
+		if item == nil {
+			return nil
+		}
+		c.ltrimLocked(s, item, start, stop)
+		return c.appendAof(ctx, common.LTRIM, key, strconv.Itoa(start), strconv.Itoa(stop))
+	})
+}
+
+// lpushLocked 将值插入列表的表头并维护内存占用，调用方需持有分片的写锁
+func (c *Cache) lpushLocked(s *shard, item *cacheItem, value string) {
+	item.value = append(item.value.([]string), value)
+	c.resizeLocked(s, item, int64(elementOverhead+len(value)))
+}
+
+// ltrimLocked 只保留列表中指定范围的元素并维护内存占用，调用方需持有分片的写锁
+func (c *Cache) ltrimLocked(s *shard, item *cacheItem, start, stop int) {
+	list := item.value.([]string)
+	var kept []string
+	if start, stop, ok := listRange(len(list), start, stop); ok {
+		// 逻辑位置 [start, stop] 对应逆序存储中的 [len-1-stop, len-1-start]
+		kept = slices.Clone(list[len(list)-1-stop : len(list)-start])
+	}
+
+	var delta int64
+	for _, value := range list {
+		delta -= int64(elementOverhead + len(value))
+	}
+	for _, value := range kept {
+		delta += int64(elementOverhead + len(value))
+	}
+	item.value = kept
+	c.resizeLocked(s, item, delta)
+}
+
+// listRange 将 LRange 与 LTrim 的位置参数转换为 [0, length) 内的闭区间，范围为空时 ok 为 false
+func listRange(length, start, stop int) (int, int, bool) {
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	stop = min(stop, length-1)
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
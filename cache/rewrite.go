@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sparrow_blog_server/cache/aof"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"time"
+)
+
+const (
+	defaultRewritePercentage = 100         // 默认的自动重写增长百分比
+	defaultRewriteMinSize    = 1           // 默认的自动重写最小大小(MB)
+	rewriteCheckInterval     = time.Second // 检查是否需要自动重写的间隔
+)
+
+// RewriteAof 参考 Redis 的 BGREWRITEAOF 重写 AOF 文件
+// 以读锁锁住所有分片，生成当前有效数据的快照并开始缓存新的写入，随后释放读锁，
+// 将快照写入新的基础文件，追加重写期间的写入后原子地替换旧文件，并删除已轮转的旧文件。
+// 写入快照期间缓存的读写不受影响。
+//
+// 参数:
+// - ctx: 上下文
+//
+// 返回:
+// - error: 未启用AOF、已有重写正在进行或重写失败时返回错误
+func (c *Cache) RewriteAof(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	c.rLockAll()
+	a := c.aof
+	if a == nil {
+		c.rUnlockAll()
+		return errors.New("AOF is not enabled")
+	}
+	// 写入操作需要持有分片的写锁，锁住所有分片期间快照与开始缓存新写入之间不会有遗漏
+	if err := a.BeginRewrite(); err != nil {
+		c.rUnlockAll()
+		return err
+	}
+	now := time.Now()
+	snapshot := make([][]string, 0, c.count.Load())
+	for _, s := range c.shards {
+		for key, item := range s.items {
+			if item.expired(now) {
+				continue
+			}
+			snapshot = append(snapshot, itemCommands(key, item)...)
+		}
+	}
+	c.rUnlockAll()
+
+	if err := a.FinishRewrite(snapshot); err != nil {
+		return fmt.Errorf("failed to rewrite AOF: %w", err)
+	}
+	return nil
+}
+
+// startRewriter 启动 AOF 自动重写的后台协程
+// 每秒检查一次，AOF 超过最小大小且相对上次重写后的增长超过配置的百分比时重写
+func (c *Cache) startRewriter(conf config.AofConfig) {
+	if conf.RewritePercentage < 0 {
+		return
+	}
+
+	percentage := conf.RewritePercentage
+	if percentage == 0 {
+		percentage = defaultRewritePercentage
+	}
+	minSize := int64(conf.RewriteMinSize)
+	if minSize == 0 {
+		minSize = defaultRewriteMinSize
+	}
+	minSize *= 1024 * 1024
+
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+
+		ticker := time.NewTicker(rewriteCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				if !c.aof.NeedRewrite(percentage, minSize) {
+					continue
+				}
+				if err := c.RewriteAof(context.Background()); err != nil && !errors.Is(err, aof.ErrRewriteInProgress) {
+					logger.Error("AOF 自动重写失败: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// stopBackground 通知后台协程退出并等待其结束，多次调用是安全的
+func (c *Cache) stopBackground() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.background.Wait()
+	c.stop = nil
+}
+
+// itemArgs 将标量条目转换为 AOF 中 SET 命令的参数（键、值、类型、过期时间），永不过期时过期时间为 0
+// 序列化为 JSON 的对象按字符串写入，而不是字节列表
+func itemArgs(key string, item *cacheItem) []string {
+	return []string{key, formatValue(item), fmt.Sprint(item.vt), expireArg(item.expireAt)}
+}
+
+// formatValue 将标量条目的值转换为字符串，序列化为 JSON 的对象按字符串转换
+func formatValue(item *cacheItem) string {
+	if b, ok := item.value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(item.value)
+}
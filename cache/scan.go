@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultScanCount 默认每次 Scan 检查的键数量
+const defaultScanCount = 10
+
+// Scan 参考 Redis 的 SCAN 增量遍历缓存中的键，返回匹配 pattern 的键与下一次调用的游标
+// 键按所属分片、分片内按哈希值排序，游标的高 32 位为分片序号，低 32 位为分片内的哈希值位置。
+// 每次调用只依次以读锁锁住需要检查的分片，不会在遍历整个缓存期间阻塞写入。
+//
+// 参数:
+// - ctx: 用于取消操作的上下文
+// - cursor: 游标，第一次调用时为 0，之后使用上一次调用返回的游标
+// - pattern: glob 模式，支持 *（任意个字符）、?（单个字符）、[abc]、[a-z]、[^a]（字符集合）与 \ 转义，为空时匹配所有键
+// - count: 本次检查的键数量，小于等于 0 时为 10；哈希值相同的键总是一起返回，实际检查的数量可能略多
+//
+// 返回:
+// - uint64: 下一次调用的游标，为 0 时遍历结束
+// - []string: 本次检查的键中匹配且未过期的键，可能为空，为空不代表遍历结束
+// - error: 上下文取消或游标无效时返回错误
+//
+// 注意:
+// - 从遍历开始到结束一直存在的键恰好返回一次，遍历期间新增或删除的键可能返回也可能不返回
+func (c *Cache) Scan(ctx context.Context, cursor uint64, pattern string, count int) (uint64, []string, error) {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+	shardIndex, from := int(cursor>>32), uint32(cursor)
+	if shardIndex >= len(c.shards) {
+		return 0, nil, fmt.Errorf("invalid scan cursor: %d", cursor)
+	}
+
+	keys := make([]string, 0)
+	for ; shardIndex < len(c.shards); shardIndex, from = shardIndex+1, 0 {
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		default:
+		}
+
+		matched, examined, next, done := c.scanShard(c.shards[shardIndex], from, count, pattern)
+		keys = append(keys, matched...)
+		count -= examined
+		if !done {
+			return uint64(shardIndex)<<32 | uint64(next), keys, nil
+		}
+		if count <= 0 && shardIndex+1 < len(c.shards) {
+			return uint64(shardIndex+1) << 32, keys, nil
+		}
+	}
+	return 0, keys, nil
+}
+
+// scanEntry 分片内待检查的键及其哈希值
+type scanEntry struct {
+	hash uint32
+	key  string
+}
+
+// scanShard 以读锁检查分片中哈希值不小于 from 的前 limit 个键
+// 返回匹配的键、检查的键数量、下一次检查的起始位置，以及分片是否已检查完
+func (c *Cache) scanShard(s *shard, from uint32, limit int, pattern string) ([]string, int, uint32, bool) {
+	s.mu.RLock()
+	entries := make([]scanEntry, 0, len(s.items))
+	for key := range s.items {
+		if hash := fnv32a(key); hash >= from {
+			entries = append(entries, scanEntry{hash: hash, key: key})
+		}
+	}
+	slices.SortFunc(entries, func(a, b scanEntry) int {
+		return cmp.Or(cmp.Compare(a.hash, b.hash), strings.Compare(a.key, b.key))
+	})
+
+	n := min(limit, len(entries))
+	// 哈希值相同的键必须在同一次返回，否则无法用哈希值表示游标的位置
+	for n > 0 && n < len(entries) && entries[n].hash == entries[n-1].hash {
+		n++
+	}
+
+	now := time.Now()
+	var keys []string
+	for _, entry := range entries[:n] {
+		if !s.items[entry.key].expired(now) && (pattern == "" || MatchPattern(pattern, entry.key)) {
+			keys = append(keys, entry.key)
+		}
+	}
+	s.mu.RUnlock()
+
+	if n == len(entries) {
+		return keys, n, 0, true
+	}
+	// 剩余的键哈希值都大于已检查的键，加一不会溢出
+	return keys, n, entries[n-1].hash + 1, false
+}
+
+// MatchPattern 判断 name 是否匹配 glob 模式 pattern，按字节匹配
+// 支持的语法:
+// - *: 任意个字符，包括空字符串
+// - ?: 单个字符
+// - [abc]、[a-z]: 集合中的单个字符，[^abc] 或 [!abc] 表示集合之外的单个字符
+// - \: 转义下一个字符，使其按普通字符匹配
+//
+// 没有闭合的 [ 与末尾的 \ 按普通字符匹配
+func MatchPattern(pattern, name string) bool {
+	px, nx := 0, 0
+	// 最近一个 * 的位置与它当前匹配到的 name 位置，匹配失败时回溯让 * 多匹配一个字符
+	starPx, starNx := -1, 0
+	for px < len(pattern) || nx < len(name) {
+		if px < len(pattern) {
+			switch pattern[px] {
+			case '*':
+				starPx, starNx = px, nx
+				px++
+				continue
+			case '?':
+				if nx < len(name) {
+					px++
+					nx++
+					continue
+				}
+			case '[':
+				if matched, end, ok := matchClass(pattern, px, name, nx); ok {
+					if matched {
+						px = end
+						nx++
+						continue
+					}
+				} else if nx < len(name) && name[nx] == '[' {
+					px++
+					nx++
+					continue
+				}
+			case '\\':
+				literal, width := byte('\\'), 1
+				if px+1 < len(pattern) {
+					literal, width = pattern[px+1], 2
+				}
+				if nx < len(name) && name[nx] == literal {
+					px += width
+					nx++
+					continue
+				}
+			default:
+				if nx < len(name) && name[nx] == pattern[px] {
+					px++
+					nx++
+					continue
+				}
+			}
+		}
+		if starPx >= 0 && starNx < len(name) {
+			starNx++
+			px, nx = starPx+1, starNx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// matchClass 匹配 pattern[px] 处以 [ 开始的字符集合
+// 返回 name[nx] 是否在集合中、集合之后的位置，集合没有闭合时 ok 为 false
+func matchClass(pattern string, px int, name string, nx int) (matched bool, end int, ok bool) {
+	i := px + 1
+	negate := i < len(pattern) && (pattern[i] == '^' || pattern[i] == '!')
+	if negate {
+		i++
+	}
+
+	var ch byte
+	hasChar := nx < len(name)
+	if hasChar {
+		ch = name[nx]
+	}
+	for i < len(pattern) && pattern[i] != ']' {
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			if hi == '\\' && i+3 < len(pattern) {
+				i++
+				hi = pattern[i+2]
+			}
+			i += 2
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+		}
+		if hasChar && lo <= ch && ch <= hi {
+			matched = true
+		}
+		i++
+	}
+	if i >= len(pattern) {
+		return false, 0, false
+	}
+	return hasChar && matched != negate, i + 1, true
+}
+
+// QuotePattern 转义字符串中的 glob 特殊字符，使其在模式中按普通字符匹配
+// 用于将键的一部分（例如博客 ID）拼接到 Scan 的模式中
+func QuotePattern(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestScan_MatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"blog_*", "blog_1", true},
+		{"blog_*", "img_blog_1", false},
+		{"*_count_*-2024*", "blog_read_count_abc-20240101", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[!e]llo", "hello", false},
+		{"key:[0-9]", "key:7", true},
+		{"key:[0-9]", "key:a", false},
+		{"key:[a-c-]", "key:-", true},
+		{`blog\*`, "blog*", true},
+		{`blog\*`, "blogX", false},
+		{"[unclosed", "[unclosed", true},
+		{`tail\`, `tail\`, true},
+		{"a[]b", "ab", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) 期望 %v，实际得到 %v", tt.pattern, tt.name, tt.want, got)
+		}
+	}
+
+	id := "a*b?[c]\\"
+	if !MatchPattern(QuotePattern(id)+"-*", id+"-20240101") || MatchPattern(QuotePattern(id), "aXb?[c]\\") {
+		t.Error("期望转义后的字符串按普通字符匹配")
+	}
+}
+
+func TestScan_Cursor(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newCache(ctx, 4)
+	defer func() { _ = c.Close() }()
+
+	want := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("scan:%d", i)
+		_ = c.Set(ctx, key, i)
+		want = append(want, key)
+	}
+	_ = c.Set(ctx, "other:1", 1)
+	_ = c.SetWithExpired(ctx, "scan:expired", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// 遍历期间删除与新增键，一直存在的键恰好返回一次
+	var got []string
+	cursor, calls := uint64(0), 0
+	for {
+		next, keys, err := c.Scan(ctx, cursor, "scan:*", 7)
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		got = append(got, keys...)
+		calls++
+		if calls == 3 {
+			_ = c.Delete(ctx, "scan:0")
+			_ = c.Set(ctx, "scan:new", 1)
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if calls < 10 {
+		t.Errorf("期望分多次返回，实际只调用了 %d 次", calls)
+	}
+
+	seen := make(map[string]int)
+	for _, key := range got {
+		seen[key]++
+	}
+	for _, key := range want[1:] {
+		if seen[key] != 1 {
+			t.Errorf("期望 %s 恰好返回一次，实际返回 %d 次", key, seen[key])
+		}
+	}
+	for _, key := range []string{"other:1", "scan:expired"} {
+		if seen[key] != 0 {
+			t.Errorf("期望不返回 %s", key)
+		}
+	}
+
+	// 一次检查全部键
+	_, all, _ := c.Scan(ctx, 0, "", 1000)
+	if !slices.Contains(all, "other:1") || !slices.Contains(all, "scan:new") || slices.Contains(all, "scan:expired") {
+		t.Errorf("期望空模式返回全部未过期的键，实际得到 %q", all)
+	}
+
+	if _, _, err := c.Scan(ctx, uint64(len(c.shards))<<32, "*", 10); err == nil {
+		t.Error("期望无效的游标返回错误")
+	}
+}
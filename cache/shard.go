@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"sync"
+)
+
+// defaultShardCount 默认的分片数量，必须是 2 的幂
+const defaultShardCount = 32
+
+// shard 缓存的一个分片，按键的哈希值划分，每个分片有独立的锁
+//
+// 字段:
+// - mu: 保护本分片的读写锁
+// - items: 本分片的缓存条目
+// - expires: 本分片中设置了过期时间的键，后台清理只在其中抽样
+// - usedBytes: 本分片条目估算的内存占用
+type shard struct {
+	mu        sync.RWMutex
+	items     map[string]*cacheItem
+	expires   map[string]struct{}
+	usedBytes int64
+}
+
+func newShard() *shard {
+	return &shard{
+		items:   make(map[string]*cacheItem),
+		expires: make(map[string]struct{}),
+	}
+}
+
+// newShards 创建 n 个分片，n 必须是 2 的幂
+func newShards(n int) []*shard {
+	if n <= 0 || n&(n-1) != 0 {
+		panic("cache shard count must be a power of 2")
+	}
+	shards := make([]*shard, n)
+	for i := range shards {
+		shards[i] = newShard()
+	}
+	return shards
+}
+
+// shardFor 返回键所属的分片，同一个键总是落在同一个分片上，
+// 因此同一个键的修改与对应的 AOF 记录在分片锁下保持顺序一致
+func (c *Cache) shardFor(key string) *shard {
+	return c.shards[fnv32a(key)&uint32(len(c.shards)-1)]
+}
+
+// lockAll 按顺序锁住所有分片，用于需要一致视图的操作（全量清理、AOF 重写、关闭）
+// 其他操作同一时间最多只持有一个分片的锁，按固定顺序加锁不会死锁
+func (c *Cache) lockAll() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+	}
+}
+
+func (c *Cache) unlockAll() {
+	for _, s := range c.shards {
+		s.mu.Unlock()
+	}
+}
+
+// rLockAll 按顺序以读锁锁住所有分片，期间所有写入都会被阻塞
+func (c *Cache) rLockAll() {
+	for _, s := range c.shards {
+		s.mu.RLock()
+	}
+}
+
+func (c *Cache) rUnlockAll() {
+	for _, s := range c.shards {
+		s.mu.RUnlock()
+	}
+}
+
+// fnv32a 计算键的 FNV-1a 哈希值，避免为每次访问分配 hash.Hash32
+func fnv32a(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= prime32
+	}
+	return hash
+}
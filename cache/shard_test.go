@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sparrow_blog_server/pkg/config"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShard_ConcurrentStress 并发执行各种读写、清理与 AOF 重写，配合 -race 检查数据竞争，
+// 并验证递增不会丢失、重新加载后 AOF 中的数据与内存一致
+func TestShard_ConcurrentStress(t *testing.T) {
+	ctx := context.Background()
+	oldCache := config.Cache
+	config.Cache.Aof.Enable = true
+	config.Cache.Aof.Path = filepath.Join(t.TempDir(), "stress.aof")
+	config.Cache.Eviction = config.EvictionConfig{}
+	defer func() { config.Cache = oldCache }()
+
+	const workers = 16
+	const rounds = 300
+
+	c, _ := NewCache(ctx)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				key := fmt.Sprintf("stress:%d:%d", w, i%10)
+				if _, err := c.Incr(ctx, "stress:counter"); err != nil {
+					t.Errorf("递增失败: %v", err)
+					return
+				}
+				_ = c.Set(ctx, key, i)
+				_ = c.SetWithExpired(ctx, key+":ttl", "val", time.Millisecond)
+				_, _ = c.Get(ctx, fmt.Sprintf("stress:%d:%d", (w+1)%workers, i%10))
+				if i%10 == 0 {
+					_ = c.Delete(ctx, key)
+					_, _, _ = c.Scan(ctx, 0, fmt.Sprintf("stress:%d:*", w), 50)
+				}
+			}
+		}(w)
+	}
+
+	// 同时执行全量清理与 AOF 重写
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			c.Cleanup()
+			if err := c.RewriteAof(ctx); err != nil {
+				t.Errorf("重写 AOF 失败: %v", err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	wg.Wait()
+	<-done
+
+	counter, err := c.GetInt(ctx, "stress:counter")
+	if err != nil || counter != workers*rounds {
+		t.Fatalf("期望计数为 %d，实际得到 %v, %v", workers*rounds, counter, err)
+	}
+	want := make(map[string]int)
+	for w := 0; w < workers; w++ {
+		for i := rounds - 10; i < rounds; i++ {
+			key := fmt.Sprintf("stress:%d:%d", w, i%10)
+			if val, err := c.GetInt(ctx, key); err == nil {
+				want[key] = val
+			}
+		}
+	}
+	_ = c.Close()
+
+	c, _ = NewCache(ctx)
+	defer func() { _ = c.Close() }()
+	if val, err := c.GetInt(ctx, "stress:counter"); err != nil || val != workers*rounds {
+		t.Errorf("期望重新加载后计数为 %d，实际得到 %v, %v", workers*rounds, val, err)
+	}
+	for key, val := range want {
+		if got, err := c.GetInt(ctx, key); err != nil || got != val {
+			t.Errorf("期望重新加载后 %s 为 %d，实际得到 %v, %v", key, val, got, err)
+		}
+	}
+}
+
+// BenchmarkCache_Parallel 对比分片锁与单个全局锁（1 个分片，即原来的实现方式）在并发读写下的性能
+// 读写比例为 9:1，不启用 AOF，只比较锁竞争的开销
+func BenchmarkCache_Parallel(b *testing.B) {
+	ctx := context.Background()
+	oldCache := config.Cache
+	config.Cache.Aof.Enable = false
+	config.Cache.Expire.Interval = -1
+	config.Cache.Eviction = config.EvictionConfig{}
+	defer func() { config.Cache = oldCache }()
+
+	const keyCount = 10000
+	keys := make([]string, keyCount)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("bench:%d", i)
+	}
+
+	for _, shardCount := range []int{1, defaultShardCount} {
+		b.Run(fmt.Sprintf("shards=%d", shardCount), func(b *testing.B) {
+			c, _ := newCache(ctx, shardCount)
+			defer func() { _ = c.Close() }()
+			for _, key := range keys {
+				_ = c.Set(ctx, key, "val")
+			}
+
+			var seed atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				// 每个协程从不同的位置开始，避免所有协程同时访问同一个键
+				i := int(seed.Add(1)) * 1237
+				for pb.Next() {
+					key := keys[(i*7919)%keyCount]
+					if i%10 == 0 {
+						_ = c.Set(ctx, key, "val")
+					} else {
+						_, _ = c.Get(ctx, key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
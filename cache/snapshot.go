@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/cache/snapshot"
+	"sparrow_blog_server/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// SaveSnapshot 参考 Redis 的 BGSAVE 生成缓存的时间点快照
+// 以读锁锁住所有分片复制当前的有效数据后释放读锁，写入快照文件期间缓存的读写不受影响。
+// 启用 AOF 时，快照写入完成后用只包含开始生成快照以来写入的新文件替换当前的 AOF，
+// 下次启动时加载该快照并只重放之后的 AOF。之前的快照文件随后被删除。
+//
+// 参数:
+// - ctx: 上下文
+//
+// 返回:
+// - *snapshot.Info: 新快照的信息，可以将其中的文件加入站点备份
+// - error: 已有 AOF 重写或快照正在进行、写入快照或替换 AOF 失败时返回错误
+func (c *Cache) SaveSnapshot(ctx context.Context) (*snapshot.Info, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	c.snapshotMu.Lock()
+	defer c.snapshotMu.Unlock()
+
+	c.rLockAll()
+	a := c.aof
+	// 与 RewriteAof 相同，锁住所有分片期间复制数据与开始缓存新写入之间不会有遗漏
+	if a != nil {
+		if err := a.BeginRewrite(); err != nil {
+			c.rUnlockAll()
+			return nil, err
+		}
+	}
+	entries := c.snapshotEntriesLocked()
+	c.rUnlockAll()
+
+	info, err := snapshot.Save(entries)
+	if err != nil {
+		if a != nil {
+			a.AbortRewrite()
+		}
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if a != nil {
+		if err := a.FinishSnapshot(strconv.FormatInt(info.ID, 10)); err != nil {
+			// AOF 没有被替换，新的快照不会被引用
+			_ = os.Remove(info.Path)
+			return nil, fmt.Errorf("failed to switch AOF to snapshot: %w", err)
+		}
+	}
+
+	if err := snapshot.RemoveBefore(info.ID); err != nil {
+		logger.Warn("删除旧的缓存快照失败: %v", err)
+	}
+	logger.Info("缓存快照已保存: %s，%d 个键，%d 字节", info.Path, info.Entries, info.Size)
+	return info, nil
+}
+
+// snapshotEntriesLocked 复制所有未过期的条目，调用方需锁住所有分片
+// 集合类型的内容会被复制，释放锁后的修改不会影响快照
+func (c *Cache) snapshotEntriesLocked() []snapshot.Entry {
+	now := time.Now()
+	entries := make([]snapshot.Entry, 0, c.count.Load())
+	for _, s := range c.shards {
+		for key, item := range s.items {
+			if item.expired(now) {
+				continue
+			}
+
+			entry := snapshot.Entry{Key: key, Type: item.vt}
+			if !item.expireAt.IsZero() {
+				entry.ExpireAt = item.expireAt.UnixMilli()
+			}
+			switch v := item.value.(type) {
+			case map[string]string:
+				entry.Hash = maps.Clone(v)
+			case map[string]float64:
+				entry.ZSet = maps.Clone(v)
+			case []string:
+				entry.List = slices.Clone(v)
+			default:
+				entry.Value = formatValue(item)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// loadSnapshotLocked 清空当前数据并加载指定的快照，只在加载AOF时调用
+func (c *Cache) loadSnapshotLocked(id int64) error {
+	entries, err := snapshot.Load(id)
+	if err != nil {
+		return err
+	}
+
+	c.clearLocked()
+	for _, entry := range entries {
+		item := &cacheItem{vt: entry.Type}
+		if entry.ExpireAt != 0 {
+			item.expireAt = time.UnixMilli(entry.ExpireAt)
+		}
+		switch entry.Type {
+		case common.HASH:
+			item.value = entry.Hash
+		case common.LIST:
+			item.value = entry.List
+		case common.ZSET:
+			item.value = entry.ZSet
+		default:
+			if item.value, err = parseValue(entry.Type, entry.Value); err != nil {
+				continue
+			}
+		}
+		if isCollection(item.vt) && collectionLen(item) == 0 {
+			continue
+		}
+		c.storeItemLocked(c.shardFor(entry.Key), entry.Key, item)
+	}
+	return nil
+}
+
+// persistSnapshotLocked 加载AOF后将当前数据保存为新的快照，并在新的 AOF 开头记录快照标记
+// 只在加载AOF时调用，此时没有其他写入，不需要缓存重写期间的命令
+func (c *Cache) persistSnapshotLocked(ctx context.Context) error {
+	info, err := snapshot.Save(c.snapshotEntriesLocked())
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := c.aof.Store(ctx, common.SNAPSHOT, strconv.FormatInt(info.ID, 10)); err != nil {
+		return fmt.Errorf("failed to store in AOF: %w", err)
+	}
+	if err := snapshot.RemoveBefore(info.ID); err != nil {
+		logger.Warn("删除旧的缓存快照失败: %v", err)
+	}
+	return nil
+}
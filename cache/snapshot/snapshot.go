@@ -0,0 +1,421 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshot 包实现了缓存的时间点快照（参考 Redis 的 RDB），以二进制格式保存所有键的类型、值与绝对过期时间。
+// 快照文件与 AOF 文件放在同一目录，文件名为 <AOF 文件前缀>_<快照标识>.rdb。
+//
+// 文件格式（版本 1）:
+//
+//	文件头: "SBRDB" + 版本号(1 字节) + '\n'
+//	快照标识(int64, 大端) + 条目数量(uvarint)
+//	条目:   类型(1 字节) + 过期时间(varint, Unix 毫秒, 0 表示永不过期) + 键 + 值
+//	文件尾: 之前所有内容的 CRC32-C 校验和(uint32, 大端)
+//
+// 字符串按长度(uvarint)与字节写入；标量的值为字符串，哈希表与有序集合为元素个数与每个字段和值（分数为 float64 的位），
+// 列表为元素个数与从表尾到表头的每个元素。
+
+const (
+	formatMagic   = "SBRDB"
+	formatVersion = 1
+	fileExt       = ".rdb"
+)
+
+var fileHeader = []byte{'S', 'B', 'R', 'D', 'B', formatVersion, '\n'}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorrupted 快照文件不完整或校验失败
+var ErrCorrupted = errors.New("snapshot file is corrupted")
+
+// Entry 快照中的一个条目
+type Entry struct {
+	Key      string
+	Type     common.ValueType
+	ExpireAt int64              // 过期时间（Unix 毫秒），0 表示永不过期
+	Value    string             // 标量的值，格式与 AOF 中 SET 命令的值相同
+	Hash     map[string]string  // HASH 类型的字段与值
+	List     []string           // LIST 类型的元素，从表尾到表头
+	ZSet     map[string]float64 // ZSET 类型的成员与分数
+}
+
+// Info 快照文件的信息
+type Info struct {
+	ID        int64     `json:"id"`         // 快照标识，即生成时间的 Unix 纳秒
+	Path      string    `json:"path"`       // 文件路径
+	Size      int64     `json:"size"`       // 文件大小（字节）
+	Entries   int       `json:"entries"`    // 条目数量，只在保存时填写
+	CreatedAt time.Time `json:"created_at"` // 生成时间
+}
+
+// Save 将条目写入新的快照文件
+// 先写入临时文件并刷到磁盘，再原子地重命名为正式文件，崩溃时不会留下不完整的快照。
+//
+// 参数:
+// - entries: 快照中的全部条目
+//
+// 返回:
+// - *Info: 新快照的信息
+// - error: 写入失败时返回错误
+func Save(entries []Entry) (*Info, error) {
+	dir, prefix, err := location()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	id := time.Now().UnixNano()
+	path := filepath.Join(dir, fmt.Sprintf("%s_%d%s", prefix, id, fileExt))
+	tmpPath := path + ".tmp"
+	size, err := writeFile(tmpPath, id, entries)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to rename snapshot file: %w", err)
+	}
+	syncDir(dir)
+
+	return &Info{ID: id, Path: path, Size: size, Entries: len(entries), CreatedAt: time.Unix(0, id)}, nil
+}
+
+// Load 读取指定标识的快照文件
+func Load(id int64) ([]Entry, error) {
+	dir, prefix, err := location()
+	if err != nil {
+		return nil, err
+	}
+	entries, fileID, err := ReadFile(filepath.Join(dir, fmt.Sprintf("%s_%d%s", prefix, id, fileExt)))
+	if err != nil {
+		return nil, err
+	}
+	if fileID != id {
+		return nil, fmt.Errorf("%w: expected id %d, got %d", ErrCorrupted, id, fileID)
+	}
+	return entries, nil
+}
+
+// List 按生成时间从早到晚列出所有快照文件
+func List() ([]Info, error) {
+	dir, prefix, err := location()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, prefix+"_*"+fileExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot files: %w", err)
+	}
+
+	infos := make([]Info, 0, len(paths))
+	for _, path := range paths {
+		idStr := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix+"_"), fileExt)
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, Info{ID: id, Path: path, Size: stat.Size(), CreatedAt: time.Unix(0, id)})
+	}
+	slices.SortFunc(infos, func(a, b Info) int { return cmp.Compare(a.ID, b.ID) })
+	return infos, nil
+}
+
+// RemoveBefore 删除比指定快照更早的快照文件，新的快照被 AOF 引用后调用
+func RemoveBefore(id int64) error {
+	infos, err := List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, info := range infos {
+		if info.ID < id {
+			if err := os.Remove(info.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReadFile 读取快照文件并校验，返回其中的条目与快照标识
+func ReadFile(path string) ([]Entry, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	r := &reader{br: bufio.NewReaderSize(file, 64*1024), crc: crc32.New(crcTable)}
+
+	header := make([]byte, len(fileHeader))
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.HasPrefix(header, []byte(formatMagic)) {
+		return nil, 0, fmt.Errorf("%w: %s is not a snapshot file", ErrCorrupted, path)
+	}
+	if !bytes.Equal(header, fileHeader) {
+		return nil, 0, fmt.Errorf("unsupported snapshot version: %q", header)
+	}
+
+	var idBuf [8]byte
+	r.full(idBuf[:])
+	id := int64(binary.BigEndian.Uint64(idBuf[:]))
+	count := r.uvarint()
+	if r.err == nil && count > math.MaxInt32 {
+		r.err = errors.New("invalid entry count")
+	}
+
+	var entries []Entry
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		entries = append(entries, r.entry())
+	}
+	if r.err != nil {
+		return nil, 0, fmt.Errorf("%w: %s: %v", ErrCorrupted, path, r.err)
+	}
+
+	// 文件尾不计入校验和，直接从底层读取
+	sum := r.crc.Sum32()
+	var trailer [4]byte
+	if _, err := io.ReadFull(r.br, trailer[:]); err != nil {
+		return nil, 0, fmt.Errorf("%w: %s: missing checksum", ErrCorrupted, path)
+	}
+	if binary.BigEndian.Uint32(trailer[:]) != sum {
+		return nil, 0, fmt.Errorf("%w: %s: checksum mismatch", ErrCorrupted, path)
+	}
+	return entries, id, nil
+}
+
+// writeFile 将快照写入文件并刷到磁盘，返回文件大小
+func writeFile(path string, id int64, entries []Entry) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	crc := crc32.New(crcTable)
+	w := &writer{w: bufio.NewWriterSize(io.MultiWriter(file, crc), 64*1024)}
+	w.write(fileHeader)
+	w.write(binary.BigEndian.AppendUint64(nil, uint64(id)))
+	w.uvarint(uint64(len(entries)))
+	for i := range entries {
+		w.entry(&entries[i])
+	}
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	if w.err == nil {
+		_, w.err = file.Write(binary.BigEndian.AppendUint32(nil, crc.Sum32()))
+		w.size += 4
+	}
+	if w.err == nil {
+		w.err = file.Sync()
+	}
+	if w.err != nil {
+		return 0, fmt.Errorf("failed to write snapshot file: %w", w.err)
+	}
+	return w.size, nil
+}
+
+// location 返回快照文件所在的目录与文件名前缀，与 AOF 文件相同
+func location() (string, string, error) {
+	if config.Cache.Aof.Path == "" {
+		return "", "", errors.New("AOF path is empty, cannot locate snapshot files")
+	}
+	base := filepath.Base(config.Cache.Aof.Path)
+	return filepath.Dir(config.Cache.Aof.Path), strings.TrimSuffix(base, filepath.Ext(base)), nil
+}
+
+// syncDir 同步目录，确保重命名在崩溃后依然有效
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
+
+// writer 记录第一个错误的写入器，出错后的写入都被忽略
+type writer struct {
+	w    *bufio.Writer
+	size int64
+	err  error
+	buf  []byte
+}
+
+func (w *writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	w.err = err
+}
+
+func (w *writer) uvarint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf[:0], v)
+	w.write(w.buf)
+}
+
+func (w *writer) string(s string) {
+	w.uvarint(uint64(len(s)))
+	if w.err == nil {
+		n, err := w.w.WriteString(s)
+		w.size += int64(n)
+		w.err = err
+	}
+}
+
+func (w *writer) entry(e *Entry) {
+	w.write([]byte{byte(e.Type)})
+	w.buf = binary.AppendVarint(w.buf[:0], e.ExpireAt)
+	w.write(w.buf)
+	w.string(e.Key)
+
+	switch e.Type {
+	case common.HASH:
+		w.uvarint(uint64(len(e.Hash)))
+		for field, value := range e.Hash {
+			w.string(field)
+			w.string(value)
+		}
+	case common.LIST:
+		w.uvarint(uint64(len(e.List)))
+		for _, value := range e.List {
+			w.string(value)
+		}
+	case common.ZSET:
+		w.uvarint(uint64(len(e.ZSet)))
+		for member, score := range e.ZSet {
+			w.string(member)
+			w.write(binary.BigEndian.AppendUint64(w.buf[:0], math.Float64bits(score)))
+		}
+	default:
+		w.string(e.Value)
+	}
+}
+
+// reader 计算已读取内容校验和的读取器，记录第一个错误，出错后的读取都返回零值
+type reader struct {
+	br  *bufio.Reader
+	crc hash.Hash32
+	err error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.br.Read(p)
+	_, _ = r.crc.Write(p[:n])
+	return n, err
+}
+
+func (r *reader) ReadByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err == nil {
+		_, _ = r.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (r *reader) full(p []byte) {
+	if r.err != nil {
+		return
+	}
+	_, r.err = io.ReadFull(r, p)
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r)
+	r.err = err
+	return v
+}
+
+// count 读取元素个数，预分配的容量有上限，防止损坏的文件导致过大的内存分配
+func (r *reader) count() int {
+	n := r.uvarint()
+	if r.err == nil && n > math.MaxInt32 {
+		r.err = errors.New("invalid element count")
+	}
+	return int(n)
+}
+
+func (r *reader) string() string {
+	n := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if n > math.MaxInt32 {
+		r.err = errors.New("invalid string length")
+		return ""
+	}
+	var b strings.Builder
+	b.Grow(int(min(n, 64*1024)))
+	if _, err := io.CopyN(&b, r, int64(n)); err != nil {
+		r.err = err
+	}
+	return b.String()
+}
+
+func (r *reader) entry() Entry {
+	var e Entry
+	var typ [1]byte
+	r.full(typ[:])
+	e.Type = common.ValueType(typ[0])
+	if r.err == nil {
+		e.ExpireAt, r.err = binary.ReadVarint(r)
+	}
+	e.Key = r.string()
+
+	switch e.Type {
+	case common.HASH:
+		n := r.count()
+		e.Hash = make(map[string]string, min(n, 1024))
+		for i := 0; i < n && r.err == nil; i++ {
+			field := r.string()
+			e.Hash[field] = r.string()
+		}
+	case common.LIST:
+		n := r.count()
+		e.List = make([]string, 0, min(n, 1024))
+		for i := 0; i < n && r.err == nil; i++ {
+			e.List = append(e.List, r.string())
+		}
+	case common.ZSET:
+		n := r.count()
+		e.ZSet = make(map[string]float64, min(n, 1024))
+		var score [8]byte
+		for i := 0; i < n && r.err == nil; i++ {
+			member := r.string()
+			r.full(score[:])
+			e.ZSet[member] = math.Float64frombits(binary.BigEndian.Uint64(score[:]))
+		}
+	default:
+		e.Value = r.string()
+	}
+	return e
+}
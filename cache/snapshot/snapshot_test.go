@@ -0,0 +1,112 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sparrow_blog_server/cache/common"
+	"sparrow_blog_server/pkg/config"
+	"testing"
+)
+
+func useTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	oldPath := config.Cache.Aof.Path
+	config.Cache.Aof.Path = filepath.Join(dir, "snapshot.aof")
+	t.Cleanup(func() { config.Cache.Aof.Path = oldPath })
+	return dir
+}
+
+func TestSnapshot_SaveLoad(t *testing.T) {
+	useTempDir(t)
+
+	entries := []Entry{
+		{Key: "string", Type: common.STRING, Value: "含有;;分隔符\n的值"},
+		{Key: "int", Type: common.INT, Value: "-42", ExpireAt: 1700000000123},
+		{Key: "hash", Type: common.HASH, Hash: map[string]string{"a": "1", "": "empty"}},
+		{Key: "list", Type: common.LIST, List: []string{"c", "b", "a"}},
+		{Key: "zset", Type: common.ZSET, ZSet: map[string]float64{"m1": 0.1, "m2": -3}},
+	}
+	info, err := Save(entries)
+	if err != nil {
+		t.Fatalf("保存快照失败: %v", err)
+	}
+	if info.Entries != len(entries) || info.Size == 0 {
+		t.Errorf("快照信息不符合预期: %+v", info)
+	}
+	if stat, _ := os.Stat(info.Path); stat == nil || stat.Size() != info.Size {
+		t.Errorf("期望文件大小为 %d", info.Size)
+	}
+
+	loaded, err := Load(info.ID)
+	if err != nil {
+		t.Fatalf("加载快照失败: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, entries) {
+		t.Errorf("期望加载的条目与保存的一致\n期望: %+v\n实际: %+v", entries, loaded)
+	}
+}
+
+func TestSnapshot_Corruption(t *testing.T) {
+	useTempDir(t)
+
+	info, err := Save([]Entry{{Key: "key", Type: common.STRING, Value: "value"}})
+	if err != nil {
+		t.Fatalf("保存快照失败: %v", err)
+	}
+	data, _ := os.ReadFile(info.Path)
+
+	// 修改内容中的一个字节，校验和不一致
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)-6] ^= 0xff
+	_ = os.WriteFile(info.Path, flipped, 0600)
+	if _, err := Load(info.ID); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("期望修改内容后返回 ErrCorrupted，实际得到 %v", err)
+	}
+
+	// 截断文件
+	_ = os.WriteFile(info.Path, data[:len(data)-2], 0600)
+	if _, err := Load(info.ID); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("期望截断后返回 ErrCorrupted，实际得到 %v", err)
+	}
+}
+
+func TestSnapshot_ListAndRemove(t *testing.T) {
+	dir := useTempDir(t)
+	// 其他前缀的文件与 AOF 文件不受影响
+	_ = os.WriteFile(filepath.Join(dir, "other_1.rdb"), nil, 0600)
+	_ = os.WriteFile(filepath.Join(dir, "snapshot.aof"), nil, 0600)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		info, err := Save(nil)
+		if err != nil {
+			t.Fatalf("保存快照失败: %v", err)
+		}
+		ids = append(ids, info.ID)
+	}
+
+	infos, err := List()
+	if err != nil || len(infos) != 3 {
+		t.Fatalf("期望列出 3 个快照，实际得到 %d 个, %v", len(infos), err)
+	}
+	for i, info := range infos {
+		if info.ID != ids[i] {
+			t.Errorf("期望按生成时间排序，第 %d 个为 %d，实际为 %d", i, ids[i], info.ID)
+		}
+	}
+
+	if err := RemoveBefore(ids[2]); err != nil {
+		t.Fatalf("删除旧快照失败: %v", err)
+	}
+	infos, _ = List()
+	if len(infos) != 1 || infos[0].ID != ids[2] {
+		t.Errorf("期望只保留最新的快照，实际得到 %+v", infos)
+	}
+	for _, name := range []string{"other_1.rdb", "snapshot.aof"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("期望 %s 不受影响: %v", name, err)
+		}
+	}
+}
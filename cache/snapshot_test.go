@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/cache/snapshot"
+	"sparrow_blog_server/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_SaveAndRestart(t *testing.T) {
+	ctx := context.Background()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Enable = true
+	config.Cache.Aof.Path = filepath.Join(t.TempDir(), "snapshot.aof")
+	defer func() { config.Cache.Aof = oldAof }()
+
+	c, _ := NewCache(ctx)
+	for i := 0; i < 200; i++ {
+		_ = c.Set(ctx, "test:snapshot_counter", i)
+	}
+	_ = c.SetWithExpired(ctx, "test:snapshot_ttl", "val", time.Hour)
+	_ = c.Set(ctx, "test:snapshot_obj", struct{ Name string }{"sparrow"})
+	_ = c.HSet(ctx, "test:snapshot_hash", "field", "value")
+	_, _ = c.LPush(ctx, "test:snapshot_list", "a", "b")
+	_, _ = c.ZIncrBy(ctx, "test:snapshot_zset", "post:1", 1.5)
+
+	before := fileSize(t, config.Cache.Aof.Path)
+	info, err := c.SaveSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("生成快照失败: %v", err)
+	}
+	if after := fileSize(t, config.Cache.Aof.Path); after >= before {
+		t.Errorf("期望生成快照后 AOF 只保留快照标记，生成前 %d 字节，生成后 %d 字节", before, after)
+	}
+
+	// 快照之后的写入只记录在 AOF 中
+	_ = c.Set(ctx, "test:snapshot_after", "val")
+	_, _ = c.LPush(ctx, "test:snapshot_list", "c")
+	_ = c.Delete(ctx, "test:snapshot_obj")
+	_ = c.Close()
+
+	c, _ = NewCache(ctx)
+	if val, err := c.GetInt(ctx, "test:snapshot_counter"); err != nil || val != 199 {
+		t.Errorf("期望计数为 199，实际得到 %v, %v", val, err)
+	}
+	if _, err := c.Get(ctx, "test:snapshot_obj"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望快照之后删除的键不存在，实际得到 %v", err)
+	}
+	if item := c.shardFor("test:snapshot_ttl").items["test:snapshot_ttl"]; item == nil || item.expireAt.IsZero() {
+		t.Error("期望过期时间被恢复")
+	}
+	if val, err := c.HGet(ctx, "test:snapshot_hash", "field"); err != nil || val != "value" {
+		t.Errorf("期望哈希表被恢复，实际得到 %q, %v", val, err)
+	}
+	if got, _ := c.LRange(ctx, "test:snapshot_list", 0, -1); !slices.Equal(got, []string{"c", "b", "a"}) {
+		t.Errorf("期望列表为 [c b a]，实际得到 %q", got)
+	}
+	if got, _ := c.ZRange(ctx, "test:snapshot_zset", 0, -1, false); len(got) != 1 || got[0].Score != 1.5 {
+		t.Errorf("期望有序集合被恢复，实际得到 %v", got)
+	}
+	if _, err := c.Get(ctx, "test:snapshot_after"); err != nil {
+		t.Errorf("期望快照之后写入的键被恢复，实际得到 %v", err)
+	}
+
+	// 启动时生成了新的快照，旧的快照被删除
+	infos, _ := snapshot.List()
+	if len(infos) != 1 || infos[0].ID <= info.ID {
+		t.Errorf("期望只保留启动时生成的新快照，实际得到 %+v", infos)
+	}
+	_ = c.Close()
+
+	// 从备份恢复：清空 AOF，只保留快照文件
+	if err := os.Remove(config.Cache.Aof.Path); err != nil {
+		t.Fatalf("删除 AOF 失败: %v", err)
+	}
+	c, _ = NewCache(ctx)
+	defer func() { _ = c.Close() }()
+	if _, err := c.Get(ctx, "test:snapshot_after"); err != nil {
+		t.Errorf("期望从快照恢复数据，实际得到 %v", err)
+	}
+}
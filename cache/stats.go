@@ -0,0 +1,341 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sparrow_blog_server/cache/aof"
+	"sparrow_blog_server/cache/snapshot"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxInspectBytes    = 1024 // Inspect 返回的标量值最多的字节数
+	maxInspectElements = 100  // Inspect 返回的集合最多的元素数量
+)
+
+// statsCounter 一组键的命中、未命中与淘汰计数，只使用原子操作
+type statsCounter struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// statsTable 按键前缀统计的计数器，创建后不再修改，替换时整体替换
+// 前缀按长度从长到短排列，键同时匹配多个前缀时计入最长的一个。
+// 每个分片有独立的一组计数器，键的计数器与键在同一个分片中，并发访问不同分片的键不会争用同一个缓存行，
+// 统计时再将各分片的计数相加。
+type statsTable struct {
+	prefixes []string
+	stripes  [][]statsCounter // 每个分片一组，下标与 prefixes 对应，最后一个计数不匹配任何前缀的键
+}
+
+func newStatsTable(prefixes []string, shardCount int) *statsTable {
+	t := &statsTable{}
+	for _, prefix := range prefixes {
+		if prefix != "" && !slices.Contains(t.prefixes, prefix) {
+			t.prefixes = append(t.prefixes, prefix)
+		}
+	}
+	slices.SortStableFunc(t.prefixes, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+	t.stripes = make([][]statsCounter, shardCount)
+	for i := range t.stripes {
+		t.stripes[i] = make([]statsCounter, len(t.prefixes)+1)
+	}
+	return t
+}
+
+// counterFor 返回键对应的计数器，前缀数量很少，逐个比较的开销可以忽略
+func (t *statsTable) counterFor(key string) *statsCounter {
+	stripe := t.stripes[fnv32a(key)&uint32(len(t.stripes)-1)]
+	return &stripe[t.groupOf(key)]
+}
+
+// groupOf 返回键匹配的前缀下标，不匹配任何前缀时返回 len(prefixes)
+func (t *statsTable) groupOf(key string) int {
+	for i, prefix := range t.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return i
+		}
+	}
+	return len(t.prefixes)
+}
+
+// SetStatsPrefixes 设置按前缀统计命中、未命中与淘汰次数的键前缀，之前的计数被清空
+// 不匹配任何前缀的键统一计入前缀为空字符串的一组
+//
+// 参数:
+// - prefixes: 键前缀，例如 "blog_cache_"
+func (c *Cache) SetStatsPrefixes(prefixes ...string) {
+	c.stats.Store(newStatsTable(prefixes, len(c.shards)))
+}
+
+// recordHit 记录一次命中，读锁下也可以调用
+func (c *Cache) recordHit(key string) {
+	if t := c.stats.Load(); t != nil {
+		t.counterFor(key).hits.Add(1)
+	}
+}
+
+// recordMiss 记录一次未命中（键不存在或已过期），读锁下也可以调用
+func (c *Cache) recordMiss(key string) {
+	if t := c.stats.Load(); t != nil {
+		t.counterFor(key).misses.Add(1)
+	}
+}
+
+// recordEviction 记录一次因容量限制的淘汰
+func (c *Cache) recordEviction(key string) {
+	if t := c.stats.Load(); t != nil {
+		t.counterFor(key).evictions.Add(1)
+	}
+}
+
+// PrefixStats 一个键前缀的统计信息
+//
+// 字段:
+// - Prefix: 键前缀，空字符串表示不匹配任何统计前缀的键
+// - Keys: 当前的键数量，包括已过期但还未删除的键
+// - Bytes: 这些键估算的内存占用
+// - Hits/Misses: 设置统计前缀以来的命中与未命中次数，只统计读取值的操作
+// - Evictions: 设置统计前缀以来因容量限制被淘汰的次数
+// - HitRate: 命中率，没有读取时为 0
+type PrefixStats struct {
+	Prefix    string  `json:"prefix"`
+	Keys      int64   `json:"keys"`
+	Bytes     int64   `json:"bytes"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Evictions uint64  `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// Stats 缓存的统计信息
+//
+// 字段:
+// - Keys: 键的总数，包括已过期但还未删除的键
+// - Expired: 已过期但还未被后台清理删除的键数量
+// - UsedBytes: 所有条目估算的内存占用，与容量限制使用相同的估算
+// - MaxEntries/MaxBytes/Policy: 容量限制与淘汰策略
+// - Hits/Misses/Evictions: 所有前缀的合计
+// - Prefixes: 按前缀的统计，不匹配任何前缀的一组在最后
+// - Aof: AOF 的统计信息，未启用 AOF 时为 nil
+// - Snapshots: 现有的快照文件
+type Stats struct {
+	Keys       int64           `json:"keys"`
+	Expired    int64           `json:"expired"`
+	UsedBytes  int64           `json:"used_bytes"`
+	MaxEntries int             `json:"max_entries"`
+	MaxBytes   int64           `json:"max_bytes"`
+	Policy     string          `json:"policy"`
+	Hits       uint64          `json:"hits"`
+	Misses     uint64          `json:"misses"`
+	Evictions  uint64          `json:"evictions"`
+	Prefixes   []PrefixStats   `json:"prefixes"`
+	Aof        *aof.Stats      `json:"aof,omitempty"`
+	Snapshots  []snapshot.Info `json:"snapshots"`
+}
+
+// Stats 统计缓存的键数量、内存占用、命中率与持久化文件
+// 逐个分片以读锁遍历所有键，同一时间只锁住一个分片，不会长时间阻塞写入；
+// 遍历期间的修改可能只有一部分被统计到，结果是近似值。
+//
+// 参数:
+// - ctx: 上下文
+//
+// 返回:
+// - *Stats: 统计信息
+// - error: 上下文取消或读取持久化文件信息失败时返回错误
+func (c *Cache) Stats(ctx context.Context) (*Stats, error) {
+	t := c.stats.Load()
+	if t == nil {
+		t = newStatsTable(nil, len(c.shards))
+	}
+	groups := make([]PrefixStats, len(t.prefixes)+1)
+	for i, prefix := range t.prefixes {
+		groups[i].Prefix = prefix
+	}
+
+	stats := &Stats{
+		MaxEntries: c.eviction.MaxEntries,
+		MaxBytes:   c.eviction.MaxBytes,
+		Policy:     c.eviction.Policy,
+	}
+	now := time.Now()
+	for _, s := range c.shards {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		s.mu.RLock()
+		for key, item := range s.items {
+			group := &groups[t.groupOf(key)]
+			group.Keys++
+			group.Bytes += item.size
+			if item.expired(now) {
+				stats.Expired++
+			}
+		}
+		s.mu.RUnlock()
+	}
+
+	// 计数在遍历之后读取，遍历期间的访问也会被统计
+	for _, stripe := range t.stripes {
+		for i := range stripe {
+			groups[i].Hits += stripe[i].hits.Load()
+			groups[i].Misses += stripe[i].misses.Load()
+			groups[i].Evictions += stripe[i].evictions.Load()
+		}
+	}
+	for i := range groups {
+		group := &groups[i]
+		if total := group.Hits + group.Misses; total > 0 {
+			group.HitRate = float64(group.Hits) / float64(total)
+		}
+		stats.Keys += group.Keys
+		stats.UsedBytes += group.Bytes
+		stats.Hits += group.Hits
+		stats.Misses += group.Misses
+		stats.Evictions += group.Evictions
+	}
+	stats.Prefixes = groups
+
+	// AOF 只在持有分片锁或关闭时修改，这里读取一次引用即可
+	c.shards[0].mu.RLock()
+	a := c.aof
+	c.shards[0].mu.RUnlock()
+	if a != nil {
+		aofStats, err := a.Stats()
+		if err != nil {
+			return nil, err
+		}
+		stats.Aof = aofStats
+	}
+
+	infos, err := snapshot.List()
+	if err != nil {
+		return nil, err
+	}
+	stats.Snapshots = infos
+	return stats, nil
+}
+
+// KeyInfo 单个键的详细信息
+//
+// 字段:
+// - Key: 条目键
+// - Type: 值类型的名称
+// - Size: 估算的内存占用
+// - Length: 集合类型的元素数量，标量为 0
+// - ExpireAt: 过期时间，永不过期时为 nil
+// - TTL: 剩余的存活时间（毫秒），永不过期时为 -1
+// - IdleTime: 距最近一次访问的时间（毫秒）
+// - Value: 值的预览，过长的字符串与过大的集合被截断，列表按从头到尾、有序集合按分数从低到高排列
+// - Truncated: 预览是否被截断
+type KeyInfo struct {
+	Key       string     `json:"key"`
+	Type      string     `json:"type"`
+	Size      int64      `json:"size"`
+	Length    int        `json:"length"`
+	ExpireAt  *time.Time `json:"expire_at,omitempty"`
+	TTL       int64      `json:"ttl"`
+	IdleTime  int64      `json:"idle_time"`
+	Value     any        `json:"value"`
+	Truncated bool       `json:"truncated"`
+}
+
+// Inspect 查看单个键的类型、大小、过期时间与值的预览
+// 只读取，不更新最近访问时间与访问频率，也不计入命中统计，不会影响淘汰与统计结果
+//
+// 参数:
+// - ctx: 上下文
+// - key: 条目键
+//
+// 返回:
+// - *KeyInfo: 键的详细信息
+// - error: 键为空、上下文取消时返回错误，键不存在或已过期时返回 ErrNotFound
+func (c *Cache) Inspect(ctx context.Context, key string) (*KeyInfo, error) {
+	if len(strings.TrimSpace(key)) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s := c.shardFor(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	item, exists := s.items[key]
+	if !exists || item.expired(now) {
+		return nil, NewNotFoundError("键不存在：" + key)
+	}
+
+	info := &KeyInfo{
+		Key:      key,
+		Type:     item.vt.Name(),
+		Size:     item.size,
+		TTL:      -1,
+		IdleTime: now.Sub(time.Unix(0, item.accessAt.Load())).Milliseconds(),
+	}
+	if !item.expireAt.IsZero() {
+		expireAt := item.expireAt
+		info.ExpireAt = &expireAt
+		info.TTL = expireAt.Sub(now).Milliseconds()
+	}
+	if isCollection(item.vt) {
+		info.Length = collectionLen(item)
+		info.Truncated = info.Length > maxInspectElements
+	}
+
+	switch v := item.value.(type) {
+	case map[string]string:
+		fields := slices.Sorted(maps.Keys(v))
+		preview := make(map[string]string, min(len(fields), maxInspectElements))
+		for _, field := range fields[:min(len(fields), maxInspectElements)] {
+			preview[field] = v[field]
+		}
+		info.Value = preview
+	case []string:
+		// 列表反向存储，切片末尾是表头
+		preview := make([]string, 0, min(len(v), maxInspectElements))
+		for i := len(v) - 1; i >= 0 && len(preview) < maxInspectElements; i-- {
+			preview = append(preview, v[i])
+		}
+		info.Value = preview
+	case map[string]float64:
+		members := sortedMembers(v, false)
+		info.Value = members[:min(len(members), maxInspectElements)]
+	case []byte:
+		info.Value, info.Truncated = truncateString(string(v))
+	case string:
+		info.Value, info.Truncated = truncateString(v)
+	default:
+		info.Value = v
+	}
+	return info, nil
+}
+
+// truncateString 将字符串截断到 maxInspectBytes 字节以内，不拆分 UTF-8 字符
+func truncateString(s string) (string, bool) {
+	if len(s) <= maxInspectBytes {
+		return s, false
+	}
+	end := maxInspectBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end], true
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sparrow_blog_server/pkg/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStats_Counters(t *testing.T) {
+	ctx := context.Background()
+	oldAof := config.Cache.Aof
+	config.Cache.Aof.Enable = true
+	config.Cache.Aof.Path = filepath.Join(t.TempDir(), "stats.aof")
+	defer func() { config.Cache.Aof = oldAof }()
+
+	c, _ := NewCache(ctx)
+	defer func() { _ = c.Close() }()
+	c.SetStatsPrefixes("blog_", "blog_read_", "img_", "")
+	c.eviction = config.EvictionConfig{MaxEntries: 4, Policy: config.EvictionLRU, Samples: 10}
+
+	_ = c.Set(ctx, "blog_1", "val")
+	_ = c.Set(ctx, "blog_read_1", 1)
+	_ = c.HSet(ctx, "img_1", "field", "val")
+	_ = c.Set(ctx, "other", "val")
+
+	_, _ = c.Get(ctx, "blog_1")
+	_, _ = c.Get(ctx, "blog_2")
+	_, _ = c.GetInt(ctx, "blog_read_1")
+	_, _ = c.HGet(ctx, "img_1", "field")
+	_, _ = c.HGet(ctx, "img_2", "field")
+	_, _ = c.Get(ctx, "other_missing")
+
+	// 超过数量限制，最久未访问的 other 被淘汰
+	time.Sleep(time.Millisecond)
+	_, _ = c.Get(ctx, "blog_1")
+	_, _ = c.Get(ctx, "blog_read_1")
+	_, _ = c.HGet(ctx, "img_1", "field")
+	_ = c.Set(ctx, "img_3", "val")
+
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("获取统计信息失败: %v", err)
+	}
+	want := []PrefixStats{
+		{Prefix: "blog_read_", Keys: 1, Hits: 2},
+		{Prefix: "blog_", Keys: 1, Hits: 2, Misses: 1},
+		{Prefix: "img_", Keys: 2, Hits: 2, Misses: 1},
+		{Prefix: "", Keys: 0, Misses: 1, Evictions: 1},
+	}
+	if len(stats.Prefixes) != len(want) {
+		t.Fatalf("期望 %d 组前缀统计，实际得到 %+v", len(want), stats.Prefixes)
+	}
+	for i, w := range want {
+		got := stats.Prefixes[i]
+		if got.Prefix != w.Prefix || got.Keys != w.Keys || got.Hits != w.Hits || got.Misses != w.Misses || got.Evictions != w.Evictions {
+			t.Errorf("第 %d 组期望 %+v，实际得到 %+v", i, w, got)
+		}
+		if got.Keys > 0 && got.Bytes == 0 {
+			t.Errorf("期望前缀 %q 的内存占用大于 0", got.Prefix)
+		}
+	}
+	if stats.Keys != c.count.Load() || stats.UsedBytes != c.usedBytes.Load() {
+		t.Errorf("期望合计与缓存的计数一致，实际得到 %d 个键 %d 字节", stats.Keys, stats.UsedBytes)
+	}
+	if stats.Hits != 6 || stats.Misses != 3 || stats.Evictions != 1 || stats.MaxEntries != 4 {
+		t.Errorf("合计不符合预期: %+v", stats)
+	}
+	if stats.Aof == nil || len(stats.Aof.Files) != 1 || stats.Aof.TotalSize == 0 || len(stats.Snapshots) != 1 {
+		t.Errorf("期望包含 AOF 文件与启动时生成的快照，实际得到 %+v %+v", stats.Aof, stats.Snapshots)
+	}
+}
+
+func TestStats_Inspect(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newCache(ctx, 4)
+	defer func() { _ = c.Close() }()
+
+	long := strings.Repeat("中", maxInspectBytes)
+	_ = c.SetWithExpired(ctx, "inspect:string", long, time.Hour)
+	_ = c.Set(ctx, "inspect:obj", struct{ Name string }{"sparrow"})
+	for i := 0; i < maxInspectElements+10; i++ {
+		_, _ = c.LPush(ctx, "inspect:list", strings.Repeat("x", i+1))
+	}
+	_, _ = c.ZIncrBy(ctx, "inspect:zset", "b", 2)
+	_, _ = c.ZIncrBy(ctx, "inspect:zset", "a", 1)
+
+	item := c.shardFor("inspect:string").items["inspect:string"]
+	accessAt := item.accessAt.Load()
+
+	info, err := c.Inspect(ctx, "inspect:string")
+	if err != nil {
+		t.Fatalf("查看键失败: %v", err)
+	}
+	value := info.Value.(string)
+	if info.Type != "string" || !info.Truncated || len(value) > maxInspectBytes || !strings.HasPrefix(long, value) {
+		t.Errorf("期望按 UTF-8 字符截断字符串，实际得到类型 %s，长度 %d", info.Type, len(value))
+	}
+	if info.ExpireAt == nil || info.TTL <= 0 || info.TTL > time.Hour.Milliseconds() {
+		t.Errorf("期望返回剩余的存活时间，实际得到 %d", info.TTL)
+	}
+	if item.accessAt.Load() != accessAt {
+		t.Error("期望查看键不更新最近访问时间")
+	}
+	if stats, _ := c.Stats(ctx); stats.Hits != 0 {
+		t.Errorf("期望查看键不计入命中统计，实际得到 %d", stats.Hits)
+	}
+
+	info, _ = c.Inspect(ctx, "inspect:obj")
+	if info.Type != "object" || info.Value != `{"Name":"sparrow"}` || info.TTL != -1 {
+		t.Errorf("对象的信息不符合预期: %+v", info)
+	}
+
+	info, _ = c.Inspect(ctx, "inspect:list")
+	list := info.Value.([]string)
+	if info.Length != maxInspectElements+10 || !info.Truncated || len(list) != maxInspectElements ||
+		list[0] != strings.Repeat("x", maxInspectElements+10) {
+		t.Errorf("期望从表头开始预览列表，实际得到长度 %d，预览 %d 个", info.Length, len(list))
+	}
+
+	info, _ = c.Inspect(ctx, "inspect:zset")
+	members := info.Value.([]ZMember)
+	if info.Truncated || len(members) != 2 || members[0].Member != "a" {
+		t.Errorf("期望按分数排序预览有序集合，实际得到 %+v", members)
+	}
+
+	if _, err := c.Inspect(ctx, "inspect:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("期望不存在的键返回 ErrNotFound，实际得到 %v", err)
+	}
+}
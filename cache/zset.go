@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"sparrow_blog_server/cache/common"
+	"strconv"
+)
+
+// ZMember 有序集合中的成员及其分数
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// ZIncrBy 原子地将有序集合中成员的分数加上 delta，成员不存在时视为 0，键不存在时创建新的有序集合
+// ctx     用于取消操作的上下文
+// key     条目键
+// member  成员
+// delta   增量，可以为负数
+//
+// 返回:
+// - float64  操作后的分数
+// - error    可能的错误:
+//   - ErrTypeMismatch 键的类型不是有序集合
+//   - ErrOutOfRange 增量或结果不是有效的数值
+//
+// 注意:
+// - 新建的有序集合没有过期时间，修改已有的有序集合保留原有的过期时间
+// - AOF 中记录为设置计算后分数的 ZADD 命令
+func (c *Cache) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	if math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return 0, NewOutOfRangeError("增量不是有效的数值")
+	}
+
+	var result float64
+	err := c.modifyCollection(ctx, key, common.ZSET, true, func(s *shard, item *cacheItem) error {
+		result = item.value.(map[string]float64)[member] + delta
+		if math.IsInf(result, 0) {
+			return NewOutOfRangeError("分数超出float64范围")
+		}
+		c.zaddLocked(s, item, member, result)
+		return c.appendAof(ctx, common.ZADD, key, member, formatScore(result))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// ZRange 按分数排序后返回有序集合中从 start 到 stop（包含）的成员，分数相同时按成员排序
+// ctx    用于取消操作的上下文
+// key    条目键
+// start  起始位置，负数表示从末尾倒数，-1 为最后一个成员
+// stop   结束位置，负数表示从末尾倒数
+// rev    为 true 时按分数从高到低排序，例如 ZRange(ctx, key, 0, 9, true) 返回分数最高的 10 个成员
+//
+// 返回:
+// - []ZMember  范围内的成员及其分数，键不存在或范围为空时返回空切片
+// - error      键的类型不是有序集合时返回 ErrTypeMismatch
+//
+// 注意:
+// - 有序集合以映射存储，每次查询时排序，适合成员数量不大的排行榜
+func (c *Cache) ZRange(ctx context.Context, key string, start, stop int, rev bool) ([]ZMember, error) {
+	result := make([]ZMember, 0)
+	err := c.readCollection(ctx, key, common.ZSET, func(item *cacheItem) error {
+		if item == nil {
+			return nil
+		}
+		zset := item.value.(map[string]float64)
+		start, stop, ok := listRange(len(zset), start, stop)
+		if !ok {
+			return nil
+		}
+
+		result = append(result, sortedMembers(zset, rev)[start:stop+1]...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sortedMembers 按分数排序有序集合的所有成员，分数相同时按成员排序，rev 为 true 时从高到低
+func sortedMembers(zset map[string]float64, rev bool) []ZMember {
+	members := make([]ZMember, 0, len(zset))
+	for member, score := range zset {
+		members = append(members, ZMember{Member: member, Score: score})
+	}
+	slices.SortFunc(members, func(a, b ZMember) int {
+		if rev {
+			a, b = b, a
+		}
+		return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Member, b.Member))
+	})
+	return members
+}
+
+// zaddLocked 设置有序集合中成员的分数并维护内存占用，调用方需持有分片的写锁
+func (c *Cache) zaddLocked(s *shard, item *cacheItem, member string, score float64) {
+	zset := item.value.(map[string]float64)
+	if _, ok := zset[member]; !ok {
+		c.resizeLocked(s, item, int64(elementOverhead+len(member)+8))
+	}
+	zset[member] = score
+}
+
+// formatScore 将分数转换为 AOF 中的参数，重放时可以精确还原
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
@@ -22,6 +22,8 @@ type BlogDto struct {
 	BlogState    bool         `json:"blog_state"`
 	BlogWordsNum uint64       `json:"blog_words_num,omitempty"`
 	BlogIsTop    bool         `json:"blog_is_top"`
+	PublishAt    *time.Time   `json:"publish_at,omitempty"`
+	UnpublishAt  *time.Time   `json:"unpublish_at,omitempty"`
 	CreateTime   time.Time    `json:"create_time,omitempty"`
 	UpdateTime   time.Time    `json:"update_time,omitempty"`
 }
@@ -34,6 +36,83 @@ func (hb *BlogDto) Name() string {
 	return hb.BlogTitle
 }
 
+// BlogRevisionDto 博客修订数据
+type BlogRevisionDto struct {
+	RevisionId   string    `json:"revision_id,omitempty"`
+	BlogId       string    `json:"blog_id,omitempty"`
+	BlogTitle    string    `json:"blog_title,omitempty"`
+	BlogBrief    string    `json:"blog_brief,omitempty"`
+	BlogWordsNum uint64    `json:"blog_words_num,omitempty"`
+	ContentHash  string    `json:"content_hash,omitempty"`
+	ObjectPath   string    `json:"object_path,omitempty"`
+	CreateTime   time.Time `json:"create_time,omitempty"`
+}
+
+func (br *BlogRevisionDto) DtoFlag() string {
+	return "BlogRevisionDto"
+}
+
+// SpamTokenDto 评论反垃圾分类器中一个分词的统计数据
+type SpamTokenDto struct {
+	Token     string `json:"token"`
+	SpamCount int    `json:"spam_count"`
+	HamCount  int    `json:"ham_count"`
+}
+
+func (st *SpamTokenDto) DtoFlag() string {
+	return "SpamTokenDto"
+}
+
+// EmailOutboxDto 发件箱中的一封邮件
+type EmailOutboxDto struct {
+	EmailId        string     `json:"email_id,omitempty"`
+	Recipient      string     `json:"recipient,omitempty"`
+	Subject        string     `json:"subject,omitempty"`
+	Content        string     `json:"content,omitempty"`
+	TextContent    string     `json:"text_content,omitempty"`
+	UnsubscribeUrl string     `json:"unsubscribe_url,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	SentTime       *time.Time `json:"sent_time,omitempty"`
+	CreateTime     time.Time  `json:"create_time,omitempty"`
+	UpdateTime     time.Time  `json:"update_time,omitempty"`
+}
+
+// 发件箱邮件的发送状态
+const (
+	EmailStatusPending   = "pending"   // 等待发送，包括等待重试
+	EmailStatusSending   = "sending"   // 正在发送，进程在发送期间退出时启动后重新发送
+	EmailStatusSent      = "sent"      // 已发送
+	EmailStatusDead      = "dead"      // 超过最大重试次数，需要管理员处理
+	EmailStatusCancelled = "cancelled" // 已被管理员取消
+)
+
+func (e *EmailOutboxDto) DtoFlag() string {
+	return "EmailOutboxDto"
+}
+
+func (e *EmailOutboxDto) Name() string {
+	return e.EmailId
+}
+
+// CommentSubscriptionDto 评论者对一层楼回复通知的订阅，楼层以楼主评论 ID 标识
+type CommentSubscriptionDto struct {
+	SubscriberEmail string    `json:"subscriber_email"`
+	ThreadId        string    `json:"thread_id"`
+	BlogId          string    `json:"blog_id"`
+	CreateTime      time.Time `json:"create_time,omitempty"`
+}
+
+func (cs *CommentSubscriptionDto) DtoFlag() string {
+	return "CommentSubscriptionDto"
+}
+
+func (br *BlogRevisionDto) Name() string {
+	return br.RevisionId
+}
+
 type BlogReadCountDto struct {
 	ReadId    string `json:"read_id,omitempty"`
 	BlogId    string `json:"blog_id,omitempty"`
@@ -108,14 +187,43 @@ func (i *ImgsDto) Name() string {
 type CommentDto struct {
 	CommentId        string    `json:"comment_id,omitempty"`
 	CommenterEmail   string    `json:"commenter_email,omitempty"`
+	Nickname         string    `json:"nickname,omitempty"`
+	Website          string    `json:"website_url,omitempty"` // 个人网站，website 已被蜜罐字段使用
 	BlogId           string    `json:"blog_id,omitempty"`
 	OriginPostId     string    `json:"origin_post_id,omitempty"`
 	ReplyToCommentId string    `json:"reply_to_comment_id,omitempty"`
 	ReplyToCommenter string    `json:"reply_to_commenter,omitempty"`
+	ReplyToNickname  string    `json:"reply_to_nickname,omitempty"`
 	Content          string    `json:"content,omitempty"`
+	ContentHtml      string    `json:"-"`                   // 评论内容渲染后的 HTML，由服务端渲染，不接受客户端传入
+	Status           string    `json:"-"`                   // 审核状态，由服务端根据审核模式设置，不接受客户端传入
+	Honeypot         string    `json:"website,omitempty"`   // 蜜罐字段，前端隐藏，正常用户不会填写，不保存
+	Subscribe        bool      `json:"subscribe,omitempty"` // 是否订阅本楼的回复通知，只在提交评论时使用，不保存到评论表
+	ReplyCount       int64     `json:"-"`                   // 已通过审核的回复数量，只在分页查询楼主评论时设置
 	CreateTime       time.Time `json:"create_time,omitempty"`
 }
 
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"  // 待审核，不在前台展示
+	CommentStatusApproved = "approved" // 已通过
+	CommentStatusRejected = "rejected" // 已拒绝
+)
+
+// 楼主评论的排序方式
+const (
+	CommentSortNewest      = "newest"       // 最新发表的在前
+	CommentSortOldest      = "oldest"       // 最早发表的在前
+	CommentSortMostReplied = "most_replied" // 回复最多的在前，回复数量相同时最新发表的在前
+)
+
+// CommentCursor 评论分页游标，记录上一页最后一条评论的排序字段，下一页从它之后开始
+type CommentCursor struct {
+	CreateTime time.Time // 创建时间
+	CommentId  string    // 评论 ID，创建时间相同时用于确定顺序
+	ReplyCount int64     // 回复数量，只在按回复数量排序时使用
+}
+
 func (c *CommentDto) DtoFlag() string {
 	return "CommentDto"
 }
@@ -3,22 +3,84 @@ package po
 import "time"
 
 type Blog struct {
-	BlogId       string    `gorm:"column:blog_id;primaryKey"`                                   // 博客 ID
-	BlogTitle    string    `gorm:"column:blog_title;unique"`                                    // 博客标题
-	BlogImageId  string    `gorm:"column:blog_image_id"`                                        // 博客图片
-	BlogBrief    string    `gorm:"column:blog_brief"`                                           // 博客简介
-	CategoryId   string    `gorm:"column:category_id"`                                          // 逻辑外键字段（无约束）
-	BlogState    bool      `gorm:"column:blog_state"`                                           // 博客状态
-	BlogWordsNum uint64    `gorm:"column:blog_words_num"`                                       // 博客字数
-	BlogIsTop    bool      `gorm:"column:blog_is_top"`                                          // 是否置顶
-	CreateTime   time.Time `gorm:"column:create_time;default:CURRENT_TIMESTAMP"`                // 创建时间
-	UpdateTime   time.Time `gorm:"column:update_time;default:CURRENT_TIMESTAMP;autoUpdateTime"` // 更新时间
+	BlogId       string     `gorm:"column:blog_id;primaryKey"`                                   // 博客 ID
+	BlogTitle    string     `gorm:"column:blog_title;unique"`                                    // 博客标题
+	BlogImageId  string     `gorm:"column:blog_image_id"`                                        // 博客图片
+	BlogBrief    string     `gorm:"column:blog_brief"`                                           // 博客简介
+	CategoryId   string     `gorm:"column:category_id"`                                          // 逻辑外键字段（无约束）
+	BlogState    bool       `gorm:"column:blog_state"`                                           // 博客状态
+	BlogWordsNum uint64     `gorm:"column:blog_words_num"`                                       // 博客字数
+	BlogIsTop    bool       `gorm:"column:blog_is_top"`                                          // 是否置顶
+	PublishAt    *time.Time `gorm:"column:publish_at"`                                           // 定时发布时间
+	UnpublishAt  *time.Time `gorm:"column:unpublish_at"`                                         // 定时下线时间
+	CreateTime   time.Time  `gorm:"column:create_time;default:CURRENT_TIMESTAMP"`                // 创建时间
+	UpdateTime   time.Time  `gorm:"column:update_time;default:CURRENT_TIMESTAMP;autoUpdateTime"` // 更新时间
 }
 
 func (hb *Blog) TableName() string {
 	return "BLOG"
 }
 
+// BlogRevision 博客修订记录，每次保存博客都会生成一条，内容保存在对象存储中
+type BlogRevision struct {
+	RevisionId   string    `gorm:"column:revision_id;primaryKey"`                // 修订 ID
+	BlogId       string    `gorm:"column:blog_id"`                               // 博客 ID
+	BlogTitle    string    `gorm:"column:blog_title"`                            // 保存时的博客标题
+	BlogBrief    string    `gorm:"column:blog_brief"`                            // 保存时的博客简介
+	BlogWordsNum uint64    `gorm:"column:blog_words_num"`                        // 保存时的博客字数
+	ContentHash  string    `gorm:"column:content_hash"`                          // 内容 SHA256
+	ObjectPath   string    `gorm:"column:object_path"`                           // 修订内容在对象存储中的路径
+	CreateTime   time.Time `gorm:"column:create_time;default:CURRENT_TIMESTAMP"` // 创建时间
+}
+
+func (br *BlogRevision) TableName() string {
+	return "BLOG_REVISION"
+}
+
+type SpamToken struct {
+	Token      string    `gorm:"column:token;primaryKey"`                      // 分词
+	SpamCount  int       `gorm:"column:spam_count"`                            // 出现在垃圾评论中的次数
+	HamCount   int       `gorm:"column:ham_count"`                             // 出现在正常评论中的次数
+	UpdateTime time.Time `gorm:"column:update_time;default:CURRENT_TIMESTAMP"` // 更新时间
+}
+
+func (st *SpamToken) TableName() string {
+	return "SPAM_TOKEN"
+}
+
+// EmailOutbox 发件箱中的一封邮件，写入后由后台发送，失败时按退避时间重试
+type EmailOutbox struct {
+	EmailId        string     `gorm:"column:email_id;primaryKey"`                                  // 邮件 ID
+	Recipient      string     `gorm:"column:recipient"`                                            // 收件人邮箱
+	Subject        string     `gorm:"column:subject"`                                              // 邮件主题
+	Content        string     `gorm:"column:content"`                                              // 渲染后的 HTML 正文
+	TextContent    string     `gorm:"column:text_content"`                                         // 渲染后的纯文本正文
+	UnsubscribeUrl string     `gorm:"column:unsubscribe_url"`                                      // 退订链接
+	Status         string     `gorm:"column:email_status;default:pending"`                         // 发送状态
+	Attempts       int        `gorm:"column:attempts"`                                             // 已尝试发送的次数
+	NextAttemptAt  time.Time  `gorm:"column:next_attempt_at"`                                      // 下次尝试发送的时间
+	LastError      string     `gorm:"column:last_error"`                                           // 最近一次发送失败的原因
+	SentTime       *time.Time `gorm:"column:sent_time"`                                            // 发送成功的时间
+	CreateTime     time.Time  `gorm:"column:create_time;default:CURRENT_TIMESTAMP"`                // 创建时间
+	UpdateTime     time.Time  `gorm:"column:update_time;default:CURRENT_TIMESTAMP;autoUpdateTime"` // 更新时间
+}
+
+func (eo *EmailOutbox) TableName() string {
+	return "EMAIL_OUTBOX"
+}
+
+// CommentSubscription 评论者对一层楼回复通知的订阅
+type CommentSubscription struct {
+	SubscriberEmail string    `gorm:"column:subscriber_email;primaryKey"`           // 订阅者邮箱
+	ThreadId        string    `gorm:"column:thread_id;primaryKey"`                  // 楼主评论 ID
+	BlogId          string    `gorm:"column:blog_id"`                               // 博客 ID
+	CreateTime      time.Time `gorm:"column:create_time;default:CURRENT_TIMESTAMP"` // 订阅时间
+}
+
+func (cs *CommentSubscription) TableName() string {
+	return "COMMENT_SUBSCRIPTION"
+}
+
 type BlogReadCount struct {
 	ReadId    string `gorm:"column:read_id;primaryKey"`
 	BlogId    string `gorm:"column:blog_id"`
@@ -97,10 +159,14 @@ func (ii *H2Img) TableName() string {
 type Comment struct {
 	CommentId        string    `gorm:"column:comment_id;primaryKey"`                                // 评论 ID
 	CommenterEmail   string    `gorm:"column:commenter_email"`                                      // 评论者邮箱
+	Nickname         string    `gorm:"column:commenter_nickname"`                                   // 评论者昵称
+	Website          string    `gorm:"column:commenter_website"`                                    // 评论者个人网站
 	BlogId           string    `gorm:"column:blog_id"`                                              // 博客 ID
 	OriginPostId     string    `gorm:"column:original_poster_id"`                                   // 楼主评论 ID（用于分组，空值表示自己就是楼主评论）
 	ReplyToCommentId string    `gorm:"column:reply_to_comment_id"`                                  // 回复的评论 ID（具体回复哪条评论，空值表示不回复任何评论）
 	Content          string    `gorm:"column:comment_content"`                                      // 评论内容
+	ContentHtml      string    `gorm:"column:comment_html"`                                         // 评论内容渲染后的 HTML
+	Status           string    `gorm:"column:comment_status;default:approved"`                      // 审核状态
 	CreateTime       time.Time `gorm:"column:create_time;default:CURRENT_TIMESTAMP"`                // 创建时间
 	UpdateTime       time.Time `gorm:"column:update_time;default:CURRENT_TIMESTAMP;autoUpdateTime"` // 更新时间
 }
@@ -17,6 +17,8 @@ type BlogVo struct {
 	BlogState    bool        `json:"blog_state"`
 	BlogWordsNum uint64      `json:"blog_words_num,omitempty"`
 	BlogIsTop    bool        `json:"blog_is_top"`
+	PublishAt    *time.Time  `json:"publish_at,omitempty"`
+	UnpublishAt  *time.Time  `json:"unpublish_at,omitempty"`
 	CreateTime   time.Time   `json:"create_time,omitempty"`
 	UpdateTime   time.Time   `json:"update_time,omitempty"`
 }
@@ -25,6 +27,20 @@ func (bv *BlogVo) VoFlag() string {
 	return "BlogVo"
 }
 
+// BlogRevisionVo 博客修订记录
+type BlogRevisionVo struct {
+	RevisionId   string    `json:"revision_id,omitempty"`
+	BlogId       string    `json:"blog_id,omitempty"`
+	BlogTitle    string    `json:"blog_title,omitempty"`
+	BlogBrief    string    `json:"blog_brief,omitempty"`
+	BlogWordsNum uint64    `json:"blog_words_num,omitempty"`
+	CreateTime   time.Time `json:"create_time,omitempty"`
+}
+
+func (brv *BlogRevisionVo) VoFlag() string {
+	return "BlogRevisionVo"
+}
+
 type TagVo struct {
 	TagId   string `json:"tag_id,omitempty"`
 	TagName string `json:"tag_name,omitempty"`
@@ -56,16 +72,35 @@ func (iv *ImgVo) VoFlag() string {
 
 type CommentVo struct {
 	CommentId        string      `json:"comment_id,omitempty"`
-	CommenterEmail   string      `json:"commenter_email,omitempty"`
+	CommenterEmail   string      `json:"commenter_email,omitempty"` // 前台接口中为打码后的邮箱
+	Nickname         string      `json:"nickname,omitempty"`
+	Website          string      `json:"website_url,omitempty"`
+	AvatarHash       string      `json:"avatar_hash,omitempty"` // 邮箱的 MD5 摘要，用于 Gravatar/Cravatar 头像
+	AvatarUrl        string      `json:"avatar_url,omitempty"`
 	BlogId           string      `json:"blog_id,omitempty"`
 	BlogTitle        string      `json:"blog_title,omitempty"`
 	OriginPostId     string      `json:"origin_post_id,omitempty"`
-	ReplyToCommenter string      `json:"reply_to_commenter,omitempty"`
+	ReplyToCommenter string      `json:"reply_to_commenter,omitempty"` // 前台接口中为打码后的邮箱
+	ReplyToNickname  string      `json:"reply_to_nickname,omitempty"`
 	Content          string      `json:"content,omitempty"`
+	ContentHtml      string      `json:"content_html,omitempty"` // 渲染并清理后的评论 HTML，前台应当展示此字段而不是 content
+	Status           string      `json:"status,omitempty"`
 	CreateTime       time.Time   `json:"create_time,omitempty"`
 	SubComments      []CommentVo `json:"sub_comments,omitempty"`
+	ReplyCount       int64       `json:"reply_count,omitempty"` // 楼层中已通过审核的回复数量，只在分页查询楼主评论时返回
 }
 
 func (cv *CommentVo) VoFlag() string {
 	return "CommentVo"
 }
+
+// CommentPageVo 评论的分页查询结果
+type CommentPageVo struct {
+	Comments   []CommentVo `json:"comments"`
+	NextCursor string      `json:"next_cursor,omitempty"` // 下一页的游标，没有更多评论时为空
+	HasMore    bool        `json:"has_more"`
+}
+
+func (cpv *CommentPageVo) VoFlag() string {
+	return "CommentPageVo"
+}
@@ -10,6 +10,7 @@ import (
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/pkg/utils"
 	"sparrow_blog_server/storage"
+	"time"
 )
 
 // FindBlogTitleById 根据博客ID查询博客标题。
@@ -38,6 +39,29 @@ func FindBlogTitleById(ctx context.Context, id string) (string, error) {
 	return blog.BlogTitle, nil
 }
 
+// FindBlogIdByTitle 根据博客标题查询博客ID，用于检查标题是否已被其它博客使用。
+// 参数:
+//   - ctx: 上下文对象，用于控制请求的生命周期和传递元数据；
+//   - title: 博客标题。
+//
+// 返回值:
+//   - string: 使用该标题的博客ID，没有博客使用该标题时为空字符串；
+//   - error: 如果查询过程中发生错误，则返回错误信息；否则返回nil。
+func FindBlogIdByTitle(ctx context.Context, title string) (string, error) {
+	blog := &po.Blog{}
+
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.Blog{}).
+		Where("blog_title = ?", title).
+		Select("blog_id").
+		Find(&blog).Error; err != nil {
+		msg := fmt.Sprintf("查询博客信息失败: %v", err)
+		logger.Warn(msg)
+		return "", errors.New(msg)
+	}
+
+	return blog.BlogId, nil
+}
+
 // FindBlogById 根据博客ID查询博客信息。
 // 参数:
 //   - ctx: 上下文对象，用于控制请求的生命周期和传递上下文信息。
@@ -67,6 +91,8 @@ func FindBlogById(ctx context.Context, id string) (*dto.BlogDto, error) {
 		CategoryId:   blog.CategoryId,
 		BlogState:    blog.BlogState,
 		BlogIsTop:    blog.BlogIsTop,
+		PublishAt:    blog.PublishAt,
+		UnpublishAt:  blog.UnpublishAt,
 		CreateTime:   blog.CreateTime,
 		UpdateTime:   blog.UpdateTime,
 	}, nil
@@ -98,6 +124,8 @@ func FindAllBlogs(ctx context.Context, needBrief bool) ([]*dto.BlogDto, error) {
 				"blog_state",
 				"blog_words_num",
 				"blog_is_top",
+				"publish_at",
+				"unpublish_at",
 				"create_time",
 				"update_time",
 			).
@@ -114,6 +142,8 @@ func FindAllBlogs(ctx context.Context, needBrief bool) ([]*dto.BlogDto, error) {
 				"blog_state",
 				"blog_words_num",
 				"blog_is_top",
+				"publish_at",
+				"unpublish_at",
 				"create_time",
 				"update_time",
 			).
@@ -142,6 +172,8 @@ func FindAllBlogs(ctx context.Context, needBrief bool) ([]*dto.BlogDto, error) {
 			BlogState:    blog.BlogState,
 			BlogWordsNum: blog.BlogWordsNum,
 			CategoryId:   blog.CategoryId,
+			PublishAt:    blog.PublishAt,
+			UnpublishAt:  blog.UnpublishAt,
 			CreateTime:   blog.CreateTime,
 			UpdateTime:   blog.UpdateTime,
 		}
@@ -185,6 +217,8 @@ func AddBlog(tx *gorm.DB, blogDto *dto.BlogDto) error {
 		BlogState:    blogDto.BlogState,
 		BlogWordsNum: blogDto.BlogWordsNum,
 		BlogIsTop:    blogDto.BlogIsTop,
+		PublishAt:    normalizeScheduleTime(blogDto.PublishAt),
+		UnpublishAt:  normalizeScheduleTime(blogDto.UnpublishAt),
 	}).Error; err != nil {
 		msg := fmt.Sprintf("创建博客失败: %v", err)
 		logger.Warn(msg)
@@ -205,8 +239,18 @@ func AddBlog(tx *gorm.DB, blogDto *dto.BlogDto) error {
 //   - 如果更新过程中发生错误，则返回错误。
 func UpdateBlog(tx *gorm.DB, blogDto *dto.BlogDto) error {
 	logger.Info("开始更新播客数据")
-	// 更新博客信息。
-	if err := tx.Model(&po.Blog{}).Where("blog_id = ?", blogDto.BlogId).Updates(po.Blog{
+	// 更新博客信息。显式指定更新的列，使状态、置顶和定时时间可以被更新为零值。
+	if err := tx.Model(&po.Blog{}).Where("blog_id = ?", blogDto.BlogId).Select(
+		"blog_image_id",
+		"blog_brief",
+		"category_id",
+		"blog_title",
+		"blog_is_top",
+		"blog_state",
+		"blog_words_num",
+		"publish_at",
+		"unpublish_at",
+	).Updates(po.Blog{
 		BlogImageId:  blogDto.BlogImageId,
 		BlogBrief:    blogDto.BlogBrief,
 		CategoryId:   blogDto.CategoryId,
@@ -214,6 +258,8 @@ func UpdateBlog(tx *gorm.DB, blogDto *dto.BlogDto) error {
 		BlogIsTop:    blogDto.BlogIsTop,
 		BlogState:    blogDto.BlogState,
 		BlogWordsNum: blogDto.BlogWordsNum,
+		PublishAt:    normalizeScheduleTime(blogDto.PublishAt),
+		UnpublishAt:  normalizeScheduleTime(blogDto.UnpublishAt),
 	}).Error; err != nil {
 		tx.Rollback()
 		msg := fmt.Sprintf("更新博客数据失败: %v", err)
@@ -319,3 +365,118 @@ func SetTopById(tx *gorm.DB, id string) error {
 
 	return nil
 }
+
+// FindBlogsWithDueSchedule 查询定时发布或定时下线时间已到的博客。
+// 参数:
+//   - ctx: 上下文对象
+//   - now: 当前时间
+//
+// 返回值:
+//   - []*dto.BlogDto: 需要切换状态的博客，仅包含 ID、标题、图片、状态与定时时间
+//   - error: 查询失败时返回错误信息
+func FindBlogsWithDueSchedule(ctx context.Context, now time.Time) ([]*dto.BlogDto, error) {
+	now = *normalizeScheduleTime(&now)
+
+	var blogs []po.Blog
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.Blog{}).
+		Select("blog_id", "blog_title", "blog_image_id", "blog_state", "publish_at", "unpublish_at").
+		Where("publish_at <= ? OR unpublish_at <= ?", now, now).
+		Find(&blogs).Error; err != nil {
+		msg := fmt.Sprintf("查询到期的定时博客失败: %v", err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	blogDtos := make([]*dto.BlogDto, 0, len(blogs))
+	for _, blog := range blogs {
+		blogDtos = append(blogDtos, &dto.BlogDto{
+			BlogId:      blog.BlogId,
+			BlogTitle:   blog.BlogTitle,
+			BlogImageId: blog.BlogImageId,
+			BlogState:   blog.BlogState,
+			PublishAt:   blog.PublishAt,
+			UnpublishAt: blog.UnpublishAt,
+		})
+	}
+
+	return blogDtos, nil
+}
+
+// FindNextScheduleTime 查询当前时间之后最近的一个定时发布或定时下线时间。
+// 参数:
+//   - ctx: 上下文对象
+//   - now: 当前时间
+//
+// 返回值:
+//   - *time.Time: 最近的定时时间，没有待执行的定时任务时返回 nil
+//   - error: 查询失败时返回错误信息
+func FindNextScheduleTime(ctx context.Context, now time.Time) (*time.Time, error) {
+	now = *normalizeScheduleTime(&now)
+
+	var next *time.Time
+	for _, column := range []string{"publish_at", "unpublish_at"} {
+		var blogs []po.Blog
+		if err := storage.Storage.Db.WithContext(ctx).Model(&po.Blog{}).
+			Select("blog_id", column).
+			Where(column+" > ?", now).
+			Order(column).
+			Limit(1).
+			Find(&blogs).Error; err != nil {
+			msg := fmt.Sprintf("查询下一个定时时间失败: %v", err)
+			logger.Error(msg)
+			return nil, errors.New(msg)
+		}
+		if len(blogs) == 0 {
+			continue
+		}
+
+		t := blogs[0].PublishAt
+		if column == "unpublish_at" {
+			t = blogs[0].UnpublishAt
+		}
+		if t != nil && (next == nil || t.Before(*next)) {
+			next = t
+		}
+	}
+
+	return next, nil
+}
+
+// ApplyBlogSchedule 执行博客的定时状态切换，并清空已执行的定时时间。
+// 参数:
+//   - tx: 数据库事务对象
+//   - id: 博客 ID
+//   - state: 切换后的博客状态
+//   - clearPublish: 是否清空定时发布时间
+//   - clearUnpublish: 是否清空定时下线时间
+//
+// 返回值:
+//   - error: 更新失败时返回错误信息
+func ApplyBlogSchedule(tx *gorm.DB, id string, state bool, clearPublish, clearUnpublish bool) error {
+	updates := map[string]any{"blog_state": state}
+	if clearPublish {
+		updates["publish_at"] = nil
+	}
+	if clearUnpublish {
+		updates["unpublish_at"] = nil
+	}
+
+	if err := tx.Model(&po.Blog{}).Where("blog_id = ?", id).Updates(updates).Error; err != nil {
+		msg := fmt.Sprintf("执行博客定时状态切换失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("博客 %v 定时切换为 %v", id, state)
+
+	return nil
+}
+
+// normalizeScheduleTime 将定时时间统一为精确到秒的 UTC 时间。
+// SQLite 以文本保存时间，统一时区后才能直接比较大小。
+func normalizeScheduleTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	normalized := t.UTC().Truncate(time.Second)
+	return &normalized
+}
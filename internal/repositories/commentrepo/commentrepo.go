@@ -14,7 +14,7 @@ import (
 	"gorm.io/gorm"
 )
 
-// FindCommentsByBlogId 根据博客ID查询评论
+// FindCommentsByBlogId 根据博客ID查询已通过审核的楼主评论
 // - ctx: 上下文对象
 // - blogId: 博客ID
 //
@@ -27,7 +27,7 @@ func FindCommentsByBlogId(ctx context.Context, blogId string) ([]dto.CommentDto,
 	logger.Info("根据博客 ID 查询楼主评论数据")
 	result := storage.Storage.Db.Model(&po.Comment{}).
 		WithContext(ctx).
-		Where("blog_id = ? AND (original_poster_id = '' OR original_poster_id IS NULL)", blogId).
+		Where("blog_id = ? AND (original_poster_id = '' OR original_poster_id IS NULL) AND comment_status = ?", blogId, dto.CommentStatusApproved).
 		Find(&comments)
 	if result.Error != nil {
 		msg := fmt.Sprintf("根据博客 ID 查询楼主评论数据失败: %v", result.Error)
@@ -39,27 +39,33 @@ func FindCommentsByBlogId(ctx context.Context, blogId string) ([]dto.CommentDto,
 	// 转换为DTO
 	var commentDtos []dto.CommentDto
 	for _, comment := range comments {
-		// 查询被回复用户的邮箱（如果存在）
-		var replyToCommenter string
+		// 查询被回复用户的邮箱与昵称（如果存在）
+		var replyToCommenter, replyToNickname string
 		if comment.ReplyToCommentId != "" {
 			var repliedComment po.Comment
 			err := storage.Storage.Db.WithContext(ctx).
-				Select("commenter_email").
+				Select("commenter_email", "commenter_nickname").
 				Where("comment_id = ?", comment.ReplyToCommentId).
 				First(&repliedComment).Error
 			if err == nil {
 				replyToCommenter = repliedComment.CommenterEmail
+				replyToNickname = repliedComment.Nickname
 			}
 		}
 
 		commentDtos = append(commentDtos, dto.CommentDto{
 			CommentId:        comment.CommentId,
 			CommenterEmail:   comment.CommenterEmail,
+			Nickname:         comment.Nickname,
+			Website:          comment.Website,
 			BlogId:           comment.BlogId,
 			OriginPostId:     comment.OriginPostId,
 			ReplyToCommentId: comment.ReplyToCommentId,
 			ReplyToCommenter: replyToCommenter,
+			ReplyToNickname:  replyToNickname,
 			Content:          comment.Content,
+			ContentHtml:      comment.ContentHtml,
+			Status:           comment.Status,
 			CreateTime:       comment.CreateTime,
 		})
 	}
@@ -92,27 +98,33 @@ func FindCommentsByOriginPostId(ctx context.Context, originPostId string) ([]dto
 	// 转换为DTO
 	var commentDtos []dto.CommentDto
 	for _, comment := range comments {
-		// 查询被回复用户的邮箱（如果存在）
-		var replyToCommenter string
+		// 查询被回复用户的邮箱与昵称（如果存在）
+		var replyToCommenter, replyToNickname string
 		if comment.ReplyToCommentId != "" {
 			var repliedComment po.Comment
 			err := storage.Storage.Db.WithContext(ctx).
-				Select("commenter_email").
+				Select("commenter_email", "commenter_nickname").
 				Where("comment_id = ?", comment.ReplyToCommentId).
 				First(&repliedComment).Error
 			if err == nil {
 				replyToCommenter = repliedComment.CommenterEmail
+				replyToNickname = repliedComment.Nickname
 			}
 		}
 
 		commentDtos = append(commentDtos, dto.CommentDto{
 			CommentId:        comment.CommentId,
 			CommenterEmail:   comment.CommenterEmail,
+			Nickname:         comment.Nickname,
+			Website:          comment.Website,
 			BlogId:           comment.BlogId,
 			OriginPostId:     comment.OriginPostId,
 			ReplyToCommentId: comment.ReplyToCommentId,
 			ReplyToCommenter: replyToCommenter,
+			ReplyToNickname:  replyToNickname,
 			Content:          comment.Content,
+			ContentHtml:      comment.ContentHtml,
+			Status:           comment.Status,
 			CreateTime:       comment.CreateTime,
 		})
 	}
@@ -142,16 +154,17 @@ func FindCommentById(ctx context.Context, commentId string) (*dto.CommentDto, er
 	}
 	logger.Info("根据评论 ID 查询评论数据成功")
 
-	// 查询被回复用户的邮箱（如果存在）
-	var replyToCommenter string
+	// 查询被回复用户的邮箱与昵称（如果存在）
+	var replyToCommenter, replyToNickname string
 	if comment.ReplyToCommentId != "" {
 		var repliedComment po.Comment
 		err := storage.Storage.Db.WithContext(ctx).
-			Select("commenter_email").
+			Select("commenter_email", "commenter_nickname").
 			Where("comment_id = ?", comment.ReplyToCommentId).
 			First(&repliedComment).Error
 		if err == nil {
 			replyToCommenter = repliedComment.CommenterEmail
+			replyToNickname = repliedComment.Nickname
 		}
 	}
 
@@ -159,11 +172,16 @@ func FindCommentById(ctx context.Context, commentId string) (*dto.CommentDto, er
 	commentDto := &dto.CommentDto{
 		CommentId:        comment.CommentId,
 		CommenterEmail:   comment.CommenterEmail,
+		Nickname:         comment.Nickname,
+		Website:          comment.Website,
 		BlogId:           comment.BlogId,
 		OriginPostId:     comment.OriginPostId,
 		ReplyToCommentId: comment.ReplyToCommentId,
 		ReplyToCommenter: replyToCommenter,
+		ReplyToNickname:  replyToNickname,
 		Content:          comment.Content,
+		ContentHtml:      comment.ContentHtml,
+		Status:           comment.Status,
 		CreateTime:       comment.CreateTime,
 	}
 
@@ -191,10 +209,14 @@ func CreateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 	comment := &po.Comment{
 		CommentId:        commentId,
 		CommenterEmail:   commentDto.CommenterEmail,
+		Nickname:         commentDto.Nickname,
+		Website:          commentDto.Website,
 		BlogId:           commentDto.BlogId,
 		OriginPostId:     commentDto.OriginPostId,
 		ReplyToCommentId: commentDto.ReplyToCommentId,
 		Content:          commentDto.Content,
+		ContentHtml:      commentDto.ContentHtml,
+		Status:           commentDto.Status,
 		CreateTime:       time.Now(),
 		UpdateTime:       time.Now(),
 	}
@@ -210,15 +232,16 @@ func CreateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 
 	logger.Info("创建评论数据成功: %v", result.RowsAffected)
 
-	// 查询被回复用户的邮箱（如果存在）
-	var replyToCommenter string
+	// 查询被回复用户的邮箱与昵称（如果存在）
+	var replyToCommenter, replyToNickname string
 	if comment.ReplyToCommentId != "" {
 		var repliedComment po.Comment
-		err := tx.Select("commenter_email").
+		err := tx.Select("commenter_email", "commenter_nickname").
 			Where("comment_id = ?", comment.ReplyToCommentId).
 			First(&repliedComment).Error
 		if err == nil {
 			replyToCommenter = repliedComment.CommenterEmail
+			replyToNickname = repliedComment.Nickname
 		}
 	}
 
@@ -226,11 +249,16 @@ func CreateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 	resultDto := &dto.CommentDto{
 		CommentId:        comment.CommentId,
 		CommenterEmail:   comment.CommenterEmail,
+		Nickname:         comment.Nickname,
+		Website:          comment.Website,
 		BlogId:           comment.BlogId,
 		OriginPostId:     comment.OriginPostId,
 		ReplyToCommentId: comment.ReplyToCommentId,
 		ReplyToCommenter: replyToCommenter,
+		ReplyToNickname:  replyToNickname,
 		Content:          comment.Content,
+		ContentHtml:      comment.ContentHtml,
+		Status:           comment.Status,
 		CreateTime:       comment.CreateTime,
 	}
 
@@ -272,10 +300,14 @@ func UpdateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 	comment := &po.Comment{
 		CommentId:        commentDto.CommentId,
 		CommenterEmail:   commentDto.CommenterEmail,
+		Nickname:         commentDto.Nickname,
+		Website:          commentDto.Website,
 		BlogId:           commentDto.BlogId,
 		OriginPostId:     commentDto.OriginPostId,
 		ReplyToCommentId: commentDto.ReplyToCommentId,
 		Content:          commentDto.Content,
+		ContentHtml:      commentDto.ContentHtml,
+		Status:           commentDto.Status,
 		UpdateTime:       time.Now(),
 	}
 
@@ -298,15 +330,16 @@ func UpdateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 		return nil, errors.New(msg)
 	}
 
-	// 查询被回复用户的邮箱（如果存在）
-	var replyToCommenter string
+	// 查询被回复用户的邮箱与昵称（如果存在）
+	var replyToCommenter, replyToNickname string
 	if updatedComment.ReplyToCommentId != "" {
 		var repliedComment po.Comment
-		err := tx.Select("commenter_email").
+		err := tx.Select("commenter_email", "commenter_nickname").
 			Where("comment_id = ?", updatedComment.ReplyToCommentId).
 			First(&repliedComment).Error
 		if err == nil {
 			replyToCommenter = repliedComment.CommenterEmail
+			replyToNickname = repliedComment.Nickname
 		}
 	}
 
@@ -314,11 +347,16 @@ func UpdateComment(tx *gorm.DB, commentDto *dto.CommentDto) (*dto.CommentDto, er
 	resultDto := &dto.CommentDto{
 		CommentId:        updatedComment.CommentId,
 		CommenterEmail:   updatedComment.CommenterEmail,
+		Nickname:         updatedComment.Nickname,
+		Website:          updatedComment.Website,
 		BlogId:           updatedComment.BlogId,
 		OriginPostId:     updatedComment.OriginPostId,
 		ReplyToCommentId: updatedComment.ReplyToCommentId,
 		ReplyToCommenter: replyToCommenter,
+		ReplyToNickname:  replyToNickname,
 		Content:          updatedComment.Content,
+		ContentHtml:      updatedComment.ContentHtml,
+		Status:           updatedComment.Status,
 		CreateTime:       updatedComment.CreateTime,
 	}
 
@@ -349,27 +387,33 @@ func FindAllComments(ctx context.Context) ([]dto.CommentDto, error) {
 	// 将PO对象转换为DTO对象
 	var commentDtos []dto.CommentDto
 	for _, comment := range comments {
-		// 查询被回复用户的邮箱（如果存在）
-		var replyToCommenter string
+		// 查询被回复用户的邮箱与昵称（如果存在）
+		var replyToCommenter, replyToNickname string
 		if comment.ReplyToCommentId != "" {
 			var repliedComment po.Comment
 			err := storage.Storage.Db.WithContext(ctx).
-				Select("commenter_email").
+				Select("commenter_email", "commenter_nickname").
 				Where("comment_id = ?", comment.ReplyToCommentId).
 				First(&repliedComment).Error
 			if err == nil {
 				replyToCommenter = repliedComment.CommenterEmail
+				replyToNickname = repliedComment.Nickname
 			}
 		}
 
 		commentDto := dto.CommentDto{
 			CommentId:        comment.CommentId,
 			CommenterEmail:   comment.CommenterEmail,
+			Nickname:         comment.Nickname,
+			Website:          comment.Website,
 			BlogId:           comment.BlogId,
 			OriginPostId:     comment.OriginPostId,
 			ReplyToCommentId: comment.ReplyToCommentId,
 			ReplyToCommenter: replyToCommenter,
+			ReplyToNickname:  replyToNickname,
 			Content:          comment.Content,
+			ContentHtml:      comment.ContentHtml,
+			Status:           comment.Status,
 			CreateTime:       comment.CreateTime,
 		}
 		commentDtos = append(commentDtos, commentDto)
@@ -399,7 +443,7 @@ func DeleteCommentsByBlogId(tx *gorm.DB, blogId string) (int64, error) {
 	return result.RowsAffected, nil
 }
 
-// FindLatestComments 获取最新的指定数量的评论
+// FindLatestComments 获取最新的指定数量的已通过审核的评论
 // - ctx: 上下文对象
 // - limit: 限制返回的评论数量
 //
@@ -413,6 +457,7 @@ func FindLatestComments(ctx context.Context, limit int) ([]dto.CommentDto, error
 
 	// 查询最新的评论，按创建时间倒序排列，限制数量
 	result := storage.Storage.Db.WithContext(ctx).
+		Where("comment_status = ?", dto.CommentStatusApproved).
 		Order("create_time DESC").
 		Limit(limit).
 		Find(&comments)
@@ -427,27 +472,33 @@ func FindLatestComments(ctx context.Context, limit int) ([]dto.CommentDto, error
 	// 将PO对象转换为DTO对象
 	var commentDtos []dto.CommentDto
 	for _, comment := range comments {
-		// 查询被回复用户的邮箱（如果存在）
-		var replyToCommenter string
+		// 查询被回复用户的邮箱与昵称（如果存在）
+		var replyToCommenter, replyToNickname string
 		if comment.ReplyToCommentId != "" {
 			var repliedComment po.Comment
 			err := storage.Storage.Db.WithContext(ctx).
-				Select("commenter_email").
+				Select("commenter_email", "commenter_nickname").
 				Where("comment_id = ?", comment.ReplyToCommentId).
 				First(&repliedComment).Error
 			if err == nil {
 				replyToCommenter = repliedComment.CommenterEmail
+				replyToNickname = repliedComment.Nickname
 			}
 		}
 
 		commentDto := dto.CommentDto{
 			CommentId:        comment.CommentId,
 			CommenterEmail:   comment.CommenterEmail,
+			Nickname:         comment.Nickname,
+			Website:          comment.Website,
 			BlogId:           comment.BlogId,
 			OriginPostId:     comment.OriginPostId,
 			ReplyToCommentId: comment.ReplyToCommentId,
 			ReplyToCommenter: replyToCommenter,
+			ReplyToNickname:  replyToNickname,
 			Content:          comment.Content,
+			ContentHtml:      comment.ContentHtml,
+			Status:           comment.Status,
 			CreateTime:       comment.CreateTime,
 		}
 		commentDtos = append(commentDtos, commentDto)
@@ -455,3 +506,235 @@ func FindLatestComments(ctx context.Context, limit int) ([]dto.CommentDto, error
 
 	return commentDtos, nil
 }
+
+// FindCommentsByStatus 根据审核状态查询评论，按创建时间正序排列
+// - ctx: 上下文对象
+// - status: 审核状态
+//
+// 返回值:
+// - []dto.CommentDto: 评论列表
+// - error: 错误信息
+func FindCommentsByStatus(ctx context.Context, status string) ([]dto.CommentDto, error) {
+	var comments []po.Comment
+
+	logger.Info("根据审核状态查询评论数据: %s", status)
+	result := storage.Storage.Db.WithContext(ctx).
+		Where("comment_status = ?", status).
+		Order("create_time ASC").
+		Find(&comments)
+	if result.Error != nil {
+		msg := fmt.Sprintf("根据审核状态查询评论数据失败: %v", result.Error)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	commentDtos := make([]dto.CommentDto, 0, len(comments))
+	for _, comment := range comments {
+		commentDtos = append(commentDtos, dto.CommentDto{
+			CommentId:        comment.CommentId,
+			CommenterEmail:   comment.CommenterEmail,
+			Nickname:         comment.Nickname,
+			Website:          comment.Website,
+			BlogId:           comment.BlogId,
+			OriginPostId:     comment.OriginPostId,
+			ReplyToCommentId: comment.ReplyToCommentId,
+			Content:          comment.Content,
+			ContentHtml:      comment.ContentHtml,
+			Status:           comment.Status,
+			CreateTime:       comment.CreateTime,
+		})
+	}
+
+	return commentDtos, nil
+}
+
+// UpdateCommentsStatus 批量修改评论的审核状态
+// - tx: 数据库事务对象
+// - ids: 评论ID列表
+// - status: 新的审核状态
+//
+// 返回值:
+// - int64: 受影响的行数
+// - error: 错误信息
+func UpdateCommentsStatus(tx *gorm.DB, ids []string, status string) (int64, error) {
+	logger.Info("修改 %d 条评论的审核状态为 %s", len(ids), status)
+	result := tx.Model(&po.Comment{}).
+		Where("comment_id IN ?", ids).
+		Updates(map[string]any{"comment_status": status, "update_time": time.Now()})
+	if result.Error != nil {
+		msg := fmt.Sprintf("修改评论审核状态失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// CountCommentsByEmailAndStatus 统计评论者指定审核状态的评论数量
+// - ctx: 上下文对象
+// - email: 评论者邮箱
+// - status: 审核状态
+//
+// 返回值:
+// - int64: 评论数量
+// - error: 错误信息
+func CountCommentsByEmailAndStatus(ctx context.Context, email string, status string) (int64, error) {
+	var count int64
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.Comment{}).
+		Where("commenter_email = ? AND comment_status = ?", email, status).
+		Count(&count)
+	if result.Error != nil {
+		msg := fmt.Sprintf("统计评论者的评论数量失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return count, nil
+}
+
+// commentWithReplyCount 带有已通过审核的回复数量的楼主评论
+type commentWithReplyCount struct {
+	po.Comment `gorm:"embedded"`
+	ReplyCount int64 `gorm:"column:reply_count"`
+}
+
+// FindThreadsByBlogId 按排序方式分页查询博客下已通过审核的楼主评论，同时统计每条楼主评论已通过审核的回复数量
+// 使用游标分页：下一页从游标之后开始，翻页期间有新评论时不会重复或遗漏已展示的楼层；
+// 按回复数量排序时回复数量在翻页期间可能变化，此时以查询时的回复数量为准。
+// - ctx: 上下文对象
+// - blogId: 博客ID
+// - sortMode: 排序方式，取值见 dto.CommentSortNewest 等常量
+// - cursor: 上一页最后一条楼主评论的游标，为 nil 时从第一页开始
+// - limit: 最多返回的数量
+//
+// 返回值:
+// - []dto.CommentDto: 楼主评论列表，ReplyCount 为回复数量
+// - error: 排序方式无效或查询失败时返回错误信息
+func FindThreadsByBlogId(ctx context.Context, blogId string, sortMode string, cursor *dto.CommentCursor, limit int) ([]dto.CommentDto, error) {
+	db := storage.Storage.Db.WithContext(ctx)
+	threads := db.Table("COMMENT AS c").
+		Select("c.*, (SELECT COUNT(*) FROM COMMENT AS r WHERE r.original_poster_id = c.comment_id AND r.comment_status = ?) AS reply_count", dto.CommentStatusApproved).
+		Where("c.blog_id = ? AND c.original_poster_id = '' AND c.comment_status = ?", blogId, dto.CommentStatusApproved)
+	query := db.Table("(?) AS t", threads)
+
+	switch sortMode {
+	case dto.CommentSortNewest:
+		if cursor != nil {
+			query = query.Where("create_time < ? OR (create_time = ? AND comment_id < ?)",
+				cursor.CreateTime, cursor.CreateTime, cursor.CommentId)
+		}
+		query = query.Order("create_time DESC, comment_id DESC")
+	case dto.CommentSortOldest:
+		if cursor != nil {
+			query = query.Where("create_time > ? OR (create_time = ? AND comment_id > ?)",
+				cursor.CreateTime, cursor.CreateTime, cursor.CommentId)
+		}
+		query = query.Order("create_time ASC, comment_id ASC")
+	case dto.CommentSortMostReplied:
+		if cursor != nil {
+			query = query.Where("reply_count < ? OR (reply_count = ? AND (create_time < ? OR (create_time = ? AND comment_id < ?)))",
+				cursor.ReplyCount, cursor.ReplyCount, cursor.CreateTime, cursor.CreateTime, cursor.CommentId)
+		}
+		query = query.Order("reply_count DESC, create_time DESC, comment_id DESC")
+	default:
+		msg := fmt.Sprintf("无效的评论排序方式: %s", sortMode)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	var comments []commentWithReplyCount
+	logger.Info("分页查询博客的楼主评论数据")
+	result := query.Limit(limit).Scan(&comments)
+	if result.Error != nil {
+		msg := fmt.Sprintf("分页查询博客的楼主评论数据失败: %v", result.Error)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	commentDtos := make([]dto.CommentDto, 0, len(comments))
+	for _, comment := range comments {
+		commentDto := commentDtoFromPo(&comment.Comment)
+		commentDto.ReplyCount = comment.ReplyCount
+		commentDtos = append(commentDtos, commentDto)
+	}
+
+	return commentDtos, nil
+}
+
+// FindRepliesByOriginPostId 按发表时间正序分页查询楼层中已通过审核的回复
+// - ctx: 上下文对象
+// - originPostId: 楼主评论ID
+// - cursor: 上一页最后一条回复的游标，为 nil 时从第一页开始
+// - limit: 最多返回的数量
+//
+// 返回值:
+// - []dto.CommentDto: 回复列表
+// - error: 错误信息
+func FindRepliesByOriginPostId(ctx context.Context, originPostId string, cursor *dto.CommentCursor, limit int) ([]dto.CommentDto, error) {
+	query := storage.Storage.Db.WithContext(ctx).
+		Where("original_poster_id = ? AND comment_status = ?", originPostId, dto.CommentStatusApproved)
+	if cursor != nil {
+		query = query.Where("create_time > ? OR (create_time = ? AND comment_id > ?)",
+			cursor.CreateTime, cursor.CreateTime, cursor.CommentId)
+	}
+
+	var comments []po.Comment
+	logger.Info("分页查询楼层的回复数据")
+	result := query.Order("create_time ASC, comment_id ASC").Limit(limit).Find(&comments)
+	if result.Error != nil {
+		msg := fmt.Sprintf("分页查询楼层的回复数据失败: %v", result.Error)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	commentDtos := make([]dto.CommentDto, 0, len(comments))
+	for _, comment := range comments {
+		commentDtos = append(commentDtos, commentDtoFromPo(&comment))
+	}
+
+	// 一次查询出本页所有被回复用户的邮箱与昵称
+	var replyToIds []string
+	for _, commentDto := range commentDtos {
+		if commentDto.ReplyToCommentId != "" {
+			replyToIds = append(replyToIds, commentDto.ReplyToCommentId)
+		}
+	}
+	if len(replyToIds) > 0 {
+		var repliedComments []po.Comment
+		err := storage.Storage.Db.WithContext(ctx).
+			Select("comment_id", "commenter_email", "commenter_nickname").
+			Where("comment_id IN ?", replyToIds).
+			Find(&repliedComments).Error
+		if err == nil {
+			replied := make(map[string]*po.Comment, len(repliedComments))
+			for i := range repliedComments {
+				replied[repliedComments[i].CommentId] = &repliedComments[i]
+			}
+			for i := range commentDtos {
+				if c, ok := replied[commentDtos[i].ReplyToCommentId]; ok {
+					commentDtos[i].ReplyToCommenter = c.CommenterEmail
+					commentDtos[i].ReplyToNickname = c.Nickname
+				}
+			}
+		}
+	}
+
+	return commentDtos, nil
+}
+
+// commentDtoFromPo 将评论转为 DTO，不包括被回复用户的邮箱与昵称
+func commentDtoFromPo(comment *po.Comment) dto.CommentDto {
+	return dto.CommentDto{
+		CommentId:        comment.CommentId,
+		CommenterEmail:   comment.CommenterEmail,
+		Nickname:         comment.Nickname,
+		Website:          comment.Website,
+		BlogId:           comment.BlogId,
+		OriginPostId:     comment.OriginPostId,
+		ReplyToCommentId: comment.ReplyToCommentId,
+		Content:          comment.Content,
+		ContentHtml:      comment.ContentHtml,
+		Status:           comment.Status,
+		CreateTime:       comment.CreateTime,
+	}
+}
@@ -34,10 +34,13 @@ func TestAddComment(t *testing.T) {
 			name: "正常添加评论",
 			commentDto: dto.CommentDto{
 				CommenterEmail:   "test@example.com",
+				Nickname:         "测试用户",
+				Website:          "https://example.com",
 				BlogId:           "test_blog_1",
 				OriginPostId:     "",
 				ReplyToCommentId: "",
 				Content:          "Test comment content",
+				ContentHtml:      "<p>Test comment content</p>\n",
 			},
 			wantErr: false,
 		},
@@ -71,7 +74,10 @@ func TestAddComment(t *testing.T) {
 				assert.NotNil(t, resultDto)
 				assert.NotEmpty(t, resultDto.CommentId)
 				assert.Equal(t, tt.commentDto.CommenterEmail, resultDto.CommenterEmail)
+				assert.Equal(t, tt.commentDto.Nickname, resultDto.Nickname)
+				assert.Equal(t, tt.commentDto.Website, resultDto.Website)
 				assert.Equal(t, tt.commentDto.Content, resultDto.Content)
+				assert.Equal(t, tt.commentDto.ContentHtml, resultDto.ContentHtml)
 				assert.Equal(t, tt.commentDto.BlogId, resultDto.BlogId)
 				assert.Equal(t, tt.commentDto.OriginPostId, resultDto.OriginPostId)
 				assert.Equal(t, tt.commentDto.ReplyToCommentId, resultDto.ReplyToCommentId)
@@ -81,6 +87,8 @@ func TestAddComment(t *testing.T) {
 				err = tx.Where("comment_id = ?", resultDto.CommentId).First(&saved).Error
 				assert.NoError(t, err)
 				assert.Equal(t, resultDto.Content, saved.Content)
+				assert.Equal(t, tt.commentDto.Nickname, saved.Nickname)
+				assert.Equal(t, tt.commentDto.ContentHtml, saved.ContentHtml)
 			}
 		})
 	}
@@ -259,3 +267,47 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestFindThreadsAndReplies(t *testing.T) {
+	ctx := context.Background()
+	tx := storage.Storage.Db.Begin()
+	thread, err := CreateComment(tx, &dto.CommentDto{CommenterEmail: "thread@example.com", Nickname: "楼主",
+		BlogId: "thread_page_blog", Content: "楼主评论", Status: dto.CommentStatusApproved})
+	assert.NoError(t, err)
+	for _, status := range []string{dto.CommentStatusApproved, dto.CommentStatusApproved, dto.CommentStatusPending} {
+		_, err := CreateComment(tx, &dto.CommentDto{CommenterEmail: "reply@example.com", BlogId: "thread_page_blog",
+			OriginPostId: thread.CommentId, ReplyToCommentId: thread.CommentId, Content: "回复", Status: status})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tx.Commit().Error)
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("blog_id = ?", "thread_page_blog").Delete(&po.Comment{})
+	})
+
+	threads, err := FindThreadsByBlogId(ctx, "thread_page_blog", dto.CommentSortMostReplied, nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, threads, 1) {
+		assert.Equal(t, thread.CommentId, threads[0].CommentId)
+		assert.Equal(t, int64(2), threads[0].ReplyCount)
+	}
+
+	// 游标之后没有更多楼主评论
+	threads, err = FindThreadsByBlogId(ctx, "thread_page_blog", dto.CommentSortNewest,
+		&dto.CommentCursor{CreateTime: thread.CreateTime, CommentId: thread.CommentId}, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, threads)
+
+	_, err = FindThreadsByBlogId(ctx, "thread_page_blog", "unknown", nil, 10)
+	assert.Error(t, err)
+
+	replies, err := FindRepliesByOriginPostId(ctx, thread.CommentId, nil, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, replies, 1) {
+		assert.Equal(t, "thread@example.com", replies[0].ReplyToCommenter)
+		assert.Equal(t, "楼主", replies[0].ReplyToNickname)
+	}
+	replies, err = FindRepliesByOriginPostId(ctx, thread.CommentId,
+		&dto.CommentCursor{CreateTime: replies[0].CreateTime, CommentId: replies[0].CommentId}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 1)
+}
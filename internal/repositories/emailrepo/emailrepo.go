@@ -0,0 +1,383 @@
+package emailrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/utils"
+	"sparrow_blog_server/storage"
+	"time"
+)
+
+// AddEmail 将邮件写入发件箱，生成的邮件 ID 会回写到 emailDto 中，邮件立即可以发送。
+// 参数:
+//   - ctx: 上下文对象
+//   - emailDto: 邮件数据，需要收件人、主题与正文
+//
+// 返回值:
+//   - error: 写入失败时返回错误信息
+func AddEmail(ctx context.Context, emailDto *dto.EmailOutboxDto) error {
+	emailId, err := utils.GenId(fmt.Sprintf("%s_%d", emailDto.Recipient, time.Now().UnixNano()))
+	if err != nil {
+		msg := fmt.Sprintf("生成邮件ID失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	now := normalizeTime(time.Now())
+	emailDto.EmailId = emailId
+	emailDto.Status = dto.EmailStatusPending
+	emailDto.Attempts = 0
+	emailDto.NextAttemptAt = now
+	emailDto.CreateTime = now
+	emailDto.UpdateTime = now
+
+	if err := storage.Storage.Db.WithContext(ctx).Create(&po.EmailOutbox{
+		EmailId:        emailDto.EmailId,
+		Recipient:      emailDto.Recipient,
+		Subject:        emailDto.Subject,
+		Content:        emailDto.Content,
+		TextContent:    emailDto.TextContent,
+		UnsubscribeUrl: emailDto.UnsubscribeUrl,
+		Status:         emailDto.Status,
+		NextAttemptAt:  emailDto.NextAttemptAt,
+		CreateTime:     emailDto.CreateTime,
+		UpdateTime:     emailDto.UpdateTime,
+	}).Error; err != nil {
+		msg := fmt.Sprintf("写入发件箱失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// FindEmailById 根据邮件ID查询发件箱中的邮件
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - *dto.EmailOutboxDto: 邮件数据，不存在时返回错误
+//   - error: 查询失败时返回错误信息
+func FindEmailById(ctx context.Context, emailId string) (*dto.EmailOutboxDto, error) {
+	var email po.EmailOutbox
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_id = ?", emailId).
+		Limit(1).
+		Find(&email)
+	if result.Error != nil {
+		msg := fmt.Sprintf("查询发件箱邮件失败: %v", result.Error)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+	if result.RowsAffected == 0 {
+		msg := fmt.Sprintf("邮件不存在: %s", emailId)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	return toDto(&email), nil
+}
+
+// FindEmails 按状态查询发件箱中的邮件，按创建时间倒序排列
+// 参数:
+//   - ctx: 上下文对象
+//   - status: 发送状态，为空时查询所有状态
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - []dto.EmailOutboxDto: 邮件列表
+//   - error: 查询失败时返回错误信息
+func FindEmails(ctx context.Context, status string, limit int) ([]dto.EmailOutboxDto, error) {
+	query := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{})
+	if status != "" {
+		query = query.Where("email_status = ?", status)
+	}
+
+	var emails []po.EmailOutbox
+	if err := query.Order("create_time DESC").Limit(limit).Find(&emails).Error; err != nil {
+		msg := fmt.Sprintf("查询发件箱邮件失败: %v", err)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	emailDtos := make([]dto.EmailOutboxDto, 0, len(emails))
+	for i := range emails {
+		emailDtos = append(emailDtos, *toDto(&emails[i]))
+	}
+
+	return emailDtos, nil
+}
+
+// FindDueEmailIds 查询已到发送时间的待发送邮件，按发送时间先后排列
+// 参数:
+//   - ctx: 上下文对象
+//   - now: 当前时间
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - []string: 邮件ID列表
+//   - error: 查询失败时返回错误信息
+func FindDueEmailIds(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var emailIds []string
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_status = ? AND next_attempt_at <= ?", dto.EmailStatusPending, normalizeTime(now)).
+		Order("next_attempt_at").
+		Limit(limit).
+		Pluck("email_id", &emailIds).Error; err != nil {
+		msg := fmt.Sprintf("查询待发送邮件失败: %v", err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return emailIds, nil
+}
+
+// FindNextAttemptTime 查询待发送邮件中最早的发送时间
+// 参数:
+//   - ctx: 上下文对象
+//
+// 返回值:
+//   - *time.Time: 最早的发送时间，没有待发送邮件时返回 nil
+//   - error: 查询失败时返回错误信息
+func FindNextAttemptTime(ctx context.Context) (*time.Time, error) {
+	var emails []po.EmailOutbox
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Select("email_id", "next_attempt_at").
+		Where("email_status = ?", dto.EmailStatusPending).
+		Order("next_attempt_at").
+		Limit(1).
+		Find(&emails).Error; err != nil {
+		msg := fmt.Sprintf("查询下一封待发送邮件失败: %v", err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	return &emails[0].NextAttemptAt, nil
+}
+
+// ClaimEmail 将待发送的邮件标记为正在发送，同一封邮件只会被一个发送协程取得
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - *dto.EmailOutboxDto: 取得的邮件，邮件已不是待发送状态（已被发送或取消）时返回 nil
+//   - error: 更新或查询失败时返回错误信息
+func ClaimEmail(ctx context.Context, emailId string) (*dto.EmailOutboxDto, error) {
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_id = ? AND email_status = ?", emailId, dto.EmailStatusPending).
+		Updates(map[string]any{
+			"email_status": dto.EmailStatusSending,
+			"update_time":  normalizeTime(time.Now()),
+		})
+	if result.Error != nil {
+		msg := fmt.Sprintf("标记邮件 %s 为正在发送失败: %v", emailId, result.Error)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return FindEmailById(ctx, emailId)
+}
+
+// MarkEmailSent 将正在发送的邮件标记为已发送
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//   - attempts: 包括本次在内已尝试发送的次数
+//
+// 返回值:
+//   - error: 更新失败时返回错误信息
+func MarkEmailSent(ctx context.Context, emailId string, attempts int) error {
+	now := normalizeTime(time.Now())
+	return updateSendingEmail(ctx, emailId, map[string]any{
+		"email_status": dto.EmailStatusSent,
+		"attempts":     attempts,
+		"sent_time":    now,
+		"update_time":  now,
+	})
+}
+
+// RescheduleEmail 发送失败后将正在发送的邮件放回待发送状态，在 nextAttemptAt 之后重试
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//   - attempts: 包括本次在内已尝试发送的次数
+//   - lastError: 本次发送失败的原因
+//   - nextAttemptAt: 下次尝试发送的时间
+//
+// 返回值:
+//   - error: 更新失败时返回错误信息
+func RescheduleEmail(ctx context.Context, emailId string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return updateSendingEmail(ctx, emailId, map[string]any{
+		"email_status":    dto.EmailStatusPending,
+		"attempts":        attempts,
+		"last_error":      lastError,
+		"next_attempt_at": normalizeTime(nextAttemptAt),
+		"update_time":     normalizeTime(time.Now()),
+	})
+}
+
+// MarkEmailDead 超过最大重试次数后将正在发送的邮件标记为死信，不再自动重试
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//   - attempts: 包括本次在内已尝试发送的次数
+//   - lastError: 最后一次发送失败的原因
+//
+// 返回值:
+//   - error: 更新失败时返回错误信息
+func MarkEmailDead(ctx context.Context, emailId string, attempts int, lastError string) error {
+	return updateSendingEmail(ctx, emailId, map[string]any{
+		"email_status": dto.EmailStatusDead,
+		"attempts":     attempts,
+		"last_error":   lastError,
+		"update_time":  normalizeTime(time.Now()),
+	})
+}
+
+// updateSendingEmail 更新正在发送的邮件
+func updateSendingEmail(ctx context.Context, emailId string, values map[string]any) error {
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_id = ? AND email_status = ?", emailId, dto.EmailStatusSending).
+		Updates(values).Error; err != nil {
+		msg := fmt.Sprintf("更新邮件 %s 的发送状态失败: %v", emailId, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// ResetSendingEmails 将所有正在发送的邮件放回待发送状态
+// 只在启动发件箱时调用，此时正在发送的邮件是上次进程在发送期间退出留下的，需要重新发送
+// 参数:
+//   - ctx: 上下文对象
+//
+// 返回值:
+//   - int64: 重置的邮件数量
+//   - error: 更新失败时返回错误信息
+func ResetSendingEmails(ctx context.Context) (int64, error) {
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_status = ?", dto.EmailStatusSending).
+		Updates(map[string]any{
+			"email_status": dto.EmailStatusPending,
+			"update_time":  normalizeTime(time.Now()),
+		})
+	if result.Error != nil {
+		msg := fmt.Sprintf("重置正在发送的邮件失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// RetryEmail 将死信、已取消或等待重试的邮件放回待发送状态并立即发送，重试次数从零开始计算
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - error: 邮件不存在、已发送或正在发送，或更新失败时返回错误信息
+func RetryEmail(ctx context.Context, emailId string) error {
+	return changeEmailStatus(ctx, emailId,
+		[]string{dto.EmailStatusDead, dto.EmailStatusCancelled, dto.EmailStatusPending},
+		map[string]any{
+			"email_status":    dto.EmailStatusPending,
+			"attempts":        0,
+			"next_attempt_at": normalizeTime(time.Now()),
+			"update_time":     normalizeTime(time.Now()),
+		})
+}
+
+// CancelEmail 取消待发送或死信状态的邮件，取消后不再发送
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - error: 邮件不存在、已发送或正在发送，或更新失败时返回错误信息
+func CancelEmail(ctx context.Context, emailId string) error {
+	return changeEmailStatus(ctx, emailId,
+		[]string{dto.EmailStatusPending, dto.EmailStatusDead},
+		map[string]any{
+			"email_status": dto.EmailStatusCancelled,
+			"update_time":  normalizeTime(time.Now()),
+		})
+}
+
+// changeEmailStatus 在邮件处于 from 中的某个状态时更新邮件，否则返回错误
+func changeEmailStatus(ctx context.Context, emailId string, from []string, values map[string]any) error {
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.EmailOutbox{}).
+		Where("email_id = ? AND email_status IN ?", emailId, from).
+		Updates(values)
+	if result.Error != nil {
+		msg := fmt.Sprintf("更新邮件 %s 失败: %v", emailId, result.Error)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	if result.RowsAffected == 0 {
+		msg := fmt.Sprintf("邮件 %s 不存在或当前状态不允许该操作", emailId)
+		logger.Warn(msg)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// DeleteFinishedEmailsBefore 删除在指定时间之前已发送或已取消的邮件，死信保留到管理员处理
+// 参数:
+//   - ctx: 上下文对象
+//   - before: 最后更新时间早于该时间的邮件会被删除
+//
+// 返回值:
+//   - int64: 删除的邮件数量
+//   - error: 删除失败时返回错误信息
+func DeleteFinishedEmailsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := storage.Storage.Db.WithContext(ctx).
+		Where("email_status IN ? AND update_time < ?",
+			[]string{dto.EmailStatusSent, dto.EmailStatusCancelled}, normalizeTime(before)).
+		Delete(&po.EmailOutbox{})
+	if result.Error != nil {
+		msg := fmt.Sprintf("清理发件箱失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// toDto 将发件箱持久化对象转换为数据传输对象
+func toDto(email *po.EmailOutbox) *dto.EmailOutboxDto {
+	return &dto.EmailOutboxDto{
+		EmailId:        email.EmailId,
+		Recipient:      email.Recipient,
+		Subject:        email.Subject,
+		Content:        email.Content,
+		TextContent:    email.TextContent,
+		UnsubscribeUrl: email.UnsubscribeUrl,
+		Status:         email.Status,
+		Attempts:       email.Attempts,
+		NextAttemptAt:  email.NextAttemptAt,
+		LastError:      email.LastError,
+		SentTime:       email.SentTime,
+		CreateTime:     email.CreateTime,
+		UpdateTime:     email.UpdateTime,
+	}
+}
+
+// normalizeTime 将时间统一为精确到秒的 UTC 时间。
+// SQLite 以文本保存时间，统一时区后才能直接比较大小，发件箱中用于比较的时间都以此格式写入。
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
@@ -0,0 +1,142 @@
+package emailrepo
+
+import (
+	"context"
+	"slices"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	err := logger.InitLogger(context.Background())
+	if err != nil {
+		return
+	}
+	// 初始化数据库组件
+	_ = storage.InitStorage(context.Background())
+}
+
+func addTestEmail(t *testing.T, recipient string) *dto.EmailOutboxDto {
+	emailDto := &dto.EmailOutboxDto{Recipient: recipient, Subject: "测试邮件", Content: "<p>测试</p>"}
+	if err := AddEmail(context.Background(), emailDto); err != nil {
+		t.Fatalf("写入发件箱失败: %v", err)
+	}
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("email_id = ?", emailDto.EmailId).Delete(&po.EmailOutbox{})
+	})
+	return emailDto
+}
+
+func TestEmailRepo_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	emailDto := addTestEmail(t, "lifecycle@example.com")
+	assert.NotEmpty(t, emailDto.EmailId)
+	assert.Equal(t, dto.EmailStatusPending, emailDto.Status)
+
+	dueIds, err := FindDueEmailIds(ctx, time.Now(), 1000)
+	assert.NoError(t, err)
+	assert.Contains(t, dueIds, emailDto.EmailId)
+
+	// 同一封邮件只能被取得一次
+	claimed, err := ClaimEmail(ctx, emailDto.EmailId)
+	assert.NoError(t, err)
+	if assert.NotNil(t, claimed) {
+		assert.Equal(t, dto.EmailStatusSending, claimed.Status)
+		assert.Equal(t, "lifecycle@example.com", claimed.Recipient)
+	}
+	claimed, err = ClaimEmail(ctx, emailDto.EmailId)
+	assert.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// 发送失败后放回待发送状态，重试时间之前不会再被查到
+	next := time.Now().Add(time.Hour)
+	assert.NoError(t, RescheduleEmail(ctx, emailDto.EmailId, 1, "连接失败", next))
+	found, err := FindEmailById(ctx, emailDto.EmailId)
+	assert.NoError(t, err)
+	assert.Equal(t, dto.EmailStatusPending, found.Status)
+	assert.Equal(t, 1, found.Attempts)
+	assert.Equal(t, "连接失败", found.LastError)
+	assert.True(t, found.NextAttemptAt.Equal(next.Truncate(time.Second)))
+	dueIds, _ = FindDueEmailIds(ctx, time.Now(), 1000)
+	assert.NotContains(t, dueIds, emailDto.EmailId)
+	dueIds, _ = FindDueEmailIds(ctx, next.Add(time.Second), 1000)
+	assert.Contains(t, dueIds, emailDto.EmailId)
+
+	nextAttempt, err := FindNextAttemptTime(ctx)
+	assert.NoError(t, err)
+	if assert.NotNil(t, nextAttempt) {
+		assert.False(t, nextAttempt.After(next))
+	}
+
+	// 只有正在发送的邮件可以标记发送结果
+	assert.NoError(t, MarkEmailSent(ctx, emailDto.EmailId, 2))
+	found, _ = FindEmailById(ctx, emailDto.EmailId)
+	assert.Equal(t, dto.EmailStatusPending, found.Status)
+
+	assert.NoError(t, RetryEmail(ctx, emailDto.EmailId))
+	claimed, _ = ClaimEmail(ctx, emailDto.EmailId)
+	if assert.NotNil(t, claimed) {
+		assert.Equal(t, 0, claimed.Attempts)
+	}
+	assert.NoError(t, MarkEmailSent(ctx, emailDto.EmailId, 1))
+	found, _ = FindEmailById(ctx, emailDto.EmailId)
+	assert.Equal(t, dto.EmailStatusSent, found.Status)
+	assert.NotNil(t, found.SentTime)
+
+	// 已发送的邮件不能重试或取消
+	assert.Error(t, RetryEmail(ctx, emailDto.EmailId))
+	assert.Error(t, CancelEmail(ctx, emailDto.EmailId))
+
+	sent, err := FindEmails(ctx, dto.EmailStatusSent, 1000)
+	assert.NoError(t, err)
+	assert.True(t, slices.ContainsFunc(sent, func(e dto.EmailOutboxDto) bool { return e.EmailId == emailDto.EmailId }))
+
+	// 已发送的邮件超过保留时间后被清理
+	_, err = DeleteFinishedEmailsBefore(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = FindEmailById(ctx, emailDto.EmailId)
+	assert.Error(t, err)
+}
+
+func TestEmailRepo_DeadAndCancel(t *testing.T) {
+	ctx := context.Background()
+	dead := addTestEmail(t, "dead@example.com")
+	sending := addTestEmail(t, "sending@example.com")
+
+	_, _ = ClaimEmail(ctx, dead.EmailId)
+	assert.NoError(t, MarkEmailDead(ctx, dead.EmailId, 8, "邮箱不存在"))
+	found, _ := FindEmailById(ctx, dead.EmailId)
+	assert.Equal(t, dto.EmailStatusDead, found.Status)
+	assert.Equal(t, 8, found.Attempts)
+
+	// 死信不会被自动发送，也不会被清理
+	dueIds, _ := FindDueEmailIds(ctx, time.Now().Add(time.Hour), 1000)
+	assert.NotContains(t, dueIds, dead.EmailId)
+	_, _ = DeleteFinishedEmailsBefore(ctx, time.Now().Add(time.Hour))
+	_, err := FindEmailById(ctx, dead.EmailId)
+	assert.NoError(t, err)
+
+	assert.NoError(t, CancelEmail(ctx, dead.EmailId))
+	found, _ = FindEmailById(ctx, dead.EmailId)
+	assert.Equal(t, dto.EmailStatusCancelled, found.Status)
+	assert.Error(t, CancelEmail(ctx, dead.EmailId))
+
+	// 进程在发送期间退出后，正在发送的邮件在启动时被放回待发送状态
+	_, _ = ClaimEmail(ctx, sending.EmailId)
+	assert.Error(t, CancelEmail(ctx, sending.EmailId))
+	count, err := ResetSendingEmails(ctx)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, count, int64(1))
+	found, _ = FindEmailById(ctx, sending.EmailId)
+	assert.Equal(t, dto.EmailStatusPending, found.Status)
+}
@@ -0,0 +1,166 @@
+package revisionrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/utils"
+	"sparrow_blog_server/storage"
+	"time"
+)
+
+// AddRevision 添加博客修订记录，生成的修订 ID 会回写到 revisionDto 中。
+// 参数:
+//   - tx: 数据库事务对象
+//   - revisionDto: 修订数据
+//
+// 返回值:
+//   - error: 添加失败时返回错误信息
+func AddRevision(tx *gorm.DB, revisionDto *dto.BlogRevisionDto) error {
+	revisionId, err := utils.GenId(fmt.Sprintf("%s_%d", revisionDto.BlogId, time.Now().UnixNano()))
+	if err != nil {
+		msg := fmt.Sprintf("生成修订ID失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	revisionDto.RevisionId = revisionId
+	revisionDto.CreateTime = time.Now()
+
+	logger.Info("添加博客修订记录")
+	if err := tx.Create(&po.BlogRevision{
+		RevisionId:   revisionDto.RevisionId,
+		BlogId:       revisionDto.BlogId,
+		BlogTitle:    revisionDto.BlogTitle,
+		BlogBrief:    revisionDto.BlogBrief,
+		BlogWordsNum: revisionDto.BlogWordsNum,
+		ContentHash:  revisionDto.ContentHash,
+		ObjectPath:   revisionDto.ObjectPath,
+		CreateTime:   revisionDto.CreateTime,
+	}).Error; err != nil {
+		msg := fmt.Sprintf("添加博客修订记录失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("添加博客修订记录成功")
+
+	return nil
+}
+
+// FindRevisionsByBlogId 查询博客的全部修订记录，按创建时间倒序排列
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客ID
+//
+// 返回值:
+//   - []dto.BlogRevisionDto: 修订记录列表
+//   - error: 查询失败时返回错误信息
+func FindRevisionsByBlogId(ctx context.Context, blogId string) ([]dto.BlogRevisionDto, error) {
+	var revisions []po.BlogRevision
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.BlogRevision{}).
+		Where("blog_id = ?", blogId).
+		Order("create_time DESC").
+		Find(&revisions).Error; err != nil {
+		msg := fmt.Sprintf("查询博客修订记录失败: %v", err)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	revisionDtos := make([]dto.BlogRevisionDto, 0, len(revisions))
+	for i := range revisions {
+		revisionDtos = append(revisionDtos, *toDto(&revisions[i]))
+	}
+
+	return revisionDtos, nil
+}
+
+// FindRevisionById 根据修订ID查询修订记录
+// 参数:
+//   - ctx: 上下文对象
+//   - revisionId: 修订ID
+//
+// 返回值:
+//   - *dto.BlogRevisionDto: 修订记录，不存在时返回错误
+//   - error: 查询失败时返回错误信息
+func FindRevisionById(ctx context.Context, revisionId string) (*dto.BlogRevisionDto, error) {
+	var revision po.BlogRevision
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.BlogRevision{}).
+		Where("revision_id = ?", revisionId).
+		Limit(1).
+		Find(&revision)
+	if result.Error != nil {
+		msg := fmt.Sprintf("查询博客修订记录失败: %v", result.Error)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+	if result.RowsAffected == 0 {
+		msg := fmt.Sprintf("博客修订记录不存在: %s", revisionId)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	return toDto(&revision), nil
+}
+
+// FindLatestRevisionByBlogId 查询博客最近一次的修订记录
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客ID
+//
+// 返回值:
+//   - *dto.BlogRevisionDto: 最近的修订记录，不存在时返回 nil
+//   - error: 查询失败时返回错误信息
+func FindLatestRevisionByBlogId(ctx context.Context, blogId string) (*dto.BlogRevisionDto, error) {
+	var revisions []po.BlogRevision
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.BlogRevision{}).
+		Where("blog_id = ?", blogId).
+		Order("create_time DESC").
+		Limit(1).
+		Find(&revisions).Error; err != nil {
+		msg := fmt.Sprintf("查询博客修订记录失败: %v", err)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+
+	return toDto(&revisions[0]), nil
+}
+
+// DeleteRevisionsByBlogId 删除博客的全部修订记录
+// 参数:
+//   - tx: 数据库事务对象
+//   - blogId: 博客ID
+//
+// 返回值:
+//   - int64: 删除的记录数
+//   - error: 删除失败时返回错误信息
+func DeleteRevisionsByBlogId(tx *gorm.DB, blogId string) (int64, error) {
+	logger.Info("删除博客修订记录")
+	result := tx.Where("blog_id = ?", blogId).Delete(&po.BlogRevision{})
+	if result.Error != nil {
+		msg := fmt.Sprintf("删除博客修订记录失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+	logger.Info("删除博客修订记录成功: %v", result.RowsAffected)
+
+	return result.RowsAffected, nil
+}
+
+func toDto(revision *po.BlogRevision) *dto.BlogRevisionDto {
+	return &dto.BlogRevisionDto{
+		RevisionId:   revision.RevisionId,
+		BlogId:       revision.BlogId,
+		BlogTitle:    revision.BlogTitle,
+		BlogBrief:    revision.BlogBrief,
+		BlogWordsNum: revision.BlogWordsNum,
+		ContentHash:  revision.ContentHash,
+		ObjectPath:   revision.ObjectPath,
+		CreateTime:   revision.CreateTime,
+	}
+}
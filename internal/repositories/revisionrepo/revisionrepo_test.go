@@ -0,0 +1,71 @@
+package revisionrepo
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	err := logger.InitLogger(context.Background())
+	if err != nil {
+		return
+	}
+	// 初始化数据库组件
+	_ = storage.InitStorage(context.Background())
+}
+
+func TestRevisionRepo(t *testing.T) {
+	ctx := context.Background()
+	blogId := "test_revision_blog"
+
+	// 清理测试数据
+	t.Cleanup(func() {
+		_, _ = DeleteRevisionsByBlogId(storage.Storage.Db, blogId)
+	})
+
+	latest, err := FindLatestRevisionByBlogId(ctx, blogId)
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	first := &dto.BlogRevisionDto{BlogId: blogId, BlogTitle: "v1", ContentHash: "hash1", ObjectPath: "blogs/revisions/x/hash1.md"}
+	assert.NoError(t, AddRevision(storage.Storage.Db, first))
+	assert.NotEmpty(t, first.RevisionId)
+
+	time.Sleep(time.Millisecond)
+	second := &dto.BlogRevisionDto{BlogId: blogId, BlogTitle: "v2", ContentHash: "hash2", ObjectPath: "blogs/revisions/x/hash2.md"}
+	assert.NoError(t, AddRevision(storage.Storage.Db, second))
+
+	revisions, err := FindRevisionsByBlogId(ctx, blogId)
+	assert.NoError(t, err)
+	if assert.Len(t, revisions, 2) {
+		assert.Equal(t, second.RevisionId, revisions[0].RevisionId)
+		assert.Equal(t, first.RevisionId, revisions[1].RevisionId)
+	}
+
+	latest, err = FindLatestRevisionByBlogId(ctx, blogId)
+	assert.NoError(t, err)
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "hash2", latest.ContentHash)
+	}
+
+	found, err := FindRevisionById(ctx, first.RevisionId)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", found.BlogTitle)
+
+	_, err = FindRevisionById(ctx, "not_exist")
+	assert.Error(t, err)
+
+	deleted, err := DeleteRevisionsByBlogId(storage.Storage.Db, blogId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+}
@@ -0,0 +1,101 @@
+package spamrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"time"
+)
+
+// TotalToken 记录训练过的垃圾评论与正常评论总数的保留分词，分词结果中不会出现 #
+const TotalToken = "#total"
+
+// queryBatchSize 单条 SQL 中最多查询的分词数量，避免超过 SQLite 的参数上限
+const queryBatchSize = 500
+
+// FindSpamTokens 查询分词的统计数据，不存在的分词不会出现在结果中
+// - ctx: 上下文对象
+// - tokens: 分词列表
+//
+// 返回值:
+// - map[string]dto.SpamTokenDto: 分词到统计数据的映射
+// - error: 错误信息
+func FindSpamTokens(ctx context.Context, tokens []string) (map[string]dto.SpamTokenDto, error) {
+	result := make(map[string]dto.SpamTokenDto, len(tokens))
+	for start := 0; start < len(tokens); start += queryBatchSize {
+		batch := tokens[start:min(start+queryBatchSize, len(tokens))]
+
+		var spamTokens []po.SpamToken
+		if err := storage.Storage.Db.WithContext(ctx).Model(&po.SpamToken{}).
+			Where("token IN ?", batch).
+			Find(&spamTokens).Error; err != nil {
+			msg := fmt.Sprintf("查询评论分词统计失败: %v", err)
+			logger.Warn(msg)
+			return nil, errors.New(msg)
+		}
+
+		for _, spamToken := range spamTokens {
+			result[spamToken.Token] = dto.SpamTokenDto{
+				Token:     spamToken.Token,
+				SpamCount: spamToken.SpamCount,
+				HamCount:  spamToken.HamCount,
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// IncrSpamTokens 将分词在垃圾评论或正常评论中的出现次数加一，不存在的分词会被创建
+// - tx: 数据库事务对象
+// - tokens: 分词列表，不应包含重复的分词
+// - spam: 是否为垃圾评论
+//
+// 返回值:
+// - error: 错误信息
+func IncrSpamTokens(tx *gorm.DB, tokens []string, spam bool) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	column := "ham_count"
+	if spam {
+		column = "spam_count"
+	}
+
+	now := time.Now()
+	for start := 0; start < len(tokens); start += queryBatchSize {
+		batch := tokens[start:min(start+queryBatchSize, len(tokens))]
+
+		spamTokens := make([]po.SpamToken, 0, len(batch))
+		for _, token := range batch {
+			spamToken := po.SpamToken{Token: token, UpdateTime: now}
+			if spam {
+				spamToken.SpamCount = 1
+			} else {
+				spamToken.HamCount = 1
+			}
+			spamTokens = append(spamTokens, spamToken)
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				column:        gorm.Expr(column + " + 1"),
+				"update_time": now,
+			}),
+		}).Create(&spamTokens).Error; err != nil {
+			msg := fmt.Sprintf("更新评论分词统计失败: %v", err)
+			logger.Error(msg)
+			return errors.New(msg)
+		}
+	}
+
+	return nil
+}
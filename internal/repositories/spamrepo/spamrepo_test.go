@@ -0,0 +1,53 @@
+package spamrepo
+
+import (
+	"context"
+	"fmt"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	err := logger.InitLogger(context.Background())
+	if err != nil {
+		return
+	}
+	// 初始化数据库组件
+	_ = storage.InitStorage(context.Background())
+}
+
+func TestSpamTokens(t *testing.T) {
+	ctx := context.Background()
+	prefix := fmt.Sprintf("test%d_", time.Now().UnixNano())
+	tokens := []string{prefix + "cheap", prefix + "pills"}
+
+	// 清理测试数据
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("token LIKE ?", prefix+"%").Delete(&po.SpamToken{})
+	})
+
+	found, err := FindSpamTokens(ctx, tokens)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.NoError(t, IncrSpamTokens(storage.Storage.Db, tokens, true))
+	assert.NoError(t, IncrSpamTokens(storage.Storage.Db, tokens[:1], true))
+	assert.NoError(t, IncrSpamTokens(storage.Storage.Db, tokens[:1], false))
+
+	found, err = FindSpamTokens(ctx, append(tokens, prefix+"unknown"))
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, 2, found[tokens[0]].SpamCount)
+	assert.Equal(t, 1, found[tokens[0]].HamCount)
+	assert.Equal(t, 1, found[tokens[1]].SpamCount)
+	assert.Equal(t, 0, found[tokens[1]].HamCount)
+}
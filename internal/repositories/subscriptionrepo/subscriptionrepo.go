@@ -0,0 +1,116 @@
+package subscriptionrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"time"
+)
+
+// AddSubscription 订阅一层楼的回复通知，已经订阅时不做修改
+// - ctx: 上下文对象
+// - subscriptionDto: 订阅数据，需要订阅者邮箱、楼主评论 ID 与博客 ID
+//
+// 返回值:
+// - error: 错误信息
+func AddSubscription(ctx context.Context, subscriptionDto *dto.CommentSubscriptionDto) error {
+	subscriptionDto.CreateTime = time.Now().UTC().Truncate(time.Second)
+	if err := storage.Storage.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&po.CommentSubscription{
+			SubscriberEmail: subscriptionDto.SubscriberEmail,
+			ThreadId:        subscriptionDto.ThreadId,
+			BlogId:          subscriptionDto.BlogId,
+			CreateTime:      subscriptionDto.CreateTime,
+		}).Error; err != nil {
+		msg := fmt.Sprintf("订阅评论回复失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// IsSubscribed 查询评论者是否订阅了一层楼的回复通知
+// - ctx: 上下文对象
+// - email: 订阅者邮箱
+// - threadId: 楼主评论 ID
+//
+// 返回值:
+// - bool: 是否已订阅
+// - error: 错误信息
+func IsSubscribed(ctx context.Context, email, threadId string) (bool, error) {
+	var count int64
+	if err := storage.Storage.Db.WithContext(ctx).Model(&po.CommentSubscription{}).
+		Where("subscriber_email = ? AND thread_id = ?", email, threadId).
+		Count(&count).Error; err != nil {
+		msg := fmt.Sprintf("查询评论回复订阅失败: %v", err)
+		logger.Warn(msg)
+		return false, errors.New(msg)
+	}
+
+	return count > 0, nil
+}
+
+// DeleteSubscription 退订一层楼的回复通知，没有订阅时不返回错误
+// - ctx: 上下文对象
+// - email: 订阅者邮箱
+// - threadId: 楼主评论 ID
+//
+// 返回值:
+// - int64: 删除的订阅数量
+// - error: 错误信息
+func DeleteSubscription(ctx context.Context, email, threadId string) (int64, error) {
+	result := storage.Storage.Db.WithContext(ctx).
+		Where("subscriber_email = ? AND thread_id = ?", email, threadId).
+		Delete(&po.CommentSubscription{})
+	if result.Error != nil {
+		msg := fmt.Sprintf("退订评论回复失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteSubscriptionsByThreadId 删除一层楼的所有订阅，在删除楼主评论时调用
+// - tx: 数据库事务对象
+// - threadId: 楼主评论 ID
+//
+// 返回值:
+// - int64: 删除的订阅数量
+// - error: 错误信息
+func DeleteSubscriptionsByThreadId(tx *gorm.DB, threadId string) (int64, error) {
+	result := tx.Where("thread_id = ?", threadId).Delete(&po.CommentSubscription{})
+	if result.Error != nil {
+		msg := fmt.Sprintf("删除楼层的评论回复订阅失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteSubscriptionsByBlogId 删除博客下所有楼层的订阅，在删除博客的评论时调用
+// - tx: 数据库事务对象
+// - blogId: 博客 ID
+//
+// 返回值:
+// - int64: 删除的订阅数量
+// - error: 错误信息
+func DeleteSubscriptionsByBlogId(tx *gorm.DB, blogId string) (int64, error) {
+	result := tx.Where("blog_id = ?", blogId).Delete(&po.CommentSubscription{})
+	if result.Error != nil {
+		msg := fmt.Sprintf("删除博客的评论回复订阅失败: %v", result.Error)
+		logger.Error(msg)
+		return 0, errors.New(msg)
+	}
+
+	return result.RowsAffected, nil
+}
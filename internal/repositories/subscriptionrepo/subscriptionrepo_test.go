@@ -0,0 +1,65 @@
+package subscriptionrepo
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	err := logger.InitLogger(context.Background())
+	if err != nil {
+		return
+	}
+	// 初始化数据库组件
+	_ = storage.InitStorage(context.Background())
+}
+
+func TestSubscriptionRepo(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("blog_id IN ?", []string{"sub_blog_1", "sub_blog_2"}).Delete(&po.CommentSubscription{})
+	})
+
+	first := &dto.CommentSubscriptionDto{SubscriberEmail: "reader@example.com", ThreadId: "sub_thread_1", BlogId: "sub_blog_1"}
+	assert.NoError(t, AddSubscription(ctx, first))
+	// 重复订阅不报错
+	assert.NoError(t, AddSubscription(ctx, first))
+	assert.NoError(t, AddSubscription(ctx, &dto.CommentSubscriptionDto{SubscriberEmail: "other@example.com", ThreadId: "sub_thread_1", BlogId: "sub_blog_1"}))
+	assert.NoError(t, AddSubscription(ctx, &dto.CommentSubscriptionDto{SubscriberEmail: "reader@example.com", ThreadId: "sub_thread_2", BlogId: "sub_blog_2"}))
+
+	subscribed, err := IsSubscribed(ctx, "reader@example.com", "sub_thread_1")
+	assert.NoError(t, err)
+	assert.True(t, subscribed)
+	subscribed, _ = IsSubscribed(ctx, "reader@example.com", "sub_thread_unknown")
+	assert.False(t, subscribed)
+
+	// 退订只影响指定的楼层，重复退订不报错
+	count, err := DeleteSubscription(ctx, "reader@example.com", "sub_thread_1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = DeleteSubscription(ctx, "reader@example.com", "sub_thread_1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	subscribed, _ = IsSubscribed(ctx, "other@example.com", "sub_thread_1")
+	assert.True(t, subscribed)
+
+	// 删除楼层或博客时清理订阅
+	count, err = DeleteSubscriptionsByThreadId(storage.Storage.Db, "sub_thread_1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = DeleteSubscriptionsByBlogId(storage.Storage.Db, "sub_blog_2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	subscribed, _ = IsSubscribed(ctx, "reader@example.com", "sub_thread_2")
+	assert.False(t, subscribed)
+}
@@ -96,6 +96,31 @@ func FindTagsByBlogId(ctx context.Context, blogId string) ([]dto.TagDto, error)
 	return tagsDto, nil
 }
 
+// FindAllBlogTagIds 查询所有博客与标签的关联关系，用于批量处理时避免逐篇查询。
+// 参数:
+//   - ctx: 上下文对象，用于控制请求的生命周期和传递元数据。
+//
+// 返回值:
+//   - map[string][]string: 以博客 ID 为键、关联的标签 ID 列表为值。
+//   - error: 如果查询过程中发生错误，则返回错误信息；否则返回 nil。
+func FindAllBlogTagIds(ctx context.Context) (map[string][]string, error) {
+	var bt []po.BlogTag
+
+	result := storage.Storage.Db.WithContext(ctx).Model(&po.BlogTag{}).Find(&bt)
+	if result.Error != nil {
+		msg := fmt.Sprintf("查询博客标签关联数据失败: %v", result.Error)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	blogTagIds := make(map[string][]string)
+	for _, item := range bt {
+		blogTagIds[item.BlogId] = append(blogTagIds[item.BlogId], item.TagId)
+	}
+
+	return blogTagIds, nil
+}
+
 // AddTags 批量添加标签到数据库。
 // 参数:
 // - tx: 数据库事务对象，用于执行数据库操作。
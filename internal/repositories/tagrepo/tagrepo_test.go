@@ -117,3 +117,17 @@ func TestGetAllTags(t *testing.T) {
 		t.Logf("tag: %v", tag)
 	}
 }
+
+func TestFindAllBlogTagIds(t *testing.T) {
+	ctx := context.Background()
+
+	blogTagIds, err := FindAllBlogTagIds(ctx)
+	if err != nil {
+		t.Errorf("FindAllBlogTagIds() error = %v", err)
+		return
+	}
+
+	for blogId, tagIds := range blogTagIds {
+		t.Logf("blog: %s, tags: %v", blogId, tagIds)
+	}
+}
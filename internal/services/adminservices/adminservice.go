@@ -104,6 +104,9 @@ func UpdateConfig() error {
 		Cache:        config.Cache,
 		Logger:       config.Logger,
 		SearchEngine: config.SearchEngine,
+		Sqlite:       config.Sqlite,
+		Comment:      config.Comment,
+		Email:        config.Email,
 	}
 
 	// 调用 projConfig 的 Store 方法，尝试将配置信息持久化。
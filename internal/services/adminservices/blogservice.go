@@ -6,7 +6,9 @@ import (
 	"sparrow_blog_server/internal/repositories/blogrepo"
 	"sparrow_blog_server/internal/repositories/categoryrepo"
 	"sparrow_blog_server/internal/repositories/commentrepo"
+	"sparrow_blog_server/internal/repositories/subscriptionrepo"
 	"sparrow_blog_server/internal/repositories/tagrepo"
+	"sparrow_blog_server/internal/services/webservice"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/searchengine"
 	"sparrow_blog_server/storage"
@@ -133,12 +135,24 @@ func DeleteBlogById(ctx context.Context, id string) error {
 		return err
 	}
 
-	// 删除博客相关的所有评论
+	// 删除博客相关的所有评论及回复订阅
 	_, err = commentrepo.DeleteCommentsByBlogId(tx, id)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
+	_, err = subscriptionrepo.DeleteSubscriptionsByBlogId(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 删除博客的修订记录，修订内容在事务提交后删除
+	revisionObjects, err := deleteRevisions(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	// 删除博客对应的 Markdown 文件
 	err = storage.Storage.DeleteObject(ctx, ossstore.GenOssSavePath(blogTitle, ossstore.MarkDown))
@@ -151,12 +165,19 @@ func DeleteBlogById(ctx context.Context, id string) error {
 	tx.Commit()
 	logger.Info("删除博客数据成功")
 
+	deleteRevisionObjects(ctx, revisionObjects)
+
 	// 从搜索索引中删除博客
 	if err := searchengine.DeleteIndex(id); err != nil {
 		logger.Warn("删除博客搜索索引失败: %v", err)
 		// 注意：这里不返回错误，因为数据库操作已经成功，索引删除失败不应该影响整个删除操作
 	}
 
+	// 已发布博客的集合发生变化，重新生成站点地图
+	if err := webservice.RefreshSitemap(ctx); err != nil {
+		logger.Warn("重新生成站点地图失败: %v", err)
+	}
+
 	// 清理无用标签和分类
 	cleanUpTx := storage.Storage.Db.WithContext(ctx).Begin()
 	if err = tagrepo.CleanTagsWithoutBlog(cleanUpTx); err != nil {
@@ -207,6 +228,18 @@ func ChangeBlogState(ctx context.Context, id string) error {
 
 	tx.Commit()
 
+	// 根据新的状态同步搜索索引
+	blogDto, err := blogrepo.FindBlogById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := syncBlogIndex(ctx, blogDto); err != nil {
+		logger.Warn("同步博客搜索索引失败: %v", err)
+	}
+	if err := webservice.RefreshSitemap(ctx); err != nil {
+		logger.Warn("重新生成站点地图失败: %v", err)
+	}
+
 	return nil
 }
 
@@ -284,6 +317,12 @@ func UpdateOrAddBlog(ctx context.Context, blogDto *dto.BlogDto) error {
 			tx.Rollback()
 			return err
 		}
+
+		// 记录博客的第一条修订
+		if err := saveRevision(ctx, tx, blogDto); err != nil {
+			tx.Rollback()
+			return err
+		}
 	} else {
 		// 更新博客信息
 		// 需要删除 OSS 中原有的文章，先从数据库中拿到原来的标题
@@ -294,6 +333,17 @@ func UpdateOrAddBlog(ctx context.Context, blogDto *dto.BlogDto) error {
 		}
 
 		if title != blogDto.BlogTitle {
+			// 删除旧文章前，如果还没有修订记录，先将旧文章保存为第一条修订
+			oldBlogDto, err := blogrepo.FindBlogById(ctx, blogDto.BlogId)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			if err := saveBaselineRevision(ctx, tx, oldBlogDto); err != nil {
+				tx.Rollback()
+				return err
+			}
+
 			// 如果标题有变化，则需要删除 OSS 中的旧文章
 			logger.Info("删除 OSS 中的旧文章: %s", title)
 			if deleteErr := storage.Storage.DeleteObject(ctx, ossstore.GenOssSavePath(title, ossstore.MarkDown)); deleteErr != nil {
@@ -311,6 +361,12 @@ func UpdateOrAddBlog(ctx context.Context, blogDto *dto.BlogDto) error {
 			return updateErr
 		}
 
+		// 记录本次保存的修订
+		if err := saveRevision(ctx, tx, blogDto); err != nil {
+			tx.Rollback()
+			return err
+		}
+
 		// 更新标签与博客的关联关系
 		if updateTagErr := tagrepo.UpdateBlogTagAssociation(tx, blogDto.BlogId, blogDto.Tags); updateTagErr != nil {
 			logger.Warn("更新标签与博客的关联关系失败: %v", updateTagErr)
@@ -330,15 +386,20 @@ func UpdateOrAddBlog(ctx context.Context, blogDto *dto.BlogDto) error {
 	// 提交事务
 	tx.Commit()
 
-	// 将更新或者新增的博客添加到索引中
+	// 定时时间可能已修改，唤醒调度器重新计算下次执行时间
+	if blogDto.PublishAt != nil || blogDto.UnpublishAt != nil {
+		notifyScheduler()
+	}
+
+	// 将更新或者新增的博客添加到索引中，隐藏状态的博客从索引中移除
 	// 注意：索引操作在事务提交后进行，确保数据库操作成功后再更新索引
 	var indexErr error
-	if isNewBlog {
+	if isNewBlog && blogDto.BlogState {
 		// 如果是新增操作，使用AddIndex
 		indexErr = searchengine.AddIndex(ctx, blogDto)
-	} else {
-		// 如果是更新操作，使用UpdateIndex
-		indexErr = searchengine.UpdateIndex(ctx, blogDto)
+	} else if !isNewBlog {
+		// 如果是更新操作，根据博客状态更新或删除索引
+		indexErr = syncBlogIndex(ctx, blogDto)
 	}
 
 	if indexErr != nil {
@@ -346,5 +407,10 @@ func UpdateOrAddBlog(ctx context.Context, blogDto *dto.BlogDto) error {
 		// 注意：这里不返回错误，因为数据库操作已经成功，索引更新失败不应该影响整个操作
 	}
 
+	// 博客的发布状态或更新时间可能已变化，重新生成站点地图
+	if err := webservice.RefreshSitemap(ctx); err != nil {
+		logger.Warn("重新生成站点地图失败: %v", err)
+	}
+
 	return nil
 }
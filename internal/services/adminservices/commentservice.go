@@ -2,11 +2,14 @@ package adminservices
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sparrow_blog_server/internal/model/dto"
 	"sparrow_blog_server/internal/model/vo"
 	"sparrow_blog_server/internal/repositories/blogrepo"
 	"sparrow_blog_server/internal/repositories/commentrepo"
+	"sparrow_blog_server/internal/repositories/subscriptionrepo"
+	"sparrow_blog_server/internal/services/webservice"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/storage"
 )
@@ -38,6 +41,10 @@ func UpdateComment(ctx context.Context, commentId string, commentDto *dto.Commen
 
 	// 更新评论内容
 	existingCommentDto.Content = commentDto.Content
+	if err := webservice.RenderCommentContent(ctx, existingCommentDto); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("渲染评论失败: %v", err)
+	}
 
 	// 保存更新
 	updatedDto, err := commentrepo.UpdateComment(tx, existingCommentDto)
@@ -60,18 +67,31 @@ func UpdateComment(ctx context.Context, commentId string, commentDto *dto.Commen
 	}
 
 	// 转换为VO对象返回
-	commentVo := &vo.CommentVo{
-		CommentId:        updatedDto.CommentId,
-		CommenterEmail:   updatedDto.CommenterEmail,
-		BlogId:           updatedDto.BlogId,
+	commentVo := adminCommentVo(updatedDto, blogTitle)
+
+	return &commentVo, nil
+}
+
+// adminCommentVo 将评论转为管理后台使用的视图对象，保留完整的邮箱
+func adminCommentVo(commentDto *dto.CommentDto, blogTitle string) vo.CommentVo {
+	avatarHash, avatarUrl := webservice.CommenterAvatar(commentDto.CommenterEmail)
+	return vo.CommentVo{
+		CommentId:        commentDto.CommentId,
+		CommenterEmail:   commentDto.CommenterEmail,
+		Nickname:         commentDto.Nickname,
+		Website:          commentDto.Website,
+		AvatarHash:       avatarHash,
+		AvatarUrl:        avatarUrl,
+		BlogId:           commentDto.BlogId,
 		BlogTitle:        blogTitle,
-		OriginPostId:     updatedDto.OriginPostId,
-		ReplyToCommenter: updatedDto.ReplyToCommenter,
-		Content:          updatedDto.Content,
-		CreateTime:       updatedDto.CreateTime,
+		OriginPostId:     commentDto.OriginPostId,
+		ReplyToCommenter: commentDto.ReplyToCommenter,
+		ReplyToNickname:  commentDto.ReplyToNickname,
+		Content:          commentDto.Content,
+		ContentHtml:      webservice.CommentContentHtml(commentDto),
+		Status:           commentDto.Status,
+		CreateTime:       commentDto.CreateTime,
 	}
-
-	return commentVo, nil
 }
 
 // DeleteComment 删除评论（管理员功能）
@@ -91,7 +111,7 @@ func DeleteComment(ctx context.Context, commentId string) error {
 	}()
 
 	// 检查评论是否存在
-	_, err := commentrepo.FindCommentById(ctx, commentId)
+	comment, err := commentrepo.FindCommentById(ctx, commentId)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("评论不存在: %v", err)
@@ -104,6 +124,14 @@ func DeleteComment(ctx context.Context, commentId string) error {
 		return fmt.Errorf("删除评论失败: %v", err)
 	}
 
+	// 删除楼主评论时清理该楼层的回复订阅
+	if comment.OriginPostId == "" {
+		if _, err = subscriptionrepo.DeleteSubscriptionsByThreadId(tx, commentId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		logger.Error("提交删除评论事务失败: %v", err)
@@ -153,6 +181,12 @@ func DeleteCommentWithSubComments(ctx context.Context, commentId string) error {
 				return fmt.Errorf("删除子评论失败: %v", err)
 			}
 		}
+
+		// 清理该楼层的回复订阅
+		if _, err = subscriptionrepo.DeleteSubscriptionsByThreadId(tx, commentId); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	// 删除主评论本身
@@ -195,17 +229,7 @@ func GetAllComments(ctx context.Context) ([]vo.CommentVo, error) {
 			blogTitle = "" // 设置为空字符串
 		}
 
-		commentVo := vo.CommentVo{
-			CommentId:        commentDto.CommentId,
-			CommenterEmail:   commentDto.CommenterEmail,
-			BlogId:           commentDto.BlogId,
-			BlogTitle:        blogTitle,
-			OriginPostId:     commentDto.OriginPostId,
-			ReplyToCommenter: commentDto.ReplyToCommenter,
-			Content:          commentDto.Content,
-			CreateTime:       commentDto.CreateTime,
-		}
-		commentVos = append(commentVos, commentVo)
+		commentVos = append(commentVos, adminCommentVo(&commentDto, blogTitle))
 	}
 
 	return commentVos, nil
@@ -234,6 +258,12 @@ func DeleteCommentsByBlogId(ctx context.Context, blogId string) error {
 		return fmt.Errorf("删除博客相关评论失败: %v", err)
 	}
 
+	// 清理博客下所有楼层的回复订阅
+	if _, err = subscriptionrepo.DeleteSubscriptionsByBlogId(tx, blogId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		logger.Error("提交删除博客相关评论事务失败: %v", err)
@@ -243,3 +273,128 @@ func DeleteCommentsByBlogId(ctx context.Context, blogId string) error {
 	logger.Info("成功删除博客相关评论，BlogId: %s, 删除数量: %d", blogId, rowsAffected)
 	return nil
 }
+
+// GetPendingComments 获取待审核的评论，按创建时间正序排列（管理员功能）
+// - ctx: 上下文对象
+//
+// 返回值:
+// - []vo.CommentVo: 待审核的评论列表
+// - error: 错误信息
+func GetPendingComments(ctx context.Context) ([]vo.CommentVo, error) {
+	commentDtos, err := commentrepo.FindCommentsByStatus(ctx, dto.CommentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一篇博客的标题只查询一次
+	blogTitles := make(map[string]string)
+	commentVos := make([]vo.CommentVo, 0, len(commentDtos))
+	for _, commentDto := range commentDtos {
+		blogTitle, ok := blogTitles[commentDto.BlogId]
+		if !ok {
+			blogTitle, err = blogrepo.FindBlogTitleById(ctx, commentDto.BlogId)
+			if err != nil {
+				logger.Warn("查询博客标题失败，BlogId: %s, 错误: %v", commentDto.BlogId, err)
+				blogTitle = ""
+			}
+			blogTitles[commentDto.BlogId] = blogTitle
+		}
+
+		commentVos = append(commentVos, adminCommentVo(&commentDto, blogTitle))
+	}
+
+	return commentVos, nil
+}
+
+// ApproveComments 批量通过评论审核（管理员功能）
+// - ctx: 上下文对象
+// - commentIds: 评论ID列表
+//
+// 返回值:
+// - []dto.CommentDto: 本次由其他状态变为通过的评论，用于发送通知
+// - error: 错误信息
+func ApproveComments(ctx context.Context, commentIds []string) ([]dto.CommentDto, error) {
+	approved, err := changeCommentsStatus(ctx, commentIds, dto.CommentStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通过的评论作为正常评论训练反垃圾分类器
+	webservice.TrainSpamFilter(ctx, commentContents(approved), false)
+	return approved, nil
+}
+
+// RejectComments 批量拒绝评论，被拒绝的评论不会在前台展示（管理员功能）
+// - ctx: 上下文对象
+// - commentIds: 评论ID列表
+//
+// 返回值:
+// - []dto.CommentDto: 本次由其他状态变为拒绝的评论
+// - error: 错误信息
+func RejectComments(ctx context.Context, commentIds []string) ([]dto.CommentDto, error) {
+	rejected, err := changeCommentsStatus(ctx, commentIds, dto.CommentStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	// 拒绝的评论作为垃圾评论训练反垃圾分类器
+	webservice.TrainSpamFilter(ctx, commentContents(rejected), true)
+	return rejected, nil
+}
+
+func commentContents(commentDtos []dto.CommentDto) []string {
+	contents := make([]string, 0, len(commentDtos))
+	for _, commentDto := range commentDtos {
+		contents = append(contents, commentDto.Content)
+	}
+	return contents
+}
+
+// changeCommentsStatus 在一个事务中修改评论的审核状态，任意一条评论不存在时整体失败
+func changeCommentsStatus(ctx context.Context, commentIds []string, status string) ([]dto.CommentDto, error) {
+	if len(commentIds) == 0 {
+		return nil, errors.New("评论ID列表不能为空")
+	}
+
+	// 检查评论是否存在，并记录状态发生变化的评论
+	var changed []dto.CommentDto
+	var changedIds []string
+	for _, commentId := range commentIds {
+		commentDto, err := commentrepo.FindCommentById(ctx, commentId)
+		if err != nil {
+			return nil, fmt.Errorf("评论不存在: %v", err)
+		}
+		if commentDto.Status != status {
+			changed = append(changed, *commentDto)
+			changedIds = append(changedIds, commentId)
+		}
+	}
+	if len(changedIds) == 0 {
+		return nil, nil
+	}
+
+	// 开启事务
+	tx := storage.Storage.Db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("修改评论审核状态事务失败: %v", r)
+			tx.Rollback()
+		}
+	}()
+
+	if _, err := commentrepo.UpdateCommentsStatus(tx, changedIds, status); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("提交修改评论审核状态事务失败: %v", err)
+		return nil, fmt.Errorf("提交事务失败: %v", err)
+	}
+
+	for i := range changed {
+		changed[i].Status = status
+	}
+	return changed, nil
+}
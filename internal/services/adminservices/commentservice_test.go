@@ -65,8 +65,9 @@ func TestAddComment(t *testing.T) {
 		t.Error("评论ID不能为空")
 	}
 
-	if commentVo.CommenterEmail != commentDto.CommenterEmail {
-		t.Errorf("评论者邮箱不匹配: 期望 %s, 实际 %s", commentDto.CommenterEmail, commentVo.CommenterEmail)
+	// 前台接口返回打码后的邮箱
+	if commentVo.CommenterEmail != webservice.MaskEmail(commentDto.CommenterEmail) {
+		t.Errorf("评论者邮箱不匹配: 期望 %s, 实际 %s", webservice.MaskEmail(commentDto.CommenterEmail), commentVo.CommenterEmail)
 	}
 
 	if commentVo.Content != commentDto.Content {
@@ -316,10 +317,10 @@ func TestAddReplyComment(t *testing.T) {
 	}
 
 	// 验证回复评论的字段
-	// 注意：现在ReplyToCommenter存储的是被回复用户的邮箱，而不是评论ID
+	// 注意：现在ReplyToCommenter存储的是被回复用户打码后的邮箱，而不是评论ID
 	// 我们应该验证OriginPostId和被回复者邮箱
-	if replyCommentVo.ReplyToCommenter != commentDto.CommenterEmail {
-		t.Errorf("被回复用户邮箱不正确: 期望 %s, 实际 %s", commentDto.CommenterEmail, replyCommentVo.ReplyToCommenter)
+	if replyCommentVo.ReplyToCommenter != webservice.MaskEmail(commentDto.CommenterEmail) {
+		t.Errorf("被回复用户邮箱不正确: 期望 %s, 实际 %s", webservice.MaskEmail(commentDto.CommenterEmail), replyCommentVo.ReplyToCommenter)
 	}
 
 	if replyCommentVo.OriginPostId != mainCommentVo.CommentId {
@@ -343,8 +344,8 @@ func TestAddReplyComment(t *testing.T) {
 
 	// 验证二级回复评论的字段
 	// 验证被回复用户的邮箱
-	if replyToReplyVo.ReplyToCommenter != replyCommentDto.CommenterEmail {
-		t.Errorf("二级回复的被回复用户邮箱不正确: 期望 %s, 实际 %s", replyCommentDto.CommenterEmail, replyToReplyVo.ReplyToCommenter)
+	if replyToReplyVo.ReplyToCommenter != webservice.MaskEmail(replyCommentDto.CommenterEmail) {
+		t.Errorf("二级回复的被回复用户邮箱不正确: 期望 %s, 实际 %s", webservice.MaskEmail(replyCommentDto.CommenterEmail), replyToReplyVo.ReplyToCommenter)
 	}
 
 	// 二级回复的OriginPostId应该和一级回复的OriginPostId相同（都指向楼主评论）
@@ -486,3 +487,96 @@ func TestGetAllComments(t *testing.T) {
 
 	t.Logf("获取所有评论测试通过: 总评论数=%d", len(comments))
 }
+
+// TestCommentModeration 测试评论审核流程
+func TestCommentModeration(t *testing.T) {
+	ctx := context.Background()
+
+	oldModeration := config.Comment.Moderation
+	config.Comment.Moderation = config.ModerationHoldAll
+	defer func() { config.Comment.Moderation = oldModeration }()
+
+	// 准备测试数据
+	commentDto1, blogId := setupTestData()
+	commentDto2 := *commentDto1
+	commentDto2.Content = "这是一条将被拒绝的评论"
+
+	commentVo1, err := webservice.AddComment(ctx, commentDto1)
+	if err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	commentVo2, err := webservice.AddComment(ctx, &commentDto2)
+	if err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	defer func() {
+		cleanupTx := storage.Storage.Db.WithContext(ctx).Begin()
+		_, _ = commentrepo.DeleteCommentById(cleanupTx, commentVo1.CommentId)
+		_, _ = commentrepo.DeleteCommentById(cleanupTx, commentVo2.CommentId)
+		cleanupTx.Commit()
+	}()
+
+	if commentVo1.Status != dto.CommentStatusPending {
+		t.Fatalf("评论状态应为 %s，实际为 %s", dto.CommentStatusPending, commentVo1.Status)
+	}
+
+	// 待审核的评论不应该在前台显示
+	comments, err := webservice.GetCommentsByBlogId(ctx, blogId)
+	if err != nil {
+		t.Fatalf("获取博客评论失败: %v", err)
+	}
+	if len(comments) != 0 {
+		t.Fatalf("待审核的评论不应该显示，实际获取到%d条", len(comments))
+	}
+
+	// 待审核列表中应包含两条评论
+	pending, err := GetPendingComments(ctx)
+	if err != nil {
+		t.Fatalf("获取待审核评论失败: %v", err)
+	}
+	foundPending := 0
+	for _, comment := range pending {
+		if comment.CommentId == commentVo1.CommentId || comment.CommentId == commentVo2.CommentId {
+			foundPending++
+		}
+	}
+	if foundPending != 2 {
+		t.Fatalf("待审核列表中应有2条测试评论，实际为%d条", foundPending)
+	}
+
+	// 通过第一条，拒绝第二条
+	approved, err := ApproveComments(ctx, []string{commentVo1.CommentId})
+	if err != nil {
+		t.Fatalf("审核通过评论失败: %v", err)
+	}
+	if len(approved) != 1 || approved[0].CommentId != commentVo1.CommentId {
+		t.Fatalf("应通过1条评论，实际为%d条", len(approved))
+	}
+	if _, err := RejectComments(ctx, []string{commentVo2.CommentId}); err != nil {
+		t.Fatalf("拒绝评论失败: %v", err)
+	}
+
+	// 再次通过已通过的评论不应重复返回
+	approved, err = ApproveComments(ctx, []string{commentVo1.CommentId})
+	if err != nil {
+		t.Fatalf("重复审核评论失败: %v", err)
+	}
+	if len(approved) != 0 {
+		t.Errorf("已通过的评论不应重复返回，实际返回%d条", len(approved))
+	}
+
+	comments, err = webservice.GetCommentsByBlogId(ctx, blogId)
+	if err != nil {
+		t.Fatalf("获取博客评论失败: %v", err)
+	}
+	if len(comments) != 1 || comments[0].CommentId != commentVo1.CommentId {
+		t.Fatalf("前台应只显示通过审核的评论，实际获取到%d条", len(comments))
+	}
+
+	// 审核不存在的评论应该失败
+	if _, err := ApproveComments(ctx, []string{"non_existent_comment_id"}); err == nil {
+		t.Error("审核不存在的评论应该返回错误")
+	}
+
+	t.Logf("评论审核测试通过: 通过=%s, 拒绝=%s", commentVo1.CommentId, commentVo2.CommentId)
+}
@@ -0,0 +1,249 @@
+package adminservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/emailrepo"
+	"sparrow_blog_server/pkg/email"
+	"sparrow_blog_server/pkg/logger"
+	"sync"
+	"time"
+)
+
+const (
+	emailWorkerCount       = 2                   // 并发发送邮件的协程数量
+	emailBatchSize         = 50                  // 每次查询到期邮件的数量
+	emailMaxAttempts       = 8                   // 最多尝试发送的次数，之后进入死信状态
+	emailRetryBaseDelay    = 30 * time.Second    // 第一次发送失败后的重试间隔，之后每次翻倍
+	emailRetryMaxDelay     = time.Hour           // 重试间隔的上限
+	emailOutboxMaxInterval = time.Minute         // 两次检查到期邮件之间的最长间隔
+	emailOutboxRetention   = 30 * 24 * time.Hour // 已发送与已取消的邮件保留的时长
+	emailListLimit         = 200                 // 管理员查询发件箱时最多返回的数量
+)
+
+// emailOutboxWakeup 有新邮件写入或邮件被重试时用于唤醒发件箱立即发送
+var emailOutboxWakeup = make(chan struct{}, 1)
+
+// emailOutbox 基于 EMAIL_OUTBOX 表的发件箱，注册到 email 包后系统邮件都写入此发件箱
+type emailOutbox struct{}
+
+// Enqueue 将邮件写入数据库并唤醒发件箱，服务重启后未发送的邮件仍会继续发送
+func (emailOutbox) Enqueue(ctx context.Context, msg email.Message) error {
+	if err := emailrepo.AddEmail(ctx, &dto.EmailOutboxDto{
+		Recipient:      msg.To,
+		Subject:        msg.Subject,
+		Content:        msg.Content,
+		TextContent:    msg.Text,
+		UnsubscribeUrl: msg.UnsubscribeUrl,
+	}); err != nil {
+		return err
+	}
+
+	notifyEmailOutbox()
+	return nil
+}
+
+// StartEmailOutbox 注册发件箱并启动后台发送
+// 启动时将上次退出时正在发送的邮件放回待发送状态并清理过期的已完成邮件；之后由一个协程查询到期的邮件，
+// 交给 emailWorkerCount 个协程并发发送。发送失败的邮件按指数退避重试，超过 emailMaxAttempts 次后进入死信状态，
+// 由管理员重试或取消。没有到期邮件时在最早的重试时间到达或有新邮件写入时被唤醒，最长每 emailOutboxMaxInterval 检查一次。
+// 参数:
+//   - ctx: 上下文对象，取消后发件箱停止发送
+//
+// 返回值:
+//   - func(): 停止发送并等待正在发送的邮件完成；发件箱保持注册，之后写入的邮件在下次启动时发送
+func StartEmailOutbox(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	if count, err := emailrepo.ResetSendingEmails(ctx); err != nil {
+		logger.Warn("重置正在发送的邮件失败: %v", err)
+	} else if count > 0 {
+		logger.Info("%d 封邮件在上次退出时未发送完成，将重新发送", count)
+	}
+	if _, err := emailrepo.DeleteFinishedEmailsBefore(ctx, time.Now().Add(-emailOutboxRetention)); err != nil {
+		logger.Warn("清理发件箱失败: %v", err)
+	}
+	email.SetOutbox(emailOutbox{})
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < emailWorkerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for emailId := range jobs {
+				sendOutboxEmail(ctx, emailId)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		logger.Info("邮件发件箱已启动")
+
+		for {
+			dispatchDueEmails(ctx, jobs)
+
+			timer := time.NewTimer(nextEmailDelay(ctx, time.Now()))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				logger.Info("邮件发件箱已停止")
+				return
+			case <-emailOutboxWakeup:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// notifyEmailOutbox 唤醒发件箱，不会阻塞调用方
+func notifyEmailOutbox() {
+	select {
+	case emailOutboxWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchDueEmails 将所有到期的邮件交给发送协程
+// 交出的邮件在发送协程取得之前仍是待发送状态，可能被下一次查询再次交出，发送协程取得邮件时会跳过已被取得的邮件。
+func dispatchDueEmails(ctx context.Context, jobs chan<- string) {
+	for {
+		emailIds, err := emailrepo.FindDueEmailIds(ctx, time.Now(), emailBatchSize)
+		if err != nil {
+			return
+		}
+
+		for _, emailId := range emailIds {
+			select {
+			case jobs <- emailId:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(emailIds) < emailBatchSize {
+			return
+		}
+	}
+}
+
+// nextEmailDelay 计算距离最早的待发送邮件的等待时长，不超过 emailOutboxMaxInterval
+func nextEmailDelay(ctx context.Context, now time.Time) time.Duration {
+	next, err := emailrepo.FindNextAttemptTime(ctx)
+	if err != nil || next == nil {
+		return emailOutboxMaxInterval
+	}
+
+	// 发送时间精确到秒，多等待一秒避免在边界上提前醒来
+	delay := next.Sub(now) + time.Second
+	if delay > emailOutboxMaxInterval {
+		return emailOutboxMaxInterval
+	}
+	return delay
+}
+
+// sendOutboxEmail 取得并发送一封邮件，根据发送结果标记为已发送、等待重试或死信
+// 发送结果的记录不受停止发件箱的影响，避免已发送的邮件在重启后被重复发送。
+func sendOutboxEmail(ctx context.Context, emailId string) {
+	emailDto, err := emailrepo.ClaimEmail(ctx, emailId)
+	if err != nil || emailDto == nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	attempts := emailDto.Attempts + 1
+	sendErr := email.Deliver(email.Message{
+		To:             emailDto.Recipient,
+		Subject:        emailDto.Subject,
+		Content:        emailDto.Content,
+		Text:           emailDto.TextContent,
+		UnsubscribeUrl: emailDto.UnsubscribeUrl,
+	})
+	if sendErr == nil {
+		_ = emailrepo.MarkEmailSent(ctx, emailId, attempts)
+		return
+	}
+
+	if attempts >= emailMaxAttempts {
+		logger.Error("邮件 %s 发送给 %s 失败 %d 次，已放弃发送: %v", emailId, emailDto.Recipient, attempts, sendErr)
+		_ = emailrepo.MarkEmailDead(ctx, emailId, attempts, sendErr.Error())
+		return
+	}
+
+	delay := emailRetryDelay(attempts)
+	logger.Warn("邮件 %s 发送给 %s 失败，%v 后重试: %v", emailId, emailDto.Recipient, delay, sendErr)
+	_ = emailrepo.RescheduleEmail(ctx, emailId, attempts, sendErr.Error(), time.Now().Add(delay))
+}
+
+// emailRetryDelay 计算第 attempts 次发送失败后的重试间隔
+// 从 emailRetryBaseDelay 开始每次翻倍，不超过 emailRetryMaxDelay
+func emailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempts && delay < emailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, emailRetryMaxDelay)
+}
+
+// GetOutboxEmails 查询发件箱中的邮件，按创建时间倒序排列，最多返回 emailListLimit 封
+// 参数:
+//   - ctx: 上下文对象
+//   - status: 发送状态，为空时查询所有状态
+//
+// 返回值:
+//   - []dto.EmailOutboxDto: 邮件列表
+//   - error: 状态无效或查询失败时返回错误信息
+func GetOutboxEmails(ctx context.Context, status string) ([]dto.EmailOutboxDto, error) {
+	statuses := []string{
+		dto.EmailStatusPending,
+		dto.EmailStatusSending,
+		dto.EmailStatusSent,
+		dto.EmailStatusDead,
+		dto.EmailStatusCancelled,
+	}
+	if status != "" && !slices.Contains(statuses, status) {
+		msg := fmt.Sprintf("无效的邮件状态: %s", status)
+		logger.Warn(msg)
+		return nil, errors.New(msg)
+	}
+
+	return emailrepo.FindEmails(ctx, status, emailListLimit)
+}
+
+// RetryOutboxEmail 立即重新发送死信、已取消或等待重试的邮件，重试次数重新计算
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - error: 邮件不存在、状态不允许重试或更新失败时返回错误信息
+func RetryOutboxEmail(ctx context.Context, emailId string) error {
+	if err := emailrepo.RetryEmail(ctx, emailId); err != nil {
+		return err
+	}
+
+	notifyEmailOutbox()
+	return nil
+}
+
+// CancelOutboxEmail 取消待发送或死信状态的邮件
+// 参数:
+//   - ctx: 上下文对象
+//   - emailId: 邮件ID
+//
+// 返回值:
+//   - error: 邮件不存在、状态不允许取消或更新失败时返回错误信息
+func CancelOutboxEmail(ctx context.Context, emailId string) error {
+	return emailrepo.CancelEmail(ctx, emailId)
+}
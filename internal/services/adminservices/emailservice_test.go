@@ -0,0 +1,211 @@
+package adminservices
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/internal/repositories/emailrepo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/email"
+	"sparrow_blog_server/pkg/email/smtptest"
+	"sparrow_blog_server/storage"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startFakeSmtp 启动本地 SMTP 服务器并将系统 SMTP 配置指向它，测试结束后恢复
+func startFakeSmtp(t *testing.T) *smtptest.Server {
+	srv, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("启动本地 SMTP 服务器失败: %v", err)
+	}
+	oldServer := config.Server
+	config.Server.SmtpAddress = srv.Host
+	config.Server.SmtpPort = srv.Port
+	config.Server.SmtpAccount = "blog@example.com"
+	config.Server.SmtpAuthCode = "unused"
+	t.Cleanup(func() {
+		config.Server = oldServer
+		srv.Close()
+	})
+	return srv
+}
+
+func addOutboxEmail(t *testing.T, recipient string) string {
+	emailDto := &dto.EmailOutboxDto{Recipient: recipient, Subject: "发件箱测试", Content: "<p>发件箱测试</p>"}
+	if err := emailrepo.AddEmail(context.Background(), emailDto); err != nil {
+		t.Fatalf("写入发件箱失败: %v", err)
+	}
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("email_id = ?", emailDto.EmailId).Delete(&po.EmailOutbox{})
+	})
+	return emailDto.EmailId
+}
+
+// waitEmailStatus 等待邮件进入指定状态，超时返回最后一次查询的结果
+func waitEmailStatus(t *testing.T, emailId, status string) *dto.EmailOutboxDto {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		emailDto, err := emailrepo.FindEmailById(context.Background(), emailId)
+		if err != nil {
+			t.Fatalf("查询邮件失败: %v", err)
+		}
+		if emailDto.Status == status || time.Now().After(deadline) {
+			return emailDto
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestEmailOutbox_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	srv := startFakeSmtp(t)
+
+	stop := StartEmailOutbox(ctx)
+	defer stop()
+	t.Cleanup(func() { email.SetOutbox(nil) })
+
+	// 回复通知写入发件箱后立即返回，由后台发送
+	if err := email.SendCommentOrReplyNotification(ctx, "replier@example.com", "发件箱测试", "回复内容",
+		"2024-06-24 15:30:00", "comment_id", "原评论", "outbox-reader@example.com",
+		"https://api.example.com/web/comment/unsubscribe?token=abc"); err != nil {
+		t.Fatalf("发送回复通知失败: %v", err)
+	}
+
+	emails, err := GetOutboxEmails(ctx, "")
+	if err != nil {
+		t.Fatalf("查询发件箱失败: %v", err)
+	}
+	var emailId string
+	for _, e := range emails {
+		if e.Recipient == "outbox-reader@example.com" {
+			emailId = e.EmailId
+			break
+		}
+	}
+	if emailId == "" {
+		t.Fatal("回复通知没有写入发件箱")
+	}
+	t.Cleanup(func() {
+		storage.Storage.Db.Where("email_id = ?", emailId).Delete(&po.EmailOutbox{})
+	})
+
+	msg, ok := srv.WaitMessage(5 * time.Second)
+	if !ok {
+		t.Fatal("本地 SMTP 服务器没有收到邮件")
+	}
+	if len(msg.To) != 1 || msg.To[0] != "outbox-reader@example.com" || msg.From != "blog@example.com" {
+		t.Errorf("收件人或发件人错误: %+v", msg)
+	}
+	if !strings.Contains(msg.Data, "List-Unsubscribe: <https://api.example.com/web/comment/unsubscribe?token=abc>") {
+		t.Error("发件箱发送的邮件应当保留退订邮件头")
+	}
+	if emailDto := waitEmailStatus(t, emailId, dto.EmailStatusSent); emailDto.Status != dto.EmailStatusSent ||
+		emailDto.Attempts != 1 || emailDto.SentTime == nil {
+		t.Errorf("邮件应当标记为已发送: %+v", emailDto)
+	}
+
+	if _, err := GetOutboxEmails(ctx, "unknown"); err == nil {
+		t.Error("无效的状态应当返回错误")
+	}
+}
+
+func TestEmailOutbox_RetryAndDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	srv := startFakeSmtp(t)
+	var accept atomic.Bool
+	srv.RejectRecipient = func(recipient string) bool { return !accept.Load() && recipient == "bounce@example.com" }
+
+	emailId := addOutboxEmail(t, "bounce@example.com")
+
+	// 第一次发送失败，按退避时间等待重试
+	before := time.Now()
+	sendOutboxEmail(ctx, emailId)
+	emailDto, _ := emailrepo.FindEmailById(ctx, emailId)
+	if emailDto.Status != dto.EmailStatusPending || emailDto.Attempts != 1 || emailDto.LastError == "" {
+		t.Fatalf("发送失败后应当等待重试: %+v", emailDto)
+	}
+	if wait := emailDto.NextAttemptAt.Sub(before); wait < emailRetryBaseDelay-time.Second || wait > emailRetryBaseDelay+time.Second {
+		t.Errorf("第一次重试应当在 %v 之后，实际 %v", emailRetryBaseDelay, wait)
+	}
+
+	// 最后一次尝试仍然失败后进入死信状态
+	storage.Storage.Db.Model(&po.EmailOutbox{}).Where("email_id = ?", emailId).Update("attempts", emailMaxAttempts-1)
+	sendOutboxEmail(ctx, emailId)
+	emailDto, _ = emailrepo.FindEmailById(ctx, emailId)
+	if emailDto.Status != dto.EmailStatusDead || emailDto.Attempts != emailMaxAttempts {
+		t.Fatalf("超过最大重试次数后应当进入死信状态: %+v", emailDto)
+	}
+
+	// 管理员重试后重新计算重试次数并发送成功
+	accept.Store(true)
+	if err := RetryOutboxEmail(ctx, emailId); err != nil {
+		t.Fatalf("重试邮件失败: %v", err)
+	}
+	sendOutboxEmail(ctx, emailId)
+	emailDto, _ = emailrepo.FindEmailById(ctx, emailId)
+	if emailDto.Status != dto.EmailStatusSent || emailDto.Attempts != 1 {
+		t.Fatalf("重试后应当发送成功: %+v", emailDto)
+	}
+	if len(srv.Messages()) != 1 {
+		t.Errorf("本地 SMTP 服务器应当只收到一封邮件，实际 %d 封", len(srv.Messages()))
+	}
+	if err := CancelOutboxEmail(ctx, emailId); err == nil {
+		t.Error("已发送的邮件不能取消")
+	}
+
+	// 取消的邮件不会被发送
+	cancelledId := addOutboxEmail(t, "cancelled@example.com")
+	if err := CancelOutboxEmail(ctx, cancelledId); err != nil {
+		t.Fatalf("取消邮件失败: %v", err)
+	}
+	sendOutboxEmail(ctx, cancelledId)
+	if emailDto, _ := emailrepo.FindEmailById(ctx, cancelledId); emailDto.Status != dto.EmailStatusCancelled {
+		t.Errorf("取消的邮件不应被发送: %+v", emailDto)
+	}
+}
+
+func TestEmailOutbox_ResumeAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	srv := startFakeSmtp(t)
+	t.Cleanup(func() { email.SetOutbox(nil) })
+
+	// 上次进程在发送期间退出，邮件停留在正在发送状态
+	emailId := addOutboxEmail(t, "resume@example.com")
+	if claimed, _ := emailrepo.ClaimEmail(ctx, emailId); claimed == nil {
+		t.Fatal("取得邮件失败")
+	}
+
+	stop := StartEmailOutbox(ctx)
+	defer stop()
+
+	if emailDto := waitEmailStatus(t, emailId, dto.EmailStatusSent); emailDto.Status != dto.EmailStatusSent {
+		t.Fatalf("重启后应当继续发送未完成的邮件: %+v", emailDto)
+	}
+	found := false
+	for _, msg := range srv.Messages() {
+		if len(msg.To) == 1 && msg.To[0] == "resume@example.com" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("本地 SMTP 服务器没有收到重启前未发送的邮件")
+	}
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1: emailRetryBaseDelay,
+		2: 2 * emailRetryBaseDelay,
+		4: 8 * emailRetryBaseDelay,
+		7: 64 * emailRetryBaseDelay,
+		8: emailRetryMaxDelay,
+	}
+	for attempts, want := range cases {
+		if got := emailRetryDelay(attempts); got != want {
+			t.Errorf("第 %d 次失败后的重试间隔应为 %v，实际 %v", attempts, want, got)
+		}
+	}
+}
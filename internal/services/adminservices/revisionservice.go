@@ -0,0 +1,275 @@
+package adminservices
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/revisionrepo"
+	"sparrow_blog_server/internal/services/webservice"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage"
+	"sparrow_blog_server/storage/ossstore"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gorm.io/gorm"
+)
+
+// GetBlogRevisions 获取博客的全部修订记录，按创建时间倒序排列
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客ID
+//
+// 返回值:
+//   - []dto.BlogRevisionDto: 修订记录列表
+//   - error: 查询失败时返回错误信息
+func GetBlogRevisions(ctx context.Context, blogId string) ([]dto.BlogRevisionDto, error) {
+	return revisionrepo.FindRevisionsByBlogId(ctx, blogId)
+}
+
+// DiffBlogRevisions 生成同一篇博客两个修订之间的统一格式差异（unified diff）
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客ID
+//   - fromId: 旧修订ID
+//   - toId: 新修订ID
+//
+// 返回值:
+//   - string: unified diff 文本，内容相同时为空字符串
+//   - error: 修订不存在或读取内容失败时返回错误信息
+func DiffBlogRevisions(ctx context.Context, blogId, fromId, toId string) (string, error) {
+	from, fromContent, err := loadRevision(ctx, blogId, fromId)
+	if err != nil {
+		return "", err
+	}
+	to, toContent, err := loadRevision(ctx, blogId, toId)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromContent)),
+		B:        difflib.SplitLines(string(toContent)),
+		FromFile: fmt.Sprintf("%s.md", from.BlogTitle),
+		FromDate: from.CreateTime.Format("2006-01-02 15:04:05"),
+		ToFile:   fmt.Sprintf("%s.md", to.BlogTitle),
+		ToDate:   to.CreateTime.Format("2006-01-02 15:04:05"),
+		Context:  3,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("生成修订差异失败: %v", err)
+		logger.Error(msg)
+		return "", errors.New(msg)
+	}
+
+	return diff, nil
+}
+
+// RestoreBlogRevision 将博客恢复到指定修订的内容、标题、简介和字数
+// 恢复本身也会生成一条新的修订记录，因此恢复操作可以再次撤销。
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客ID
+//   - revisionId: 要恢复的修订ID
+//
+// 返回值:
+//   - error: 恢复失败时返回错误信息
+func RestoreBlogRevision(ctx context.Context, blogId, revisionId string) error {
+	revision, content, err := loadRevision(ctx, blogId, revisionId)
+	if err != nil {
+		return err
+	}
+
+	blogDto, err := blogrepo.FindBlogById(ctx, blogId)
+	if err != nil {
+		return err
+	}
+	if blogDto.BlogId == "" {
+		msg := fmt.Sprintf("博客不存在: %s", blogId)
+		logger.Warn(msg)
+		return errors.New(msg)
+	}
+	oldTitle := blogDto.BlogTitle
+
+	// 修订中的标题可能已被其它博客使用，写入任何内容之前先检查，避免覆盖其它博客在 OSS 中的文章
+	if revision.BlogTitle != oldTitle {
+		ownerId, err := blogrepo.FindBlogIdByTitle(ctx, revision.BlogTitle)
+		if err != nil {
+			return err
+		}
+		if ownerId != "" {
+			msg := fmt.Sprintf("标题 %s 已被其它博客使用，无法恢复到该修订", revision.BlogTitle)
+			logger.Warn(msg)
+			return errors.New(msg)
+		}
+	}
+
+	// 开启事务
+	tx := storage.Storage.Db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("恢复博客修订失败: %v", r)
+			tx.Rollback()
+		}
+	}()
+
+	// 先更新数据库元数据，成功后再写回修订内容
+	blogDto.BlogTitle = revision.BlogTitle
+	blogDto.BlogBrief = revision.BlogBrief
+	blogDto.BlogWordsNum = revision.BlogWordsNum
+	if err := blogrepo.UpdateBlog(tx, blogDto); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := storage.Storage.PutContentToOss(ctx, content, ossstore.GenOssSavePath(revision.BlogTitle, ossstore.MarkDown)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveRevision(ctx, tx, blogDto); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	logger.Info("恢复博客修订成功: %s -> %s", blogId, revisionId)
+
+	// 标题变化时删除旧标题对应的文章，内容已保存在修订中，删除失败不影响恢复结果
+	if oldTitle != revision.BlogTitle {
+		if err := storage.Storage.DeleteObject(ctx, ossstore.GenOssSavePath(oldTitle, ossstore.MarkDown)); err != nil {
+			logger.Warn("删除 OSS 中的旧文章失败: %v", err)
+		}
+	}
+
+	// 删除缓存中的博客预签名 URL
+	if err := storage.Storage.Cache.Delete(ctx, storage.BuildBlogCacheKey(blogId)); err != nil {
+		logger.Warn("删除缓存中的博客预签名 URL 失败: %v", err)
+	}
+
+	// 根据博客状态同步搜索索引，隐藏或定时发布的博客不会因为恢复修订而被搜索到
+	if err := syncBlogIndex(ctx, blogDto); err != nil {
+		logger.Warn("同步博客搜索索引失败: %v", err)
+	}
+
+	// 标题与更新时间发生变化，重新生成站点地图
+	if err := webservice.RefreshSitemap(ctx); err != nil {
+		logger.Warn("重新生成站点地图失败: %v", err)
+	}
+
+	return nil
+}
+
+// saveRevision 将博客当前保存在对象存储中的内容记录为一条修订
+// 内容和标题都与最近一次修订相同时不会重复记录；对象存储中没有内容时跳过。
+// 参数:
+//   - ctx: 上下文对象
+//   - tx: 数据库事务对象
+//   - blogDto: 博客数据，需要包含博客ID和标题
+//
+// 返回值:
+//   - error: 读取内容或保存修订失败时返回错误信息
+func saveRevision(ctx context.Context, tx *gorm.DB, blogDto *dto.BlogDto) error {
+	contentPath := ossstore.GenOssSavePath(blogDto.BlogTitle, ossstore.MarkDown)
+	exist, err := storage.Storage.IsExist(ctx, contentPath)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		logger.Warn("博客内容不存在，跳过修订记录: %s", contentPath)
+		return nil
+	}
+
+	content, err := storage.Storage.GetContentFromOss(ctx, contentPath)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	contentHash := hex.EncodeToString(sum[:])
+
+	latest, err := revisionrepo.FindLatestRevisionByBlogId(ctx, blogDto.BlogId)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.ContentHash == contentHash && latest.BlogTitle == blogDto.BlogTitle {
+		logger.Info("博客内容未变化，跳过修订记录")
+		return nil
+	}
+
+	return addRevision(ctx, tx, blogDto, content, contentHash)
+}
+
+// saveBaselineRevision 博客还没有任何修订时，将即将被覆盖的旧内容保存为第一条修订
+// 用于在重命名博客删除旧文章之前保留原有内容。
+func saveBaselineRevision(ctx context.Context, tx *gorm.DB, blogDto *dto.BlogDto) error {
+	latest, err := revisionrepo.FindLatestRevisionByBlogId(ctx, blogDto.BlogId)
+	if err != nil || latest != nil {
+		return err
+	}
+	return saveRevision(ctx, tx, blogDto)
+}
+
+// addRevision 写入修订内容并添加修订记录
+// 修订内容按哈希保存，内容相同的修订共用同一个对象。
+func addRevision(ctx context.Context, tx *gorm.DB, blogDto *dto.BlogDto, content []byte, contentHash string) error {
+	objectPath := ossstore.GenRevisionSavePath(blogDto.BlogId, contentHash)
+	if err := storage.Storage.PutContentToOss(ctx, content, objectPath); err != nil {
+		return err
+	}
+
+	return revisionrepo.AddRevision(tx, &dto.BlogRevisionDto{
+		BlogId:       blogDto.BlogId,
+		BlogTitle:    blogDto.BlogTitle,
+		BlogBrief:    blogDto.BlogBrief,
+		BlogWordsNum: blogDto.BlogWordsNum,
+		ContentHash:  contentHash,
+		ObjectPath:   objectPath,
+	})
+}
+
+// deleteRevisions 删除博客的全部修订记录，返回需要删除的修订内容
+// 修订内容在事务提交后才能通过 deleteRevisionObjects 删除，避免事务回滚后修订记录指向已删除的内容。
+func deleteRevisions(ctx context.Context, tx *gorm.DB, blogId string) ([]string, error) {
+	objects, err := storage.Storage.ListOssDirFiles(ctx, ossstore.GenRevisionDir(blogId))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := revisionrepo.DeleteRevisionsByBlogId(tx, blogId); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// deleteRevisionObjects 删除修订内容，修订记录已经删除，删除失败只留下无法访问的对象，不影响结果
+func deleteRevisionObjects(ctx context.Context, objects []string) {
+	for _, object := range objects {
+		if err := storage.Storage.DeleteObject(ctx, object); err != nil {
+			logger.Warn("删除 OSS 中的修订内容失败: %v", err)
+		}
+	}
+}
+
+// loadRevision 读取修订记录及其内容，并校验修订属于指定博客
+func loadRevision(ctx context.Context, blogId, revisionId string) (*dto.BlogRevisionDto, []byte, error) {
+	revision, err := revisionrepo.FindRevisionById(ctx, revisionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if revision.BlogId != blogId {
+		msg := fmt.Sprintf("修订 %s 不属于博客 %s", revisionId, blogId)
+		logger.Warn(msg)
+		return nil, nil, errors.New(msg)
+	}
+
+	content, err := storage.Storage.GetContentFromOss(ctx, revision.ObjectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return revision, content, nil
+}
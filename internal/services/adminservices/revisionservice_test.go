@@ -0,0 +1,191 @@
+package adminservices
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/revisionrepo"
+	"sparrow_blog_server/searchengine"
+	"sparrow_blog_server/storage"
+	"sparrow_blog_server/storage/ossstore"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlogRevisions(t *testing.T) {
+	ctx := context.Background()
+	blogDto := &dto.BlogDto{BlogId: "test_revision_service", BlogTitle: "修订测试"}
+	contentPath := ossstore.GenOssSavePath(blogDto.BlogTitle, ossstore.MarkDown)
+
+	t.Cleanup(func() {
+		objects, _ := deleteRevisions(ctx, storage.Storage.Db, blogDto.BlogId)
+		deleteRevisionObjects(ctx, objects)
+		_ = storage.Storage.DeleteObject(ctx, contentPath)
+	})
+
+	save := func(content string) {
+		if err := storage.Storage.PutContentToOss(ctx, []byte(content), contentPath); err != nil {
+			t.Fatalf("上传博客内容失败: %v", err)
+		}
+		if err := saveRevision(ctx, storage.Storage.Db, blogDto); err != nil {
+			t.Fatalf("保存修订失败: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	save("# 标题\n\n第一行\n第二行\n")
+	save("# 标题\n\n第一行\n第二行\n")
+	save("# 标题\n\n第一行\n修改后的第二行\n")
+
+	revisions, err := GetBlogRevisions(ctx, blogDto.BlogId)
+	if err != nil {
+		t.Fatalf("获取修订记录失败: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("内容未变化时不应重复记录修订: %d", len(revisions))
+	}
+
+	diff, err := DiffBlogRevisions(ctx, blogDto.BlogId, revisions[1].RevisionId, revisions[0].RevisionId)
+	if err != nil {
+		t.Fatalf("生成修订差异失败: %v", err)
+	}
+	if !strings.Contains(diff, "-第二行\n") || !strings.Contains(diff, "+修改后的第二行\n") {
+		t.Fatalf("修订差异错误:\n%s", diff)
+	}
+
+	if _, err := DiffBlogRevisions(ctx, "other_blog", revisions[1].RevisionId, revisions[0].RevisionId); err == nil {
+		t.Fatal("不属于该博客的修订应当报错")
+	}
+
+	// 事务回滚时修订记录与修订内容都保留
+	tx := storage.Storage.Db.Begin()
+	if _, err := deleteRevisions(ctx, tx, blogDto.BlogId); err != nil {
+		t.Fatalf("删除修订失败: %v", err)
+	}
+	tx.Rollback()
+	if latest, _ := revisionrepo.FindLatestRevisionByBlogId(ctx, blogDto.BlogId); latest == nil {
+		t.Fatal("事务回滚后应当保留修订记录")
+	}
+	if exist, _ := storage.Storage.IsExist(ctx, revisions[0].ObjectPath); !exist {
+		t.Fatal("事务回滚后应当保留修订内容")
+	}
+
+	objects, err := deleteRevisions(ctx, storage.Storage.Db, blogDto.BlogId)
+	if err != nil {
+		t.Fatalf("删除修订失败: %v", err)
+	}
+	deleteRevisionObjects(ctx, objects)
+	if latest, _ := revisionrepo.FindLatestRevisionByBlogId(ctx, blogDto.BlogId); latest != nil {
+		t.Fatal("删除后不应存在修订记录")
+	}
+	if exist, _ := storage.Storage.IsExist(ctx, revisions[0].ObjectPath); exist {
+		t.Fatal("删除后不应存在修订内容")
+	}
+}
+
+func TestRestoreBlogRevision_TitleConflict(t *testing.T) {
+	ctx := context.Background()
+	blog := &dto.BlogDto{BlogTitle: "修订恢复测试"}
+	other := &dto.BlogDto{BlogTitle: "修订恢复测试_其它"}
+	for _, b := range []*dto.BlogDto{blog, other} {
+		if err := blogrepo.AddBlog(storage.Storage.Db, b); err != nil {
+			t.Fatalf("创建博客失败: %v", err)
+		}
+	}
+	otherPath := ossstore.GenOssSavePath(other.BlogTitle, ossstore.MarkDown)
+	t.Cleanup(func() {
+		for _, b := range []*dto.BlogDto{blog, other} {
+			_ = blogrepo.DeleteBlogById(storage.Storage.Db, b.BlogId)
+			objects, _ := deleteRevisions(ctx, storage.Storage.Db, b.BlogId)
+			deleteRevisionObjects(ctx, objects)
+		}
+		_ = storage.Storage.DeleteObject(ctx, otherPath)
+	})
+
+	if err := storage.Storage.PutContentToOss(ctx, []byte("其它博客的内容"), otherPath); err != nil {
+		t.Fatalf("上传博客内容失败: %v", err)
+	}
+
+	// 博客曾经使用过的标题后来被其它博客使用
+	content := []byte("旧标题下的内容")
+	revisionBlog := &dto.BlogDto{BlogId: blog.BlogId, BlogTitle: other.BlogTitle}
+	if err := addRevision(ctx, storage.Storage.Db, revisionBlog, content, "title_conflict_hash"); err != nil {
+		t.Fatalf("添加修订失败: %v", err)
+	}
+	revisions, err := GetBlogRevisions(ctx, blog.BlogId)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("获取修订记录失败: %v, %v", err, revisions)
+	}
+
+	if err := RestoreBlogRevision(ctx, blog.BlogId, revisions[0].RevisionId); err == nil {
+		t.Fatal("修订的标题已被其它博客使用时应当报错")
+	}
+	if got, _ := storage.Storage.GetContentFromOss(ctx, otherPath); string(got) != "其它博客的内容" {
+		t.Errorf("其它博客的内容不应被覆盖: %s", got)
+	}
+	if title, _ := blogrepo.FindBlogTitleById(ctx, blog.BlogId); title != blog.BlogTitle {
+		t.Errorf("恢复失败时博客标题不应改变: %s", title)
+	}
+}
+
+func TestRestoreBlogRevision_HiddenBlogIndex(t *testing.T) {
+	ctx := context.Background()
+	if err := searchengine.LoadingIndex(ctx); err != nil {
+		t.Fatalf("加载搜索索引失败: %v", err)
+	}
+
+	blog := &dto.BlogDto{BlogTitle: "隐藏博客修订恢复测试", BlogState: false}
+	if err := blogrepo.AddBlog(storage.Storage.Db, blog); err != nil {
+		t.Fatalf("创建博客失败: %v", err)
+	}
+	contentPath := ossstore.GenOssSavePath(blog.BlogTitle, ossstore.MarkDown)
+	t.Cleanup(func() {
+		_ = blogrepo.DeleteBlogById(storage.Storage.Db, blog.BlogId)
+		objects, _ := deleteRevisions(ctx, storage.Storage.Db, blog.BlogId)
+		deleteRevisionObjects(ctx, objects)
+		_ = storage.Storage.DeleteObject(ctx, contentPath)
+		_ = searchengine.DeleteIndex(blog.BlogId)
+	})
+
+	if err := storage.Storage.PutContentToOss(ctx, []byte("restorehidden 隐藏的内容"), contentPath); err != nil {
+		t.Fatalf("上传博客内容失败: %v", err)
+	}
+	if err := saveRevision(ctx, storage.Storage.Db, blog); err != nil {
+		t.Fatalf("保存修订失败: %v", err)
+	}
+	revisions, err := GetBlogRevisions(ctx, blog.BlogId)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("获取修订记录失败: %v, %v", err, revisions)
+	}
+
+	searchable := func() bool {
+		result, err := searchengine.Search(searchengine.SearchRequest{Query: "restorehidden"})
+		if err != nil {
+			t.Fatalf("搜索失败: %v", err)
+		}
+		for _, hit := range result.Hits {
+			if hit.ID == blog.BlogId {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err := RestoreBlogRevision(ctx, blog.BlogId, revisions[0].RevisionId); err != nil {
+		t.Fatalf("恢复修订失败: %v", err)
+	}
+	if searchable() {
+		t.Error("恢复隐藏博客的修订后不应能被搜索到")
+	}
+
+	// 显示状态的博客恢复修订后可以被搜索到
+	storage.Storage.Db.Model(&po.Blog{}).Where("blog_id = ?", blog.BlogId).Update("blog_state", true)
+	if err := RestoreBlogRevision(ctx, blog.BlogId, revisions[0].RevisionId); err != nil {
+		t.Fatalf("恢复修订失败: %v", err)
+	}
+	if !searchable() {
+		t.Error("恢复显示博客的修订后应当能被搜索到")
+	}
+}
@@ -0,0 +1,168 @@
+package adminservices
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/services/webservice"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/searchengine"
+	"sparrow_blog_server/storage"
+	"sync"
+	"time"
+)
+
+// schedulerMaxInterval 调度器两次检查之间的最长间隔
+const schedulerMaxInterval = time.Minute
+
+// schedulerWakeup 定时时间被修改时用于唤醒调度器重新计算下次执行时间
+var schedulerWakeup = make(chan struct{}, 1)
+
+// StartBlogScheduler 启动博客定时发布与定时下线调度器
+// 启动时会立即执行一次，补上服务停止期间错过的状态切换；之后在最近的定时时间到达时执行，
+// 最长每 schedulerMaxInterval 检查一次。
+// 参数:
+//   - ctx: 上下文对象，取消后调度器退出
+//
+// 返回值:
+//   - func(): 停止调度器并等待正在执行的切换完成
+func StartBlogScheduler(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		logger.Info("博客定时调度器已启动")
+
+		for {
+			if _, err := applyDueSchedules(ctx, time.Now()); err != nil {
+				logger.Warn("执行博客定时任务失败: %v", err)
+			}
+
+			timer := time.NewTimer(nextScheduleDelay(ctx, time.Now()))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				logger.Info("博客定时调度器已停止")
+				return
+			case <-schedulerWakeup:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// notifyScheduler 唤醒调度器，不会阻塞调用方
+func notifyScheduler() {
+	select {
+	case schedulerWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// nextScheduleDelay 计算距离下一次定时时间的等待时长，不超过 schedulerMaxInterval
+func nextScheduleDelay(ctx context.Context, now time.Time) time.Duration {
+	next, err := blogrepo.FindNextScheduleTime(ctx, now)
+	if err != nil || next == nil {
+		return schedulerMaxInterval
+	}
+
+	// 定时时间精确到秒，多等待一秒避免在边界上提前醒来
+	delay := next.Sub(now) + time.Second
+	if delay > schedulerMaxInterval {
+		return schedulerMaxInterval
+	}
+	return delay
+}
+
+// applyDueSchedules 执行所有到期的定时发布与定时下线
+// 同一篇博客的发布时间和下线时间都已到期时（如服务停止期间），以较晚的一个为准。
+// 搜索索引和缓存在提交数据库之前更新，这两步都是幂等的，进程在中途退出时重启后会重新执行；
+// 定时时间在切换成功后才会清空，因此服务停止期间到期的切换会在下次启动时补上。
+// 参数:
+//   - ctx: 上下文对象
+//   - now: 当前时间
+//
+// 返回值:
+//   - int: 本次切换的博客数量
+//   - error: 查询或更新失败时返回错误信息
+func applyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	blogDtos, err := blogrepo.FindBlogsWithDueSchedule(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, blogDto := range blogDtos {
+		if ctx.Err() != nil {
+			return applied, ctx.Err()
+		}
+
+		publishDue := blogDto.PublishAt != nil && !blogDto.PublishAt.After(now)
+		unpublishDue := blogDto.UnpublishAt != nil && !blogDto.UnpublishAt.After(now)
+		state := publishDue
+		if publishDue && unpublishDue {
+			state = blogDto.PublishAt.After(*blogDto.UnpublishAt)
+		}
+
+		if err := applyBlogSchedule(ctx, blogDto, state, publishDue, unpublishDue); err != nil {
+			logger.Warn("博客 %v 定时切换失败: %v", blogDto.BlogId, err)
+			continue
+		}
+		applied++
+	}
+
+	// 已发布博客的集合发生变化，重新生成站点地图
+	if applied > 0 {
+		if err := webservice.RefreshSitemap(ctx); err != nil {
+			logger.Warn("重新生成站点地图失败: %v", err)
+		}
+	}
+
+	return applied, nil
+}
+
+// applyBlogSchedule 切换单篇博客的状态，并同步搜索索引和缓存
+func applyBlogSchedule(ctx context.Context, blogDto *dto.BlogDto, state bool, clearPublish, clearUnpublish bool) error {
+	tx := storage.Storage.Db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("博客定时切换失败: %v", r)
+			tx.Rollback()
+		}
+	}()
+
+	if err := blogrepo.ApplyBlogSchedule(tx, blogDto.BlogId, state, clearPublish, clearUnpublish); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 索引更新失败不阻止状态切换，与手动修改博客时的处理保持一致
+	blogDto.BlogState = state
+	if err := syncBlogIndex(ctx, blogDto); err != nil {
+		logger.Warn("同步博客搜索索引失败: %v", err)
+	}
+
+	// 删除缓存中的博客预签名 URL，下线后不再复用之前生成的内容链接
+	if err := storage.Storage.Cache.Delete(ctx, storage.BuildBlogCacheKey(blogDto.BlogId)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// syncBlogIndex 根据博客状态同步搜索索引，只有显示状态的博客可以被搜索到
+func syncBlogIndex(ctx context.Context, blogDto *dto.BlogDto) error {
+	if blogDto.BlogState {
+		return searchengine.UpdateIndex(ctx, blogDto)
+	}
+	return searchengine.DeleteIndex(blogDto.BlogId)
+}
@@ -0,0 +1,85 @@
+package adminservices
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/storage"
+	"testing"
+	"time"
+)
+
+func addScheduledBlog(t *testing.T, title string, state bool, publishAt, unpublishAt *time.Time) string {
+	blogDto := &dto.BlogDto{
+		BlogTitle:   title,
+		CategoryId:  "schedule_test_category",
+		BlogState:   state,
+		PublishAt:   publishAt,
+		UnpublishAt: unpublishAt,
+	}
+	tx := storage.Storage.Db.Begin()
+	if err := blogrepo.AddBlog(tx, blogDto); err != nil {
+		t.Fatalf("添加博客失败: %v", err)
+	}
+	tx.Commit()
+
+	t.Cleanup(func() {
+		tx := storage.Storage.Db.Begin()
+		_ = blogrepo.DeleteBlogById(tx, blogDto.BlogId)
+		tx.Commit()
+	})
+	return blogDto.BlogId
+}
+
+func TestApplyDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	earlier := now.Add(-2 * time.Hour)
+	future := now.Add(time.Hour)
+
+	publishId := addScheduledBlog(t, "定时发布测试", false, &past, nil)
+	unpublishId := addScheduledBlog(t, "定时下线测试", true, nil, &past)
+	// 服务停止期间发布和下线都已到期，以较晚的下线为准
+	bothId := addScheduledBlog(t, "定时发布后下线测试", false, &earlier, &past)
+	futureId := addScheduledBlog(t, "未到期测试", false, &future, nil)
+
+	applied, err := applyDueSchedules(ctx, now)
+	if err != nil {
+		t.Fatalf("执行定时任务失败: %v", err)
+	}
+	if applied != 3 {
+		t.Fatalf("应当切换 3 篇博客，实际 %d", applied)
+	}
+
+	check := func(id string, state bool, hasPublish, hasUnpublish bool) {
+		blogDto, err := blogrepo.FindBlogById(ctx, id)
+		if err != nil {
+			t.Fatalf("查询博客失败: %v", err)
+		}
+		if blogDto.BlogState != state {
+			t.Errorf("博客 %s 状态应为 %v", blogDto.BlogTitle, state)
+		}
+		if (blogDto.PublishAt != nil) != hasPublish || (blogDto.UnpublishAt != nil) != hasUnpublish {
+			t.Errorf("博客 %s 的定时时间清理错误: %v, %v", blogDto.BlogTitle, blogDto.PublishAt, blogDto.UnpublishAt)
+		}
+	}
+	check(publishId, true, false, false)
+	check(unpublishId, false, false, false)
+	check(bothId, false, false, false)
+	check(futureId, false, true, false)
+
+	// 已执行的定时任务不会重复执行
+	applied, err = applyDueSchedules(ctx, now)
+	if err != nil || applied != 0 {
+		t.Fatalf("重复执行不应再切换博客: %d, %v", applied, err)
+	}
+
+	next, err := blogrepo.FindNextScheduleTime(ctx, now)
+	if err != nil || next == nil || !next.Equal(future.Truncate(time.Second)) {
+		t.Fatalf("下一个定时时间错误: %v, %v", next, err)
+	}
+	if delay := nextScheduleDelay(ctx, now); delay != schedulerMaxInterval {
+		t.Fatalf("等待时间不应超过最长间隔: %v", delay)
+	}
+}
@@ -0,0 +1,111 @@
+package webservice
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/vo"
+	"sparrow_blog_server/pkg/config"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxNicknameLength = 50  // 昵称最多包含的字符数
+	maxWebsiteLength  = 255 // 个人网站地址的最大长度
+)
+
+// NormalizeCommenterProfile 校验并整理评论者填写的昵称与个人网站，两者都可以为空
+// - commentDto: 评论数据传输对象，整理后的昵称与个人网站会写回其中
+//
+// 返回值:
+// - error: 昵称过长或包含控制字符、个人网站不是 http/https 地址时返回错误信息
+func NormalizeCommenterProfile(commentDto *dto.CommentDto) error {
+	nickname := strings.TrimSpace(commentDto.Nickname)
+	if utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return fmt.Errorf("昵称不能超过 %d 个字符", maxNicknameLength)
+	}
+	if strings.ContainsFunc(nickname, unicode.IsControl) {
+		return errors.New("昵称包含无效字符")
+	}
+	commentDto.Nickname = nickname
+
+	website := strings.TrimSpace(commentDto.Website)
+	if website != "" {
+		if len(website) > maxWebsiteLength {
+			return fmt.Errorf("个人网站地址不能超过 %d 个字符", maxWebsiteLength)
+		}
+		// 只允许 http 与 https 地址，避免 javascript: 等地址被前台渲染为链接
+		u, err := url.Parse(website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("个人网站必须是 http 或 https 地址")
+		}
+		website = u.String()
+	}
+	commentDto.Website = website
+
+	return nil
+}
+
+// MaskEmail 将邮箱打码后用于前台展示，只保留用户名的第一个字符与域名，如 a***@example.com
+// - email: 邮箱地址
+//
+// 返回值:
+// - string: 打码后的邮箱，邮箱为空时返回空字符串
+func MaskEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first) + "***" + email[at:]
+}
+
+// CommenterAvatar 根据评论者邮箱计算 Gravatar/Cravatar 头像
+// - email: 评论者邮箱
+//
+// 返回值:
+// - string: 去除首尾空白并转为小写后的邮箱的 MD5 摘要
+// - string: 使用 comment.avatar_base_url 拼接的头像地址
+func CommenterAvatar(email string) (string, string) {
+	sum := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+	hash := hex.EncodeToString(sum[:])
+
+	baseUrl := config.Comment.AvatarBaseUrl
+	if baseUrl == "" {
+		baseUrl = config.DefaultAvatarBaseUrl
+	}
+	if !strings.HasSuffix(baseUrl, "/") {
+		baseUrl += "/"
+	}
+	return hash, baseUrl + hash
+}
+
+// publicCommentVo 将评论转为前台展示的视图对象，邮箱打码，头像由服务端计算
+func publicCommentVo(commentDto *dto.CommentDto, blogTitle string) vo.CommentVo {
+	avatarHash, avatarUrl := CommenterAvatar(commentDto.CommenterEmail)
+	return vo.CommentVo{
+		CommentId:        commentDto.CommentId,
+		CommenterEmail:   MaskEmail(commentDto.CommenterEmail),
+		Nickname:         commentDto.Nickname,
+		Website:          commentDto.Website,
+		AvatarHash:       avatarHash,
+		AvatarUrl:        avatarUrl,
+		BlogId:           commentDto.BlogId,
+		BlogTitle:        blogTitle,
+		OriginPostId:     commentDto.OriginPostId,
+		ReplyToCommenter: MaskEmail(commentDto.ReplyToCommenter),
+		ReplyToNickname:  commentDto.ReplyToNickname,
+		Content:          commentDto.Content,
+		ContentHtml:      CommentContentHtml(commentDto),
+		CreateTime:       commentDto.CreateTime,
+	}
+}
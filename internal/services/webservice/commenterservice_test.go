@@ -0,0 +1,131 @@
+package webservice
+
+import (
+	"context"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/storage"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCommenterProfile(t *testing.T) {
+	commentDto := &dto.CommentDto{Nickname: "  小明 ", Website: " https://example.com/blog "}
+	if err := NormalizeCommenterProfile(commentDto); err != nil {
+		t.Fatalf("有效的昵称与个人网站不应返回错误: %v", err)
+	}
+	if commentDto.Nickname != "小明" || commentDto.Website != "https://example.com/blog" {
+		t.Errorf("昵称与个人网站应去除首尾空白: %q, %q", commentDto.Nickname, commentDto.Website)
+	}
+
+	// 昵称与个人网站都可以不填
+	if err := NormalizeCommenterProfile(&dto.CommentDto{}); err != nil {
+		t.Errorf("不填写昵称与个人网站不应返回错误: %v", err)
+	}
+
+	invalid := []dto.CommentDto{
+		{Nickname: strings.Repeat("长", maxNicknameLength+1)},
+		{Nickname: "换\n行"},
+		{Website: "javascript:alert(1)"},
+		{Website: "ftp://example.com"},
+		{Website: "example.com"},
+		{Website: "https://example.com/" + strings.Repeat("a", maxWebsiteLength)},
+	}
+	for _, c := range invalid {
+		if err := NormalizeCommenterProfile(&c); err == nil {
+			t.Errorf("无效的昵称或个人网站应当返回错误: %+v", c)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	cases := map[string]string{
+		"alice@example.com": "a***@example.com",
+		"张三@example.cn":     "张***@example.cn",
+		"a@b.c":             "a***@b.c",
+		"invalid":           "***",
+		"@example.com":      "***",
+		"":                  "",
+	}
+	for email, want := range cases {
+		if got := MaskEmail(email); got != want {
+			t.Errorf("MaskEmail(%q) = %q, 期望 %q", email, got, want)
+		}
+	}
+}
+
+func TestCommenterAvatar(t *testing.T) {
+	oldBaseUrl := config.Comment.AvatarBaseUrl
+	defer func() { config.Comment.AvatarBaseUrl = oldBaseUrl }()
+
+	// 摘要与 Gravatar 文档中的示例一致，邮箱不区分大小写并忽略首尾空白
+	config.Comment.AvatarBaseUrl = "https://www.gravatar.com/avatar"
+	hash, avatarUrl := CommenterAvatar(" MyEmailAddress@example.com ")
+	if hash != "0bc83cb571cd1c50ba6f3e8a78ef1346" {
+		t.Errorf("头像摘要错误: %s", hash)
+	}
+	if avatarUrl != "https://www.gravatar.com/avatar/"+hash {
+		t.Errorf("头像地址错误: %s", avatarUrl)
+	}
+
+	config.Comment.AvatarBaseUrl = ""
+	if _, avatarUrl := CommenterAvatar("reader@example.com"); !strings.HasPrefix(avatarUrl, config.DefaultAvatarBaseUrl) {
+		t.Errorf("未配置头像服务时应使用默认地址: %s", avatarUrl)
+	}
+}
+
+func TestPublicCommentsMaskEmail(t *testing.T) {
+	ctx := context.Background()
+	oldModeration := config.Comment.Moderation
+	config.Comment.Moderation = config.ModerationAutoApprove
+	t.Cleanup(func() {
+		config.Comment.Moderation = oldModeration
+		storage.Storage.Db.Where("blog_id = ?", "masked_blog").Delete(&po.Comment{})
+	})
+
+	thread, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail: "author@example.com",
+		Nickname:       "楼主",
+		Website:        "https://author.example.com",
+		BlogId:         "masked_blog",
+		Content:        "楼主评论",
+	})
+	if err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	reply, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail:   "replier@example.com",
+		BlogId:           "masked_blog",
+		ReplyToCommentId: thread.CommentId,
+		Content:          "回复楼主",
+	})
+	if err != nil {
+		t.Fatalf("添加回复失败: %v", err)
+	}
+	if reply.CommenterEmail != "r***@example.com" || reply.ReplyToCommenter != "a***@example.com" || reply.ReplyToNickname != "楼主" {
+		t.Errorf("提交评论的响应中邮箱应当打码: %+v", reply)
+	}
+
+	comments, err := GetCommentsByBlogId(ctx, "masked_blog")
+	if err != nil || len(comments) != 1 || len(comments[0].SubComments) != 1 {
+		t.Fatalf("获取评论失败: %v, %+v", err, comments)
+	}
+	if c := comments[0]; c.CommenterEmail != "a***@example.com" || c.Nickname != "楼主" ||
+		c.Website != "https://author.example.com" || c.AvatarHash == "" || c.AvatarUrl == "" {
+		t.Errorf("楼主评论的展示数据错误: %+v", c)
+	}
+	if sub := comments[0].SubComments[0]; sub.CommenterEmail != "r***@example.com" || sub.ReplyToCommenter != "a***@example.com" {
+		t.Errorf("子评论的邮箱应当打码: %+v", sub)
+	}
+
+	latest, err := GetLatestComments(ctx)
+	if err != nil {
+		t.Fatalf("获取最新评论失败: %v", err)
+	}
+	for _, c := range latest {
+		if strings.Contains(c.CommenterEmail+c.ReplyToCommenter, "author@") || strings.Contains(c.CommenterEmail, "replier@") {
+			t.Errorf("最新评论中的邮箱应当打码: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package webservice
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/vo"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/commentrepo"
+	"sparrow_blog_server/pkg/logger"
+	"time"
+)
+
+const (
+	DefaultCommentPageSize = 10 // 每页默认返回的评论数量
+	MaxCommentPageSize     = 50 // 每页最多返回的评论数量
+)
+
+// ErrInvalidCommentPage 评论分页的排序方式或游标无效
+var ErrInvalidCommentPage = errors.New("评论分页参数无效")
+
+// ErrCommentNotFound 评论不存在或未通过审核
+var ErrCommentNotFound = errors.New("评论不存在")
+
+// commentCursorToken 接口中的分页游标，编码为 base64 的 JSON，客户端不需要解析
+// 游标记录了生成时的排序方式，不能用于其它排序方式的翻页
+type commentCursorToken struct {
+	Sort       string    `json:"s,omitempty"`
+	CreateTime time.Time `json:"t"`
+	CommentId  string    `json:"i"`
+	ReplyCount int64     `json:"n,omitempty"`
+}
+
+// GetCommentThreads 分页获取博客下的楼主评论（业务端功能）
+// 楼层中的回复不随楼主评论返回，只返回回复数量，由前台通过 GetCommentReplies 按需加载。
+// - ctx: 上下文对象
+// - blogId: 博客ID
+// - sortMode: 排序方式，为空时按最新发表排序，取值见 dto.CommentSortNewest 等常量
+// - cursor: 上一页返回的游标，为空时获取第一页
+// - limit: 每页数量，不在 1 到 MaxCommentPageSize 之间时使用默认值或上限
+//
+// 返回值:
+// - *vo.CommentPageVo: 楼主评论与下一页的游标
+// - error: 排序方式或游标无效时返回 ErrInvalidCommentPage，查询失败时返回错误信息
+func GetCommentThreads(ctx context.Context, blogId string, sortMode string, cursor string, limit int) (*vo.CommentPageVo, error) {
+	if sortMode == "" {
+		sortMode = dto.CommentSortNewest
+	}
+	switch sortMode {
+	case dto.CommentSortNewest, dto.CommentSortOldest, dto.CommentSortMostReplied:
+	default:
+		return nil, fmt.Errorf("%w: 排序方式只能是 %s、%s 或 %s", ErrInvalidCommentPage,
+			dto.CommentSortNewest, dto.CommentSortOldest, dto.CommentSortMostReplied)
+	}
+
+	after, err := decodeCommentCursor(cursor, sortMode)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = commentPageSize(limit)
+	commentDtos, err := commentrepo.FindThreadsByBlogId(ctx, blogId, sortMode, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根据博客ID查询博客标题（只查询一次，因为都是同一篇博客的评论）
+	blogTitle, err := blogrepo.FindBlogTitleById(ctx, blogId)
+	if err != nil {
+		logger.Warn("查询博客标题失败，BlogId: %s, 错误: %v", blogId, err)
+		blogTitle = "" // 设置为空字符串
+	}
+
+	return buildCommentPage(commentDtos, blogTitle, sortMode, limit), nil
+}
+
+// GetCommentReplies 按发表时间正序分页获取楼层中的回复（业务端功能）
+// - ctx: 上下文对象
+// - commentId: 楼主评论ID
+// - cursor: 上一页返回的游标，为空时获取第一页
+// - limit: 每页数量，不在 1 到 MaxCommentPageSize 之间时使用默认值或上限
+//
+// 返回值:
+// - *vo.CommentPageVo: 回复与下一页的游标
+// - error: 楼主评论不存在、未通过审核或不是楼主评论时返回 ErrCommentNotFound，游标无效时返回 ErrInvalidCommentPage
+func GetCommentReplies(ctx context.Context, commentId string, cursor string, limit int) (*vo.CommentPageVo, error) {
+	after, err := decodeCommentCursor(cursor, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// 未通过审核的楼层在前台不可见，其中的回复同样不可见
+	thread, err := commentrepo.FindCommentById(ctx, commentId)
+	if err != nil || thread.Status != dto.CommentStatusApproved || thread.OriginPostId != "" {
+		return nil, ErrCommentNotFound
+	}
+
+	limit = commentPageSize(limit)
+	commentDtos, err := commentrepo.FindRepliesByOriginPostId(ctx, commentId, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	blogTitle, err := blogrepo.FindBlogTitleById(ctx, thread.BlogId)
+	if err != nil {
+		logger.Warn("查询博客标题失败，BlogId: %s, 错误: %v", thread.BlogId, err)
+		blogTitle = "" // 设置为空字符串
+	}
+
+	return buildCommentPage(commentDtos, blogTitle, "", limit), nil
+}
+
+// commentPageSize 将每页数量限制在 1 到 MaxCommentPageSize 之间，未指定时使用默认值
+func commentPageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultCommentPageSize
+	}
+	return min(limit, MaxCommentPageSize)
+}
+
+// buildCommentPage 将查询结果转为分页结果，commentDtos 比 limit 多一条时表示还有下一页
+func buildCommentPage(commentDtos []dto.CommentDto, blogTitle string, sortMode string, limit int) *vo.CommentPageVo {
+	page := &vo.CommentPageVo{Comments: make([]vo.CommentVo, 0, min(len(commentDtos), limit))}
+	if len(commentDtos) > limit {
+		commentDtos = commentDtos[:limit]
+		page.HasMore = true
+	}
+
+	for _, commentDto := range commentDtos {
+		commentVo := publicCommentVo(&commentDto, blogTitle)
+		commentVo.ReplyCount = commentDto.ReplyCount
+		page.Comments = append(page.Comments, commentVo)
+	}
+
+	if page.HasMore {
+		last := commentDtos[len(commentDtos)-1]
+		page.NextCursor = encodeCommentCursor(commentCursorToken{
+			Sort:       sortMode,
+			CreateTime: last.CreateTime,
+			CommentId:  last.CommentId,
+			ReplyCount: last.ReplyCount,
+		})
+	}
+	return page
+}
+
+func encodeCommentCursor(token commentCursorToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCommentCursor 解析游标并检查游标的排序方式，游标为空时返回 nil
+func decodeCommentCursor(cursor string, sortMode string) (*dto.CommentCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 游标格式错误", ErrInvalidCommentPage)
+	}
+	var token commentCursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.CommentId == "" {
+		return nil, fmt.Errorf("%w: 游标格式错误", ErrInvalidCommentPage)
+	}
+	if token.Sort != sortMode {
+		return nil, fmt.Errorf("%w: 游标与排序方式不一致", ErrInvalidCommentPage)
+	}
+
+	return &dto.CommentCursor{
+		CreateTime: token.CreateTime,
+		CommentId:  token.CommentId,
+		ReplyCount: token.ReplyCount,
+	}, nil
+}
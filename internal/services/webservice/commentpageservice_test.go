@@ -0,0 +1,149 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/internal/model/vo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/storage"
+	"testing"
+	"time"
+)
+
+// collectCommentPages 按游标依次获取所有分页，返回评论 ID 与每页的数量
+func collectCommentPages(t *testing.T, fetch func(cursor string) (*vo.CommentPageVo, error)) ([]string, []int) {
+	var ids []string
+	var sizes []int
+	cursor := ""
+	for range 20 {
+		page, err := fetch(cursor)
+		if err != nil {
+			t.Fatalf("分页获取评论失败: %v", err)
+		}
+		for _, c := range page.Comments {
+			ids = append(ids, c.CommentId)
+		}
+		sizes = append(sizes, len(page.Comments))
+		if page.HasMore != (page.NextCursor != "") {
+			t.Fatalf("has_more 与 next_cursor 不一致: %+v", page)
+		}
+		if !page.HasMore {
+			return ids, sizes
+		}
+		cursor = page.NextCursor
+	}
+	t.Fatal("分页没有结束")
+	return nil, nil
+}
+
+func TestGetCommentThreads(t *testing.T) {
+	ctx := context.Background()
+	oldModeration := config.Comment.Moderation
+	config.Comment.Moderation = config.ModerationAutoApprove
+	t.Cleanup(func() {
+		config.Comment.Moderation = oldModeration
+		storage.Storage.Db.Where("blog_id = ?", "page_blog").Delete(&po.Comment{})
+	})
+
+	// 5 个楼层，第 2 层有 3 条回复，第 4 层有 1 条回复
+	var threads []string
+	for range 5 {
+		thread, err := AddComment(ctx, &dto.CommentDto{CommenterEmail: "page@example.com", BlogId: "page_blog", Content: "楼层"})
+		if err != nil {
+			t.Fatalf("添加评论失败: %v", err)
+		}
+		threads = append(threads, thread.CommentId)
+	}
+	var replies []string
+	for _, threadIndex := range []int{1, 1, 3, 1} {
+		reply, err := AddComment(ctx, &dto.CommentDto{CommenterEmail: "page@example.com", BlogId: "page_blog",
+			ReplyToCommentId: threads[threadIndex], Content: "回复"})
+		if err != nil {
+			t.Fatalf("添加回复失败: %v", err)
+		}
+		if threadIndex == 1 {
+			replies = append(replies, reply.CommentId)
+		}
+	}
+
+	// 待审核的楼层与回复不会出现在分页结果中
+	config.Comment.Moderation = config.ModerationHoldAll
+	if _, err := AddComment(ctx, &dto.CommentDto{CommenterEmail: "page@example.com", BlogId: "page_blog", Content: "待审核"}); err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	if _, err := AddComment(ctx, &dto.CommentDto{CommenterEmail: "page@example.com", BlogId: "page_blog",
+		ReplyToCommentId: threads[3], Content: "待审核回复"}); err != nil {
+		t.Fatalf("添加回复失败: %v", err)
+	}
+
+	// 第 1 层与第 3 层的创建时间相同，由评论 ID 确定顺序
+	sameTime := time.Now().Add(-time.Hour)
+	storage.Storage.Db.Model(&po.Comment{}).Where("comment_id IN ?", []string{threads[0], threads[2]}).Update("create_time", sameTime)
+	oldest := []string{threads[0], threads[2]}
+	if threads[2] < threads[0] {
+		oldest = []string{threads[2], threads[0]}
+	}
+
+	cases := []struct {
+		sortMode string
+		want     []string
+	}{
+		{dto.CommentSortNewest, []string{threads[4], threads[3], threads[1], oldest[1], oldest[0]}},
+		{dto.CommentSortOldest, []string{oldest[0], oldest[1], threads[1], threads[3], threads[4]}},
+		{dto.CommentSortMostReplied, []string{threads[1], threads[3], threads[4], oldest[1], oldest[0]}},
+	}
+	for _, c := range cases {
+		ids, sizes := collectCommentPages(t, func(cursor string) (*vo.CommentPageVo, error) {
+			return GetCommentThreads(ctx, "page_blog", c.sortMode, cursor, 2)
+		})
+		if !slices.Equal(ids, c.want) || !slices.Equal(sizes, []int{2, 2, 1}) {
+			t.Errorf("按 %s 排序的分页结果错误: %v %v, 期望 %v", c.sortMode, ids, sizes, c.want)
+		}
+	}
+
+	page, err := GetCommentThreads(ctx, "page_blog", "", "", 0)
+	if err != nil || len(page.Comments) != 5 || page.HasMore {
+		t.Fatalf("默认按最新发表排序并返回默认数量: %v, %+v", err, page)
+	}
+	if c := page.Comments[2]; c.CommentId != threads[1] || c.ReplyCount != 3 || len(c.SubComments) != 0 {
+		t.Errorf("楼主评论应当只返回回复数量: %+v", c)
+	}
+	if page.Comments[1].ReplyCount != 1 {
+		t.Errorf("待审核的回复不应计入回复数量: %+v", page.Comments[1])
+	}
+
+	// 楼层中的回复按发表时间正序分页
+	ids, sizes := collectCommentPages(t, func(cursor string) (*vo.CommentPageVo, error) {
+		return GetCommentReplies(ctx, threads[1], cursor, 2)
+	})
+	if !slices.Equal(ids, replies) || !slices.Equal(sizes, []int{2, 1}) {
+		t.Errorf("回复的分页结果错误: %v %v, 期望 %v", ids, sizes, replies)
+	}
+	replyPage, err := GetCommentReplies(ctx, threads[1], "", 1)
+	if err != nil || replyPage.Comments[0].ReplyToCommenter != "p***@example.com" {
+		t.Errorf("回复应当包含打码后的被回复邮箱: %v, %+v", err, replyPage)
+	}
+
+	// 无效的参数
+	newest, _ := GetCommentThreads(ctx, "page_blog", dto.CommentSortNewest, "", 1)
+	for _, args := range [][2]string{
+		{"hottest", ""},
+		{dto.CommentSortNewest, "not-a-cursor"},
+		{dto.CommentSortOldest, newest.NextCursor},
+	} {
+		if _, err := GetCommentThreads(ctx, "page_blog", args[0], args[1], 2); !errors.Is(err, ErrInvalidCommentPage) {
+			t.Errorf("无效的排序方式或游标应当返回 ErrInvalidCommentPage: %v, %v", args, err)
+		}
+	}
+	if _, err := GetCommentReplies(ctx, threads[1], newest.NextCursor, 2); !errors.Is(err, ErrInvalidCommentPage) {
+		t.Errorf("楼主评论的游标不能用于回复分页: %v", err)
+	}
+	for _, commentId := range []string{replies[0], "not_exist"} {
+		if _, err := GetCommentReplies(ctx, commentId, "", 2); !errors.Is(err, ErrCommentNotFound) {
+			t.Errorf("不是楼主评论时应当返回 ErrCommentNotFound: %s, %v", commentId, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package webservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/categoryrepo"
+	"sparrow_blog_server/internal/repositories/tagrepo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/feed"
+	"sparrow_blog_server/pkg/logger"
+	"time"
+)
+
+// feedItemLimit 订阅源中最多包含的文章数量
+const feedItemLimit = 20
+
+// ErrFeedNotFound 订阅源指定的分类或标签不存在
+var ErrFeedNotFound = errors.New("订阅源不存在")
+
+// FeedQuery 订阅源查询条件
+type FeedQuery struct {
+	CategoryId  string // 只包含该分类下的文章，为空时不限制
+	TagId       string // 只包含带有该标签的文章，为空时不限制
+	FullContent bool   // 是否输出渲染后的全文，否则只输出简介
+}
+
+// FeedSource 订阅源的数据来源
+// 在读取文章内容之前即可得到 ETag 与最后修改时间，客户端缓存有效时无需读取 OSS。
+type FeedSource struct {
+	ETag         string    // 实体标签，由查询条件与文章的更新时间计算
+	LastModified time.Time // 文章中最近的更新时间
+
+	query   FeedQuery
+	siteUrl string
+	apiUrl  string
+	title   string
+	blogs   []*dto.BlogDto
+}
+
+// PrepareFeed 查询订阅源需要的文章，只包含已发布的文章，按创建时间倒序排列
+// 参数:
+//   - ctx: 上下文对象
+//   - query: 订阅源查询条件
+//   - siteUrl: 博客前台访问地址，用于生成文章链接
+//   - apiUrl: 服务端对外访问地址，用于生成订阅源自身的链接
+//
+// 返回值:
+//   - *FeedSource: 订阅源数据来源
+//   - error: 分类或标签不存在时返回 ErrFeedNotFound，查询失败时返回错误信息
+func PrepareFeed(ctx context.Context, query FeedQuery, siteUrl string, apiUrl string) (*FeedSource, error) {
+	source := &FeedSource{
+		query:   query,
+		siteUrl: siteUrl,
+		apiUrl:  apiUrl,
+		title:   fmt.Sprintf("%s 的博客", config.User.Username),
+	}
+
+	categories, err := categoryrepo.FindAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[string]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.CategoryId] = category.CategoryName
+	}
+
+	if query.CategoryId != "" {
+		name, ok := categoryNames[query.CategoryId]
+		if !ok {
+			return nil, fmt.Errorf("%w: 分类 %s", ErrFeedNotFound, query.CategoryId)
+		}
+		source.title = fmt.Sprintf("%s - 分类: %s", source.title, name)
+	}
+
+	if query.TagId != "" {
+		tags, err := tagrepo.FindAllTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, tag := range tags {
+			if tag.TagId == query.TagId {
+				source.title = fmt.Sprintf("%s - 标签: %s", source.title, tag.TagName)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: 标签 %s", ErrFeedNotFound, query.TagId)
+		}
+	}
+
+	blogDtos, err := blogrepo.FindAllBlogs(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	// 订阅源按发布时间排序，不考虑置顶
+	sort.SliceStable(blogDtos, func(i, j int) bool {
+		return blogDtos[i].CreateTime.After(blogDtos[j].CreateTime)
+	})
+
+	for _, blogDto := range blogDtos {
+		if len(source.blogs) >= feedItemLimit {
+			break
+		}
+		if !blogDto.BlogState || (query.CategoryId != "" && blogDto.CategoryId != query.CategoryId) {
+			continue
+		}
+
+		tags, err := tagrepo.FindTagsByBlogId(ctx, blogDto.BlogId)
+		if err != nil {
+			return nil, err
+		}
+		if query.TagId != "" && !containsTag(tags, query.TagId) {
+			continue
+		}
+
+		blogDto.Tags = tags
+		blogDto.Category = &dto.CategoryDto{
+			CategoryId:   blogDto.CategoryId,
+			CategoryName: categoryNames[blogDto.CategoryId],
+		}
+		source.blogs = append(source.blogs, blogDto)
+	}
+
+	// ETag 由影响输出内容的所有因素计算，文章被修改、发布或下线时都会变化
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s|%s|%s|%s|%v|%s|%s\n",
+		siteUrl, apiUrl, query.CategoryId, query.TagId, query.FullContent, source.title, config.User.UserEmail)
+	for _, blogDto := range source.blogs {
+		_, _ = fmt.Fprintf(hash, "%s|%d\n", blogDto.BlogId, blogDto.UpdateTime.UnixNano())
+		if blogDto.UpdateTime.After(source.LastModified) {
+			source.LastModified = blogDto.UpdateTime
+		}
+	}
+	source.ETag = fmt.Sprintf(`W/"%s"`, hex.EncodeToString(hash.Sum(nil))[:32])
+
+	return source, nil
+}
+
+// Build 生成订阅源，需要全文时从 OSS 读取 Markdown 并渲染为 HTML
+// 参数:
+//   - ctx: 上下文对象
+//   - feedPath: 订阅源自身的请求路径与查询参数，拼接在服务端对外访问地址之后
+//
+// 返回值:
+//   - *feed.Feed: 订阅源
+func (s *FeedSource) Build(ctx context.Context, feedPath string) *feed.Feed {
+	f := &feed.Feed{
+		Title:       s.title,
+		Link:        s.siteUrl,
+		FeedUrl:     s.apiUrl + feedPath,
+		Description: s.title,
+		AuthorName:  config.User.Username,
+		AuthorEmail: config.User.UserEmail,
+		Updated:     s.LastModified,
+		Items:       make([]feed.Item, 0, len(s.blogs)),
+	}
+
+	for _, blogDto := range s.blogs {
+		categories := make([]string, 0, len(blogDto.Tags)+1)
+		if blogDto.Category.CategoryName != "" {
+			categories = append(categories, blogDto.Category.CategoryName)
+		}
+		for _, tag := range blogDto.Tags {
+			categories = append(categories, tag.TagName)
+		}
+
+		item := feed.Item{
+			Id:         blogDto.BlogId,
+			Title:      blogDto.BlogTitle,
+			Link:       BlogLink(s.siteUrl, blogDto.BlogId),
+			Summary:    blogDto.BlogBrief,
+			Categories: categories,
+			Published:  blogDto.CreateTime,
+			Updated:    blogDto.UpdateTime,
+		}
+		if s.query.FullContent {
+			item.Content = renderBlogContent(ctx, blogDto)
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f
+}
+
+// BlogLink 生成博客文章在前台的访问地址
+func BlogLink(siteUrl string, blogId string) string {
+	return fmt.Sprintf("%s/blog/%s", siteUrl, blogId)
+}
+
+// renderBlogContent 获取博客渲染后的 HTML，失败时返回空字符串，订阅源中只保留简介
+func renderBlogContent(ctx context.Context, blogDto *dto.BlogDto) string {
+	rendered, err := GetRenderedBlog(ctx, blogDto.BlogId, blogDto.BlogTitle, blogDto.UpdateTime)
+	if err != nil {
+		logger.Warn("渲染博客 %s 的内容失败: %v", blogDto.BlogId, err)
+		return ""
+	}
+	return rendered.HTML
+}
+
+func containsTag(tags []dto.TagDto, tagId string) bool {
+	for _, tag := range tags {
+		if tag.TagId == tagId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPrepareFeed(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := PrepareFeed(ctx, FeedQuery{}, "https://blog.example.com", "https://api.example.com")
+	if err != nil {
+		t.Fatalf("获取订阅源失败: %v", err)
+	}
+	if source.ETag == "" {
+		t.Fatal("订阅源应当包含 ETag")
+	}
+
+	again, err := PrepareFeed(ctx, FeedQuery{}, "https://blog.example.com", "https://api.example.com")
+	if err != nil || again.ETag != source.ETag {
+		t.Fatalf("数据未变化时 ETag 应当一致: %v, %v", again, err)
+	}
+
+	full, err := PrepareFeed(ctx, FeedQuery{FullContent: true}, "https://blog.example.com", "https://api.example.com")
+	if err != nil || full.ETag == source.ETag {
+		t.Fatalf("查询条件不同时 ETag 应当不同: %v, %v", full, err)
+	}
+
+	other, err := PrepareFeed(ctx, FeedQuery{}, "https://blog.example.com", "https://other.example.com")
+	if err != nil || other.ETag == source.ETag {
+		t.Fatalf("服务端地址不同时 ETag 应当不同: %v, %v", other, err)
+	}
+
+	f := source.Build(ctx, "/web/feed.xml?tag=go")
+	if f.Link != "https://blog.example.com" || f.FeedUrl != "https://api.example.com/web/feed.xml?tag=go" {
+		t.Errorf("订阅源链接应当使用配置的地址: %s, %s", f.Link, f.FeedUrl)
+	}
+	if len(f.Items) > feedItemLimit {
+		t.Errorf("订阅源最多包含 %d 篇文章，实际 %d 篇", feedItemLimit, len(f.Items))
+	}
+	for i := 1; i < len(f.Items); i++ {
+		if f.Items[i-1].Published.Before(f.Items[i].Published) {
+			t.Errorf("订阅源应当按发布时间倒序排列")
+		}
+	}
+}
+
+func TestPrepareFeed_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := PrepareFeed(ctx, FeedQuery{CategoryId: "not-exist"}, "", ""); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("分类不存在时应当返回 ErrFeedNotFound: %v", err)
+	}
+	if _, err := PrepareFeed(ctx, FeedQuery{TagId: "not-exist"}, "", ""); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("标签不存在时应当返回 ErrFeedNotFound: %v", err)
+	}
+}
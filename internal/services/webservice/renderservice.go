@@ -0,0 +1,178 @@
+package webservice
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/commentrepo"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/markdown"
+	"sparrow_blog_server/storage"
+	"sparrow_blog_server/storage/ossstore"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renderCacheSize 渲染结果缓存的最大文章数，超过时淘汰最久未访问的文章
+const renderCacheSize = 128
+
+// renderEntry 一篇博客的渲染结果
+type renderEntry struct {
+	blogId     string
+	updateTime time.Time
+	result     *markdown.Result
+}
+
+// renderCache 博客渲染结果的内存缓存
+// 以博客 ID 为键并记录渲染时的更新时间，博客更新后更新时间变化，旧的渲染结果自然失效。
+// 渲染结果体积较大，不放入会持久化到 AOF 的 storage.Storage.Cache。
+var renderCache = struct {
+	mu    sync.Mutex
+	order *list.List               // 按访问时间排序，最近访问的在前
+	items map[string]*list.Element // 博客 ID 到渲染结果的映射
+}{
+	order: list.New(),
+	items: make(map[string]*list.Element),
+}
+
+// GetRenderedBlog 获取博客渲染后的 HTML 与目录，优先使用缓存
+// 参数:
+//   - ctx: 上下文对象
+//   - blogId: 博客 ID
+//   - blogTitle: 博客标题，用于定位 OSS 中的 Markdown 文件
+//   - updateTime: 博客的更新时间，与缓存中的不一致时重新渲染
+//
+// 返回值:
+//   - *markdown.Result: 渲染结果
+//   - error: 读取或渲染失败时返回错误信息
+func GetRenderedBlog(ctx context.Context, blogId string, blogTitle string, updateTime time.Time) (*markdown.Result, error) {
+	if result, ok := getRenderCache(blogId, updateTime); ok {
+		return result, nil
+	}
+
+	content, err := storage.Storage.GetContentFromOss(ctx, ossstore.GenOssSavePath(blogTitle, ossstore.MarkDown))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := markdown.Render(content)
+	if err != nil {
+		return nil, err
+	}
+
+	putRenderCache(blogId, updateTime, result)
+	return result, nil
+}
+
+func getRenderCache(blogId string, updateTime time.Time) (*markdown.Result, bool) {
+	renderCache.mu.Lock()
+	defer renderCache.mu.Unlock()
+
+	elem, ok := renderCache.items[blogId]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*renderEntry)
+	if !entry.updateTime.Equal(updateTime) {
+		return nil, false
+	}
+
+	renderCache.order.MoveToFront(elem)
+	return entry.result, true
+}
+
+func putRenderCache(blogId string, updateTime time.Time, result *markdown.Result) {
+	renderCache.mu.Lock()
+	defer renderCache.mu.Unlock()
+
+	entry := &renderEntry{blogId: blogId, updateTime: updateTime, result: result}
+	if elem, ok := renderCache.items[blogId]; ok {
+		elem.Value = entry
+		renderCache.order.MoveToFront(elem)
+		return
+	}
+
+	renderCache.items[blogId] = renderCache.order.PushFront(entry)
+	for renderCache.order.Len() > renderCacheSize {
+		oldest := renderCache.order.Back()
+		renderCache.order.Remove(oldest)
+		delete(renderCache.items, oldest.Value.(*renderEntry).blogId)
+	}
+}
+
+// RenderCommentContent 将评论内容渲染为 HTML 并写入 commentDto.ContentHtml
+// 同一楼中在该评论之前发表、已通过审核且填写了昵称的评论者可以被 @ 提及；查询楼中评论失败时不识别提及。
+// 参数:
+//   - ctx: 上下文对象
+//   - commentDto: 评论数据传输对象，需要已设置 OriginPostId；新评论的 CreateTime 为零值，此时楼中所有评论都在它之前
+//
+// 返回值:
+//   - error: 渲染失败时返回错误信息
+func RenderCommentContent(ctx context.Context, commentDto *dto.CommentDto) error {
+	mentions, err := threadMentions(ctx, commentDto)
+	if err != nil {
+		logger.Warn("查询评论 @ 提及的评论者失败: %v", err)
+	}
+
+	html, err := markdown.RenderComment([]byte(commentDto.Content), mentions)
+	if err != nil {
+		return err
+	}
+	commentDto.ContentHtml = html
+	return nil
+}
+
+// CommentContentHtml 返回评论渲染后的 HTML
+// 保存渲染结果之前发表的评论没有 HTML，读取时渲染，此时不识别 @ 提及
+func CommentContentHtml(commentDto *dto.CommentDto) string {
+	if commentDto.ContentHtml != "" || commentDto.Content == "" {
+		return commentDto.ContentHtml
+	}
+
+	html, err := markdown.RenderComment([]byte(commentDto.Content), nil)
+	if err != nil {
+		logger.Warn("渲染评论 %s 失败: %v", commentDto.CommentId, err)
+		return ""
+	}
+	return html
+}
+
+// threadMentions 查询同一楼中在评论之前发表的评论者，同一昵称只保留最近的一条评论
+func threadMentions(ctx context.Context, commentDto *dto.CommentDto) ([]markdown.Mention, error) {
+	if commentDto.OriginPostId == "" {
+		return nil, nil
+	}
+
+	originPost, err := commentrepo.FindCommentById(ctx, commentDto.OriginPostId)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := commentrepo.FindCommentsByOriginPostId(ctx, commentDto.OriginPostId)
+	if err != nil {
+		return nil, err
+	}
+	earlier := append([]dto.CommentDto{*originPost}, replies...)
+	sort.SliceStable(earlier, func(i, j int) bool {
+		return earlier[i].CreateTime.After(earlier[j].CreateTime)
+	})
+
+	var mentions []markdown.Mention
+	seen := make(map[string]bool)
+	for _, c := range earlier {
+		if c.CommentId == commentDto.CommentId || c.Status != dto.CommentStatusApproved || c.Nickname == "" {
+			continue
+		}
+		if !commentDto.CreateTime.IsZero() && !c.CreateTime.Before(commentDto.CreateTime) {
+			continue
+		}
+		key := strings.ToLower(c.Nickname)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mentions = append(mentions, markdown.Mention{Name: c.Nickname, CommentId: c.CommentId})
+	}
+	return mentions, nil
+}
@@ -0,0 +1,96 @@
+package webservice
+
+import (
+	"context"
+	"fmt"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/markdown"
+	"sparrow_blog_server/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCache(t *testing.T) {
+	updateTime := time.Now()
+	result := &markdown.Result{HTML: "<p>正文</p>"}
+
+	putRenderCache("render-test", updateTime, result)
+	if cached, ok := getRenderCache("render-test", updateTime); !ok || cached != result {
+		t.Fatal("应当命中渲染缓存")
+	}
+	if _, ok := getRenderCache("render-test", updateTime.Add(time.Second)); ok {
+		t.Fatal("更新时间变化后不应命中渲染缓存")
+	}
+
+	for i := 0; i < renderCacheSize; i++ {
+		putRenderCache(fmt.Sprintf("render-test-%d", i), updateTime, result)
+	}
+	if _, ok := getRenderCache("render-test", updateTime); ok {
+		t.Fatal("超过缓存容量后最久未访问的文章应当被淘汰")
+	}
+	if renderCache.order.Len() != renderCacheSize || len(renderCache.items) != renderCacheSize {
+		t.Fatalf("缓存数量错误: %d, %d", renderCache.order.Len(), len(renderCache.items))
+	}
+}
+
+func TestRenderCommentContent(t *testing.T) {
+	ctx := context.Background()
+	oldModeration := config.Comment.Moderation
+	config.Comment.Moderation = config.ModerationAutoApprove
+	t.Cleanup(func() {
+		config.Comment.Moderation = oldModeration
+		storage.Storage.Db.Where("blog_id = ?", "render_comment_blog").Delete(&po.Comment{})
+	})
+
+	thread, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail: "author@example.com",
+		Nickname:       "楼主",
+		BlogId:         "render_comment_blog",
+		Content:        "@路人 还没有人回复 <script>alert(1)</script>",
+	})
+	if err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	if thread.Content != "@路人 还没有人回复 <script>alert(1)</script>" {
+		t.Errorf("应当原样返回评论内容: %s", thread.Content)
+	}
+	if strings.Contains(thread.ContentHtml, "<script") || strings.Contains(thread.ContentHtml, "mention") {
+		t.Errorf("楼主评论渲染错误: %s", thread.ContentHtml)
+	}
+
+	reply, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail:   "reader@example.com",
+		Nickname:         "路人",
+		BlogId:           "render_comment_blog",
+		ReplyToCommentId: thread.CommentId,
+		Content:          "@楼主 见 [文档](https://example.com)，@路人 是我自己还没有发表过",
+	})
+	if err != nil {
+		t.Fatalf("添加回复失败: %v", err)
+	}
+	for _, want := range []string{
+		`<span class="mention" data-comment-id="` + thread.CommentId + `">@楼主</span>`,
+		`<a href="https://example.com" rel="nofollow ugc">文档</a>`,
+		"@路人 是我自己",
+	} {
+		if !strings.Contains(reply.ContentHtml, want) {
+			t.Errorf("回复的渲染结果缺少 %s: %s", want, reply.ContentHtml)
+		}
+	}
+
+	// 读取时返回保存的渲染结果，没有保存渲染结果的旧评论在读取时渲染
+	storage.Storage.Db.Model(&po.Comment{}).Where("comment_id = ?", thread.CommentId).Update("comment_html", "")
+	comments, err := GetCommentsByBlogId(ctx, "render_comment_blog")
+	if err != nil || len(comments) != 1 || len(comments[0].SubComments) != 1 {
+		t.Fatalf("获取评论失败: %v, %+v", err, comments)
+	}
+	if html := comments[0].ContentHtml; !strings.Contains(html, "&lt;script&gt;") {
+		t.Errorf("没有保存渲染结果的评论应当在读取时渲染: %s", html)
+	}
+	if html := comments[0].SubComments[0].ContentHtml; html != reply.ContentHtml {
+		t.Errorf("应当返回保存的渲染结果: %s", html)
+	}
+}
@@ -0,0 +1,263 @@
+package webservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/tagrepo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/sitemap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SitemapPath 站点地图（或站点地图索引）的访问路径
+const SitemapPath = "/web/sitemap.xml"
+
+// sitemapPagePath 拆分后的站点地图文件的访问路径，页码从 1 开始
+const sitemapPagePath = "/web/sitemap/%d.xml"
+
+// defaultRobots 未配置 server.robots 时的抓取规则
+const defaultRobots = "User-agent: *\nAllow: /"
+
+// ErrSitemapNotFound 请求的站点地图页不存在
+var ErrSitemapNotFound = errors.New("站点地图不存在")
+
+// sitemapSnapshot 生成的站点地图数据，地址为相对于博客前台的路径，输出时再拼接站点地址
+type sitemapSnapshot struct {
+	urls         []sitemap.Url // 首页、已发布的博客、分类与标签
+	lastModified time.Time     // 最近的更新时间
+	version      string        // 地址与更新时间的摘要，用于计算 ETag
+}
+
+var (
+	sitemapMu    sync.Mutex
+	sitemapCache *sitemapSnapshot
+)
+
+// SitemapDoc 站点地图的一页，在输出内容之前即可得到 ETag 与最后修改时间
+type SitemapDoc struct {
+	ETag         string    // 实体标签
+	LastModified time.Time // 最后修改时间
+
+	snapshot *sitemapSnapshot
+	siteUrl  string
+	apiUrl   string
+	page     int
+}
+
+// RefreshSitemap 根据当前已发布的博客重新生成站点地图
+// 已发布博客的集合发生变化后调用，生成失败时清空缓存，下次请求时重新生成
+// 参数:
+//   - ctx: 上下文对象
+//
+// 返回值:
+//   - error: 查询失败时返回错误信息
+func RefreshSitemap(ctx context.Context) error {
+	sitemapMu.Lock()
+	defer sitemapMu.Unlock()
+
+	snapshot, err := buildSitemap(ctx)
+	if err != nil {
+		sitemapCache = nil
+		return err
+	}
+	sitemapCache = snapshot
+	return nil
+}
+
+// GetSitemap 获取站点地图的一页
+// 地址数量不超过 sitemap.MaxUrls 时第 0 页为完整的站点地图，否则第 0 页为站点地图索引，各页从 1 开始编号
+// 站点地图由服务端提供，其中的地址却位于博客前台，搜索引擎只有在前台的 robots.txt 引用了该站点地图时
+// 才接受这些地址（跨站点提交），见 GetRobots。
+// 参数:
+//   - ctx: 上下文对象
+//   - siteUrl: 配置的博客前台访问地址
+//   - apiUrl: 配置的服务端对外访问地址，用于生成站点地图索引中各页的地址
+//   - page: 页码
+//
+// 返回值:
+//   - *SitemapDoc: 站点地图
+//   - error: 页码不存在时返回 ErrSitemapNotFound，生成失败时返回错误信息
+func GetSitemap(ctx context.Context, siteUrl string, apiUrl string, page int) (*SitemapDoc, error) {
+	snapshot, err := loadSitemap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pageCount := sitemap.PageCount(len(snapshot.urls))
+	if page < 0 || page > pageCount || (page > 0 && pageCount == 1) {
+		return nil, fmt.Errorf("%w: 第 %d 页", ErrSitemapNotFound, page)
+	}
+
+	// 输出的地址由站点地址拼接而成，地址配置变化后 ETag 也随之变化
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d", snapshot.version, siteUrl, apiUrl, page)))
+	return &SitemapDoc{
+		ETag:         fmt.Sprintf(`W/"%s"`, hex.EncodeToString(hash[:])[:32]),
+		LastModified: snapshot.lastModified,
+		snapshot:     snapshot,
+		siteUrl:      siteUrl,
+		apiUrl:       apiUrl,
+		page:         page,
+	}, nil
+}
+
+// Render 输出站点地图的 XML 内容
+func (d *SitemapDoc) Render() ([]byte, error) {
+	urls := d.snapshot.urls
+	pageCount := sitemap.PageCount(len(urls))
+
+	if d.page == 0 && pageCount > 1 {
+		pages := make([]sitemap.Url, 0, pageCount)
+		for i := 1; i <= pageCount; i++ {
+			pages = append(pages, sitemap.Url{
+				Loc:     d.apiUrl + fmt.Sprintf(sitemapPagePath, i),
+				LastMod: latestLastMod(pageUrls(urls, i)),
+			})
+		}
+		return sitemap.Index(pages)
+	}
+
+	page := d.page
+	if page == 0 {
+		page = 1
+	}
+	relative := pageUrls(urls, page)
+	absolute := make([]sitemap.Url, 0, len(relative))
+	for _, u := range relative {
+		absolute = append(absolute, sitemap.Url{Loc: d.siteUrl + u.Loc, LastMod: u.LastMod})
+	}
+	return sitemap.UrlSet(absolute)
+}
+
+// GetRobots 生成 robots.txt，在配置的抓取规则后追加站点地图地址
+// robots.txt 必须在博客前台的站点根路径提供（由前台或反向代理将 /robots.txt 转发到 /web/robots.txt）：
+// 站点地图位于服务端，其中列出的是前台的地址，只有前台的 robots.txt 引用该站点地图时搜索引擎才会接受。
+// 参数:
+//   - apiUrl: 配置的服务端对外访问地址，为空时不输出站点地图地址
+//
+// 返回值:
+//   - string: robots.txt 内容
+func GetRobots(apiUrl string) string {
+	rules := strings.TrimSpace(config.Server.Robots)
+	if rules == "" {
+		rules = defaultRobots
+	}
+	if apiUrl == "" {
+		return rules + "\n"
+	}
+	return fmt.Sprintf("%s\n\nSitemap: %s%s\n", rules, apiUrl, SitemapPath)
+}
+
+// loadSitemap 获取缓存的站点地图，不存在时重新生成
+func loadSitemap(ctx context.Context) (*sitemapSnapshot, error) {
+	sitemapMu.Lock()
+	defer sitemapMu.Unlock()
+
+	if sitemapCache != nil {
+		return sitemapCache, nil
+	}
+
+	snapshot, err := buildSitemap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sitemapCache = snapshot
+	return snapshot, nil
+}
+
+// buildSitemap 查询已发布的博客及其分类、标签，生成站点地图数据
+// 分类与标签只包含有已发布博客的，最后修改时间取其中博客的最近更新时间
+func buildSitemap(ctx context.Context) (*sitemapSnapshot, error) {
+	blogDtos, err := blogrepo.FindAllBlogs(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	blogTagIds, err := tagrepo.FindAllBlogTagIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(blogDtos, func(i, j int) bool {
+		return blogDtos[i].CreateTime.After(blogDtos[j].CreateTime)
+	})
+
+	snapshot := &sitemapSnapshot{}
+	blogUrls := make([]sitemap.Url, 0, len(blogDtos))
+	categoryLastMod := make(map[string]time.Time)
+	tagLastMod := make(map[string]time.Time)
+	for _, blogDto := range blogDtos {
+		if !blogDto.BlogState {
+			continue
+		}
+
+		updated := blogDto.UpdateTime
+		blogUrls = append(blogUrls, sitemap.Url{Loc: "/blog/" + blogDto.BlogId, LastMod: updated})
+		if updated.After(snapshot.lastModified) {
+			snapshot.lastModified = updated
+		}
+		if updated.After(categoryLastMod[blogDto.CategoryId]) {
+			categoryLastMod[blogDto.CategoryId] = updated
+		}
+		for _, tagId := range blogTagIds[blogDto.BlogId] {
+			if updated.After(tagLastMod[tagId]) {
+				tagLastMod[tagId] = updated
+			}
+		}
+	}
+
+	snapshot.urls = make([]sitemap.Url, 0, 1+len(blogUrls)+len(categoryLastMod)+len(tagLastMod))
+	snapshot.urls = append(snapshot.urls, sitemap.Url{Loc: "/", LastMod: snapshot.lastModified})
+	snapshot.urls = append(snapshot.urls, blogUrls...)
+	snapshot.urls = append(snapshot.urls, groupUrls("/category/", categoryLastMod)...)
+	snapshot.urls = append(snapshot.urls, groupUrls("/tag/", tagLastMod)...)
+
+	hash := sha256.New()
+	for _, u := range snapshot.urls {
+		_, _ = fmt.Fprintf(hash, "%s|%d\n", u.Loc, u.LastMod.UnixNano())
+	}
+	snapshot.version = hex.EncodeToString(hash.Sum(nil))
+
+	logger.Info("生成站点地图，共 %d 个地址", len(snapshot.urls))
+	return snapshot, nil
+}
+
+// groupUrls 生成分类或标签页面的地址，按 ID 排序保证输出稳定
+func groupUrls(prefix string, lastMods map[string]time.Time) []sitemap.Url {
+	ids := make([]string, 0, len(lastMods))
+	for id := range lastMods {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	urls := make([]sitemap.Url, 0, len(ids))
+	for _, id := range ids {
+		urls = append(urls, sitemap.Url{Loc: prefix + id, LastMod: lastMods[id]})
+	}
+	return urls
+}
+
+// pageUrls 返回第 page 页的地址，页码从 1 开始
+func pageUrls(urls []sitemap.Url, page int) []sitemap.Url {
+	start := (page - 1) * sitemap.MaxUrls
+	end := min(start+sitemap.MaxUrls, len(urls))
+	return urls[start:end]
+}
+
+func latestLastMod(urls []sitemap.Url) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
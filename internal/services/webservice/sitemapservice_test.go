@@ -0,0 +1,73 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"sparrow_blog_server/pkg/sitemap"
+	"strings"
+	"testing"
+)
+
+func TestGetSitemap(t *testing.T) {
+	ctx := context.Background()
+
+	if err := RefreshSitemap(ctx); err != nil {
+		t.Fatalf("生成站点地图失败: %v", err)
+	}
+
+	doc, err := GetSitemap(ctx, "https://blog.example.com", "https://api.example.com", 0)
+	if err != nil {
+		t.Fatalf("获取站点地图失败: %v", err)
+	}
+	data, err := doc.Render()
+	if err != nil {
+		t.Fatalf("输出站点地图失败: %v", err)
+	}
+	if !strings.Contains(string(data), "<loc>https://blog.example.com/</loc>") {
+		t.Errorf("站点地图应当包含首页: %s", data)
+	}
+
+	again, err := GetSitemap(ctx, "https://blog.example.com", "https://api.example.com", 0)
+	if err != nil || again.ETag != doc.ETag {
+		t.Fatalf("数据未变化时 ETag 应当一致: %v, %v", again, err)
+	}
+
+	other, err := GetSitemap(ctx, "https://other.example.com", "https://api.example.com", 0)
+	if err != nil || other.ETag == doc.ETag {
+		t.Fatalf("站点地址不同时 ETag 应当不同: %v, %v", other, err)
+	}
+
+	if _, err := GetSitemap(ctx, "https://blog.example.com", "https://api.example.com", 1); !errors.Is(err, ErrSitemapNotFound) {
+		t.Errorf("地址未超过上限时不应存在分页: %v", err)
+	}
+}
+
+func TestSitemapDoc_Index(t *testing.T) {
+	snapshot := &sitemapSnapshot{urls: make([]sitemap.Url, sitemap.MaxUrls+1)}
+	for i := range snapshot.urls {
+		snapshot.urls[i].Loc = "/blog/x"
+	}
+
+	index, err := (&SitemapDoc{snapshot: snapshot, siteUrl: "https://blog.example.com", apiUrl: "https://api.example.com"}).Render()
+	if err != nil {
+		t.Fatalf("输出站点地图索引失败: %v", err)
+	}
+	if !strings.Contains(string(index), "<sitemapindex") || !strings.Contains(string(index), "https://api.example.com/web/sitemap/2.xml") {
+		t.Fatalf("超过上限时应当输出站点地图索引: %.300s", index)
+	}
+
+	last, err := (&SitemapDoc{snapshot: snapshot, siteUrl: "https://blog.example.com", page: 2}).Render()
+	if err != nil || strings.Count(string(last), "<url>") != 1 {
+		t.Fatalf("第 2 页应当只有 1 个地址: %v", err)
+	}
+}
+
+func TestGetRobots(t *testing.T) {
+	robots := GetRobots("https://api.example.com")
+	if !strings.Contains(robots, "Sitemap: https://api.example.com/web/sitemap.xml") {
+		t.Errorf("robots.txt 应当包含站点地图地址: %s", robots)
+	}
+	if robots := GetRobots(""); strings.Contains(robots, "Sitemap:") {
+		t.Errorf("未配置服务端地址时不应输出站点地图地址: %s", robots)
+	}
+}
@@ -0,0 +1,139 @@
+package webservice
+
+import (
+	"context"
+	"sparrow_blog_server/internal/repositories/spamrepo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/spam"
+	"sparrow_blog_server/storage"
+	"strings"
+	"time"
+)
+
+// 反垃圾配置的默认值，配置为 0 时使用
+const (
+	defaultSpamMaxLinks       = 3
+	defaultSpamRateBurst      = 5
+	defaultSpamRateInterval   = 60
+	defaultSpamBayesThreshold = 0.95
+)
+
+// bayesMinDocs 垃圾评论与正常评论都至少训练过这么多条后，贝叶斯分类器才开始判定
+const bayesMinDocs = 10
+
+// spamTokenStore 将贝叶斯分类器的训练数据保存在数据库中
+type spamTokenStore struct{}
+
+func (spamTokenStore) TokenCounts(ctx context.Context, tokens []string) (map[string]spam.TokenCount, spam.TokenCount, error) {
+	spamTokens, err := spamrepo.FindSpamTokens(ctx, append(tokens[:len(tokens):len(tokens)], spamrepo.TotalToken))
+	if err != nil {
+		return nil, spam.TokenCount{}, err
+	}
+
+	counts := make(map[string]spam.TokenCount, len(spamTokens))
+	for token, spamToken := range spamTokens {
+		counts[token] = spam.TokenCount{Spam: spamToken.SpamCount, Ham: spamToken.HamCount}
+	}
+	totals := counts[spamrepo.TotalToken]
+	delete(counts, spamrepo.TotalToken)
+
+	return counts, totals, nil
+}
+
+func (spamTokenStore) Learn(ctx context.Context, tokens []string, isSpam bool) error {
+	tx := storage.Storage.Db.WithContext(ctx).Begin()
+	if err := spamrepo.IncrSpamTokens(tx, append(tokens[:len(tokens):len(tokens)], spamrepo.TotalToken), isSpam); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// CheckCommentSpam 检查评论是否为垃圾评论或提交过于频繁
+// 依次执行蜜罐、IP 与邮箱限流、链接数量、屏蔽关键词与贝叶斯分类器检查，被拒绝时记录原因
+// 参数:
+//   - ctx: 上下文对象
+//   - sub: 待检查的评论
+//
+// 返回值:
+//   - *spam.Rejection: 评论被拒绝时返回拒绝原因，否则返回 nil
+func CheckCommentSpam(ctx context.Context, sub *spam.Submission) *spam.Rejection {
+	rejection := newSpamChain().Check(ctx, sub)
+	if rejection != nil {
+		logger.Warn("拒绝评论 %s，IP: %s，邮箱: %s", rejection, sub.Ip, sub.Email)
+	}
+	return rejection
+}
+
+// TrainSpamFilter 使用管理员审核过的评论训练贝叶斯分类器
+// 训练失败只记录日志，不影响审核结果
+// 参数:
+//   - ctx: 上下文对象
+//   - contents: 评论内容列表
+//   - isSpam: 是否为垃圾评论
+func TrainSpamFilter(ctx context.Context, contents []string, isSpam bool) {
+	bayes := newBayes()
+	for _, content := range contents {
+		if err := bayes.Learn(ctx, content, isSpam); err != nil {
+			logger.Warn("训练评论反垃圾分类器失败: %v", err)
+			return
+		}
+	}
+}
+
+// newSpamChain 根据当前配置创建评论检查链
+// 限流放在内容检查之前，被内容规则拒绝的提交同样消耗提交次数
+func newSpamChain() spam.Chain {
+	conf := config.Comment.Spam
+
+	burst := conf.RateBurst
+	if burst <= 0 {
+		burst = defaultSpamRateBurst
+	}
+	interval := conf.RateInterval
+	if interval <= 0 {
+		interval = defaultSpamRateInterval
+	}
+
+	chain := spam.Chain{
+		spam.HoneypotRule{},
+		&spam.RateLimit{
+			Label:     "ip",
+			Store:     storage.Storage.Cache,
+			KeyPrefix: storage.CommentRateLimitKeyPrefix + "ip_",
+			Key:       func(sub *spam.Submission) string { return sub.Ip },
+			Burst:     burst,
+			Interval:  time.Duration(interval) * time.Second,
+		},
+		&spam.RateLimit{
+			Label:     "email",
+			Store:     storage.Storage.Cache,
+			KeyPrefix: storage.CommentRateLimitKeyPrefix + "email_",
+			Key:       func(sub *spam.Submission) string { return strings.ToLower(strings.TrimSpace(sub.Email)) },
+			Burst:     burst,
+			Interval:  time.Duration(interval) * time.Second,
+		},
+	}
+
+	maxLinks := conf.MaxLinks
+	if maxLinks == 0 {
+		maxLinks = defaultSpamMaxLinks
+	}
+	if maxLinks > 0 {
+		chain = append(chain, spam.LinkRule{MaxLinks: maxLinks})
+	}
+	if len(conf.BlockedKeywords) > 0 {
+		chain = append(chain, spam.KeywordRule{Keywords: conf.BlockedKeywords})
+	}
+
+	return append(chain, newBayes())
+}
+
+func newBayes() *spam.Bayes {
+	threshold := config.Comment.Spam.BayesThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultSpamBayesThreshold
+	}
+	return &spam.Bayes{Store: spamTokenStore{}, Threshold: threshold, MinDocs: bayesMinDocs}
+}
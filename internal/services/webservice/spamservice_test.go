@@ -0,0 +1,59 @@
+package webservice
+
+import (
+	"context"
+	"fmt"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/spam"
+	"testing"
+	"time"
+)
+
+func TestCheckCommentSpam(t *testing.T) {
+	ctx := context.Background()
+
+	oldSpam := config.Comment.Spam
+	config.Comment.Spam = config.SpamConfig{
+		MaxLinks:        1,
+		BlockedKeywords: []string{"casino"},
+		RateBurst:       2,
+		RateInterval:    3600,
+	}
+	defer func() { config.Comment.Spam = oldSpam }()
+
+	unique := time.Now().UnixNano()
+	newSub := func(content string) *spam.Submission {
+		return &spam.Submission{
+			Ip:      fmt.Sprintf("spam_test_%d", unique),
+			Email:   fmt.Sprintf("spam_test_%d@example.com", unique),
+			Content: content,
+		}
+	}
+
+	if rejection := CheckCommentSpam(ctx, newSub("写得很好，谢谢分享")); rejection != nil {
+		t.Fatalf("正常评论不应被拒绝: %s", rejection)
+	}
+
+	honeypot := newSub("你好")
+	honeypot.Honeypot = "http://spam.example"
+	if rejection := CheckCommentSpam(ctx, honeypot); rejection == nil || rejection.Checker != "honeypot" {
+		t.Fatalf("填写了蜜罐字段的评论应被拒绝，实际为 %v", rejection)
+	}
+
+	if rejection := CheckCommentSpam(ctx, newSub("http://a.com http://b.com")); rejection == nil || rejection.Checker != "links" {
+		t.Fatalf("链接过多的评论应被拒绝，实际为 %v", rejection)
+	}
+
+	// 前两次提交已经用完令牌，第三次应被限流
+	rejection := CheckCommentSpam(ctx, newSub("best casino"))
+	if rejection == nil || !rejection.RateLimited {
+		t.Fatalf("超过提交次数后应被限流，实际为 %v", rejection)
+	}
+
+	// 换一个 IP 仍然受邮箱限流
+	other := newSub("写得很好")
+	other.Ip = fmt.Sprintf("spam_test_other_%d", unique)
+	if rejection := CheckCommentSpam(ctx, other); rejection == nil || rejection.Checker != "rate_limit_email" {
+		t.Fatalf("同一邮箱应被限流，实际为 %v", rejection)
+	}
+}
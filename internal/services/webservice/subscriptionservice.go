@@ -0,0 +1,105 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/repositories/subscriptionrepo"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/webjwt"
+	"strings"
+)
+
+// UnsubscribePath 退订评论回复通知的接口路径，退订链接由服务端访问地址与此路径拼接
+const UnsubscribePath = "/web/comment/unsubscribe"
+
+// commentThreadId 返回评论所在楼层的楼主评论 ID，楼主评论所在的楼层就是它自己
+func commentThreadId(commentDto *dto.CommentDto) string {
+	if commentDto.OriginPostId != "" {
+		return commentDto.OriginPostId
+	}
+	return commentDto.CommentId
+}
+
+// subscribeThread 评论者提交评论时选择订阅后，订阅评论所在楼层的回复通知
+// 订阅失败只记录日志，不影响评论的提交。
+func subscribeThread(ctx context.Context, commentDto *dto.CommentDto) {
+	if err := subscriptionrepo.AddSubscription(ctx, &dto.CommentSubscriptionDto{
+		SubscriberEmail: commentDto.CommenterEmail,
+		ThreadId:        commentThreadId(commentDto),
+		BlogId:          commentDto.BlogId,
+	}); err != nil {
+		logger.Warn("订阅评论回复失败，CommentId: %s, 错误: %v", commentDto.CommentId, err)
+	}
+}
+
+// GetReplyUnsubscribeUrl 查询被回复的评论者是否订阅了回复所在楼层的通知，订阅时生成退订链接
+// 退订链接使用配置的 server.api_url 拼接，不使用请求中的 Host 等可以伪造的请求头；未配置时不生成退订链接。
+// - ctx: 上下文对象
+// - email: 被回复的评论者邮箱
+// - threadId: 回复所在楼层的楼主评论 ID
+//
+// 返回值:
+// - string: 退订链接，未配置 server.api_url 时为空
+// - bool: 是否订阅，未订阅或查询失败时不应发送回复通知
+func GetReplyUnsubscribeUrl(ctx context.Context, email, threadId string) (string, bool) {
+	if email == "" || threadId == "" {
+		return "", false
+	}
+
+	subscribed, err := subscriptionrepo.IsSubscribed(ctx, email, threadId)
+	if err != nil || !subscribed {
+		return "", false
+	}
+
+	baseUrl := strings.TrimRight(strings.TrimSpace(config.Server.ApiUrl), "/")
+	if baseUrl == "" {
+		return "", true
+	}
+
+	token, err := webjwt.GenerateUnsubscribeToken(email, threadId)
+	if err != nil {
+		logger.Warn("生成退订令牌失败: %v", err)
+		return "", false
+	}
+	return baseUrl + UnsubscribePath + "?token=" + url.QueryEscape(token), true
+}
+
+// CheckUnsubscribeToken 检查退订令牌是否有效，不修改订阅，用于展示退订确认页
+// - token: 退订令牌
+//
+// 返回值:
+// - error: 令牌无效或过期时返回错误信息
+func CheckUnsubscribeToken(token string) error {
+	if _, err := webjwt.ParseUnsubscribeToken(token); err != nil {
+		return fmt.Errorf("退订链接无效或已过期: %v", err)
+	}
+	return nil
+}
+
+// UnsubscribeByToken 根据通知邮件中的退订令牌退订楼层的回复通知，重复退订不返回错误
+// - ctx: 上下文对象
+// - token: 退订令牌
+//
+// 返回值:
+// - error: 令牌无效、过期或退订失败时返回错误信息
+func UnsubscribeByToken(ctx context.Context, token string) error {
+	claims, err := webjwt.ParseUnsubscribeToken(token)
+	if err != nil {
+		msg := fmt.Sprintf("退订链接无效或已过期: %v", err)
+		logger.Warn(msg)
+		return errors.New(msg)
+	}
+
+	count, err := subscriptionrepo.DeleteSubscription(ctx, claims.Email, claims.ThreadId)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		logger.Info("%s 已退订楼层 %s 的回复通知", claims.Email, claims.ThreadId)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package webservice
+
+import (
+	"context"
+	"net/url"
+	"sparrow_blog_server/internal/model/dto"
+	"sparrow_blog_server/internal/model/po"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/storage"
+	"strings"
+	"testing"
+)
+
+func TestCommentSubscription(t *testing.T) {
+	ctx := context.Background()
+	oldModeration := config.Comment.Moderation
+	oldApiUrl := config.Server.ApiUrl
+	config.Comment.Moderation = config.ModerationAutoApprove
+	config.Server.ApiUrl = "https://api.example.com/"
+	t.Cleanup(func() {
+		config.Comment.Moderation = oldModeration
+		config.Server.ApiUrl = oldApiUrl
+		storage.Storage.Db.Where("blog_id = ?", "subscription_blog").Delete(&po.Comment{})
+		storage.Storage.Db.Where("blog_id = ?", "subscription_blog").Delete(&po.CommentSubscription{})
+	})
+
+	// 楼主选择订阅，回复者没有订阅
+	thread, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail: "author@example.com",
+		BlogId:         "subscription_blog",
+		Content:        "楼主评论",
+		Subscribe:      true,
+	})
+	if err != nil {
+		t.Fatalf("添加评论失败: %v", err)
+	}
+	reply, err := AddComment(ctx, &dto.CommentDto{
+		CommenterEmail:   "replier@example.com",
+		BlogId:           "subscription_blog",
+		ReplyToCommentId: thread.CommentId,
+		Content:          "回复楼主",
+	})
+	if err != nil {
+		t.Fatalf("添加回复失败: %v", err)
+	}
+
+	if _, subscribed := GetReplyUnsubscribeUrl(ctx, "replier@example.com", reply.OriginPostId); subscribed {
+		t.Error("没有选择订阅的评论者不应收到回复通知")
+	}
+	unsubscribeUrl, subscribed := GetReplyUnsubscribeUrl(ctx, "author@example.com", reply.OriginPostId)
+	if !subscribed || !strings.HasPrefix(unsubscribeUrl, "https://api.example.com"+UnsubscribePath+"?token=") {
+		t.Fatalf("订阅了楼层的评论者应当收到带退订链接的通知: %q", unsubscribeUrl)
+	}
+
+	// 未配置服务端地址时仍然通知，但不生成退订链接，避免使用请求头中可以伪造的地址
+	config.Server.ApiUrl = ""
+	if unsubscribeUrl, subscribed := GetReplyUnsubscribeUrl(ctx, "author@example.com", reply.OriginPostId); !subscribed || unsubscribeUrl != "" {
+		t.Errorf("未配置服务端地址时不应生成退订链接: %q, %v", unsubscribeUrl, subscribed)
+	}
+
+	// 通过退订链接中的令牌退订，重复退订不报错
+	parsed, err := url.Parse(unsubscribeUrl)
+	if err != nil {
+		t.Fatalf("退订链接无效: %v", err)
+	}
+	token := parsed.Query().Get("token")
+	if err := CheckUnsubscribeToken(token); err != nil {
+		t.Fatalf("有效的退订令牌检查失败: %v", err)
+	}
+	if _, subscribed := GetReplyUnsubscribeUrl(ctx, "author@example.com", reply.OriginPostId); !subscribed {
+		t.Fatal("检查令牌不应退订")
+	}
+	if err := UnsubscribeByToken(ctx, token); err != nil {
+		t.Fatalf("退订失败: %v", err)
+	}
+	if err := UnsubscribeByToken(ctx, token); err != nil {
+		t.Errorf("重复退订不应返回错误: %v", err)
+	}
+	if _, subscribed := GetReplyUnsubscribeUrl(ctx, "author@example.com", reply.OriginPostId); subscribed {
+		t.Error("退订后不应再收到回复通知")
+	}
+
+	if err := CheckUnsubscribeToken(token + "x"); err == nil {
+		t.Error("无效的退订令牌应当检查失败")
+	}
+	if err := UnsubscribeByToken(ctx, token+"x"); err == nil {
+		t.Error("无效的退订令牌应当返回错误")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/storage"
 	"sparrow_blog_server/storage/ossstore"
+	"sparrow_blog_server/storage/ossstore/local"
 	"strings"
 	"time"
 )
@@ -266,17 +267,25 @@ func GetBlogDataById(ctx context.Context, id string) (*vo.BlogVo, string, error)
 	}
 
 	// 处理该博客其他日期的阅读数据
-	// 获取该博客所有相关的缓存key
-	keys, err := storage.Storage.Cache.GetKeysLike(ctx, blogDto.BlogId)
+	// 增量遍历该博客所有日期的阅读数缓存key
+	var keys []string
+	pattern := storage.BuildBlogReadCountPattern(blogDto.BlogId)
+	for cursor := uint64(0); ; {
+		var batch []string
+		cursor, batch, err = storage.Storage.Cache.Scan(ctx, cursor, pattern, 100)
+		if err != nil {
+			break
+		}
+		keys = append(keys, batch...)
+		if cursor == 0 {
+			break
+		}
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("获取博客阅读数缓存失败: %v", err))
 	} else {
 		// 遍历处理每个缓存key
 		for _, key := range keys {
-			// 过滤掉不是博客阅读数缓存的 key
-			if !strings.HasPrefix(key, storage.BlogReadCountKeyPrefix) {
-				continue
-			}
 			// 过滤掉今天的 key
 			if strings.HasSuffix(key, time.Now().Format("20060102")) {
 				continue
@@ -287,7 +296,7 @@ func GetBlogDataById(ctx context.Context, id string) (*vo.BlogVo, string, error)
 				logger.Error(fmt.Sprintf("获取博客阅读数缓存失败: %v", err))
 			} else {
 				// 构建数据传输对象
-				date := strings.Split(key, "-")[1]
+				date := strings.TrimPrefix(key, storage.BlogReadCountKeyPrefix+blogDto.BlogId+"-")
 				blogReadCountDto := &dto.BlogReadCountDto{
 					BlogId:    blogDto.BlogId,
 					ReadCount: count,
@@ -408,17 +417,8 @@ func GetCommentsByBlogId(ctx context.Context, blogId string) ([]vo.CommentVo, er
 
 	// 遍历所有楼主评论
 	for _, commentDto := range commentDtos {
-		// 创建楼主评论Vo
-		commentVo := vo.CommentVo{
-			CommentId:        commentDto.CommentId,
-			CommenterEmail:   commentDto.CommenterEmail,
-			BlogId:           commentDto.BlogId,
-			BlogTitle:        blogTitle,
-			OriginPostId:     commentDto.OriginPostId,
-			ReplyToCommenter: commentDto.ReplyToCommenter,
-			Content:          commentDto.Content,
-			CreateTime:       commentDto.CreateTime,
-		}
+		// 创建楼主评论Vo，邮箱打码后展示
+		commentVo := publicCommentVo(&commentDto, blogTitle)
 
 		// 获取楼层子评论
 		subCommentDtos, err := commentrepo.FindCommentsByOriginPostId(ctx, commentDto.CommentId)
@@ -426,18 +426,12 @@ func GetCommentsByBlogId(ctx context.Context, blogId string) ([]vo.CommentVo, er
 			return nil, err
 		}
 
-		// 将子评论转为 Vo，并保存
+		// 将已通过审核的子评论转为 Vo，并保存
 		for _, subCommentDto := range subCommentDtos {
-			commentVo.SubComments = append(commentVo.SubComments, vo.CommentVo{
-				CommentId:        subCommentDto.CommentId,
-				CommenterEmail:   subCommentDto.CommenterEmail,
-				BlogId:           subCommentDto.BlogId,
-				BlogTitle:        blogTitle,
-				OriginPostId:     subCommentDto.OriginPostId,
-				ReplyToCommenter: subCommentDto.ReplyToCommenter,
-				Content:          subCommentDto.Content,
-				CreateTime:       subCommentDto.CreateTime,
-			})
+			if subCommentDto.Status != dto.CommentStatusApproved {
+				continue
+			}
+			commentVo.SubComments = append(commentVo.SubComments, publicCommentVo(&subCommentDto, blogTitle))
 		}
 
 		// 添加到楼主评论集合
@@ -472,6 +466,11 @@ func AddComment(ctx context.Context, commentDto *dto.CommentDto) (*vo.CommentVo,
 			tx.Rollback()
 			return nil, fmt.Errorf("被回复的评论不存在: %v", err)
 		}
+		// 未通过审核的评论在前台不可见，不能被回复
+		if replyToComment.Status != dto.CommentStatusApproved {
+			tx.Rollback()
+			return nil, errors.New("被回复的评论不存在")
+		}
 
 		// 如果回复的是楼主评论，则 OriginPostId 设置为被回复评论的ID
 		// 如果回复的是子评论，则 OriginPostId 设置为原楼主评论的ID
@@ -484,6 +483,20 @@ func AddComment(ctx context.Context, commentDto *dto.CommentDto) (*vo.CommentVo,
 		}
 	}
 
+	// 根据审核模式决定评论是否需要审核
+	status, err := moderateComment(ctx, commentDto)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	commentDto.Status = status
+
+	// 渲染评论内容，前台展示经过清理的 HTML
+	if err := RenderCommentContent(ctx, commentDto); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("渲染评论失败: %v", err)
+	}
+
 	// 保存到数据库
 	resultDto, err := commentrepo.CreateComment(tx, commentDto)
 	if err != nil {
@@ -497,6 +510,11 @@ func AddComment(ctx context.Context, commentDto *dto.CommentDto) (*vo.CommentVo,
 		return nil, fmt.Errorf("提交事务失败: %v", err)
 	}
 
+	// 评论者选择订阅时订阅本楼的回复通知
+	if commentDto.Subscribe {
+		subscribeThread(ctx, resultDto)
+	}
+
 	// 根据博客ID查询博客标题
 	blogTitle, err := blogrepo.FindBlogTitleById(ctx, resultDto.BlogId)
 	if err != nil {
@@ -504,19 +522,37 @@ func AddComment(ctx context.Context, commentDto *dto.CommentDto) (*vo.CommentVo,
 		blogTitle = "" // 设置为空字符串
 	}
 
-	// 转换为VO对象返回
-	commentVo := &vo.CommentVo{
-		CommentId:        resultDto.CommentId,
-		CommenterEmail:   resultDto.CommenterEmail,
-		BlogId:           resultDto.BlogId,
-		BlogTitle:        blogTitle,
-		OriginPostId:     resultDto.OriginPostId,
-		ReplyToCommenter: resultDto.ReplyToCommenter,
-		Content:          resultDto.Content,
-		CreateTime:       resultDto.CreateTime,
-	}
+	// 转换为VO对象返回，响应会返回给前台，同样需要打码
+	commentVo := publicCommentVo(resultDto, blogTitle)
+	commentVo.Status = resultDto.Status
+
+	return &commentVo, nil
+}
 
-	return commentVo, nil
+// moderateComment 根据评论审核模式决定新评论的审核状态
+// - ctx: 上下文对象
+// - commentDto: 评论数据传输对象
+//
+// 返回值:
+// - string: 审核状态
+// - error: 错误信息
+func moderateComment(ctx context.Context, commentDto *dto.CommentDto) (string, error) {
+	switch config.Comment.Moderation {
+	case config.ModerationHoldAll:
+		return dto.CommentStatusPending, nil
+	case config.ModerationHoldFirstTime:
+		// 评论者有过通过审核的评论时自动通过
+		count, err := commentrepo.CountCommentsByEmailAndStatus(ctx, commentDto.CommenterEmail, dto.CommentStatusApproved)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return dto.CommentStatusPending, nil
+		}
+		return dto.CommentStatusApproved, nil
+	default:
+		return dto.CommentStatusApproved, nil
+	}
 }
 
 // GetLatestComments 获取最新的5条评论（业务端功能）
@@ -543,19 +579,43 @@ func GetLatestComments(ctx context.Context) ([]vo.CommentVo, error) {
 			blogTitle = "" // 设置为空字符串
 		}
 
-		commentVo := vo.CommentVo{
-			CommentId:        commentDto.CommentId,
-			CommenterEmail:   commentDto.CommenterEmail,
-			BlogId:           commentDto.BlogId,
-			BlogTitle:        blogTitle,
-			OriginPostId:     commentDto.OriginPostId,
-			ReplyToCommenter: commentDto.ReplyToCommenter,
-			Content:          commentDto.Content,
-			CreateTime:       commentDto.CreateTime,
-		}
-
-		commentVos = append(commentVos, commentVo)
+		commentVos = append(commentVos, publicCommentVo(&commentDto, blogTitle))
 	}
 
 	return commentVos, nil
 }
+
+// VerifyLocalObjectRequest 校验本地对象存储的预签名请求（仅在使用本地对象存储时可用）
+// - ctx: 上下文对象
+// - method: 请求方法
+// - objectName: 对象名称
+// - contentType: 请求的 Content-Type
+// - expires: URL 中的过期时间戳
+// - signature: URL 中的签名
+//
+// 返回值:
+// - string: 对象在磁盘上的路径
+// - error: 错误信息
+func VerifyLocalObjectRequest(ctx context.Context, method, objectName, contentType, expires, signature string) (string, error) {
+	store, ok := storage.Storage.ObjectStore.(*local.Store)
+	if !ok {
+		return "", errors.New("当前未使用本地对象存储")
+	}
+
+	return store.Verify(method, objectName, contentType, expires, signature)
+}
+
+// PutLocalObject 向本地对象存储上传内容，调用前需先通过 VerifyLocalObjectRequest 校验请求
+// - ctx: 上下文对象
+// - objectName: 对象名称
+// - content: 上传的内容
+//
+// 返回值:
+// - error: 错误信息
+func PutLocalObject(ctx context.Context, objectName string, content []byte) error {
+	if _, ok := storage.Storage.ObjectStore.(*local.Store); !ok {
+		return errors.New("当前未使用本地对象存储")
+	}
+
+	return storage.Storage.PutContentToOss(ctx, content, objectName)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"sparrow_blog_server/env"
+	"sparrow_blog_server/internal/services/adminservices"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/routers"
@@ -119,7 +120,9 @@ func startWebServer() *http.Server {
 
 // gracefulShutdown 实现服务的优雅关闭机制
 // @param webServer HTTP 服务器实例，需要被优雅关闭
-func gracefulShutdown(webServer *http.Server) {
+// @param stopScheduler 停止博客定时调度器的函数
+// @param stopEmailOutbox 停止邮件发件箱的函数
+func gracefulShutdown(webServer *http.Server, stopScheduler func(), stopEmailOutbox func()) {
 	// 创建缓冲为1的信号通道，避免信号丢失
 	signalChannel := make(chan os.Signal, 1)
 
@@ -138,6 +141,14 @@ func gracefulShutdown(webServer *http.Server) {
 
 	// 按照依赖关系的逆序关闭组件，确保数据一致性
 
+	// 停止博客定时调度器，等待正在执行的状态切换完成
+	logger.Info("停止博客定时调度器")
+	stopScheduler()
+
+	// 停止邮件发件箱，等待正在发送的邮件完成，未发送的邮件在下次启动时继续发送
+	logger.Info("停止邮件发件箱")
+	stopEmailOutbox()
+
 	// 第一步: 关闭数据存储层（数据库连接池、缓存系统等）
 	// 优先关闭数据层，确保所有数据写入完成
 	logger.Info("关闭数据层")
@@ -162,12 +173,35 @@ func parseCommandLineArgs() {
 	// 初始化全局参数存储映射
 	Args = make(map[string]string)
 
-	// 遍历命令行参数，查找 --env 标志
-	for i := 0; i < len(os.Args); i++ {
+	// 遍历命令行参数，查找 --env 标志与子命令
+	for i := 1; i < len(os.Args); i++ {
 		// 检查当前参数是否为 --env 且存在对应的值
 		if os.Args[i] == "--env" && i+1 < len(os.Args) {
 			Args["env"] = os.Args[i+1]
 			i++ // 跳过已处理的环境值参数
+			continue
+		}
+
+		// 数据库迁移子命令: migrate status|up|down [回滚数量]
+		if os.Args[i] == "migrate" && Args["command"] == "" {
+			Args["command"] = "migrate"
+			if i+1 < len(os.Args) {
+				Args["migrate"] = os.Args[i+1]
+				i++
+			}
+			if Args["migrate"] == "down" && i+1 < len(os.Args) && os.Args[i+1] != "--env" {
+				Args["steps"] = os.Args[i+1]
+				i++
+			}
+		}
+
+		// 缓存 AOF 文件子命令: aof check|repair
+		if os.Args[i] == "aof" && Args["command"] == "" {
+			Args["command"] = "aof"
+			if i+1 < len(os.Args) {
+				Args["aof"] = os.Args[i+1]
+				i++
+			}
 		}
 	}
 
@@ -226,6 +260,18 @@ func main() {
 		return
 	}
 
+	// 执行数据库迁移命令后直接退出，不启动服务
+	if Args["command"] == "migrate" {
+		env.CurrentEnv = Args["env"]
+		os.Exit(runMigrateCommand())
+	}
+
+	// 检查或修复缓存 AOF 文件后直接退出，不启动服务
+	if Args["command"] == "aof" {
+		env.CurrentEnv = Args["env"]
+		os.Exit(runAofCommand())
+	}
+
 	// 阶段5: 初始化应用程序核心组件，设置1分钟超时
 	// 包括日志系统、数据存储层、搜索引擎等关键组件
 	initializationCtx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
@@ -238,10 +284,16 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// 阶段7: 启动 Web 服务器，开始处理 HTTP 请求
+	// 阶段7: 启动邮件发件箱，之后的系统邮件写入发件箱由后台发送，继续发送上次退出时未发送的邮件
+	stopEmailOutbox := adminservices.StartEmailOutbox(context.Background())
+
+	// 阶段8: 启动 Web 服务器，开始处理 HTTP 请求
 	webServer := startWebServer()
 
-	// 阶段8: 进入信号监听状态，等待优雅关闭信号
+	// 阶段9: 启动博客定时发布调度器，补上停机期间错过的定时任务
+	stopScheduler := adminservices.StartBlogScheduler(context.Background())
+
+	// 阶段10: 进入信号监听状态，等待优雅关闭信号
 	// 程序将在此处阻塞，直到接收到 SIGINT 或 SIGTERM 信号
-	gracefulShutdown(webServer)
+	gracefulShutdown(webServer, stopScheduler, stopEmailOutbox)
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage/db/migration"
+	"sparrow_blog_server/storage/db/sqlite"
+)
+
+// runMigrateCommand 执行数据库迁移命令: migrate status|up|down [回滚数量]
+// @return int 进程退出码
+func runMigrateCommand() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := logger.InitLogger(ctx); err != nil {
+		fmt.Printf("❗ 日志模块初始化失败: %v\n", err)
+		return 1
+	}
+
+	db, err := sqlite.OpenSqlite(ctx)
+	if err != nil {
+		fmt.Printf("❗ 连接数据库失败: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	switch Args["migrate"] {
+	case "status":
+		statuses, err := migration.GetStatus(ctx, db)
+		if err != nil {
+			fmt.Printf("❗ 获取迁移状态失败: %v\n", err)
+			return 1
+		}
+		for _, s := range statuses {
+			state := "未执行"
+			if s.Applied {
+				state = "已执行 " + s.AppliedTime.Local().Format(time.DateTime)
+			}
+			if s.ChecksumMismatch {
+				state += " (校验和不一致)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	case "up":
+		applied, err := migration.Up(ctx, db)
+		if err != nil {
+			fmt.Printf("❗ 执行迁移失败: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("ℹ️ 数据库已是最新版本")
+		}
+		for _, m := range applied {
+			fmt.Printf("✅ 已执行 %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		steps := 1
+		if s, ok := Args["steps"]; ok {
+			steps, err = strconv.Atoi(s)
+			if err != nil || steps <= 0 {
+				fmt.Printf("❗ 回滚数量错误: %s\n", s)
+				return 1
+			}
+		}
+		reverted, err := migration.Down(ctx, db, steps)
+		if err != nil {
+			fmt.Printf("❗ 回滚迁移失败: %v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("ℹ️ 没有可回滚的迁移")
+		}
+		for _, m := range reverted {
+			fmt.Printf("✅ 已回滚 %04d_%s\n", m.Version, m.Name)
+		}
+	default:
+		fmt.Println("用法: sparrow_blog_server migrate status|up|down [回滚数量]")
+		return 1
+	}
+
+	return 0
+}
@@ -39,6 +39,12 @@ var (
 
 	// Cache 保存全局缓存配置
 	Cache CacheConfig
+
+	// Comment 保存全局评论配置
+	Comment CommentConfig
+
+	// Email 保存全局邮件配置
+	Email EmailConfig
 )
 
 // LoadConfig 加载配置文件。
@@ -108,6 +114,8 @@ func LoadConfig() {
 		Sqlite = conf.Sqlite
 		Oss = conf.Oss
 		Cache = conf.Cache
+		Comment = conf.Comment
+		Email = conf.Email
 	})
 }
 
@@ -141,14 +149,42 @@ func createDefaultConfig() (*ProjectConfig, error) {
 		Sqlite: SqliteConfig{
 			Path: filepath.Join(projDir, "data", "sparrow_blog.db"),
 		},
-		Oss: OssConfig{},
+		Oss: OssConfig{
+			LocalPath: filepath.Join(projDir, "oss"),
+		},
 		Cache: CacheConfig{
 			Aof: AofConfig{
 				Enable:   true,
 				Path:     filepath.Join(projDir, "aof", "sparrow_blog.aof"),
 				MaxSize:  10,
 				Compress: true,
+				Fsync:    FsyncEverySec,
+
+				RewritePercentage: 100,
+				RewriteMinSize:    1,
+			},
+			Expire: ExpireConfig{
+				Interval:   100,
+				SampleSize: 20,
+			},
+			Eviction: EvictionConfig{
+				Policy:  EvictionLRU,
+				Samples: 5,
+			},
+		},
+		Comment: CommentConfig{
+			Moderation: ModerationAutoApprove,
+			Spam: SpamConfig{
+				MaxLinks:       3,
+				RateBurst:      5,
+				RateInterval:   60,
+				BayesThreshold: 0.95,
 			},
+			AvatarBaseUrl: DefaultAvatarBaseUrl,
+		},
+		Email: EmailConfig{
+			TemplatePath: filepath.Join(projDir, "templates", "email"),
+			Locale:       "zh-CN",
 		},
 	}, nil
 }
@@ -215,6 +251,15 @@ func (pc *ProjectConfig) Store() error {
 	return nil
 }
 
+// ProjDir 返回 SPARROW_BLOG_HOME 目录，未设置该环境变量时为用户主目录下的 .sparrow_blog
+//
+// 返回:
+//   - string: 目录的完整路径
+//   - error: 获取用户主目录时遇到的任何错误
+func ProjDir() (string, error) {
+	return getProjDir()
+}
+
 // getProjDir 返回h2blog配置和数据的基础目录
 // 它使用用户的主目录作为基础路径
 //
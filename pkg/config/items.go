@@ -9,6 +9,8 @@ type ProjectConfig struct {
 	Sqlite       SqliteConfig     `yaml:"sqlite"`        // Sqlite 数据库配置
 	Oss          OssConfig        `yaml:"oss"`           // OSS 对象存储配置
 	Cache        CacheConfig      `yaml:"cache"`         // 缓存配置
+	Comment      CommentConfig    `yaml:"comment"`       // 评论配置
+	Email        EmailConfig      `yaml:"email"`         // 邮件配置
 }
 
 // UserConfigData 用户配置
@@ -35,6 +37,10 @@ type ServerConfigData struct {
 	SmtpPort            uint16         `yaml:"smtp_port"`             // 邮箱 SMTP 端口
 	SmtpAuthCode        string         `yaml:"smtp_auth_code"`        // 邮箱 SMTP 密码
 	SSL                 SSLConfigData  `yaml:"ssl"`                   // SSL/TLS配置
+	SiteUrl             string         `yaml:"site_url"`              // 博客前台访问地址，用于生成订阅源等对外链接
+	ApiUrl              string         `yaml:"api_url"`               // 服务端对外访问地址，用于生成邮件中的退订链接等指向服务端接口的链接
+	Robots              string         `yaml:"robots"`                // robots.txt 抓取规则，为空时允许抓取全部页面；robots.txt 需要在博客前台站点的根路径提供
+	TrustedProxies      []string       `yaml:"trusted_proxies"`       // 可信的反向代理地址或网段，只有来自这些地址的请求才使用 X-Forwarded-For 中的客户端地址
 }
 
 // SSLConfigData 定义了SSL/TLS相关配置
@@ -72,6 +78,7 @@ type SqliteConfig struct {
 
 // OssConfig 定义了对象存储服务配置
 type OssConfig struct {
+	Provider        string `yaml:"provider"`          // 对象存储后端: aliyun、s3、local，为空时根据是否配置访问密钥自动选择
 	Endpoint        string `yaml:"endpoint"`          // OSS 服务的访问域名
 	Region          string `yaml:"region"`            // OSS 服务的地域
 	AccessKeyId     string `yaml:"access_key_id"`     // OSS 访问密钥ID
@@ -79,17 +86,86 @@ type OssConfig struct {
 	Bucket          string `yaml:"bucket"`            // OSS 存储空间名称
 	ImageOssPath    string `yaml:"image_oss_path"`    // 图片存储路径
 	BlogOssPath     string `yaml:"blog_oss_path"`     // 博客内容存储路径
+	UsePathStyle    bool   `yaml:"use_path_style"`    // S3 是否使用路径风格访问，MinIO 等自建服务通常需要开启
+	LocalPath       string `yaml:"local_path"`        // 本地对象存储根目录，未配置云存储时使用
+	LocalBaseUrl    string `yaml:"local_base_url"`    // 本地对象存储对外访问地址，为空时使用 http://localhost:端口
 }
 
 // CacheConfig 定义了缓存系统配置
 type CacheConfig struct {
-	Aof AofConfig `yaml:"aof"` // AOF持久化配置
+	Aof      AofConfig      `yaml:"aof"`      // AOF持久化配置
+	Expire   ExpireConfig   `yaml:"expire"`   // 过期键后台清理配置
+	Eviction EvictionConfig `yaml:"eviction"` // 容量限制与淘汰策略配置
 }
 
+// ExpireConfig 定义了过期键的后台清理配置
+// 每轮从设置了过期时间的键中随机抽样检查，过期比例较高时继续下一批，避免长时间持有全局锁
+type ExpireConfig struct {
+	Interval   int `yaml:"interval"`    // 后台清理的间隔(毫秒)，为 0 时使用默认值 100，小于 0 时关闭后台清理
+	SampleSize int `yaml:"sample_size"` // 每批抽样检查的键数量，为 0 时使用默认值 20
+}
+
+// 缓存淘汰策略
+const (
+	EvictionLRU = "lru" // 淘汰最久未访问的键
+	EvictionLFU = "lfu" // 淘汰访问频率最低的键
+)
+
+// EvictionConfig 定义了缓存的容量限制与淘汰策略，超过任一限制时按策略淘汰
+type EvictionConfig struct {
+	MaxEntries int    `yaml:"max_entries"` // 最多缓存的键数量，为 0 时不限制
+	MaxBytes   int64  `yaml:"max_bytes"`   // 估算的最大内存占用(字节)，为 0 时不限制
+	Policy     string `yaml:"policy"`      // 淘汰策略: lru、lfu，为空时使用 lru
+	Samples    int    `yaml:"samples"`     // 每次淘汰时抽样比较的键数量，为 0 时使用默认值 5
+}
+
+// AOF 刷盘策略
+const (
+	FsyncAlways   = "always"   // 每次写入后都刷盘，崩溃时不丢失已返回的写入
+	FsyncEverySec = "everysec" // 后台每秒刷盘一次，崩溃时最多丢失 1 秒的写入
+	FsyncNo       = "no"       // 不主动刷盘，由操作系统决定何时写入磁盘
+)
+
 // AofConfig 定义了追加文件持久化配置
 type AofConfig struct {
 	Enable   bool   `yaml:"enable"`   // 是否启用AOF持久化
 	Path     string `yaml:"path"`     // AOF文件路径
 	MaxSize  uint16 `yaml:"max_size"` // AOF文件最大大小(MB)
 	Compress bool   `yaml:"compress"` // 是否压缩AOF文件
+	Fsync    string `yaml:"fsync"`    // 刷盘策略: always、everysec、no，为空时使用 everysec
+
+	RewritePercentage int    `yaml:"rewrite_percentage"` // AOF 相对上次重写后的大小增长超过该百分比时自动重写，为 0 时使用默认值 100，小于 0 时关闭自动重写
+	RewriteMinSize    uint16 `yaml:"rewrite_min_size"`   // 自动重写时 AOF 的最小大小(MB)，为 0 时使用默认值 1
+}
+
+// 评论审核模式
+const (
+	ModerationAutoApprove   = "auto_approve"    // 自动通过所有评论
+	ModerationHoldAll       = "hold_all"        // 所有评论都需要审核
+	ModerationHoldFirstTime = "hold_first_time" // 只审核首次评论的评论者，有过通过审核的评论后自动通过
+)
+
+// DefaultAvatarBaseUrl 默认的评论者头像服务地址，Cravatar 兼容 Gravatar 的头像地址格式并在国内可以访问
+const DefaultAvatarBaseUrl = "https://cravatar.cn/avatar/"
+
+// CommentConfig 定义了评论配置
+type CommentConfig struct {
+	Moderation    string     `yaml:"moderation"`      // 评论审核模式: auto_approve、hold_all、hold_first_time，为空时自动通过
+	Spam          SpamConfig `yaml:"spam"`            // 反垃圾配置
+	AvatarBaseUrl string     `yaml:"avatar_base_url"` // 评论者头像服务地址，拼接邮箱的 MD5 摘要得到头像，如 https://www.gravatar.com/avatar/，为空时使用 Cravatar
+}
+
+// SpamConfig 定义了评论反垃圾配置，数值为 0 时使用默认值
+type SpamConfig struct {
+	MaxLinks        int      `yaml:"max_links"`        // 单条评论最多包含的链接数，默认 3，小于 0 时不限制
+	BlockedKeywords []string `yaml:"blocked_keywords"` // 屏蔽关键词，不区分大小写
+	RateBurst       int      `yaml:"rate_burst"`       // 同一 IP 或邮箱允许连续提交评论的次数，默认 5
+	RateInterval    int      `yaml:"rate_interval"`    // 恢复一次提交机会所需的秒数，默认 60
+	BayesThreshold  float64  `yaml:"bayes_threshold"`  // 贝叶斯分类器判定为垃圾评论的概率阈值，默认 0.95
+}
+
+// EmailConfig 定义了邮件配置
+type EmailConfig struct {
+	TemplatePath string `yaml:"template_path"` // 邮件模板目录，其中的模板覆盖内置模板，为空时使用 SPARROW_BLOG_HOME 下的 templates/email
+	Locale       string `yaml:"locale"`        // 邮件语言: zh-CN、en-US，为空或不支持时使用 zh-CN
 }
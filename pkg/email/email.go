@@ -4,17 +4,79 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"html/template"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/utils"
 	"sparrow_blog_server/storage"
-	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
-// SendVerificationCodeByArgs 发送包含验证码的电子邮件。
+// Message 一封使用系统 SMTP 配置发送的邮件
+type Message struct {
+	To      string // 收件人邮箱
+	Subject string // 邮件主题
+	Content string // HTML 格式的邮件正文
+	Text    string // 纯文本格式的替代正文，为空时只发送 HTML 正文
+	// UnsubscribeUrl 退订链接，不为空时设置 List-Unsubscribe 与 List-Unsubscribe-Post 邮件头，
+	// 邮件客户端据此显示一键退订按钮，向该链接发送 POST 请求完成退订（RFC 8058）
+	UnsubscribeUrl string
+}
+
+// Outbox 发件箱，持久化邮件并在后台发送，发送失败时自动重试
+type Outbox interface {
+	// Enqueue 将邮件写入发件箱，写入成功即返回，不等待发送结果
+	Enqueue(ctx context.Context, msg Message) error
+}
+
+var (
+	outboxMu sync.RWMutex
+	outbox   Outbox
+)
+
+// SetOutbox 注册发件箱，之后使用系统 SMTP 配置发送的邮件都写入发件箱，传入 nil 时恢复为直接发送。
+// 使用调用方指定 SMTP 配置的 ByArgs 函数不经过发件箱：这些调用用于验证新的 SMTP 配置，
+// 需要立即得到发送结果，SMTP 授权码也不应写入数据库。
+// 验证码邮件同样不经过发件箱：验证码只在5分钟内有效，重试送达已经没有意义，明文验证码也不应保存在发件箱中。
+//
+// 参数:
+//   - o: 发件箱，为 nil 时注销
+func SetOutbox(o Outbox) {
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	outbox = o
+}
+
+// Deliver 使用系统 SMTP 配置立即发送邮件，不经过发件箱，供发件箱的发送协程调用。
+// 参数:
+//   - msg: 待发送的邮件
+//
+// 返回值:
+//   - error: 连接 SMTP 服务器或发送失败时返回错误信息
+func Deliver(msg Message) error {
+	return sendContent(msg,
+		config.Server.SmtpAccount,
+		config.Server.SmtpAddress,
+		config.Server.SmtpAuthCode,
+		config.Server.SmtpPort,
+	)
+}
+
+// dispatch 注册了发件箱时将邮件写入发件箱，否则立即发送
+func dispatch(ctx context.Context, msg Message) error {
+	outboxMu.RLock()
+	o := outbox
+	outboxMu.RUnlock()
+
+	if o != nil {
+		return o.Enqueue(ctx, msg)
+	}
+	return Deliver(msg)
+}
+
+// SendVerificationCodeByArgs 使用指定的 SMTP 配置发送包含验证码的电子邮件。
+// 邮件立即发送而不经过发件箱，用于验证新的 SMTP 配置是否可用。
 // 参数说明：
 //   - ctx: 上下文对象，用于控制请求的生命周期。
 //   - email: 收件人的电子邮件地址。
@@ -26,10 +88,40 @@ import (
 // 返回值：
 //   - error: 如果发送邮件过程中发生错误，则返回错误信息；否则返回nil。
 func SendVerificationCodeByArgs(ctx context.Context, email, smtpAccount, smtpAddress, smtpAuthCode string, smtpPort uint16) error {
+	msg, err := renderVerificationCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 调用SendContent函数发送包含验证码的邮件。
+	msg.To = email
+	return sendContent(*msg, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
+}
+
+// SendVerificationCode 使用系统 SMTP 配置向指定邮箱立即发送验证码，不经过发件箱。
+// 参数说明：
+//   - ctx: 上下文对象，用于控制请求的生命周期。
+//   - email: 收件人的电子邮件地址。
+//
+// 返回值：
+//   - error: 如果生成验证码或发送过程中发生错误，则返回错误信息；否则返回nil。
+func SendVerificationCode(ctx context.Context, email string) error {
+	msg, err := renderVerificationCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	msg.To = email
+	return Deliver(*msg)
+}
+
+// renderVerificationCode 取得当前有效的验证码并渲染验证码邮件。
+// 缓存中没有验证码时生成新的验证码并缓存5分钟。
+func renderVerificationCode(ctx context.Context) (*Message, error) {
 	// 生成一个长度为20的随机验证码，基于用户邮箱和当前时间。
 	code, err := utils.HashWithLength(config.User.UserEmail+time.Now().String(), 20)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 如果缓存中不存在验证码，则将生成的验证码存储到缓存中，并设置5分钟的过期时间。
@@ -37,39 +129,20 @@ func SendVerificationCodeByArgs(ctx context.Context, email, smtpAccount, smtpAdd
 	if getErr != nil {
 		setErr := storage.Storage.Cache.SetWithExpired(ctx, storage.VerificationCodeKey, code, 5*time.Minute)
 		if setErr != nil {
-			msg := fmt.Sprintf("缓存验证码失败: %v", err)
-			return errors.New(msg)
+			msg := fmt.Sprintf("缓存验证码失败: %v", setErr)
+			return nil, errors.New(msg)
 		}
 	} else {
 		code = c
 	}
 
-	// 解析HTML模板，准备渲染验证码。
-	tmpl, err := template.New("email").Parse(VerificationCodeTemplate)
-	if err != nil {
-		return err
-	}
-
-	// 创建一个字符串构建器，用于存储渲染后的HTML内容。
-	var htmlContent strings.Builder
-
-	// 执行模板渲染，将验证码插入到HTML模板中。
-	err = tmpl.Execute(&htmlContent, struct {
-		Code string
-	}{Code: template.HTMLEscapeString(code)})
-	if err != nil {
-		return err
-	}
-
-	// 调用SendContent函数发送包含验证码的邮件。
-	return sendContent(email, htmlContent.String(), VerificationCodeSubject, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
+	// 渲染验证码邮件模板，模板负责转义
+	return renderTemplate(TemplateVerificationCode, VerificationCodeData{Code: code})
 }
 
-// sendContent 发送邮件内容到指定邮箱。
+// sendContent 发送邮件到指定邮箱。
 // 参数说明：
-//   - email: 收件人的邮箱地址。
-//   - content: 邮件的正文内容，支持 HTML 格式。
-//   - subject: 邮件主题。
+//   - msg: 待发送的邮件，包含收件人、主题、HTML 正文与可选的纯文本正文。
 //   - smtpAccount: SMTP 服务器的发件人账号（通常是邮箱地址）。
 //   - smtpAddress: SMTP 服务器的地址（如 smtp.example.com）。
 //   - smtpAuthCode: SMTP 服务器的授权码或密码。
@@ -77,17 +150,26 @@ func SendVerificationCodeByArgs(ctx context.Context, email, smtpAccount, smtpAdd
 //
 // 返回值：
 //   - error: 如果发送邮件失败，则返回错误信息；否则返回 nil。
-func sendContent(email, content, subject, smtpAccount, smtpAddress, smtpAuthCode string, smtpPort uint16) error {
+func sendContent(msg Message, smtpAccount, smtpAddress, smtpAuthCode string, smtpPort uint16) error {
 	// 创建邮件内容
 	m := gomail.NewMessage()
 
 	// 设置邮件头部信息，包括发件人、收件人和主题
 	m.SetHeader("From", smtpAccount)
-	m.SetHeader("To", email)
-	m.SetHeader("Subject", subject)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	if msg.UnsubscribeUrl != "" {
+		m.SetHeader("List-Unsubscribe", "<"+msg.UnsubscribeUrl+">")
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
 
-	// 设置邮件正文为 HTML 格式
-	m.SetBody("text/html", content)
+	// 有纯文本正文时作为 multipart/alternative 发送，邮件客户端优先显示靠后的 HTML 正文
+	if msg.Text != "" {
+		m.SetBody("text/plain", msg.Text)
+		m.AddAlternative("text/html", msg.Content)
+	} else {
+		m.SetBody("text/html", msg.Content)
+	}
 
 	// 配置 SMTP 服务器连接信息
 	d := gomail.NewDialer(smtpAddress, int(smtpPort), smtpAccount, smtpAuthCode)
@@ -100,32 +182,16 @@ func sendContent(email, content, subject, smtpAccount, smtpAddress, smtpAuthCode
 	return nil
 }
 
-// SendVerificationCodeBySys 发送验证码邮件给指定的邮箱地址。
+// SendVerificationCodeBySys 使用系统 SMTP 配置向博主邮箱立即发送验证码邮件，不经过发件箱。
 // 参数:
 //   - ctx: 上下文对象，用于控制请求的生命周期和传递元数据；
-//   - email: 目标邮箱地址，验证码将发送到该邮箱；
 //
 // 返回值:
 //   - error: 如果发送过程中出现错误，则返回具体的错误信息；否则返回 nil。
 func SendVerificationCodeBySys(ctx context.Context) error {
-	// 调用 SendVerificationCodeByArgs 函数发送验证码邮件，
-	// 使用系统配置中的 SMTP 账号、地址、授权码和端口信息。
-	if err := SendVerificationCodeByArgs(
-		ctx,
-		config.User.UserEmail,
-		config.Server.SmtpAccount,
-		config.Server.SmtpAddress,
-		config.Server.SmtpAuthCode,
-		config.Server.SmtpPort,
-	); err != nil {
-		return err
-	}
-
-	return nil
+	return SendVerificationCode(ctx, config.User.UserEmail)
 }
 
-
-
 // CommentData 评论信息结构体
 type CommentData struct {
 	CommenterEmail string // 评论者邮箱
@@ -141,6 +207,7 @@ type ReplyData struct {
 	OriginalContent string // 原评论内容
 	ReplyContent    string // 回复内容
 	CreateTime      string // 创建时间
+	UnsubscribeUrl  string // 退订本楼回复通知的链接，为空时邮件中不显示退订入口
 }
 
 // SendCommentNotificationByArgs 发送评论通知邮件。
@@ -156,31 +223,17 @@ type ReplyData struct {
 // 返回值：
 //   - error: 如果发送邮件过程中发生错误，则返回错误信息；否则返回nil。
 func SendCommentNotificationByArgs(ctx context.Context, email string, comment CommentData, smtpAccount, smtpAddress, smtpAuthCode string, smtpPort uint16) error {
-	// 解析HTML模板，准备渲染评论信息。
-	tmpl, err := template.New("comment").Parse(CommentNotificationTemplate)
-	if err != nil {
-		return err
-	}
-
-	// 创建一个字符串构建器，用于存储渲染后的HTML内容。
-	var htmlContent strings.Builder
-
-	// 执行模板渲染，将评论信息插入到HTML模板中。
-	err = tmpl.Execute(&htmlContent, CommentData{
-		CommenterEmail: template.HTMLEscapeString(comment.CommenterEmail),
-		BlogTitle:      template.HTMLEscapeString(comment.BlogTitle),
-		Content:        template.HTMLEscapeString(comment.Content),
-		CreateTime:     template.HTMLEscapeString(comment.CreateTime),
-	})
+	msg, err := renderTemplate(TemplateCommentNotification, comment)
 	if err != nil {
 		return err
 	}
 
 	// 调用sendContent函数发送评论通知邮件。
-	return sendContent(email, htmlContent.String(), CommentNotificationSubject, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
+	msg.To = email
+	return sendContent(*msg, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
 }
 
-// SendCommentNotificationBySys 发送评论通知邮件给系统配置的邮箱地址。
+// SendCommentNotificationBySys 使用系统 SMTP 配置发送评论通知邮件给博主，注册了发件箱时写入发件箱。
 // 参数:
 //   - ctx: 上下文对象，用于控制请求的生命周期和传递元数据；
 //   - comment: 评论信息；
@@ -188,21 +241,13 @@ func SendCommentNotificationByArgs(ctx context.Context, email string, comment Co
 // 返回值:
 //   - error: 如果发送过程中出现错误，则返回具体的错误信息；否则返回 nil。
 func SendCommentNotificationBySys(ctx context.Context, comment CommentData) error {
-	// 调用 SendCommentNotificationByArgs 函数发送评论通知邮件，
-	// 使用系统配置中的 SMTP 账号、地址、授权码和端口信息。
-	if err := SendCommentNotificationByArgs(
-		ctx,
-		config.User.UserEmail,
-		comment,
-		config.Server.SmtpAccount,
-		config.Server.SmtpAddress,
-		config.Server.SmtpAuthCode,
-		config.Server.SmtpPort,
-	); err != nil {
+	msg, err := renderTemplate(TemplateCommentNotification, comment)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	msg.To = config.User.UserEmail
+	return dispatch(ctx, *msg)
 }
 
 // SendReplyNotificationByArgs 发送回复通知邮件。
@@ -218,32 +263,18 @@ func SendCommentNotificationBySys(ctx context.Context, comment CommentData) erro
 // 返回值：
 //   - error: 如果发送邮件过程中发生错误，则返回错误信息；否则返回nil。
 func SendReplyNotificationByArgs(ctx context.Context, email string, reply ReplyData, smtpAccount, smtpAddress, smtpAuthCode string, smtpPort uint16) error {
-	// 解析HTML模板，准备渲染回复信息。
-	tmpl, err := template.New("reply").Parse(ReplyNotificationTemplate)
-	if err != nil {
-		return err
-	}
-
-	// 创建一个字符串构建器，用于存储渲染后的HTML内容。
-	var htmlContent strings.Builder
-
-	// 执行模板渲染，将回复信息插入到HTML模板中。
-	err = tmpl.Execute(&htmlContent, ReplyData{
-		ReplierEmail:    template.HTMLEscapeString(reply.ReplierEmail),
-		BlogTitle:       template.HTMLEscapeString(reply.BlogTitle),
-		OriginalContent: template.HTMLEscapeString(reply.OriginalContent),
-		ReplyContent:    template.HTMLEscapeString(reply.ReplyContent),
-		CreateTime:      template.HTMLEscapeString(reply.CreateTime),
-	})
+	msg, err := renderTemplate(TemplateReplyNotification, reply)
 	if err != nil {
 		return err
 	}
 
 	// 调用sendContent函数发送回复通知邮件。
-	return sendContent(email, htmlContent.String(), ReplyNotificationSubject, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
+	msg.To = email
+	msg.UnsubscribeUrl = reply.UnsubscribeUrl
+	return sendContent(*msg, smtpAccount, smtpAddress, smtpAuthCode, smtpPort)
 }
 
-// SendReplyNotificationBySys 发送回复通知邮件给系统配置的邮箱地址。
+// SendReplyNotificationBySys 使用系统 SMTP 配置发送回复通知邮件给博主，注册了发件箱时写入发件箱。
 // 参数:
 //   - ctx: 上下文对象，用于控制请求的生命周期和传递元数据；
 //   - reply: 回复信息；
@@ -251,25 +282,24 @@ func SendReplyNotificationByArgs(ctx context.Context, email string, reply ReplyD
 // 返回值:
 //   - error: 如果发送过程中出现错误，则返回具体的错误信息；否则返回 nil。
 func SendReplyNotificationBySys(ctx context.Context, reply ReplyData) error {
-	// 调用 SendReplyNotificationByArgs 函数发送回复通知邮件，
-	// 使用系统配置中的 SMTP 账号、地址、授权码和端口信息。
-	if err := SendReplyNotificationByArgs(
-		ctx,
-		config.User.UserEmail,
-		reply,
-		config.Server.SmtpAccount,
-		config.Server.SmtpAddress,
-		config.Server.SmtpAuthCode,
-		config.Server.SmtpPort,
-	); err != nil {
+	return sendReplyNotification(ctx, config.User.UserEmail, reply)
+}
+
+// sendReplyNotification 使用系统 SMTP 配置向指定邮箱发送回复通知邮件，注册了发件箱时写入发件箱
+func sendReplyNotification(ctx context.Context, email string, reply ReplyData) error {
+	msg, err := renderTemplate(TemplateReplyNotification, reply)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	msg.To = email
+	msg.UnsubscribeUrl = reply.UnsubscribeUrl
+	return dispatch(ctx, *msg)
 }
 
 // SendCommentOrReplyNotification 智能发送评论或回复通知邮件
 // 根据评论的 ReplyToCommentId 字段判断是评论还是回复，并发送相应的通知邮件
+// 使用系统 SMTP 配置，注册了发件箱时写入发件箱，由后台发送
 // 参数说明：
 //   - ctx: 上下文对象，用于控制请求的生命周期。
 //   - commenterEmail: 评论者邮箱
@@ -278,11 +308,12 @@ func SendReplyNotificationBySys(ctx context.Context, reply ReplyData) error {
 //   - createTime: 创建时间
 //   - replyToCommentId: 回复的评论ID（空表示是新评论，非空表示是回复）
 //   - originalContent: 被回复的原评论内容（仅在回复时需要）
-//   - originalCommenterEmail: 被回复评论的作者邮箱（仅在回复时需要）
+//   - originalCommenterEmail: 被回复评论的作者邮箱（仅在回复时需要），作者没有订阅该楼层的回复时调用方应传入空字符串
+//   - unsubscribeUrl: 被回复评论的作者退订该楼层回复通知的链接（仅在回复时需要）
 //
 // 返回值：
 //   - error: 如果发送邮件过程中发生错误，则返回错误信息；否则返回nil。
-func SendCommentOrReplyNotification(ctx context.Context, commenterEmail, blogTitle, content, createTime, replyToCommentId, originalContent, originalCommenterEmail string) error {
+func SendCommentOrReplyNotification(ctx context.Context, commenterEmail, blogTitle, content, createTime, replyToCommentId, originalContent, originalCommenterEmail, unsubscribeUrl string) error {
 	// 判断是评论还是回复
 	if replyToCommentId == "" {
 		// 这是一条新评论，发送评论通知给博主
@@ -301,19 +332,12 @@ func SendCommentOrReplyNotification(ctx context.Context, commenterEmail, blogTit
 			OriginalContent: originalContent,
 			ReplyContent:    content,
 			CreateTime:      createTime,
+			UnsubscribeUrl:  unsubscribeUrl,
 		}
 
 		// 如果被回复评论的作者邮箱不为空且不是自己回复自己，则发送通知
 		if originalCommenterEmail != "" && originalCommenterEmail != commenterEmail {
-			return SendReplyNotificationByArgs(
-				ctx,
-				originalCommenterEmail,
-				reply,
-				config.Server.SmtpAccount,
-				config.Server.SmtpAddress,
-				config.Server.SmtpAuthCode,
-				config.Server.SmtpPort,
-			)
+			return sendReplyNotification(ctx, originalCommenterEmail, reply)
 		}
 	}
 
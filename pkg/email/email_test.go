@@ -3,9 +3,12 @@ package email
 import (
 	"context"
 	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/email/smtptest"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/storage"
+	"strings"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -21,23 +24,65 @@ func init() {
 	_ = storage.InitStorage(context.Background())
 }
 
+// startFakeSmtp 启动本地 SMTP 服务器并将系统 SMTP 配置指向它，测试结束后恢复
+func startFakeSmtp(t *testing.T) *smtptest.Server {
+	srv, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("启动本地 SMTP 服务器失败: %v", err)
+	}
+	oldServer, oldUser, oldEmail := config.Server, config.User, config.Email
+	config.Email = config.EmailConfig{TemplatePath: t.TempDir(), Locale: LocaleZhCN}
+	config.Server.SmtpAddress = srv.Host
+	config.Server.SmtpPort = srv.Port
+	config.Server.SmtpAccount = "blog@example.com"
+	config.Server.SmtpAuthCode = "unused"
+	config.User.UserEmail = "owner@example.com"
+	t.Cleanup(func() {
+		config.Server, config.User, config.Email = oldServer, oldUser, oldEmail
+		srv.Close()
+	})
+	return srv
+}
+
+// expectMessage 等待本地 SMTP 服务器收到发给 recipient 的邮件
+func expectMessage(t *testing.T, srv *smtptest.Server, recipient string) smtptest.Message {
+	msg, ok := srv.WaitMessage(5 * time.Second)
+	if !ok {
+		t.Fatalf("本地 SMTP 服务器没有收到发给 %s 的邮件", recipient)
+	}
+	if len(msg.To) != 1 || msg.To[0] != recipient || msg.From != "blog@example.com" {
+		t.Fatalf("收件人或发件人错误: %v, %v", msg.From, msg.To)
+	}
+	return msg
+}
+
 func TestSendVerificationCodeEmail(t *testing.T) {
 	ctx := context.Background()
+	srv := startFakeSmtp(t)
 	err := SendVerificationCodeBySys(ctx)
 	if err != nil {
 		t.Fatalf("SendVerificationCodeBySys failed: %v", err)
 	}
 
+	first := expectMessage(t, srv, "owner@example.com")
+
+	// 验证码有效期内再次发送的是同一个验证码
 	err = SendVerificationCodeBySys(ctx)
 	if err != nil {
 		t.Fatalf("SendVerificationCodeBySys failed: %v", err)
 	}
+	second := expectMessage(t, srv, "owner@example.com")
+	code, _ := storage.Storage.Cache.GetString(ctx, storage.VerificationCodeKey)
+	if code == "" || !strings.Contains(first.Data, code) || !strings.Contains(second.Data, code) {
+		t.Errorf("邮件中应当包含缓存的验证码 %q", code)
+	}
 }
 
 
 
 func TestSendCommentNotificationEmail(t *testing.T) {
 	ctx := context.Background()
+	srv := startFakeSmtp(t)
 
 	// 创建测试评论数据
 	comment := CommentData{
@@ -51,10 +96,12 @@ func TestSendCommentNotificationEmail(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SendCommentNotificationBySys failed: %v", err)
 	}
+	expectMessage(t, srv, "owner@example.com")
 }
 
 func TestSendReplyNotificationEmail(t *testing.T) {
 	ctx := context.Background()
+	srv := startFakeSmtp(t)
 
 	// 创建测试回复数据
 	reply := ReplyData{
@@ -69,10 +116,12 @@ func TestSendReplyNotificationEmail(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SendReplyNotificationBySys failed: %v", err)
 	}
+	expectMessage(t, srv, "owner@example.com")
 }
 
 func TestSendCommentOrReplyNotificationEmail(t *testing.T) {
 	ctx := context.Background()
+	srv := startFakeSmtp(t)
 
 	// 测试评论通知（replyToCommentId 为空）
 	t.Run("发送评论通知", func(t *testing.T) {
@@ -85,11 +134,12 @@ func TestSendCommentOrReplyNotificationEmail(t *testing.T) {
 			"", // 空字符串表示这是评论而不是回复
 			"",
 			"",
+			"",
 		)
 		if err != nil {
 			t.Errorf("发送评论通知失败: %v", err)
-		} else {
-			t.Log("评论通知发送成功")
+		} else if msg := expectMessage(t, srv, "owner@example.com"); strings.Contains(msg.Data, "List-Unsubscribe") {
+			t.Error("评论通知不应包含退订邮件头")
 		}
 	})
 
@@ -104,11 +154,21 @@ func TestSendCommentOrReplyNotificationEmail(t *testing.T) {
 			"comment_id_123", // 非空表示这是回复
 			"这篇Docker教程非常实用，希望能看到更多关于Kubernetes的内容！",
 			"newcommenter@example.com",
+			"https://api.example.com/web/comment/unsubscribe?token=abc",
 		)
 		if err != nil {
 			t.Errorf("发送回复通知失败: %v", err)
-		} else {
-			t.Log("回复通知发送成功")
+			return
+		}
+
+		// 回复通知带有一键退订邮件头，正文中也有退订链接
+		msg := expectMessage(t, srv, "newcommenter@example.com")
+		if !strings.Contains(msg.Data, "List-Unsubscribe: <https://api.example.com/web/comment/unsubscribe?token=abc>") ||
+			!strings.Contains(msg.Data, "List-Unsubscribe-Post: List-Unsubscribe=One-Click") {
+			t.Errorf("回复通知缺少退订邮件头: %s", msg.Data)
+		}
+		if !strings.Contains(msg.Data, "unsubscribe?token=3Dabc") && !strings.Contains(msg.Data, "unsubscribe?token=abc") {
+			t.Error("回复通知的正文中缺少退订链接")
 		}
 	})
 
@@ -123,11 +183,71 @@ func TestSendCommentOrReplyNotificationEmail(t *testing.T) {
 			"comment_id_456",
 			"我之前发表的评论内容",
 			"same@example.com", // 相同邮箱，不应该发送通知
+			"",
 		)
 		if err != nil {
 			t.Errorf("处理自己回复自己时出错: %v", err)
-		} else {
-			t.Log("自己回复自己，正确地未发送通知")
+		} else if _, ok := srv.WaitMessage(100 * time.Millisecond); ok {
+			t.Error("自己回复自己不应发送通知")
 		}
 	})
 }
+
+// recordingOutbox 记录写入的邮件而不发送
+type recordingOutbox struct {
+	messages []Message
+}
+
+func (o *recordingOutbox) Enqueue(_ context.Context, msg Message) error {
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	srv := startFakeSmtp(t)
+	outbox := &recordingOutbox{}
+	SetOutbox(outbox)
+	defer SetOutbox(nil)
+
+	// 使用系统 SMTP 配置的邮件写入发件箱
+	if err := SendCommentOrReplyNotification(ctx, "replier@example.com", "发件箱", "回复", "2024-06-24 15:30:00",
+		"comment_id", "原评论", "reader@example.com", "https://api.example.com/unsubscribe"); err != nil {
+		t.Fatalf("写入发件箱失败: %v", err)
+	}
+	if len(outbox.messages) != 1 || outbox.messages[0].To != "reader@example.com" ||
+		outbox.messages[0].Subject != "收到新回复" || outbox.messages[0].UnsubscribeUrl != "https://api.example.com/unsubscribe" {
+		t.Fatalf("发件箱中的邮件不符合预期: %+v", outbox.messages)
+	}
+	if len(srv.Messages()) != 0 {
+		t.Fatal("注册发件箱后不应直接发送")
+	}
+
+	// 验证码很快过期，不经过发件箱直接发送
+	if err := SendVerificationCode(ctx, "new@example.com"); err != nil {
+		t.Fatalf("发送验证码失败: %v", err)
+	}
+	expectMessage(t, srv, "new@example.com")
+	if len(outbox.messages) != 1 {
+		t.Error("验证码邮件不应写入发件箱")
+	}
+
+	// 指定 SMTP 配置的邮件用于验证配置，不经过发件箱直接发送
+	if err := SendVerificationCodeByArgs(ctx, "verify@example.com", "blog@example.com",
+		srv.Host, "unused", srv.Port); err != nil {
+		t.Fatalf("直接发送失败: %v", err)
+	}
+	expectMessage(t, srv, "verify@example.com")
+	if len(outbox.messages) != 1 {
+		t.Error("指定 SMTP 配置的邮件不应写入发件箱")
+	}
+
+	// 发件箱发送协程调用 Deliver 直接发送
+	if err := Deliver(outbox.messages[0]); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+	expectMessage(t, srv, "reader@example.com")
+	if !strings.Contains(outbox.messages[0].Content, "replier@example.com") {
+		t.Error("邮件正文应当包含回复者邮箱")
+	}
+}
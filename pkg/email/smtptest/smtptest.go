@@ -0,0 +1,186 @@
+// Package smtptest 提供用于测试的本地 SMTP 服务器，类似 net/http/httptest。
+// 服务器只实现发送邮件所需的最少命令，不支持认证与 STARTTLS，客户端在服务器不声明这些扩展时会跳过它们。
+package smtptest
+
+import (
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 服务器收到的一封邮件
+type Message struct {
+	From string   // 发件人
+	To   []string // 收件人
+	Data string   // 包括邮件头在内的原始内容
+}
+
+// Server 本地 SMTP 服务器
+//
+// 字段:
+// - Host/Port: 监听的地址与端口，用于配置 SMTP 客户端
+// - RejectRecipient: 返回 true 时以 550 拒绝该收件人，用于模拟发送失败，为 nil 时接收所有收件人，需要在发送邮件之前设置
+type Server struct {
+	Host            string
+	Port            uint16
+	RejectRecipient func(recipient string) bool
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+	received chan Message
+	wg       sync.WaitGroup
+}
+
+// NewServer 在 127.0.0.1 的随机端口上启动服务器，使用完后需要调用 Close
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	s := &Server{
+		Host:     addr.IP.String(),
+		Port:     uint16(addr.Port),
+		listener: listener,
+		received: make(chan Message, 100),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr 返回服务器的监听地址
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(int(s.Port)))
+}
+
+// Close 停止接收新的连接并等待已有的连接结束
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+// Messages 返回目前收到的所有邮件
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// WaitMessage 等待下一封邮件，超时返回 false
+func (s *Server) WaitMessage(timeout time.Duration) (Message, bool) {
+	select {
+	case msg := <-s.received:
+		return msg, true
+	case <-time.After(timeout):
+		return Message{}, false
+	}
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle 处理一个连接上的 SMTP 会话
+func (s *Server) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	tp := textproto.NewConn(conn)
+	reply := func(code int, msg string) bool {
+		return tp.PrintfLine("%d %s", code, msg) == nil
+	}
+	if !reply(220, "localhost ESMTP smtptest") {
+		return
+	}
+
+	var msg Message
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		ok := true
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			ok = reply(250, "localhost")
+		case "MAIL":
+			msg = Message{From: address(arg)}
+			ok = reply(250, "OK")
+		case "RCPT":
+			recipient := address(arg)
+			if s.RejectRecipient != nil && s.RejectRecipient(recipient) {
+				ok = reply(550, "mailbox unavailable")
+				break
+			}
+			msg.To = append(msg.To, recipient)
+			ok = reply(250, "OK")
+		case "DATA":
+			if len(msg.To) == 0 {
+				ok = reply(503, "need RCPT command")
+				break
+			}
+			if !reply(354, "end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			select {
+			case s.received <- msg:
+			default:
+			}
+			msg = Message{}
+			ok = reply(250, "OK")
+		case "RSET":
+			msg = Message{}
+			ok = reply(250, "OK")
+		case "NOOP":
+			ok = reply(250, "OK")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			ok = reply(502, "command not implemented")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// address 从 "FROM:<a@b.c>" 或 "TO:<a@b.c>" 形式的参数中取出邮箱地址
+func address(arg string) string {
+	_, addr, found := strings.Cut(arg, ":")
+	if !found {
+		addr = arg
+	}
+	addr = strings.TrimSpace(addr)
+	if end := strings.IndexByte(addr, ' '); end >= 0 {
+		addr = addr[:end]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "<"), ">")
+}
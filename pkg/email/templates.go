@@ -1,392 +1,388 @@
 package email
 
-// VerificationCodeTemplate 验证码邮件的HTML模板
-const VerificationCodeTemplate = `
-<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Sparrow Blog 验证码</title>
-    <style>
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif;
-            background-color: #f5f7fa;
-            color: #333;
-            margin: 0;
-            padding: 0;
-            -webkit-font-smoothing: antialiased;
-        }
-        .container {
-            max-width: 600px;
-            margin: 40px auto;
-            background: linear-gradient(135deg, #ffffff, #f5f7fa);
-            padding: 40px 30px;
-            border-radius: 16px;
-            box-shadow: 0 10px 25px rgba(0, 0, 0, 0.05);
-            border: 1px solid rgba(0, 0, 0, 0.05);
-        }
-        .logo {
-            text-align: center;
-            margin-bottom: 30px;
-        }
-        .logo img {
-            height: 50px;
-        }
-        h1 {
-            color: #2d3748;
-            text-align: center;
-            font-size: 24px;
-            font-weight: 600;
-            margin-bottom: 30px;
-        }
-        .code-container {
-            background-color: #f8fafc;
-            border: 1px dashed #cbd5e0;
-            border-radius: 8px;
-            padding: 20px;
-            text-align: center;
-            margin: 25px 0;
-        }
-        .verification-code {
-            font-family: 'Courier New', monospace;
-            font-size: 28px;
-            font-weight: bold;
-            color: #3182ce;
-            letter-spacing: 2px;
-            word-break: break-all;
-            line-height: 1.4;
-            text-align: center;
-        }
-        .message {
-            text-align: center;
-            color: #718096;
-            font-size: 16px;
-            margin: 25px 0;
-            line-height: 1.6;
-        }
-        @media (max-width: 600px) {
-            .container {
-                margin: 20px auto;
-                padding: 25px 15px;
-            }
-            .verification-code {
-                font-size: 22px;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="logo">
-            <!-- 替换为实际的博客logo -->
-            <h2 style="color: #3182ce;">Sparrow Blog</h2>
-        </div>
-        
-        <h1>验证您的邮箱</h1>
-        
-        <div class="message">
-            请使用以下验证码完成邮箱验证流程：
-        </div>
-        
-        <div class="code-container">
-            <div class="verification-code">{{.Code}}</div>
-        </div>
-        
-        <div class="message">
-            <strong>此验证码将在 5 分钟内有效</strong><br>
-            请勿将验证码泄露给他人，以确保您的账户安全。
-        </div>
-    </div>
-</body>
-</html>
-`
-
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"strings"
+	texttemplate "text/template"
+)
 
+// builtinTemplates 内置的邮件模板，目录结构为 templates/<语言>/<模板名称>.<扩展名>
+//
+//go:embed templates
+var builtinTemplates embed.FS
 
-// CommentNotificationTemplate 评论通知邮件的HTML模板
-const CommentNotificationTemplate = `
-<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Sparrow Blog 评论通知</title>
-    <style>
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif;
-            background-color: #f5f7fa;
-            color: #333;
-            margin: 0;
-            padding: 0;
-            -webkit-font-smoothing: antialiased;
-        }
-        .container {
-            max-width: 600px;
-            margin: 40px auto;
-            background: linear-gradient(135deg, #ffffff, #f5f7fa);
-            padding: 40px 30px;
-            border-radius: 16px;
-            box-shadow: 0 10px 25px rgba(0, 0, 0, 0.05);
-            border: 1px solid rgba(0, 0, 0, 0.05);
-        }
-        .logo {
-            text-align: center;
-            margin-bottom: 30px;
-        }
-        h1 {
-            color: #2d3748;
-            text-align: center;
-            font-size: 24px;
-            font-weight: 600;
-            margin-bottom: 30px;
-        }
-        .comment-container {
-            background-color: #f8fafc;
-            border: 1px solid #e2e8f0;
-            border-radius: 12px;
-            padding: 25px;
-            margin: 25px 0;
-            box-shadow: 0 2px 4px rgba(0, 0, 0, 0.05);
-        }
-        .comment-header {
-            margin-bottom: 15px;
-            padding-bottom: 10px;
-            border-bottom: 1px solid #e2e8f0;
-        }
-        .commenter-email {
-            font-size: 16px;
-            font-weight: 600;
-            color: #3182ce;
-            margin: 0 0 5px 0;
-        }
-        .blog-title {
-            font-size: 18px;
-            font-weight: 600;
-            color: #2d3748;
-            margin: 0 0 10px 0;
-        }
-        .comment-content {
-            background-color: #ffffff;
-            padding: 20px;
-            border-radius: 8px;
-            color: #4a5568;
-            line-height: 1.6;
-            border-left: 4px solid #48bb78;
-            margin-top: 15px;
-            font-size: 16px;
-        }
-        .comment-time {
-            color: #718096;
-            font-size: 14px;
-            margin-top: 10px;
-            text-align: right;
-        }
-        .message {
-            text-align: center;
-            color: #718096;
-            font-size: 16px;
-            margin: 25px 0;
-            line-height: 1.6;
-        }
-        @media (max-width: 600px) {
-            .container {
-                margin: 20px auto;
-                padding: 25px 15px;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="logo">
-            <h2 style="color: #3182ce;">Sparrow Blog</h2>
-        </div>
-        
-        <h1>💬 收到新评论</h1>
-        
-        <div class="message">
-            您好！您的博客收到了一条新评论：
-        </div>
-        
-        <div class="comment-container">
-            <div class="comment-header">
-                <div class="commenter-email">👤 {{.CommenterEmail}}</div>
-                <div class="blog-title">📝 {{.BlogTitle}}</div>
-            </div>
-            
-            <div class="comment-content">
-                {{.Content}}
-            </div>
-            
-            <div class="comment-time">
-                ⏰ {{.CreateTime}}
-            </div>
-        </div>
-        
-        <div class="message">
-            感谢读者对您博客的关注和互动！<br>
-            <strong>让我们一起创造更好的内容！✨</strong>
-        </div>
-    </div>
-</body>
-</html>
-`
+// 邮件模板名称
+const (
+	TemplateVerificationCode    = "verification_code"    // 验证码邮件
+	TemplateCommentNotification = "comment_notification" // 评论通知邮件
+	TemplateReplyNotification   = "reply_notification"   // 回复通知邮件
+)
 
-// ReplyNotificationTemplate 回复通知邮件的HTML模板
-const ReplyNotificationTemplate = `
-<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Sparrow Blog 回复通知</title>
-    <style>
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif;
-            background-color: #f5f7fa;
-            color: #333;
-            margin: 0;
-            padding: 0;
-            -webkit-font-smoothing: antialiased;
-        }
-        .container {
-            max-width: 600px;
-            margin: 40px auto;
-            background: linear-gradient(135deg, #ffffff, #f5f7fa);
-            padding: 40px 30px;
-            border-radius: 16px;
-            box-shadow: 0 10px 25px rgba(0, 0, 0, 0.05);
-            border: 1px solid rgba(0, 0, 0, 0.05);
-        }
-        .logo {
-            text-align: center;
-            margin-bottom: 30px;
-        }
-        h1 {
-            color: #2d3748;
-            text-align: center;
-            font-size: 24px;
-            font-weight: 600;
-            margin-bottom: 30px;
-        }
-        .reply-container {
-            background-color: #f8fafc;
-            border: 1px solid #e2e8f0;
-            border-radius: 12px;
-            padding: 25px;
-            margin: 25px 0;
-            box-shadow: 0 2px 4px rgba(0, 0, 0, 0.05);
-        }
-        .reply-header {
-            margin-bottom: 15px;
-            padding-bottom: 10px;
-            border-bottom: 1px solid #e2e8f0;
-        }
-        .replier-email {
-            font-size: 16px;
-            font-weight: 600;
-            color: #3182ce;
-            margin: 0 0 5px 0;
-        }
-        .blog-title {
-            font-size: 18px;
-            font-weight: 600;
-            color: #2d3748;
-            margin: 0 0 10px 0;
-        }
-        .original-comment {
-            background-color: #f0f4f8;
-            padding: 15px;
-            border-radius: 8px;
-            color: #4a5568;
-            line-height: 1.6;
-            border-left: 4px solid #90cdf4;
-            margin: 15px 0;
-            font-size: 14px;
-        }
-        .original-comment-label {
-            font-size: 12px;
-            color: #718096;
-            margin-bottom: 8px;
-            font-weight: 600;
-        }
-        .reply-content {
-            background-color: #ffffff;
-            padding: 20px;
-            border-radius: 8px;
-            color: #4a5568;
-            line-height: 1.6;
-            border-left: 4px solid #ed8936;
-            margin-top: 15px;
-            font-size: 16px;
-        }
-        .reply-time {
-            color: #718096;
-            font-size: 14px;
-            margin-top: 10px;
-            text-align: right;
-        }
-        .message {
-            text-align: center;
-            color: #718096;
-            font-size: 16px;
-            margin: 25px 0;
-            line-height: 1.6;
-        }
-        @media (max-width: 600px) {
-            .container {
-                margin: 20px auto;
-                padding: 25px 15px;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="logo">
-            <h2 style="color: #3182ce;">Sparrow Blog</h2>
-        </div>
-        
-        <h1>🔄 收到新回复</h1>
-        
-        <div class="message">
-            您好！有人回复了您的评论：
-        </div>
-        
-        <div class="reply-container">
-            <div class="reply-header">
-                <div class="replier-email">👤 {{.ReplierEmail}}</div>
-                <div class="blog-title">📝 {{.BlogTitle}}</div>
-            </div>
-            
-            {{if .OriginalContent}}
-            <div class="original-comment">
-                <div class="original-comment-label">您的原评论：</div>
-                {{.OriginalContent}}
-            </div>
-            {{end}}
-            
-            <div class="reply-content">
-                {{.ReplyContent}}
-            </div>
-            
-            <div class="reply-time">
-                ⏰ {{.CreateTime}}
-            </div>
-        </div>
-        
-        <div class="message">
-            快去看看这条回复，继续精彩的讨论吧！<br>
-            <strong>互动让博客更有趣！🎉</strong>
-        </div>
-    </div>
-</body>
-</html>
-`
+// 支持的邮件语言
+const (
+	LocaleZhCN    = "zh-CN"
+	LocaleEnUS    = "en-US"
+	DefaultLocale = LocaleZhCN // 指定语言的模板不存在时回退到的语言
+)
 
+// 模板各部分的文件扩展名，一个模板由主题、HTML 正文与纯文本正文三个文件组成
 const (
-	VerificationCodeSubject    = "博客验证码"
-	CommentNotificationSubject = "收到新评论"
-	ReplyNotificationSubject   = "收到新回复"
+	subjectExt = ".subject" // 主题，使用 text/template
+	htmlExt    = ".html"    // HTML 正文，使用 html/template
+	textExt    = ".txt"     // 纯文本正文，使用 text/template
+)
+
+var (
+	templateNames = []string{TemplateVerificationCode, TemplateCommentNotification, TemplateReplyNotification}
+	locales       = []string{LocaleZhCN, LocaleEnUS}
 )
+
+// VerificationCodeData 验证码邮件模板的数据
+type VerificationCodeData struct {
+	Code string // 验证码
+}
+
+// TemplateSource 一个邮件模板的源码
+type TemplateSource struct {
+	Subject string `json:"subject"` // 主题，渲染后多行会合并为一行
+	HTML    string `json:"html"`    // HTML 正文
+	Text    string `json:"text"`    // 纯文本正文，作为不显示 HTML 的邮件客户端的替代内容
+}
+
+// TemplateInfo 邮件模板的信息
+type TemplateInfo struct {
+	Name       string `json:"name"`       // 模板名称
+	Locale     string `json:"locale"`     // 语言
+	Overridden bool   `json:"overridden"` // 模板目录中是否有覆盖内置模板的文件
+}
+
+// TemplateNames 返回所有邮件模板的名称
+func TemplateNames() []string {
+	return slices.Clone(templateNames)
+}
+
+// Locales 返回支持的邮件语言
+func Locales() []string {
+	return slices.Clone(locales)
+}
+
+// ResolveLocale 返回支持的语言，为空或不支持时返回默认语言
+func ResolveLocale(locale string) string {
+	if slices.Contains(locales, locale) {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// templateDir 返回存放覆盖模板的目录
+func templateDir() (string, error) {
+	if config.Email.TemplatePath != "" {
+		return config.Email.TemplatePath, nil
+	}
+	projDir, err := config.ProjDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projDir, "templates", "email"), nil
+}
+
+// checkTemplate 检查模板名称与语言是否有效，语言必须是支持的语言之一
+func checkTemplate(name, locale string) error {
+	if !slices.Contains(templateNames, name) {
+		return fmt.Errorf("邮件模板不存在: %s", name)
+	}
+	if !slices.Contains(locales, locale) {
+		return fmt.Errorf("不支持的邮件语言: %s，支持 %s", locale, strings.Join(locales, "、"))
+	}
+	return nil
+}
+
+// loadPart 读取模板的一个部分
+// 依次查找指定语言与默认语言，每种语言先查找模板目录中的覆盖文件，再查找内置模板，
+// 因此覆盖了中文模板后，英文邮件仍然使用内置的英文模板。
+func loadPart(dir, name, locale, ext string) (string, bool, error) {
+	chain := []string{locale}
+	if locale != DefaultLocale {
+		chain = append(chain, DefaultLocale)
+	}
+
+	for _, loc := range chain {
+		if dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, loc, name+ext))
+			if err == nil {
+				return string(data), true, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", false, fmt.Errorf("读取邮件模板 %s/%s%s 失败: %w", loc, name, ext, err)
+			}
+		}
+		data, err := builtinTemplates.ReadFile(path.Join("templates", loc, name+ext))
+		if err == nil {
+			return string(data), false, nil
+		}
+	}
+	return "", false, fmt.Errorf("邮件模板 %s%s 不存在", name, ext)
+}
+
+// LoadTemplate 读取生效的邮件模板源码
+// 模板每次使用时从磁盘读取，直接修改模板目录中的文件也会在下一封邮件生效，不需要重启服务。
+//
+// 参数:
+//   - name: 模板名称
+//   - locale: 语言
+//
+// 返回值:
+//   - *TemplateSource: 模板源码
+//   - bool: 是否有任何部分来自模板目录中的覆盖文件
+//   - error: 模板名称或语言无效、读取失败时返回错误信息
+func LoadTemplate(name, locale string) (*TemplateSource, bool, error) {
+	if err := checkTemplate(name, locale); err != nil {
+		return nil, false, err
+	}
+	dir, err := templateDir()
+	if err != nil {
+		return nil, false, err
+	}
+
+	src := &TemplateSource{}
+	overridden := false
+	for _, part := range []struct {
+		ext string
+		dst *string
+	}{{subjectExt, &src.Subject}, {htmlExt, &src.HTML}, {textExt, &src.Text}} {
+		content, fromDir, err := loadPart(dir, name, locale, part.ext)
+		if err != nil {
+			return nil, false, err
+		}
+		*part.dst = content
+		overridden = overridden || fromDir
+	}
+	return src, overridden, nil
+}
+
+// ListTemplates 列出所有模板在每种语言下的信息
+//
+// 返回值:
+//   - []TemplateInfo: 模板信息，按模板名称与语言排列
+//   - error: 读取模板目录失败时返回错误信息
+func ListTemplates() ([]TemplateInfo, error) {
+	dir, err := templateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TemplateInfo, 0, len(templateNames)*len(locales))
+	for _, name := range templateNames {
+		for _, locale := range locales {
+			info := TemplateInfo{Name: name, Locale: locale}
+			for _, ext := range []string{subjectExt, htmlExt, textExt} {
+				if _, err := os.Stat(filepath.Join(dir, locale, name+ext)); err == nil {
+					info.Overridden = true
+				} else if !errors.Is(err, fs.ErrNotExist) {
+					return nil, err
+				}
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// renderSource 使用数据渲染模板源码，返回的邮件不包含收件人
+func renderSource(name string, src *TemplateSource, data any) (*Message, error) {
+	subjectTmpl, err := texttemplate.New(name + subjectExt).Parse(src.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("解析邮件主题模板失败: %w", err)
+	}
+	htmlTmpl, err := htmltemplate.New(name + htmlExt).Parse(src.HTML)
+	if err != nil {
+		return nil, fmt.Errorf("解析 HTML 邮件模板失败: %w", err)
+	}
+	textTmpl, err := texttemplate.New(name + textExt).Parse(src.Text)
+	if err != nil {
+		return nil, fmt.Errorf("解析纯文本邮件模板失败: %w", err)
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("渲染邮件主题失败: %w", err)
+	}
+	if err := htmlTmpl.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("渲染 HTML 邮件正文失败: %w", err)
+	}
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("渲染纯文本邮件正文失败: %w", err)
+	}
+
+	// 主题是邮件头，不能包含换行
+	msg := &Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Content: html.String(),
+		Text:    text.String(),
+	}
+	if msg.Subject == "" {
+		return nil, errors.New("邮件主题不能为空")
+	}
+	return msg, nil
+}
+
+// renderTemplate 使用配置的语言渲染邮件模板，返回的邮件不包含收件人
+func renderTemplate(name string, data any) (*Message, error) {
+	src, _, err := LoadTemplate(name, ResolveLocale(config.Email.Locale))
+	if err != nil {
+		return nil, err
+	}
+	return renderSource(name, src, data)
+}
+
+// sampleData 返回用于预览与校验模板的示例数据，类型与实际发送时相同
+func sampleData(name string) any {
+	switch name {
+	case TemplateVerificationCode:
+		return VerificationCodeData{Code: "8f3a1c9e2b7d4f6a0c5e"}
+	case TemplateCommentNotification:
+		return CommentData{
+			CommenterEmail: "reader@example.com",
+			BlogTitle:      "Sparrow Blog",
+			Content:        "Great post! <script>alert(1)</script>",
+			CreateTime:     "2024-06-24 10:30:00",
+		}
+	default:
+		return ReplyData{
+			ReplierEmail:    "replier@example.com",
+			BlogTitle:       "Sparrow Blog",
+			OriginalContent: "Great post!",
+			ReplyContent:    "Thanks, glad you liked it.",
+			CreateTime:      "2024-06-24 11:15:00",
+			UnsubscribeUrl:  "https://example.com/web/comment/unsubscribe?token=sample",
+		}
+	}
+}
+
+// PreviewTemplate 使用示例数据渲染模板
+// 参数:
+//   - name: 模板名称
+//   - locale: 语言
+//   - src: 待预览的模板源码，为 nil 时预览当前生效的模板，某个部分为空时使用当前生效的内容
+//
+// 返回值:
+//   - *Message: 渲染后的邮件，不包含收件人
+//   - error: 模板无效时返回错误信息
+func PreviewTemplate(name, locale string, src *TemplateSource) (*Message, error) {
+	current, _, err := LoadTemplate(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	if src != nil {
+		if src.Subject != "" {
+			current.Subject = src.Subject
+		}
+		if src.HTML != "" {
+			current.HTML = src.HTML
+		}
+		if src.Text != "" {
+			current.Text = src.Text
+		}
+	}
+	return renderSource(name, current, sampleData(name))
+}
+
+// SaveTemplate 校验模板后保存到模板目录，覆盖内置模板
+// 模板必须能使用示例数据渲染成功，引用不存在的字段等错误在保存前就会被发现。
+//
+// 参数:
+//   - name: 模板名称
+//   - locale: 语言
+//   - src: 模板源码，三个部分都不能为空
+//
+// 返回值:
+//   - error: 模板无效或写入失败时返回错误信息
+func SaveTemplate(name, locale string, src TemplateSource) error {
+	if err := checkTemplate(name, locale); err != nil {
+		return err
+	}
+	if strings.TrimSpace(src.Subject) == "" || strings.TrimSpace(src.HTML) == "" || strings.TrimSpace(src.Text) == "" {
+		return errors.New("邮件模板的主题、HTML 正文与纯文本正文都不能为空")
+	}
+	if _, err := renderSource(name, &src, sampleData(name)); err != nil {
+		return err
+	}
+
+	dir, err := templateDir()
+	if err != nil {
+		return err
+	}
+	localeDir := filepath.Join(dir, locale)
+	if err := os.MkdirAll(localeDir, 0755); err != nil {
+		msg := fmt.Sprintf("创建邮件模板目录失败: %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	for ext, content := range map[string]string{subjectExt: src.Subject, htmlExt: src.HTML, textExt: src.Text} {
+		if err := writeFileAtomic(filepath.Join(localeDir, name+ext), []byte(content)); err != nil {
+			msg := fmt.Sprintf("保存邮件模板 %s/%s%s 失败: %v", locale, name, ext, err)
+			logger.Error(msg)
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// ResetTemplate 删除模板目录中的覆盖文件，恢复使用内置模板
+// 参数:
+//   - name: 模板名称
+//   - locale: 语言
+//
+// 返回值:
+//   - error: 模板名称或语言无效、删除失败时返回错误信息
+func ResetTemplate(name, locale string) error {
+	if err := checkTemplate(name, locale); err != nil {
+		return err
+	}
+	dir, err := templateDir()
+	if err != nil {
+		return err
+	}
+	for _, ext := range []string{subjectExt, htmlExt, textExt} {
+		if err := os.Remove(filepath.Join(dir, locale, name+ext)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			msg := fmt.Sprintf("删除邮件模板 %s/%s%s 失败: %v", locale, name, ext, err)
+			logger.Error(msg)
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic 将内容写入同目录下的临时文件后重命名为目标文件，避免发送邮件时读到写了一半的模板
+func writeFileAtomic(p string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".template-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, p)
+}
@@ -0,0 +1,152 @@
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"sparrow_blog_server/pkg/config"
+	"strings"
+	"testing"
+)
+
+func TestTemplates_LocaleFallback(t *testing.T) {
+	oldEmail := config.Email
+	config.Email = config.EmailConfig{TemplatePath: t.TempDir()}
+	defer func() { config.Email = oldEmail }()
+
+	for _, name := range TemplateNames() {
+		for _, locale := range Locales() {
+			if _, err := PreviewTemplate(name, locale, nil); err != nil {
+				t.Errorf("内置模板 %s/%s 无法渲染: %v", locale, name, err)
+			}
+		}
+	}
+
+	// 未配置语言时使用中文模板
+	msg, err := renderTemplate(TemplateCommentNotification, sampleData(TemplateCommentNotification))
+	if err != nil {
+		t.Fatalf("渲染模板失败: %v", err)
+	}
+	if msg.Subject != "收到新评论" || !strings.Contains(msg.Content, `lang="zh-CN"`) {
+		t.Errorf("期望使用中文模板，实际主题为 %q", msg.Subject)
+	}
+
+	// 模板负责转义：HTML 正文转义评论内容，纯文本正文保留原文
+	if strings.Contains(msg.Content, "<script>") || !strings.Contains(msg.Content, "&lt;script&gt;") {
+		t.Error("HTML 正文应当转义评论内容")
+	}
+	if !strings.Contains(msg.Text, "<script>alert(1)</script>") || strings.Contains(msg.Text, "&lt;") {
+		t.Error("纯文本正文不应转义评论内容")
+	}
+
+	config.Email.Locale = LocaleEnUS
+	msg, _ = renderTemplate(TemplateReplyNotification, sampleData(TemplateReplyNotification))
+	if msg.Subject != "New reply to your comment" || !strings.Contains(msg.Text, "Your comment:") {
+		t.Errorf("期望使用英文模板，实际主题为 %q", msg.Subject)
+	}
+
+	// 不支持的语言回退到默认语言
+	config.Email.Locale = "fr-FR"
+	msg, _ = renderTemplate(TemplateVerificationCode, VerificationCodeData{Code: "abc"})
+	if msg.Subject != "博客验证码" {
+		t.Errorf("不支持的语言应当回退到中文模板，实际主题为 %q", msg.Subject)
+	}
+	if _, _, err := LoadTemplate(TemplateVerificationCode, "fr-FR"); err == nil {
+		t.Error("直接读取不支持的语言应当返回错误")
+	}
+	if _, _, err := LoadTemplate("unknown", LocaleZhCN); err == nil {
+		t.Error("不存在的模板应当返回错误")
+	}
+}
+
+func TestTemplates_SaveAndReset(t *testing.T) {
+	dir := t.TempDir()
+	oldEmail := config.Email
+	config.Email = config.EmailConfig{TemplatePath: dir, Locale: LocaleEnUS}
+	defer func() { config.Email = oldEmail }()
+
+	src := TemplateSource{
+		Subject: "Code\n{{.Code}}",
+		HTML:    "<p>{{.Code}}</p>",
+		Text:    "code: {{.Code}}",
+	}
+
+	// 引用不存在的字段、语法错误与缺少部分的模板都不能保存
+	invalid := []TemplateSource{
+		{Subject: src.Subject, HTML: "<p>{{.Missing}}</p>", Text: src.Text},
+		{Subject: src.Subject, HTML: "<p>{{.Code</p>", Text: src.Text},
+		{Subject: src.Subject, HTML: src.HTML},
+	}
+	for _, bad := range invalid {
+		if err := SaveTemplate(TemplateVerificationCode, LocaleEnUS, bad); err == nil {
+			t.Errorf("无效的模板不应保存: %+v", bad)
+		}
+	}
+	if err := SaveTemplate(TemplateVerificationCode, "fr-FR", src); err == nil {
+		t.Error("不支持的语言不应保存")
+	}
+	if _, err := os.Stat(filepath.Join(dir, LocaleEnUS)); !os.IsNotExist(err) {
+		t.Fatal("校验失败时不应写入任何文件")
+	}
+
+	// 预览未保存的模板不影响生效的模板
+	preview, err := PreviewTemplate(TemplateVerificationCode, LocaleEnUS, &TemplateSource{HTML: "<b>{{.Code}}</b>"})
+	if err != nil {
+		t.Fatalf("预览模板失败: %v", err)
+	}
+	if preview.Subject != "Blog verification code" || !strings.HasPrefix(preview.Content, "<b>") {
+		t.Errorf("预览应当只替换提供的部分: %+v", preview)
+	}
+
+	if err := SaveTemplate(TemplateVerificationCode, LocaleEnUS, src); err != nil {
+		t.Fatalf("保存模板失败: %v", err)
+	}
+	msg, err := renderTemplate(TemplateVerificationCode, VerificationCodeData{Code: "abc"})
+	if err != nil {
+		t.Fatalf("渲染模板失败: %v", err)
+	}
+	if msg.Subject != "Code abc" || msg.Content != "<p>abc</p>" || msg.Text != "code: abc" {
+		t.Errorf("期望使用保存的模板且主题合并为一行，实际得到 %+v", msg)
+	}
+
+	infos, err := ListTemplates()
+	if err != nil {
+		t.Fatalf("列出模板失败: %v", err)
+	}
+	for _, info := range infos {
+		want := info.Name == TemplateVerificationCode && info.Locale == LocaleEnUS
+		if info.Overridden != want {
+			t.Errorf("模板 %s/%s 的覆盖状态错误", info.Locale, info.Name)
+		}
+	}
+
+	// 覆盖了英文模板后，中文邮件仍然使用内置模板
+	if src, overridden, _ := LoadTemplate(TemplateVerificationCode, LocaleZhCN); overridden || !strings.Contains(src.HTML, "验证您的邮箱") {
+		t.Error("中文模板不应受英文覆盖模板的影响")
+	}
+
+	if err := ResetTemplate(TemplateVerificationCode, LocaleEnUS); err != nil {
+		t.Fatalf("恢复内置模板失败: %v", err)
+	}
+	if _, overridden, _ := LoadTemplate(TemplateVerificationCode, LocaleEnUS); overridden {
+		t.Error("恢复后应当使用内置模板")
+	}
+}
+
+func TestTemplates_PlainTextAlternative(t *testing.T) {
+	srv := startFakeSmtp(t)
+
+	msg, err := renderTemplate(TemplateCommentNotification, sampleData(TemplateCommentNotification))
+	if err != nil {
+		t.Fatalf("渲染模板失败: %v", err)
+	}
+	msg.To = "alt@example.com"
+	if err := Deliver(*msg); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+
+	received := expectMessage(t, srv, "alt@example.com")
+	if !strings.Contains(received.Data, "multipart/alternative") ||
+		!strings.Contains(received.Data, "text/plain") || !strings.Contains(received.Data, "text/html") {
+		t.Error("邮件应当同时包含纯文本与 HTML 正文")
+	}
+}
@@ -0,0 +1,253 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+// Feed 订阅源，与具体格式无关，可以输出为 RSS 2.0、Atom 1.0 与 JSON Feed 1.1
+type Feed struct {
+	Title       string    // 订阅源标题
+	Link        string    // 网站地址
+	FeedUrl     string    // 订阅源自身的地址
+	Description string    // 订阅源描述
+	AuthorName  string    // 作者名称
+	AuthorEmail string    // 作者邮箱
+	Updated     time.Time // 最近更新时间
+	Items       []Item    // 条目，按发布时间倒序排列
+}
+
+// Item 订阅源中的一个条目
+type Item struct {
+	Id         string    // 条目唯一标识
+	Title      string    // 标题
+	Link       string    // 文章地址
+	Summary    string    // 摘要，纯文本
+	Content    string    // 全文 HTML，为空时只输出摘要
+	Categories []string  // 分类与标签
+	Published  time.Time // 发布时间
+	Updated    time.Time // 更新时间
+}
+
+// rss RSS 2.0 文档
+type rss struct {
+	XMLName     xml.Name  `xml:"rss"`
+	Version     string    `xml:"version,attr"`
+	AtomNS      string    `xml:"xmlns:atom,attr"`
+	ContentNS   string    `xml:"xmlns:content,attr"`
+	Title       string    `xml:"channel>title"`
+	Link        string    `xml:"channel>link"`
+	Description string    `xml:"channel>description"`
+	SelfLink    atomLink  `xml:"channel>atom:link"`
+	LastBuild   string    `xml:"channel>lastBuildDate,omitempty"`
+	Items       []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// atom Atom 1.0 文档
+type atom struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	Id         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// jsonFeed JSON Feed 1.1 文档
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageUrl string         `json:"home_page_url,omitempty"`
+	FeedUrl     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Authors     []jsonAuthor   `json:"authors,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	Id            string   `json:"id"`
+	Url           string   `json:"url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ContentHtml   string   `json:"content_html,omitempty"`
+	ContentText   string   `json:"content_text,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// RSS 输出 RSS 2.0 格式
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version:     "2.0",
+		AtomNS:      "http://www.w3.org/2005/Atom",
+		ContentNS:   "http://purl.org/rss/1.0/modules/content/",
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		SelfLink:    atomLink{Href: f.FeedUrl, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(f.Items)),
+	}
+	if !f.Updated.IsZero() {
+		doc.LastBuild = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		doc.Items = append(doc.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Guid:        rssGuid{IsPermaLink: false, Value: item.Id},
+			Description: item.Summary,
+			Content:     item.Content,
+			Categories:  item.Categories,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalXML(doc)
+}
+
+// Atom 输出 Atom 1.0 格式
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atom{
+		Title:   f.Title,
+		Id:      f.FeedUrl,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedUrl, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author:  atomAuthor{Name: f.AuthorName, Email: f.AuthorEmail},
+		Entries: make([]atomEntry, 0, len(f.Items)),
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			Id:        item.Link,
+			Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// JSON 输出 JSON Feed 1.1 格式
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageUrl: f.Link,
+		FeedUrl:     f.FeedUrl,
+		Description: f.Description,
+		Items:       make([]jsonFeedItem, 0, len(f.Items)),
+	}
+	if f.AuthorName != "" {
+		doc.Authors = []jsonAuthor{{Name: f.AuthorName, Url: f.Link}}
+	}
+
+	for _, item := range f.Items {
+		jsonItem := jsonFeedItem{
+			Id:            item.Id,
+			Url:           item.Link,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		// JSON Feed 要求 content_html 与 content_text 至少有一个
+		if item.Content != "" {
+			jsonItem.ContentHtml = item.Content
+		} else {
+			jsonItem.ContentText = item.Summary
+		}
+		doc.Items = append(doc.Items, jsonItem)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成 JSON Feed 失败: %w", err)
+	}
+	return data, nil
+}
+
+func marshalXML(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成订阅源失败: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
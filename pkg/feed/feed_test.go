@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &Feed{
+		Title:       "测试博客",
+		Link:        "https://example.com",
+		FeedUrl:     "https://api.example.com/web/feed.xml",
+		Description: "测试博客的订阅源",
+		AuthorName:  "tester",
+		Updated:     published,
+		Items: []Item{
+			{
+				Id:         "blog001",
+				Title:      "C++ & Go",
+				Link:       "https://example.com/blog/blog001",
+				Summary:    "简介",
+				Content:    "<p>正文 <code>a &lt; b</code></p>",
+				Categories: []string{"编程", "Go"},
+				Published:  published,
+				Updated:    published,
+			},
+			{
+				Id:        "blog002",
+				Title:     "只有简介",
+				Link:      "https://example.com/blog/blog002",
+				Summary:   "只有简介的文章",
+				Published: published,
+				Updated:   published,
+			},
+		},
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	data, err := testFeed().RSS()
+	if err != nil {
+		t.Fatalf("生成 RSS 失败: %v", err)
+	}
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title       string   `xml:"title"`
+				Description string   `xml:"description"`
+				Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				Categories  []string `xml:"category"`
+				PubDate     string   `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("RSS 不是合法的 XML: %v\n%s", err, data)
+	}
+	if doc.Version != "2.0" || doc.Channel.Title != "测试博客" || len(doc.Channel.Items) != 2 {
+		t.Fatalf("RSS 内容错误:\n%s", data)
+	}
+	item := doc.Channel.Items[0]
+	if item.Title != "C++ & Go" || item.Content != "<p>正文 <code>a &lt; b</code></p>" || len(item.Categories) != 2 {
+		t.Fatalf("RSS 条目错误: %+v", item)
+	}
+	if item.PubDate != "Thu, 02 Jan 2025 03:04:05 +0000" {
+		t.Fatalf("RSS 发布时间格式错误: %v", item.PubDate)
+	}
+	if strings.Contains(string(data), "<content:encoded></content:encoded>") {
+		t.Fatal("没有全文时不应输出 content:encoded")
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	data, err := testFeed().Atom()
+	if err != nil {
+		t.Fatalf("生成 Atom 失败: %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			Id      string `xml:"id"`
+			Content struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Atom 不是合法的 XML: %v\n%s", err, data)
+	}
+	if doc.Updated != "2025-01-02T03:04:05Z" || len(doc.Entries) != 2 {
+		t.Fatalf("Atom 内容错误:\n%s", data)
+	}
+	if doc.Entries[0].Content.Type != "html" || !strings.Contains(doc.Entries[0].Content.Value, "<code>") {
+		t.Fatalf("Atom 全文错误: %+v", doc.Entries[0])
+	}
+}
+
+func TestFeed_JSON(t *testing.T) {
+	data, err := testFeed().JSON()
+	if err != nil {
+		t.Fatalf("生成 JSON Feed 失败: %v", err)
+	}
+
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("JSON Feed 格式错误: %v", err)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || len(doc.Items) != 2 {
+		t.Fatalf("JSON Feed 内容错误: %s", data)
+	}
+	if doc.Items[0].ContentHtml == "" || doc.Items[1].ContentText != "只有简介的文章" {
+		t.Fatalf("JSON Feed 条目内容错误: %+v", doc.Items)
+	}
+}
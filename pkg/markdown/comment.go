@@ -0,0 +1,262 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// commentLinkRel 评论中链接的 rel 属性，告知搜索引擎这是用户生成的内容且不传递权重
+const commentLinkRel = "nofollow ugc"
+
+var (
+	// commentMd 评论使用的 Markdown 解析器，只支持段落、引用、代码、链接与强调，以及 @ 提及
+	// 不解析原始 HTML、标题、列表与表格，图片转为指向图片地址的链接
+	commentMd = goldmark.New(
+		goldmark.WithParser(parser.NewParser(
+			parser.WithBlockParsers(
+				util.Prioritized(parser.NewCodeBlockParser(), 500),
+				util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+				util.Prioritized(parser.NewBlockquoteParser(), 800),
+				util.Prioritized(parser.NewParagraphParser(), 1000),
+			),
+			parser.WithInlineParsers(
+				util.Prioritized(parser.NewCodeSpanParser(), 100),
+				util.Prioritized(parser.NewLinkParser(), 200),
+				util.Prioritized(parser.NewAutoLinkParser(), 300),
+				util.Prioritized(parser.NewEmphasisParser(), 500),
+				util.Prioritized(&mentionParser{}, 600),
+				util.Prioritized(extension.NewLinkifyParser(), 999),
+			),
+			parser.WithParagraphTransformers(
+				util.Prioritized(parser.LinkReferenceParagraphTransformer, 100),
+			),
+			parser.WithASTTransformers(util.Prioritized(&commentLinkTransformer{}, 100)),
+		)),
+		goldmark.WithRendererOptions(
+			html.WithHardWraps(),
+			renderer.WithNodeRenderers(util.Prioritized(&mentionRenderer{}, 500)),
+		),
+	)
+
+	// commentPolicy 评论的 HTML 白名单，只保留评论语法能产生的元素
+	commentPolicy = newCommentPolicy()
+
+	// mentionsKey 渲染评论时可以 @ 的评论者，保存在解析上下文中
+	mentionsKey = parser.NewContextKey()
+)
+
+// Mention 评论中可以 @ 的评论者
+type Mention struct {
+	Name      string // 评论者昵称，评论中写作 @昵称
+	CommentId string // 该评论者在楼中的评论 ID，用于前端跳转
+}
+
+// RenderComment 将评论的 Markdown 渲染为经过清理的 HTML
+// 支持段落、引用、行内代码与代码块、链接与强调，换行保留为 <br>；原始 HTML 按文本输出。
+// 链接只允许 http、https 与 mailto 地址，并带有 rel="nofollow ugc"。
+// 与 mentions 中昵称匹配的 @昵称 输出为 <span class="mention" data-comment-id="...">，其余 @ 保持原样。
+// 参数:
+//   - src: 评论内容
+//   - mentions: 可以 @ 的评论者，通常是同一楼中较早的评论者
+//
+// 返回值:
+//   - string: 可以直接嵌入页面的 HTML
+//   - error: 渲染失败时返回错误信息
+func RenderComment(src []byte, mentions []Mention) (string, error) {
+	// 昵称较长的优先匹配，避免 @张三丰 被识别为 @张三
+	candidates := make([]Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.Name != "" {
+			candidates = append(candidates, mention)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b Mention) int {
+		return len(b.Name) - len(a.Name)
+	})
+
+	pc := parser.NewContext()
+	pc.Set(mentionsKey, candidates)
+	doc := commentMd.Parser().Parse(text.NewReader(src), parser.WithContext(pc))
+
+	var buf bytes.Buffer
+	if err := commentMd.Renderer().Render(&buf, src, doc); err != nil {
+		return "", fmt.Errorf("渲染评论失败: %w", err)
+	}
+
+	return commentPolicy.Sanitize(buf.String()), nil
+}
+
+// newCommentPolicy 只允许评论语法产生的元素与属性，不允许图片、标题、表格等
+func newCommentPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "em", "strong", "code", "pre", "blockquote")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.RequireParseableURLs(true)
+	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^nofollow ugc$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.\-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^mention$`)).OnElements("span")
+	p.AllowAttrs("data-comment-id").Matching(regexp.MustCompile(`^[\w\-]+$`)).OnElements("span")
+	return p
+}
+
+// commentLinkTransformer 为链接加上 rel 属性，将图片替换为指向图片地址的链接，
+// 地址不是 http、https 或 mailto 的链接与图片只保留文字
+type commentLinkTransformer struct{}
+
+func (t *commentLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var links []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.AutoLink:
+			node.SetAttributeString("rel", []byte(commentLinkRel))
+		case *ast.Link, *ast.Image:
+			links = append(links, node)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, node := range links {
+		parent := node.Parent()
+		var destination, title []byte
+		switch n := node.(type) {
+		case *ast.Link:
+			destination, title = n.Destination, n.Title
+		case *ast.Image:
+			destination, title = n.Destination, n.Title
+		}
+
+		if !isCommentUrl(destination) {
+			for child := node.FirstChild(); child != nil; {
+				next := child.NextSibling()
+				parent.InsertBefore(parent, node, child)
+				child = next
+			}
+			parent.RemoveChild(parent, node)
+			continue
+		}
+
+		link, ok := node.(*ast.Link)
+		if !ok {
+			link = ast.NewLink()
+			link.Destination = destination
+			link.Title = title
+			for child := node.FirstChild(); child != nil; {
+				next := child.NextSibling()
+				link.AppendChild(link, child)
+				child = next
+			}
+			parent.ReplaceChild(parent, node, link)
+		}
+		link.SetAttributeString("rel", []byte(commentLinkRel))
+	}
+}
+
+// isCommentUrl 判断地址是否可以作为评论中的链接，只允许 http、https 与 mailto 地址
+func isCommentUrl(destination []byte) bool {
+	u, err := url.Parse(string(destination))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+// KindMention @ 提及节点类型
+var KindMention = ast.NewNodeKind("Mention")
+
+// MentionNode 评论中的 @ 提及
+type MentionNode struct {
+	ast.BaseInline
+	Mention Mention // 被提及的评论者
+}
+
+// Kind 实现 ast.Node
+func (n *MentionNode) Kind() ast.NodeKind {
+	return KindMention
+}
+
+// Dump 实现 ast.Node
+func (n *MentionNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Mention.Name, "CommentId": n.Mention.CommentId}, nil)
+}
+
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+// Parse 匹配 @ 之后的昵称，昵称不区分大小写
+// @ 前紧跟字母或数字时不是提及，以避免把邮箱地址识别为提及；
+// 英文昵称之后紧跟字母或数字时也不匹配，避免 @bob 匹配 @bobby 的前半部分。
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	mentions, _ := pc.Get(mentionsKey).([]Mention)
+	if len(mentions) == 0 || isMentionNameRune(block.PrecendingCharacter()) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	rest := line[1:]
+	for _, mention := range mentions {
+		name := []byte(mention.Name)
+		if len(rest) < len(name) || !bytes.EqualFold(rest[:len(name)], name) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRune(name)
+		next, _ := utf8.DecodeRune(rest[len(name):])
+		if last < utf8.RuneSelf && next < utf8.RuneSelf && isMentionNameRune(last) && isMentionNameRune(next) {
+			continue
+		}
+		block.Advance(1 + len(name))
+		return &MentionNode{Mention: mention}
+	}
+	return nil
+}
+
+func isMentionNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+type mentionRenderer struct{}
+
+func (r *mentionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMention, r.renderMention)
+}
+
+func (r *mentionRenderer) renderMention(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	mention := n.(*MentionNode).Mention
+	_, _ = w.WriteString(`<span class="mention" data-comment-id="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(mention.CommentId)))
+	_, _ = w.WriteString(`">@`)
+	_, _ = w.Write(util.EscapeHTML([]byte(mention.Name)))
+	_, _ = w.WriteString("</span>")
+	return ast.WalkSkipChildren, nil
+}
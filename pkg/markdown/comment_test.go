@@ -0,0 +1,77 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderComment(t *testing.T) {
+	html, err := RenderComment([]byte("**加粗** *强调* `a<b>`\n第二行\n\n> 引用\n\n```go\nfmt.Println(1)\n```\n\n# 不是标题\n"), nil)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, want := range []string{"<strong>加粗</strong>", "<em>强调</em>", "<code>a&lt;b&gt;</code>", "<br>",
+		"<blockquote>", `<code class="language-go">`, "# 不是标题"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, html)
+		}
+	}
+	if strings.Contains(html, "<h1") {
+		t.Errorf("评论不应渲染标题: %s", html)
+	}
+}
+
+func TestRenderComment_Sanitize(t *testing.T) {
+	html, err := RenderComment([]byte("<script>alert(1)</script><img src=x onerror=alert(1)>\n\n"+
+		"[危险](javascript:alert(1)) [相对](/admin) ![图片](https://example.com/a.png)\n"), nil)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, bad := range []string{"<script", "<img", "javascript:", "/admin"} {
+		if strings.Contains(html, bad) {
+			t.Errorf("渲染结果不应包含 %s: %s", bad, html)
+		}
+	}
+	for _, want := range []string{"&lt;script&gt;", "危险", "相对", `<a href="https://example.com/a.png" rel="nofollow ugc">图片</a>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, html)
+		}
+	}
+}
+
+func TestRenderComment_Links(t *testing.T) {
+	html, err := RenderComment([]byte("[博客](https://example.com) https://example.org <https://example.net>\n"), nil)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, want := range []string{
+		`<a href="https://example.com" rel="nofollow ugc">博客</a>`,
+		`<a href="https://example.org" rel="nofollow ugc">https://example.org</a>`,
+		`<a href="https://example.net" rel="nofollow ugc">https://example.net</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, html)
+		}
+	}
+}
+
+func TestRenderComment_Mention(t *testing.T) {
+	mentions := []Mention{{Name: "Bob", CommentId: "c1"}, {Name: "张三", CommentId: "c2"}, {Name: "张三丰", CommentId: "c3"}}
+	html, err := RenderComment([]byte("@bob 你好，@bobby 与 @Alice 不在楼中，bob@example.com 是邮箱\n\n@张三丰 @张三你好 `@Bob`\n"), mentions)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, want := range []string{
+		`<span class="mention" data-comment-id="c1">@Bob</span> 你好`,
+		`<span class="mention" data-comment-id="c3">@张三丰</span>`,
+		`<span class="mention" data-comment-id="c2">@张三</span>你好`,
+		"@bobby", "@Alice", "<code>@Bob</code>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, html)
+		}
+	}
+	if strings.Count(html, `class="mention"`) != 3 {
+		t.Errorf("应当只有 3 个提及: %s", html)
+	}
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+// headingIDs 生成标题锚点，与 goldmark 默认实现不同的是保留中文等非 ASCII 字符
+// 每次渲染使用新的实例，保证同一篇文章内的锚点不重复
+type headingIDs struct {
+	values map[string]bool
+}
+
+func newHeadingIDs() parser.IDs {
+	return &headingIDs{values: map[string]bool{}}
+}
+
+// Generate 将标题文本转换为锚点：字母转为小写，空白、连字符和下划线转为连字符，其余符号忽略
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var buf bytes.Buffer
+	lastDash := true
+	for _, r := range string(bytes.TrimSpace(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			buf.WriteRune(unicode.ToLower(r))
+			lastDash = false
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			if !lastDash {
+				buf.WriteByte('-')
+				lastDash = true
+			}
+		}
+	}
+
+	id := string(bytes.TrimRight(buf.Bytes(), "-"))
+	if id == "" {
+		if kind == ast.KindHeading {
+			id = "heading"
+		} else {
+			id = "id"
+		}
+	}
+
+	if !s.values[id] {
+		s.values[id] = true
+		return []byte(id)
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", id, i)
+		if !s.values[candidate] {
+			s.values[candidate] = true
+			return []byte(candidate)
+		}
+	}
+}
+
+// Put 记录已使用的锚点
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
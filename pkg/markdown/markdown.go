@@ -0,0 +1,151 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	// md Markdown 解析器，支持 GitHub 风格的表格、删除线、任务列表和自动链接，以及脚注、数学公式和标题锚点
+	// 保留原始 HTML，由 policy 统一清理
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote, Math),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	// policy HTML 白名单，移除脚本、事件属性等不安全内容
+	policy = newPolicy()
+)
+
+// Result Markdown 渲染结果
+type Result struct {
+	HTML string     `json:"html"` // 经过清理的 HTML
+	Toc  []*TocItem `json:"toc"`  // 目录树
+}
+
+// TocItem 目录中的一个标题
+type TocItem struct {
+	Level    int        `json:"level"`              // 标题级别，1-6
+	Id       string     `json:"id"`                 // 标题锚点，对应 HTML 中标题的 id 属性
+	Title    string     `json:"title"`              // 标题文本
+	Children []*TocItem `json:"children,omitempty"` // 下级标题
+}
+
+// Render 将 Markdown 渲染为经过清理的 HTML，并生成目录
+// 标题带有可用于跳转的 id，代码块带有 language-xxx 样式类供前端高亮，
+// 数学公式输出为带有 math 样式类的占位元素，由前端渲染。
+// 参数:
+//   - src: Markdown 文本
+//
+// 返回值:
+//   - *Result: 渲染结果
+//   - error: 渲染失败时返回错误信息
+func Render(src []byte) (*Result, error) {
+	pc := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(pc))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, fmt.Errorf("渲染 Markdown 失败: %w", err)
+	}
+
+	return &Result{
+		HTML: policy.Sanitize(buf.String()),
+		Toc:  buildToc(doc, src),
+	}, nil
+}
+
+// ToHTML 将 Markdown 渲染为经过清理的 HTML
+// 参数:
+//   - src: Markdown 文本
+//
+// 返回值:
+//   - string: 可以直接嵌入页面的 HTML
+//   - error: 渲染失败时返回错误信息
+func ToHTML(src []byte) (string, error) {
+	result, err := Render(src)
+	if err != nil {
+		return "", err
+	}
+	return result.HTML, nil
+}
+
+// newPolicy 在 UGC 白名单的基础上允许标题锚点、脚注与代码高亮、数学公式需要的属性
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_:\-]+$`)).
+		OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).
+		OnElements("code", "pre", "span", "div", "a", "sup")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div")
+	return p
+}
+
+// buildToc 按标题级别将文档中的标题组织为目录树
+func buildToc(doc ast.Node, src []byte) []*TocItem {
+	var root []*TocItem
+	var stack []*TocItem
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		item := &TocItem{Level: heading.Level, Title: nodeText(heading, src)}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.Id = string(b)
+			}
+		}
+
+		// 弹出级别不低于当前标题的节点，栈顶即为上级标题
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			root = append(root, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return root
+}
+
+// nodeText 提取节点中的纯文本，忽略强调、链接等标记
+func nodeText(n ast.Node, src []byte) string {
+	var buf bytes.Buffer
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			buf.Write(t.Value(src))
+			if t.SoftLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(t.Value)
+		case *MathInline:
+			buf.Write(t.Formula)
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.String()
+}
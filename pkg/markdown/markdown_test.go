@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	html, err := ToHTML([]byte("# 标题\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n~~删除~~\n"))
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, want := range []string{`<h1 id="标题">标题</h1>`, "<table>", "<td>1</td>", "<del>删除</del>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, html)
+		}
+	}
+}
+
+func TestToHTML_Sanitize(t *testing.T) {
+	html, err := ToHTML([]byte("正文<script>alert(1)</script>\n\n<a href=\"javascript:alert(1)\" onclick=\"x()\">链接</a>\n"))
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	for _, bad := range []string{"<script", "javascript:", "onclick"} {
+		if strings.Contains(html, bad) {
+			t.Errorf("渲染结果不应包含 %s: %s", bad, html)
+		}
+	}
+}
+
+func TestRender_Toc(t *testing.T) {
+	result, err := Render([]byte("# 简介 Intro\n\n## 安装\n\n### 细节 **加粗**\n\n## 安装\n\n# 结尾\n"))
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+
+	for _, want := range []string{`<h1 id="简介-intro">`, `<h2 id="安装">`, `<h2 id="安装-1">`, `<h3 id="细节-加粗">`} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("渲染结果缺少锚点 %s: %s", want, result.HTML)
+		}
+	}
+
+	if len(result.Toc) != 2 || result.Toc[0].Id != "简介-intro" || result.Toc[1].Title != "结尾" {
+		t.Fatalf("目录的一级标题错误: %+v", result.Toc)
+	}
+	children := result.Toc[0].Children
+	if len(children) != 2 || children[1].Id != "安装-1" {
+		t.Fatalf("目录的二级标题错误: %+v", children)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].Title != "细节 加粗" {
+		t.Fatalf("目录的三级标题错误: %+v", children[0].Children)
+	}
+}
+
+func TestRender_CodeAndFootnote(t *testing.T) {
+	result, err := Render([]byte("正文[^1]\n\n```go\nfmt.Println(\"<x>\")\n```\n\n[^1]: 脚注内容\n"))
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+
+	for _, want := range []string{`<code class="language-go">`, `&lt;x&gt;`, `<sup id="fnref:1">`, `<li id="fn:1">`, `class="footnotes"`} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, result.HTML)
+		}
+	}
+}
+
+func TestRender_Math(t *testing.T) {
+	result, err := Render([]byte("公式 $a_b < c$ 与 $$\\sum x$$，价格 $5 和 $10。\n\n```math\nE = mc^2\n```\n"))
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+
+	for _, want := range []string{
+		`<span class="math math-inline">a_b &lt; c</span>`,
+		`<span class="math math-display">\sum x</span>`,
+		`<div class="math math-display">E = mc^2</div>`,
+		"价格 $5 和 $10",
+	} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("渲染结果缺少 %s: %s", want, result.HTML)
+		}
+	}
+}
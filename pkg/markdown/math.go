@@ -0,0 +1,167 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindMathInline 行内公式节点类型
+var KindMathInline = ast.NewNodeKind("MathInline")
+
+// KindMathBlock 公式块节点类型
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathInline 行内公式，$...$ 为行内样式，$$...$$ 为独立显示样式
+type MathInline struct {
+	ast.BaseInline
+	Formula []byte // TeX 公式，不含分隔符
+	Display bool   // 是否为独立显示样式
+}
+
+// Kind 实现 ast.Node
+func (n *MathInline) Kind() ast.NodeKind {
+	return KindMathInline
+}
+
+// Dump 实现 ast.Node
+func (n *MathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Formula": string(n.Formula)}, nil)
+}
+
+// MathBlock 语言为 math 的代码块
+type MathBlock struct {
+	ast.BaseBlock
+	Formula []byte // TeX 公式
+}
+
+// Kind 实现 ast.Node
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// Dump 实现 ast.Node
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Formula": string(n.Formula)}, nil)
+}
+
+// Math 数学公式扩展，公式原样输出到带有 math 样式类的占位元素中，由前端使用 KaTeX 等工具渲染
+//   - $...$ 输出为 <span class="math math-inline">
+//   - $$...$$ 输出为 <span class="math math-display">
+//   - ```math 代码块输出为 <div class="math math-display">
+var Math goldmark.Extender = &mathExtension{}
+
+type mathExtension struct{}
+
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 150)),
+		parser.WithASTTransformers(util.Prioritized(&mathBlockTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&mathRenderer{}, 500)))
+}
+
+type mathInlineParser struct{}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse 解析当前行中的公式，公式不能跨行
+// 行内公式的内容不能以空白开头或结尾，结束的 $ 后不能紧跟数字，以避免把 "$5 和 $10" 这样的金额识别为公式
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	if bytes.HasPrefix(line, []byte("$$")) {
+		end := bytes.Index(line[2:], []byte("$$"))
+		if end <= 0 {
+			return nil
+		}
+		formula := bytes.TrimSpace(line[2 : 2+end])
+		if len(formula) == 0 {
+			return nil
+		}
+		block.Advance(end + 4)
+		return &MathInline{Formula: append([]byte(nil), formula...), Display: true}
+	}
+
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			formula := line[1:i]
+			if len(formula) == 0 || util.IsSpace(formula[0]) || util.IsSpace(formula[len(formula)-1]) {
+				return nil
+			}
+			if i+1 < len(line) && util.IsNumeric(line[i+1]) {
+				return nil
+			}
+			block.Advance(i + 1)
+			return &MathInline{Formula: append([]byte(nil), formula...)}
+		}
+	}
+	return nil
+}
+
+// mathBlockTransformer 将语言为 math 的代码块替换为公式块
+type mathBlockTransformer struct{}
+
+func (t *mathBlockTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var blocks []*ast.FencedCodeBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if code, ok := n.(*ast.FencedCodeBlock); ok && entering && string(code.Language(source)) == "math" {
+			blocks = append(blocks, code)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, code := range blocks {
+		var formula bytes.Buffer
+		lines := code.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			formula.Write(segment.Value(source))
+		}
+		math := &MathBlock{Formula: bytes.TrimSpace(formula.Bytes())}
+		code.Parent().ReplaceChild(code.Parent(), code, math)
+	}
+}
+
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMathInline, r.renderMathInline)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+}
+
+func (r *mathRenderer) renderMathInline(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*MathInline)
+	if node.Display {
+		_, _ = w.WriteString(`<span class="math math-display">`)
+	} else {
+		_, _ = w.WriteString(`<span class="math math-inline">`)
+	}
+	_, _ = w.Write(util.EscapeHTML(node.Formula))
+	_, _ = w.WriteString("</span>")
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *mathRenderer) renderMathBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<div class="math math-display">`)
+	_, _ = w.Write(util.EscapeHTML(n.(*MathBlock).Formula))
+	_, _ = w.WriteString("</div>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,103 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// ContentType 站点地图的响应类型
+const ContentType = "application/xml; charset=utf-8"
+
+// MaxUrls 单个站点地图文件最多包含的地址数量，超过时需要拆分并使用站点地图索引
+const MaxUrls = 50000
+
+// xmlns 站点地图协议的命名空间
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// Url 站点地图中的一个地址
+type Url struct {
+	Loc     string    // 绝对地址
+	LastMod time.Time // 最后修改时间，为零值时不输出
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Urls    []urlEntry
+}
+
+type urlEntry struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry
+}
+
+type sitemapEntry struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+// PageCount 计算 n 个地址需要拆分成的站点地图文件数量，至少为 1
+func PageCount(n int) int {
+	if n <= MaxUrls {
+		return 1
+	}
+	return (n + MaxUrls - 1) / MaxUrls
+}
+
+// UrlSet 输出站点地图文件
+// 参数:
+//   - urls: 地址列表，数量不能超过 MaxUrls
+//
+// 返回值:
+//   - []byte: XML 内容
+//   - error: 地址过多或生成失败时返回错误信息
+func UrlSet(urls []Url) ([]byte, error) {
+	if len(urls) > MaxUrls {
+		return nil, fmt.Errorf("站点地图最多包含 %d 个地址，实际 %d 个", MaxUrls, len(urls))
+	}
+
+	doc := urlSet{Xmlns: xmlns, Urls: make([]urlEntry, 0, len(urls))}
+	for _, u := range urls {
+		doc.Urls = append(doc.Urls, urlEntry{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)})
+	}
+	return marshal(doc)
+}
+
+// Index 输出站点地图索引文件
+// 参数:
+//   - sitemaps: 各个站点地图文件的地址与最后修改时间
+//
+// 返回值:
+//   - []byte: XML 内容
+//   - error: 生成失败时返回错误信息
+func Index(sitemaps []Url) ([]byte, error) {
+	doc := sitemapIndex{Xmlns: xmlns, Sitemaps: make([]sitemapEntry, 0, len(sitemaps))}
+	for _, s := range sitemaps {
+		doc.Sitemaps = append(doc.Sitemaps, sitemapEntry{Loc: s.Loc, LastMod: formatLastMod(s.LastMod)})
+	}
+	return marshal(doc)
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成站点地图失败: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
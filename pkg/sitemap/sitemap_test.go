@@ -0,0 +1,61 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUrlSet(t *testing.T) {
+	lastMod := time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CST", 8*3600))
+	data, err := UrlSet([]Url{
+		{Loc: "https://example.com/", LastMod: lastMod},
+		{Loc: "https://example.com/blog/a?x=1&y=2"},
+	})
+	if err != nil {
+		t.Fatalf("生成站点地图失败: %v", err)
+	}
+
+	var doc struct {
+		Urls []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("站点地图不是合法的 XML: %v", err)
+	}
+	if len(doc.Urls) != 2 || doc.Urls[0].LastMod != "2025-01-01T19:04:05Z" || doc.Urls[1].LastMod != "" {
+		t.Fatalf("站点地图内容错误: %+v", doc.Urls)
+	}
+	if doc.Urls[1].Loc != "https://example.com/blog/a?x=1&y=2" {
+		t.Errorf("地址转义错误: %s", doc.Urls[1].Loc)
+	}
+	if !strings.Contains(string(data), `xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"`) {
+		t.Error("站点地图缺少命名空间")
+	}
+
+	if _, err := UrlSet(make([]Url, MaxUrls+1)); err == nil {
+		t.Error("地址超过上限时应当报错")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	data, err := Index([]Url{{Loc: "https://api.example.com/web/sitemap/1.xml"}, {Loc: "https://api.example.com/web/sitemap/2.xml"}})
+	if err != nil {
+		t.Fatalf("生成站点地图索引失败: %v", err)
+	}
+	if !strings.Contains(string(data), "<sitemapindex") || strings.Count(string(data), "<sitemap>") != 2 {
+		t.Fatalf("站点地图索引内容错误: %s", data)
+	}
+}
+
+func TestPageCount(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 1, MaxUrls: 1, MaxUrls + 1: 2, 3 * MaxUrls: 3}
+	for n, want := range cases {
+		if got := PageCount(n); got != want {
+			t.Errorf("PageCount(%d) = %d, 期望 %d", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// maxTokenLen 参与分类的单词最大长度，更长的通常是链接参数或随机字符串
+const maxTokenLen = 32
+
+// TokenCount 一个词在垃圾评论与正常评论中出现的次数
+// 同一条评论中重复出现只计一次
+type TokenCount struct {
+	Spam int
+	Ham  int
+}
+
+// BayesStore 贝叶斯分类器的训练数据存储
+type BayesStore interface {
+	// TokenCounts 查询各词的出现次数，以及训练过的垃圾评论与正常评论总数
+	TokenCounts(ctx context.Context, tokens []string) (map[string]TokenCount, TokenCount, error)
+	// Learn 记录一条评论的分词结果
+	Learn(ctx context.Context, tokens []string, spam bool) error
+}
+
+// Bayes 朴素贝叶斯分类器，由管理员通过或拒绝评论的操作训练
+type Bayes struct {
+	Store     BayesStore
+	Threshold float64 // 垃圾评论概率不低于该值时拒绝
+	MinDocs   int     // 垃圾评论与正常评论都至少训练过这么多条后才开始判定
+}
+
+func (*Bayes) Name() string { return "bayes" }
+
+func (b *Bayes) Check(ctx context.Context, sub *Submission) (*Rejection, error) {
+	probability, trained, err := b.SpamProbability(ctx, sub.Content)
+	if err != nil || !trained {
+		return nil, err
+	}
+	if probability >= b.Threshold {
+		return &Rejection{Reason: fmt.Sprintf("垃圾评论概率 %.3f", probability)}, nil
+	}
+	return nil, nil
+}
+
+// Learn 使用一条评论训练分类器
+// 参数:
+//   - ctx: 上下文对象
+//   - content: 评论内容
+//   - spam: 是否为垃圾评论
+//
+// 返回值:
+//   - error: 保存训练数据失败时返回错误信息
+func (b *Bayes) Learn(ctx context.Context, content string, spam bool) error {
+	return b.Store.Learn(ctx, Tokenize(content), spam)
+}
+
+// SpamProbability 计算评论为垃圾评论的概率
+// 参数:
+//   - ctx: 上下文对象
+//   - content: 评论内容
+//
+// 返回值:
+//   - float64: 垃圾评论的概率
+//   - bool: 训练数据是否足够，不足时概率没有意义
+//   - error: 查询训练数据失败时返回错误信息
+func (b *Bayes) SpamProbability(ctx context.Context, content string) (float64, bool, error) {
+	tokens := Tokenize(content)
+	counts, totals, err := b.Store.TokenCounts(ctx, tokens)
+	if err != nil {
+		return 0, false, err
+	}
+	if totals.Spam < b.MinDocs || totals.Ham < b.MinDocs {
+		return 0, false, nil
+	}
+
+	// 在对数空间中累加各词的似然比，使用拉普拉斯平滑，忽略从未出现过的词
+	spamDocs := float64(totals.Spam)
+	hamDocs := float64(totals.Ham)
+	logOdds := math.Log(spamDocs / hamDocs)
+	for _, token := range tokens {
+		count, ok := counts[token]
+		if !ok || count.Spam+count.Ham == 0 {
+			continue
+		}
+		pSpam := (float64(count.Spam) + 1) / (spamDocs + 2)
+		pHam := (float64(count.Ham) + 1) / (hamDocs + 2)
+		logOdds += math.Log(pSpam / pHam)
+	}
+
+	return 1 / (1 + math.Exp(-logOdds)), true, nil
+}
+
+// Tokenize 将文本切分为去重后的词
+// 英文与数字按连续字符切分并转为小写，汉字等表意文字之间没有空格，切分为相邻两字的组合
+// 参数:
+//   - text: 文本
+//
+// 返回值:
+//   - []string: 按首次出现顺序排列的词
+func Tokenize(text string) []string {
+	seen := make(map[string]struct{})
+	var tokens []string
+	add := func(token string) {
+		if _, ok := seen[token]; ok {
+			return
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+
+	var word strings.Builder
+	var prevIdeograph rune
+	flushWord := func() {
+		if n := len([]rune(word.String())); n >= 2 && n <= maxTokenLen {
+			add(word.String())
+		}
+		word.Reset()
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			if prevIdeograph != 0 {
+				add(string([]rune{prevIdeograph, r}))
+			}
+			prevIdeograph = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flushWord()
+		}
+		prevIdeograph = 0
+	}
+	flushWord()
+
+	return tokens
+}
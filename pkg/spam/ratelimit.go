@@ -0,0 +1,108 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BucketStore 令牌桶状态的存储，storage.Storage.Cache 满足该接口
+type BucketStore interface {
+	GetString(ctx context.Context, key string) (string, error)
+	SetWithExpired(ctx context.Context, key string, value any, ttl time.Duration) error
+}
+
+// bucketMu 保证同一进程内令牌桶的读取与写回不会交错
+var bucketMu sync.Mutex
+
+// RateLimit 令牌桶限流，每次提交消耗一个令牌，令牌按固定间隔恢复
+type RateLimit struct {
+	Label     string                   // 检查项名称，如 ip、email
+	Store     BucketStore              // 令牌桶状态的存储
+	KeyPrefix string                   // 存储键前缀
+	Key       func(*Submission) string // 从提交中取出限流的对象，为空时不限流
+	Burst     int                      // 令牌桶容量，即允许连续提交的次数
+	Interval  time.Duration            // 恢复一个令牌所需的时间
+
+	now func() time.Time // 当前时间，测试时替换
+}
+
+func (r *RateLimit) Name() string { return "rate_limit_" + r.Label }
+
+func (r *RateLimit) Check(ctx context.Context, sub *Submission) (*Rejection, error) {
+	key := r.Key(sub)
+	if key == "" {
+		return nil, nil
+	}
+
+	allowed, retryAfter, err := r.take(ctx, r.KeyPrefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return &Rejection{
+			Reason:      fmt.Sprintf("%s %s 提交过于频繁，%d 秒后重试", r.Label, key, int(retryAfter.Seconds()+0.999)),
+			RateLimited: true,
+		}, nil
+	}
+	return nil, nil
+}
+
+// take 从令牌桶中取出一个令牌
+// 令牌桶以 "剩余令牌数,上次更新时间" 的格式保存，令牌恢复满后键自动过期
+func (r *RateLimit) take(ctx context.Context, key string) (bool, time.Duration, error) {
+	bucketMu.Lock()
+	defer bucketMu.Unlock()
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	burst := float64(r.Burst)
+
+	// 键不存在或无法解析时视为满桶
+	tokens := burst
+	if state, err := r.Store.GetString(ctx, key); err == nil {
+		if t, last, ok := parseBucket(state); ok {
+			tokens = min(burst, t+float64(now.Sub(last))/float64(r.Interval))
+		}
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	ttl := time.Duration((burst - tokens) * float64(r.Interval))
+	if ttl <= 0 {
+		ttl = r.Interval
+	}
+	state := strconv.FormatFloat(tokens, 'f', 4, 64) + "," + strconv.FormatInt(now.UnixNano(), 10)
+	if err := r.Store.SetWithExpired(ctx, key, state, ttl); err != nil {
+		return false, 0, err
+	}
+
+	if allowed {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - tokens) * float64(r.Interval)), nil
+}
+
+func parseBucket(state string) (float64, time.Time, bool) {
+	tokensStr, lastStr, found := strings.Cut(state, ",")
+	if !found {
+		return 0, time.Time{}, false
+	}
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	last, err := strconv.ParseInt(lastStr, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return tokens, time.Unix(0, last), true
+}
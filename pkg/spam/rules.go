@@ -0,0 +1,60 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// linkPattern 匹配评论中的链接，包括不带协议的 www. 开头的地址
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)[^\s]+`)
+
+// HoneypotRule 蜜罐字段被填写时拒绝，用于拦截自动填写表单的机器人
+type HoneypotRule struct{}
+
+func (HoneypotRule) Name() string { return "honeypot" }
+
+func (HoneypotRule) Check(_ context.Context, sub *Submission) (*Rejection, error) {
+	if strings.TrimSpace(sub.Honeypot) != "" {
+		return &Rejection{Reason: "蜜罐字段被填写"}, nil
+	}
+	return nil, nil
+}
+
+// LinkRule 评论中的链接数量超过上限时拒绝
+type LinkRule struct {
+	MaxLinks int // 最多允许的链接数
+}
+
+func (LinkRule) Name() string { return "links" }
+
+func (r LinkRule) Check(_ context.Context, sub *Submission) (*Rejection, error) {
+	count := len(linkPattern.FindAllString(sub.Content, -1))
+	if count > r.MaxLinks {
+		return &Rejection{Reason: fmt.Sprintf("包含 %d 个链接，超过上限 %d", count, r.MaxLinks)}, nil
+	}
+	return nil, nil
+}
+
+// KeywordRule 评论内容或邮箱包含屏蔽关键词时拒绝，不区分大小写
+type KeywordRule struct {
+	Keywords []string // 屏蔽关键词
+}
+
+func (KeywordRule) Name() string { return "keywords" }
+
+func (r KeywordRule) Check(_ context.Context, sub *Submission) (*Rejection, error) {
+	content := strings.ToLower(sub.Content)
+	email := strings.ToLower(sub.Email)
+	for _, keyword := range r.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			continue
+		}
+		if strings.Contains(content, keyword) || strings.Contains(email, keyword) {
+			return &Rejection{Reason: fmt.Sprintf("包含屏蔽关键词 %q", keyword)}, nil
+		}
+	}
+	return nil, nil
+}
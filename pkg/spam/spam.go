@@ -0,0 +1,63 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"sparrow_blog_server/pkg/logger"
+)
+
+// Submission 一次待检查的评论提交
+type Submission struct {
+	Ip       string // 提交者 IP
+	Email    string // 评论者邮箱
+	Content  string // 评论内容
+	Honeypot string // 蜜罐字段，前端隐藏，正常用户不会填写
+}
+
+// Rejection 评论被拒绝的原因
+type Rejection struct {
+	Checker     string // 拒绝评论的检查项
+	Reason      string // 拒绝原因
+	RateLimited bool   // 是否因为提交过于频繁被拒绝
+}
+
+func (r *Rejection) String() string {
+	return fmt.Sprintf("[%s] %s", r.Checker, r.Reason)
+}
+
+// Checker 评论检查项
+type Checker interface {
+	// Name 检查项名称，记录在拒绝原因中
+	Name() string
+	// Check 检查评论，返回非空的 Rejection 表示拒绝
+	// 返回 error 表示检查项自身出错，不代表评论有问题
+	Check(ctx context.Context, sub *Submission) (*Rejection, error)
+}
+
+// Chain 按顺序执行的检查链，任一检查项拒绝即停止
+type Chain []Checker
+
+// Check 依次执行检查项
+// 检查项出错时记录日志并跳过，不因为检查失败拒绝正常评论
+// 参数:
+//   - ctx: 上下文对象
+//   - sub: 待检查的评论
+//
+// 返回值:
+//   - *Rejection: 评论被拒绝时返回拒绝原因，否则返回 nil
+func (c Chain) Check(ctx context.Context, sub *Submission) *Rejection {
+	for _, checker := range c {
+		rejection, err := checker.Check(ctx, sub)
+		if err != nil {
+			logger.Warn("评论检查项 %s 执行失败: %v", checker.Name(), err)
+			continue
+		}
+		if rejection != nil {
+			if rejection.Checker == "" {
+				rejection.Checker = checker.Name()
+			}
+			return rejection
+		}
+	}
+	return nil
+}
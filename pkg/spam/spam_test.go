@@ -0,0 +1,182 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// memoryBayesStore 内存中的训练数据
+type memoryBayesStore struct {
+	counts map[string]TokenCount
+	totals TokenCount
+}
+
+func (s *memoryBayesStore) TokenCounts(_ context.Context, tokens []string) (map[string]TokenCount, TokenCount, error) {
+	counts := make(map[string]TokenCount, len(tokens))
+	for _, token := range tokens {
+		if count, ok := s.counts[token]; ok {
+			counts[token] = count
+		}
+	}
+	return counts, s.totals, nil
+}
+
+func (s *memoryBayesStore) Learn(_ context.Context, tokens []string, spam bool) error {
+	for _, token := range tokens {
+		count := s.counts[token]
+		if spam {
+			count.Spam++
+		} else {
+			count.Ham++
+		}
+		s.counts[token] = count
+	}
+	if spam {
+		s.totals.Spam++
+	} else {
+		s.totals.Ham++
+	}
+	return nil
+}
+
+// memoryBucketStore 内存中的令牌桶状态
+type memoryBucketStore map[string]string
+
+func (s memoryBucketStore) GetString(_ context.Context, key string) (string, error) {
+	value, ok := s[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func (s memoryBucketStore) SetWithExpired(_ context.Context, key string, value any, _ time.Duration) error {
+	s[key] = value.(string)
+	return nil
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Buy CHEAP pills, buy now! 这篇文章写得好 a 1")
+	expected := []string{"buy", "cheap", "pills", "now", "这篇", "篇文", "文章", "章写", "写得", "得好"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("分词结果错误:\n得到 %v\n期望 %v", tokens, expected)
+	}
+}
+
+func TestRules(t *testing.T) {
+	ctx := context.Background()
+	chain := Chain{
+		HoneypotRule{},
+		LinkRule{MaxLinks: 2},
+		KeywordRule{Keywords: []string{"Casino"}},
+	}
+
+	cases := []struct {
+		name    string
+		sub     Submission
+		checker string
+	}{
+		{"正常评论", Submission{Content: "写得很好，参考 https://go.dev"}, ""},
+		{"蜜罐", Submission{Content: "你好", Honeypot: "http://spam.example"}, "honeypot"},
+		{"链接过多", Submission{Content: "http://a.com www.b.com https://c.com"}, "links"},
+		{"关键词", Submission{Content: "best CASINO online"}, "keywords"},
+		{"邮箱关键词", Submission{Content: "你好", Email: "casino@example.com"}, "keywords"},
+	}
+	for _, c := range cases {
+		rejection := chain.Check(ctx, &c.sub)
+		switch {
+		case c.checker == "" && rejection != nil:
+			t.Errorf("%s: 不应被拒绝，实际被 %s 拒绝", c.name, rejection)
+		case c.checker != "" && (rejection == nil || rejection.Checker != c.checker):
+			t.Errorf("%s: 应被 %s 拒绝，实际为 %v", c.name, c.checker, rejection)
+		}
+	}
+}
+
+func TestBayes(t *testing.T) {
+	ctx := context.Background()
+	bayes := &Bayes{
+		Store:     &memoryBayesStore{counts: make(map[string]TokenCount)},
+		Threshold: 0.9,
+		MinDocs:   3,
+	}
+
+	spams := []string{
+		"cheap pills discount viagra",
+		"buy cheap watches discount",
+		"discount casino bonus cheap",
+	}
+	hams := []string{
+		"谢谢分享，这篇文章对我很有帮助",
+		"请问这个配置文件放在哪里",
+		"文章里的代码有一个小错误",
+	}
+
+	if rejection, _ := bayes.Check(ctx, &Submission{Content: spams[0]}); rejection != nil {
+		t.Fatal("训练数据不足时不应判定")
+	}
+
+	for _, content := range spams {
+		_ = bayes.Learn(ctx, content, true)
+	}
+	for _, content := range hams {
+		_ = bayes.Learn(ctx, content, false)
+	}
+
+	rejection, err := bayes.Check(ctx, &Submission{Content: "cheap discount for you"})
+	if err != nil || rejection == nil {
+		t.Errorf("应判定为垃圾评论，实际为 %v, %v", rejection, err)
+	}
+
+	rejection, err = bayes.Check(ctx, &Submission{Content: "这篇文章的代码对我很有帮助"})
+	if err != nil || rejection != nil {
+		t.Errorf("不应判定为垃圾评论，实际为 %v, %v", rejection, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := &RateLimit{
+		Label:     "ip",
+		Store:     memoryBucketStore{},
+		KeyPrefix: "test_",
+		Key:       func(sub *Submission) string { return sub.Ip },
+		Burst:     2,
+		Interval:  time.Minute,
+		now:       func() time.Time { return now },
+	}
+	sub := &Submission{Ip: "1.2.3.4"}
+
+	for i := 0; i < 2; i++ {
+		if rejection, err := limit.Check(ctx, sub); err != nil || rejection != nil {
+			t.Fatalf("第 %d 次提交不应被限流: %v, %v", i+1, rejection, err)
+		}
+	}
+	rejection, err := limit.Check(ctx, sub)
+	if err != nil || rejection == nil || !rejection.RateLimited {
+		t.Fatalf("超过容量后应被限流，实际为 %v, %v", rejection, err)
+	}
+
+	// 其他 IP 不受影响
+	if rejection, _ := limit.Check(ctx, &Submission{Ip: "5.6.7.8"}); rejection != nil {
+		t.Error("不同 IP 的令牌桶应相互独立")
+	}
+
+	// 一个间隔后恢复一个令牌
+	now = now.Add(time.Minute)
+	if rejection, _ := limit.Check(ctx, sub); rejection != nil {
+		t.Errorf("令牌恢复后不应被限流: %v", rejection)
+	}
+	if rejection, _ := limit.Check(ctx, sub); rejection == nil {
+		t.Error("恢复的令牌用完后应被限流")
+	}
+
+	// 没有限流对象时不限流
+	if rejection, _ := limit.Check(ctx, &Submission{}); rejection != nil {
+		t.Error("IP 为空时不应限流")
+	}
+}
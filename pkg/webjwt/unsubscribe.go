@@ -0,0 +1,85 @@
+package webjwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"sparrow_blog_server/pkg/config"
+	"time"
+)
+
+// UnsubscribeTokenExpire 退订令牌的有效期，通知邮件发出后在此期间内可以一键退订
+const UnsubscribeTokenExpire = 90 * 24 * time.Hour
+
+// unsubscribeAudience 退订令牌的受众，解析时校验，避免其他用途的令牌被当作退订令牌
+const unsubscribeAudience = "comment-unsubscribe"
+
+// UnsubscribeClaims 评论回复退订令牌的声明，标识退订的邮箱与楼层
+type UnsubscribeClaims struct {
+	Email    string `json:"email"`     // 订阅者邮箱
+	ThreadId string `json:"thread_id"` // 楼主评论 ID
+	jwt.RegisteredClaims
+}
+
+// unsubscribeKey 由 server.token_key 派生退订令牌的签名密钥。
+// 退订令牌随邮件发给评论者，使用独立的密钥签名，保证它不能作为管理员登录令牌通过校验。
+func unsubscribeKey() []byte {
+	mac := hmac.New(sha256.New, []byte(config.Server.TokenKey))
+	mac.Write([]byte(unsubscribeAudience))
+	return mac.Sum(nil)
+}
+
+// GenerateUnsubscribeToken 生成评论回复的退订令牌，有效期为 UnsubscribeTokenExpire
+// 参数:
+//   - email: 订阅者邮箱
+//   - threadId: 楼主评论 ID
+//
+// 返回值:
+//   - string: 退订令牌
+//   - error: 签名失败时返回错误信息
+func GenerateUnsubscribeToken(email, threadId string) (string, error) {
+	now := time.Now()
+	claims := UnsubscribeClaims{
+		Email:    email,
+		ThreadId: threadId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{unsubscribeAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(UnsubscribeTokenExpire)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(unsubscribeKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to sign unsubscribe token: %v", err)
+	}
+	return tokenString, nil
+}
+
+// ParseUnsubscribeToken 解析并验证评论回复的退订令牌
+// 参数:
+//   - tokenString: 退订令牌
+//
+// 返回值:
+//   - *UnsubscribeClaims: 令牌有效时返回退订的邮箱与楼层
+//   - error: 签名无效、令牌过期或缺少邮箱与楼层时返回错误信息
+func ParseUnsubscribeToken(tokenString string) (*UnsubscribeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &UnsubscribeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return unsubscribeKey(), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(unsubscribeAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*UnsubscribeClaims)
+	if !ok || !token.Valid || claims.Email == "" || claims.ThreadId == "" {
+		return nil, errors.New("退订令牌无效")
+	}
+	return claims, nil
+}
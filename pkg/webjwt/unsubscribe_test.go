@@ -0,0 +1,81 @@
+package webjwt
+
+import (
+	"sparrow_blog_server/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	token, err := GenerateUnsubscribeToken("reader@example.com", "thread_id")
+	if err != nil {
+		t.Fatalf("生成退订令牌失败: %v", err)
+	}
+
+	claims, err := ParseUnsubscribeToken(token)
+	if err != nil {
+		t.Fatalf("解析退订令牌失败: %v", err)
+	}
+	if claims.Email != "reader@example.com" || claims.ThreadId != "thread_id" {
+		t.Errorf("退订令牌的内容错误: %+v", claims)
+	}
+	if expire := time.Until(claims.ExpiresAt.Time); expire <= 0 || expire > UnsubscribeTokenExpire {
+		t.Errorf("退订令牌的有效期错误: %v", expire)
+	}
+
+	// 被篡改的令牌无法通过校验
+	if _, err := ParseUnsubscribeToken(token[:len(token)-2] + "xx"); err == nil {
+		t.Error("被篡改的退订令牌不应通过校验")
+	}
+
+	// 更换 token_key 后之前的令牌失效
+	oldKey := config.Server.TokenKey
+	config.Server.TokenKey = oldKey + "_changed"
+	_, err = ParseUnsubscribeToken(token)
+	config.Server.TokenKey = oldKey
+	if err == nil {
+		t.Error("更换密钥后退订令牌不应通过校验")
+	}
+}
+
+func TestUnsubscribeToken_Expired(t *testing.T) {
+	claims := UnsubscribeClaims{
+		Email:    "reader@example.com",
+		ThreadId: "thread_id",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{unsubscribeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(unsubscribeKey())
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if _, err := ParseUnsubscribeToken(token); err == nil {
+		t.Error("过期的退订令牌不应通过校验")
+	}
+}
+
+func TestUnsubscribeToken_NotLoginToken(t *testing.T) {
+	// 退订令牌不能作为登录令牌使用，登录令牌也不能用于退订
+	unsubscribeToken, err := GenerateUnsubscribeToken("reader@example.com", "thread_id")
+	if err != nil {
+		t.Fatalf("生成退订令牌失败: %v", err)
+	}
+	if _, err := ParseJWTToken(unsubscribeToken); err == nil {
+		t.Error("退订令牌不应通过登录校验")
+	}
+
+	loginToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{
+		UserEmail:        "reader@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte(config.Server.TokenKey))
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if _, err := ParseUnsubscribeToken(loginToken); err == nil {
+		t.Error("登录令牌不应通过退订校验")
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"path/filepath"
+	"sparrow_blog_server/cache"
 	"sparrow_blog_server/internal/model/dto"
 	"sparrow_blog_server/internal/model/vo"
+	"sparrow_blog_server/internal/repositories/blogrepo"
+	"sparrow_blog_server/internal/repositories/commentrepo"
 	"sparrow_blog_server/internal/services/adminservices"
+	"sparrow_blog_server/internal/services/webservice"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/email"
 	"sparrow_blog_server/pkg/logger"
@@ -16,6 +22,7 @@ import (
 	"sparrow_blog_server/searchengine"
 	"sparrow_blog_server/storage"
 	"sparrow_blog_server/storage/ossstore"
+	"strconv"
 	"strings"
 	"time"
 
@@ -194,6 +201,8 @@ func getAllBlogs(ctx *gin.Context) {
 			BlogState:    blogDto.BlogState,
 			BlogWordsNum: blogDto.BlogWordsNum,
 			BlogIsTop:    blogDto.BlogIsTop,
+			PublishAt:    blogDto.PublishAt,
+			UnpublishAt:  blogDto.UnpublishAt,
 			CreateTime:   blogDto.CreateTime,
 			UpdateTime:   blogDto.UpdateTime,
 		}
@@ -351,6 +360,8 @@ func getBlogData(ctx *gin.Context) {
 		Tags:         tagVos,
 		BlogState:    blogDto.BlogState,
 		BlogWordsNum: blogDto.BlogWordsNum,
+		PublishAt:    blogDto.PublishAt,
+		UnpublishAt:  blogDto.UnpublishAt,
 	}
 
 	resp.Ok(ctx, "获取成功", map[string]any{
@@ -359,6 +370,67 @@ func getBlogData(ctx *gin.Context) {
 	})
 }
 
+// getBlogRevisions 获取博客的修订记录
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文
+func getBlogRevisions(ctx *gin.Context) {
+	revisionDtos, err := adminservices.GetBlogRevisions(ctx, ctx.Param("blog_id"))
+	if err != nil {
+		resp.Err(ctx, "获取修订记录失败", err.Error())
+		return
+	}
+
+	revisionVos := make([]vo.BlogRevisionVo, 0, len(revisionDtos))
+	for _, revision := range revisionDtos {
+		revisionVos = append(revisionVos, vo.BlogRevisionVo{
+			RevisionId:   revision.RevisionId,
+			BlogId:       revision.BlogId,
+			BlogTitle:    revision.BlogTitle,
+			BlogBrief:    revision.BlogBrief,
+			BlogWordsNum: revision.BlogWordsNum,
+			CreateTime:   revision.CreateTime,
+		})
+	}
+
+	resp.Ok(ctx, "获取成功", revisionVos)
+}
+
+// diffBlogRevisions 获取博客两个修订之间的差异，通过 from 与 to 查询参数指定修订ID
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文
+func diffBlogRevisions(ctx *gin.Context) {
+	fromId := ctx.Query("from")
+	toId := ctx.Query("to")
+	if fromId == "" || toId == "" {
+		resp.BadRequest(ctx, "请求参数错误", "from 与 to 不能为空")
+		return
+	}
+
+	diff, err := adminservices.DiffBlogRevisions(ctx, ctx.Param("blog_id"), fromId, toId)
+	if err != nil {
+		resp.Err(ctx, "获取修订差异失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", map[string]any{
+		"from": fromId,
+		"to":   toId,
+		"diff": diff,
+	})
+}
+
+// restoreBlogRevision 将博客恢复到指定修订
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文
+func restoreBlogRevision(ctx *gin.Context) {
+	if err := adminservices.RestoreBlogRevision(ctx, ctx.Param("blog_id"), ctx.Param("revision_id")); err != nil {
+		resp.Err(ctx, "恢复修订失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "恢复成功", nil)
+}
+
 // addImgs 添加图片
 // 参数:
 //   - ctx *gin.Context: HTTP请求上下文
@@ -709,15 +781,8 @@ func verifyNewEmail(ctx *gin.Context) {
 		return
 	}
 
-	// 发送验证码
-	if err := email.SendVerificationCodeByArgs(
-		ctx,
-		newEmail,
-		config.Server.SmtpAccount,
-		config.Server.SmtpAddress,
-		config.Server.SmtpAuthCode,
-		config.Server.SmtpPort,
-	); err != nil {
+	// 使用系统 SMTP 配置发送验证码，写入发件箱后由后台发送
+	if err := email.SendVerificationCode(ctx, newEmail); err != nil {
 		resp.Err(ctx, "发送失败", err.Error())
 		return
 	}
@@ -819,6 +884,10 @@ func getServerConfig(ctx *gin.Context) {
 		"smtp_account":          config.Server.SmtpAccount,
 		"smtp_address":          config.Server.SmtpAddress,
 		"smtp_port":             config.Server.SmtpPort,
+		"site_url":              config.Server.SiteUrl,
+		"api_url":               config.Server.ApiUrl,
+		"robots":                config.Server.Robots,
+		"trusted_proxies":       config.Server.TrustedProxies,
 	})
 }
 
@@ -876,6 +945,34 @@ func updateServerConfig(ctx *gin.Context) {
 		return
 	}
 
+	// 站点地址为可选配置，未传入时保持原值
+	siteUrl := config.Server.SiteUrl
+	if rawSiteUrl, getErr := tools.GetStringFromRawData(rawData, "server.site_url"); getErr == nil {
+		if anaErr := tools.AnalyzeSiteUrl(rawSiteUrl); anaErr != nil {
+			msg := fmt.Sprintf("站点地址配置错误: %s", anaErr.Error())
+			resp.BadRequest(ctx, msg, nil)
+			return
+		}
+		siteUrl = strings.TrimRight(strings.TrimSpace(rawSiteUrl), "/")
+	}
+
+	// 服务端地址为可选配置，未传入时保持原值
+	apiUrl := config.Server.ApiUrl
+	if rawApiUrl, getErr := tools.GetStringFromRawData(rawData, "server.api_url"); getErr == nil {
+		if anaErr := tools.AnalyzeSiteUrl(rawApiUrl); anaErr != nil {
+			msg := fmt.Sprintf("服务端地址配置错误: %s", anaErr.Error())
+			resp.BadRequest(ctx, msg, nil)
+			return
+		}
+		apiUrl = strings.TrimRight(strings.TrimSpace(rawApiUrl), "/")
+	}
+
+	// robots.txt 抓取规则为可选配置，未传入时保持原值
+	robots := config.Server.Robots
+	if rawRobots, getErr := tools.GetStringFromRawData(rawData, "server.robots"); getErr == nil {
+		robots = strings.TrimSpace(rawRobots)
+	}
+
 	smtpAccount := config.Server.SmtpAccount
 	smtpAddress := config.Server.SmtpAddress
 	smtpPort := config.Server.SmtpPort
@@ -950,6 +1047,12 @@ func updateServerConfig(ctx *gin.Context) {
 		SmtpAddress:  smtpAddress,
 		SmtpPort:     smtpPort,
 		SmtpAuthCode: smtpAuthCode,
+		SSL:          config.Server.SSL,
+		SiteUrl:      siteUrl,
+		ApiUrl:       apiUrl,
+		Robots:       robots,
+		// 可信反向代理在启动时生效，只能通过配置文件修改
+		TrustedProxies: config.Server.TrustedProxies,
 	}
 
 	// 更新配置到存储系统
@@ -1079,6 +1182,8 @@ func updateLoggerConfig(ctx *gin.Context) {
 //
 // 功能描述:
 //  1. 从系统配置中获取OSS相关配置信息，包括:
+//     - 对象存储后端 (provider)
+//     - S3 路径风格访问 (use_path_style)
 //     - 访问端点 (endpoint)
 //     - 地域信息 (region)
 //     - 存储桶名称 (bucket)
@@ -1087,6 +1192,8 @@ func updateLoggerConfig(ctx *gin.Context) {
 //  2. 将配置信息封装为map结构返回给客户端
 func getOssConfig(ctx *gin.Context) {
 	resp.Ok(ctx, "获取成功", map[string]any{
+		"provider":       storage.ObjectStoreProvider(&config.Oss),
+		"use_path_style": config.Oss.UsePathStyle,
 		"endpoint":       config.Oss.Endpoint,
 		"region":         config.Oss.Region,
 		"bucket":         config.Oss.Bucket,
@@ -1102,12 +1209,13 @@ func getOssConfig(ctx *gin.Context) {
 // 功能描述:
 //  1. 从请求中解析并验证OSS配置参数
 //  2. 验证各项参数的合法性，包括:
+//     - 对象存储后端(provider)，可选 aliyun、s3、local
 //     - 访问端点(endpoint)
 //     - 地域信息(region)
 //     - 访问密钥(access key)
 //     - 存储桶名称(bucket)
 //     - 图片存储路径(image path)
-//  3. 测试OSS连接配置的有效性
+//  3. 根据对象存储后端测试连接配置的有效性
 //  4. 更新系统配置并保存
 func updateOssConfig(ctx *gin.Context) {
 	// 从请求中解析原始数据
@@ -1115,8 +1223,11 @@ func updateOssConfig(ctx *gin.Context) {
 	if err != nil {
 		return
 	}
-	// 初始化OSS配置结构体。
-	ossConfig := config.OssConfig{}
+	// 初始化OSS配置结构体，保留本地对象存储的配置
+	ossConfig := config.OssConfig{
+		LocalPath:    config.Oss.LocalPath,
+		LocalBaseUrl: config.Oss.LocalBaseUrl,
+	}
 
 	// 从原始数据中提取并清理OSS配置参数
 	ossConfig.Endpoint = strings.TrimSpace(rawData["oss.endpoint"].(string))                 // OSS访问端点
@@ -1125,9 +1236,24 @@ func updateOssConfig(ctx *gin.Context) {
 	ossConfig.AccessKeySecret = strings.TrimSpace(rawData["oss.access_key_secret"].(string)) // 访问密钥密文
 	ossConfig.Bucket = strings.TrimSpace(rawData["oss.bucket"].(string))                     // 存储桶名称
 	ossConfig.ImageOssPath = strings.TrimSpace(rawData["oss.image_oss_path"].(string))       // 图片存储路径
+	if provider, ok := rawData["oss.provider"].(string); ok {
+		ossConfig.Provider = strings.TrimSpace(provider) // 对象存储后端
+	}
+	if usePathStyle, ok := rawData["oss.use_path_style"].(bool); ok {
+		ossConfig.UsePathStyle = usePathStyle // S3 路径风格访问
+	}
 
-	// 验证OSS配置。
-	if err = tools.AnalyzeOssConfig(&ossConfig); err != nil {
+	// 根据对象存储后端验证连接配置
+	switch storage.ObjectStoreProvider(&ossConfig) {
+	case ossstore.AliyunProvider:
+		err = tools.AnalyzeOssConfig(&ossConfig)
+	case ossstore.S3Provider:
+		err = tools.AnalyzeS3Config(&ossConfig)
+	case ossstore.LocalProvider:
+	default:
+		err = fmt.Errorf("不支持的对象存储类型: %s", ossConfig.Provider)
+	}
+	if err != nil {
 		// 如果连接配置验证失败，返回400错误响应。
 		msg := fmt.Sprintf("OSS连接配置错误: %s", err.Error())
 		resp.BadRequest(ctx, msg, nil)
@@ -1178,6 +1304,8 @@ func updateOssConfig(ctx *gin.Context) {
 //     - AOF文件存储目录路径 (aof_dir_path)
 //     - AOF文件大小限制 (aof_mix_size)
 //     - AOF文件是否压缩 (aof_compress)
+//     - AOF刷盘策略 (aof_fsync)
+//     - 缓存容量限制与淘汰策略 (eviction)
 //  2. 将配置信息封装为map结构返回给客户端
 func getCacheAndIndexConfig(ctx *gin.Context) {
 	resp.Ok(ctx, "获取成功", map[string]any{
@@ -1185,7 +1313,13 @@ func getCacheAndIndexConfig(ctx *gin.Context) {
 		"aof_dir_path": filepath.Dir(config.Cache.Aof.Path),
 		"aof_mix_size": config.Cache.Aof.MaxSize,
 		"aof_compress": config.Cache.Aof.Compress,
-		"index_path":   config.SearchEngine.IndexPath,
+		"aof_fsync":    config.Cache.Aof.Fsync,
+		"eviction": map[string]any{
+			"max_entries": config.Cache.Eviction.MaxEntries,
+			"max_bytes":   config.Cache.Eviction.MaxBytes,
+			"policy":      config.Cache.Eviction.Policy,
+		},
+		"index_path": config.SearchEngine.IndexPath,
 	})
 }
 
@@ -1200,6 +1334,7 @@ func getCacheAndIndexConfig(ctx *gin.Context) {
 //     - AOF文件存储路径
 //     - AOF文件大小限制
 //     - AOF文件压缩选项
+//     - AOF刷盘策略（可选）
 //  3. 更新系统配置并保存
 //  4. 返回操作结果
 func updateCacheAndIndexConfig(ctx *gin.Context) {
@@ -1211,8 +1346,8 @@ func updateCacheAndIndexConfig(ctx *gin.Context) {
 		return
 	}
 
-	// 初始化缓存配置结构体。
-	cacheConfig := config.CacheConfig{}
+	// 以当前缓存配置为基础，保留过期清理与淘汰策略等未在此处修改的配置。
+	cacheConfig := config.Cache
 
 	// 从原始数据中提取并清理缓存配置参数
 	cacheConfig.Aof.Enable, err = tools.GetBoolFromRawData(rawData, "cache.aof.enable") // AOF持久化是否启用
@@ -1248,6 +1383,23 @@ func updateCacheAndIndexConfig(ctx *gin.Context) {
 		return
 	}
 
+	// 刷盘策略为可选参数，未提供时保留当前配置
+	if _, ok := rawData["cache.aof.fsync"]; ok {
+		cacheConfig.Aof.Fsync, err = tools.GetStringFromRawData(rawData, "cache.aof.fsync")
+		if err != nil {
+			msg := fmt.Sprintf("AOF刷盘策略配置错误: %s", err.Error())
+			resp.BadRequest(ctx, msg, nil)
+			return
+		}
+		switch cacheConfig.Aof.Fsync {
+		case config.FsyncAlways, config.FsyncEverySec, config.FsyncNo:
+		default:
+			msg := fmt.Sprintf("AOF刷盘策略配置错误: 不支持的策略 %s", cacheConfig.Aof.Fsync)
+			resp.BadRequest(ctx, msg, nil)
+			return
+		}
+	}
+
 	// 从请求中获取文本搜索引擎索引文件路径
 	indexPath, err := tools.GetStringFromRawData(rawData, "search_engine.index_path")
 	if err != nil {
@@ -1339,6 +1491,323 @@ func rebuildIndex(ctx *gin.Context) {
 	})
 }
 
+// saveCacheSnapshot 生成缓存的时间点快照
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，包含请求参数和响应方法
+//
+// 功能描述:
+//  1. 将缓存中的全部数据保存为快照文件，启用 AOF 时同时用快照替换之前的 AOF
+//  2. 返回快照文件的路径、大小与键的数量，可以将该文件加入站点备份
+//  3. 从备份恢复时，停止服务后清空 AOF 目录并放入快照文件，启动时会加载最新的快照
+func saveCacheSnapshot(ctx *gin.Context) {
+	snapshotCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	info, err := storage.Storage.Cache.SaveSnapshot(snapshotCtx)
+	if err != nil {
+		msg := fmt.Sprintf("生成缓存快照失败: %s", err.Error())
+		logger.Error(msg)
+		resp.Err(ctx, msg, nil)
+		return
+	}
+
+	resp.Ok(ctx, "生成缓存快照成功", info)
+}
+
+// getCacheStats 获取缓存的统计信息
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文
+//
+// 功能描述:
+//  1. 返回键数量、估算的内存占用与容量限制
+//  2. 按键前缀返回键数量、内存占用以及启动以来的命中、未命中与淘汰次数
+//  3. 返回 AOF 文件大小、轮转与重写次数，以及现有的快照文件
+func getCacheStats(ctx *gin.Context) {
+	stats, err := storage.Storage.Cache.Stats(ctx)
+	if err != nil {
+		msg := fmt.Sprintf("获取缓存统计信息失败: %s", err.Error())
+		logger.Error(msg)
+		resp.Err(ctx, msg, nil)
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", stats)
+}
+
+// scanCacheKeys 按 glob 模式分批列出缓存中的键
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，查询参数 cursor 为上一批返回的游标（首次为 0），
+//     pattern 为匹配模式（为空时匹配所有键），count 为每批大约检查的键数量
+//
+// 返回的游标为 0 时表示遍历结束
+func scanCacheKeys(ctx *gin.Context) {
+	cursor, err := strconv.ParseUint(ctx.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		resp.BadRequest(ctx, "游标格式错误", err.Error())
+		return
+	}
+	count, err := strconv.Atoi(ctx.DefaultQuery("count", "100"))
+	if err != nil || count <= 0 || count > 1000 {
+		resp.BadRequest(ctx, "请求参数错误", "count 必须在 1 到 1000 之间")
+		return
+	}
+
+	next, keys, err := storage.Storage.Cache.Scan(ctx, cursor, ctx.Query("pattern"), count)
+	if err != nil {
+		resp.Err(ctx, "遍历缓存失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", map[string]any{
+		"cursor": strconv.FormatUint(next, 10),
+		"keys":   keys,
+	})
+}
+
+// inspectCacheKey 查看单个缓存键
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，查询参数 key 为要查看的键
+//
+// 只读取键的类型、大小、过期时间与值的预览，不影响淘汰顺序与命中统计
+func inspectCacheKey(ctx *gin.Context) {
+	key := ctx.Query("key")
+	if strings.TrimSpace(key) == "" {
+		resp.BadRequest(ctx, "请求参数错误", "key 不能为空")
+		return
+	}
+
+	info, err := storage.Storage.Cache.Inspect(ctx, key)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			resp.MakeResp(ctx, http.StatusNotFound, "缓存键不存在", key)
+			return
+		}
+		resp.Err(ctx, "查看缓存键失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", info)
+}
+
+// deleteCacheKey 删除单个缓存键
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，查询参数 key 为要删除的键
+//
+// 只删除完全匹配的一个键，不支持通配符；键不存在时返回 404，删除会记录到 AOF 与日志
+func deleteCacheKey(ctx *gin.Context) {
+	key := ctx.Query("key")
+	if strings.TrimSpace(key) == "" {
+		resp.BadRequest(ctx, "请求参数错误", "key 不能为空")
+		return
+	}
+
+	info, err := storage.Storage.Cache.Inspect(ctx, key)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			resp.MakeResp(ctx, http.StatusNotFound, "缓存键不存在", key)
+			return
+		}
+		resp.Err(ctx, "删除缓存键失败", err.Error())
+		return
+	}
+
+	if err := storage.Storage.Cache.Delete(ctx, key); err != nil {
+		msg := fmt.Sprintf("删除缓存键 %s 失败: %s", key, err.Error())
+		logger.Error(msg)
+		resp.Err(ctx, msg, nil)
+		return
+	}
+	logger.Info("管理员删除了缓存键: %s，类型 %s，大小 %d 字节", key, info.Type, info.Size)
+
+	resp.Ok(ctx, "删除成功", nil)
+}
+
+// getOutboxEmails 查询发件箱中的邮件，按创建时间倒序排列
+// RESTful API: GET /admin/emails?status=dead
+//
+// @param ctx *gin.Context - Gin上下文，查询参数 status 为发送状态（pending/sending/sent/dead/cancelled），为空时查询所有状态
+// @return 无返回值，通过resp包响应邮件列表
+func getOutboxEmails(ctx *gin.Context) {
+	emails, err := adminservices.GetOutboxEmails(ctx, ctx.Query("status"))
+	if err != nil {
+		resp.BadRequest(ctx, "获取发件箱邮件失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", emails)
+}
+
+// retryOutboxEmail 立即重新发送死信、已取消或等待重试的邮件
+// RESTful API: PUT /admin/emails/:email_id/retry
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func retryOutboxEmail(ctx *gin.Context) {
+	emailId := ctx.Param("email_id")
+	if err := adminservices.RetryOutboxEmail(ctx, emailId); err != nil {
+		resp.BadRequest(ctx, "重试发送邮件失败", err.Error())
+		return
+	}
+	logger.Info("管理员重试发送邮件: %s", emailId)
+
+	resp.Ok(ctx, "已重新加入发送队列", nil)
+}
+
+// cancelOutboxEmail 取消待发送或死信状态的邮件
+// RESTful API: PUT /admin/emails/:email_id/cancel
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func cancelOutboxEmail(ctx *gin.Context) {
+	emailId := ctx.Param("email_id")
+	if err := adminservices.CancelOutboxEmail(ctx, emailId); err != nil {
+		resp.BadRequest(ctx, "取消发送邮件失败", err.Error())
+		return
+	}
+	logger.Info("管理员取消发送邮件: %s", emailId)
+
+	resp.Ok(ctx, "已取消发送", nil)
+}
+
+// listEmailTemplates 列出所有邮件模板及每种语言下是否被覆盖
+// RESTful API: GET /admin/email-templates
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应模板列表
+func listEmailTemplates(ctx *gin.Context) {
+	templates, err := email.ListTemplates()
+	if err != nil {
+		resp.Err(ctx, "获取邮件模板失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", map[string]any{
+		"templates": templates,
+		"locales":   email.Locales(),
+		"locale":    email.ResolveLocale(config.Email.Locale),
+	})
+}
+
+// getEmailTemplate 获取生效的邮件模板源码
+// RESTful API: GET /admin/email-templates/:name?locale=en-US
+//
+// @param ctx *gin.Context - Gin上下文，查询参数 locale 为空时使用配置的语言
+// @return 无返回值，通过resp包响应模板源码
+func getEmailTemplate(ctx *gin.Context) {
+	name, locale := ctx.Param("name"), templateLocale(ctx.Query("locale"))
+	src, overridden, err := email.LoadTemplate(name, locale)
+	if err != nil {
+		resp.BadRequest(ctx, "获取邮件模板失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取成功", map[string]any{
+		"name":       name,
+		"locale":     locale,
+		"overridden": overridden,
+		"source":     src,
+	})
+}
+
+// previewEmailTemplate 使用示例数据预览邮件模板，不保存
+// RESTful API: POST /admin/email-templates/:name/preview
+// 请求体: {"locale": "zh-CN", "subject": "...", "html": "...", "text": "..."}，未提供的部分使用当前生效的模板
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应渲染后的主题与正文
+func previewEmailTemplate(ctx *gin.Context) {
+	locale, src, ok := getTemplateSourceFromRawData(ctx)
+	if !ok {
+		return
+	}
+
+	msg, err := email.PreviewTemplate(ctx.Param("name"), locale, src)
+	if err != nil {
+		resp.BadRequest(ctx, "邮件模板无效", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "预览成功", map[string]string{
+		"subject": msg.Subject,
+		"html":    msg.Content,
+		"text":    msg.Text,
+	})
+}
+
+// updateEmailTemplate 校验并保存邮件模板，覆盖内置模板
+// RESTful API: PUT /admin/email-templates/:name
+// 请求体: {"locale": "zh-CN", "subject": "...", "html": "...", "text": "..."}，三个部分都必须提供
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func updateEmailTemplate(ctx *gin.Context) {
+	locale, src, ok := getTemplateSourceFromRawData(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	if err := email.SaveTemplate(name, locale, *src); err != nil {
+		resp.BadRequest(ctx, "保存邮件模板失败", err.Error())
+		return
+	}
+	logger.Info("管理员更新了邮件模板: %s/%s", locale, name)
+
+	resp.Ok(ctx, "保存成功", nil)
+}
+
+// resetEmailTemplate 删除覆盖的邮件模板，恢复使用内置模板
+// RESTful API: DELETE /admin/email-templates/:name?locale=zh-CN
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func resetEmailTemplate(ctx *gin.Context) {
+	name, locale := ctx.Param("name"), templateLocale(ctx.Query("locale"))
+	if err := email.ResetTemplate(name, locale); err != nil {
+		resp.BadRequest(ctx, "恢复邮件模板失败", err.Error())
+		return
+	}
+	logger.Info("管理员恢复了内置邮件模板: %s/%s", locale, name)
+
+	resp.Ok(ctx, "已恢复内置模板", nil)
+}
+
+// templateLocale 返回请求的模板语言，未指定时使用配置的语言
+func templateLocale(locale string) string {
+	if locale == "" {
+		return email.ResolveLocale(config.Email.Locale)
+	}
+	return locale
+}
+
+// getTemplateSourceFromRawData 从请求体中获取模板语言与源码，未提供的部分为空字符串，失败时已经写入错误响应
+func getTemplateSourceFromRawData(ctx *gin.Context) (string, *email.TemplateSource, bool) {
+	rawData, err := tools.GetMapFromRawData(ctx)
+	if err != nil {
+		resp.BadRequest(ctx, "请求数据解析失败", err.Error())
+		return "", nil, false
+	}
+
+	fields := map[string]string{"locale": "", "subject": "", "html": "", "text": ""}
+	for key := range fields {
+		if _, exists := rawData[key]; !exists {
+			continue
+		}
+		value, err := tools.GetStringFromRawData(rawData, key)
+		if err != nil {
+			resp.BadRequest(ctx, "请求参数错误", err.Error())
+			return "", nil, false
+		}
+		fields[key] = value
+	}
+
+	return templateLocale(fields["locale"]), &email.TemplateSource{
+		Subject: fields["subject"],
+		HTML:    fields["html"],
+		Text:    fields["text"],
+	}, true
+}
+
 // getAllComments 获取所有评论（管理员用）
 // @param ctx *gin.Context - Gin上下文
 // @return 无返回值，通过resp包响应评论数据
@@ -1421,3 +1890,218 @@ func deleteCommentWithSubComments(ctx *gin.Context) {
 	// 返回成功响应
 	resp.Ok(ctx, "评论删除成功", nil)
 }
+
+// getPendingComments 获取待审核的评论（管理员用）
+// RESTful API: GET /admin/comments/pending
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应评论数据
+func getPendingComments(ctx *gin.Context) {
+	comments, err := adminservices.GetPendingComments(ctx)
+	if err != nil {
+		resp.Err(ctx, "获取待审核评论失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取待审核评论成功", comments)
+}
+
+// approveComments 批量通过评论审核（管理员用），通过的回复会通知被回复的评论者
+// RESTful API: PUT /admin/comments/approve
+// 请求体: {"comment_ids": ["..."]}
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func approveComments(ctx *gin.Context) {
+	commentIds, ok := getCommentIdsFromRawData(ctx)
+	if !ok {
+		return
+	}
+
+	approved, err := adminservices.ApproveComments(ctx, commentIds)
+	if err != nil {
+		resp.Err(ctx, "审核评论失败: "+err.Error(), nil)
+		return
+	}
+
+	// 异步发送回复通知，新评论在提交时已通知博主，被回复的评论者订阅了该楼层时才通知
+	go func(c *gin.Context) {
+		for _, comment := range approved {
+			if comment.ReplyToCommentId == "" {
+				continue
+			}
+			originalComment, err := commentrepo.FindCommentById(c, comment.ReplyToCommentId)
+			if err != nil {
+				continue
+			}
+			unsubscribeUrl, subscribed := webservice.GetReplyUnsubscribeUrl(c, originalComment.CommenterEmail, comment.OriginPostId)
+			if !subscribed {
+				continue
+			}
+			blogTitle, err := blogrepo.FindBlogTitleById(c, comment.BlogId)
+			if err != nil {
+				continue
+			}
+			if err := email.SendCommentOrReplyNotification(
+				c,
+				comment.CommenterEmail,
+				blogTitle,
+				comment.Content,
+				comment.CreateTime.Format("2006-01-02 15:04:05"),
+				comment.ReplyToCommentId,
+				originalComment.Content,
+				originalComment.CommenterEmail,
+				unsubscribeUrl,
+			); err != nil {
+				logger.Warn("发送回复通知邮件失败: %v", err)
+			}
+		}
+	}(ctx.Copy())
+
+	resp.Ok(ctx, "审核通过", map[string]any{"count": len(approved)})
+}
+
+// rejectComments 批量拒绝评论（管理员用）
+// RESTful API: PUT /admin/comments/reject
+// 请求体: {"comment_ids": ["..."]}
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func rejectComments(ctx *gin.Context) {
+	commentIds, ok := getCommentIdsFromRawData(ctx)
+	if !ok {
+		return
+	}
+
+	rejected, err := adminservices.RejectComments(ctx, commentIds)
+	if err != nil {
+		resp.Err(ctx, "拒绝评论失败: "+err.Error(), nil)
+		return
+	}
+
+	resp.Ok(ctx, "已拒绝", map[string]any{"count": len(rejected)})
+}
+
+// getCommentIdsFromRawData 从请求体中获取评论ID列表，失败时已经写入错误响应
+func getCommentIdsFromRawData(ctx *gin.Context) ([]string, bool) {
+	rawData, err := tools.GetMapFromRawData(ctx)
+	if err != nil {
+		resp.BadRequest(ctx, "请求数据解析失败", err.Error())
+		return nil, false
+	}
+
+	commentIds, err := tools.GetStrListFromRawData(rawData, "comment_ids")
+	if err != nil {
+		resp.BadRequest(ctx, "评论ID列表解析失败", err.Error())
+		return nil, false
+	}
+	if len(commentIds) == 0 {
+		resp.BadRequest(ctx, "评论ID列表不能为空", nil)
+		return nil, false
+	}
+
+	return commentIds, true
+}
+
+// getCommentConfig 获取评论配置信息
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，包含请求参数和响应方法
+//
+// 功能描述:
+//
+//	从系统配置中获取评论审核模式 (moderation)、反垃圾配置 (spam) 与头像服务地址 (avatar_base_url)
+func getCommentConfig(ctx *gin.Context) {
+	moderation := config.Comment.Moderation
+	if moderation == "" {
+		moderation = config.ModerationAutoApprove
+	}
+	avatarBaseUrl := config.Comment.AvatarBaseUrl
+	if avatarBaseUrl == "" {
+		avatarBaseUrl = config.DefaultAvatarBaseUrl
+	}
+
+	resp.Ok(ctx, "获取成功", map[string]any{
+		"moderation":      moderation,
+		"avatar_base_url": avatarBaseUrl,
+		"spam": map[string]any{
+			"max_links":        config.Comment.Spam.MaxLinks,
+			"blocked_keywords": config.Comment.Spam.BlockedKeywords,
+			"rate_burst":       config.Comment.Spam.RateBurst,
+			"rate_interval":    config.Comment.Spam.RateInterval,
+			"bayes_threshold":  config.Comment.Spam.BayesThreshold,
+		},
+	})
+}
+
+// updateCommentConfig 更新评论配置信息
+// 参数:
+//   - ctx *gin.Context: HTTP请求上下文，包含请求参数和响应方法
+//
+// 功能描述:
+//  1. 从请求中解析并验证评论审核模式 (comment.moderation)
+//  2. 解析可选的反垃圾配置 (comment.spam.*) 与头像服务地址 (comment.avatar_base_url)，未传入的保持原值
+//  3. 更新系统配置并保存
+func updateCommentConfig(ctx *gin.Context) {
+	rawData, err := tools.GetMapFromRawData(ctx)
+	if err != nil {
+		msg := fmt.Sprintf("请求数据有误，请检查错误: %s", err.Error())
+		resp.BadRequest(ctx, msg, nil)
+		return
+	}
+
+	moderation, getErr := tools.GetStringFromRawData(rawData, "comment.moderation")
+	if getErr != nil {
+		msg := fmt.Sprintf("评论审核模式配置错误: %s", getErr.Error())
+		resp.BadRequest(ctx, msg, nil)
+		return
+	}
+	if anaErr := tools.AnalyzeCommentModeration(moderation); anaErr != nil {
+		msg := fmt.Sprintf("评论审核模式配置错误: %s", anaErr.Error())
+		resp.BadRequest(ctx, msg, nil)
+		return
+	}
+
+	spamConf := config.Comment.Spam
+	if keywords, getErr := tools.GetStrListFromRawData(rawData, "comment.spam.blocked_keywords"); getErr == nil {
+		spamConf.BlockedKeywords = keywords
+	}
+	if maxLinks, getErr := tools.GetUInt16FromRawData(rawData, "comment.spam.max_links"); getErr == nil {
+		spamConf.MaxLinks = int(maxLinks)
+	}
+	if rateBurst, getErr := tools.GetUInt16FromRawData(rawData, "comment.spam.rate_burst"); getErr == nil {
+		spamConf.RateBurst = int(rateBurst)
+	}
+	if rateInterval, getErr := tools.GetUInt16FromRawData(rawData, "comment.spam.rate_interval"); getErr == nil {
+		spamConf.RateInterval = int(rateInterval)
+	}
+	if threshold, getErr := tools.GetFloatFromRawData(rawData, "comment.spam.bayes_threshold"); getErr == nil {
+		if threshold <= 0 || threshold > 1 {
+			resp.BadRequest(ctx, "贝叶斯分类器阈值必须在 0 到 1 之间", nil)
+			return
+		}
+		// float32 转换为 float64 后保留三位小数，避免写入配置文件的值出现误差
+		spamConf.BayesThreshold = math.Round(float64(threshold)*1000) / 1000
+	}
+
+	avatarBaseUrl := config.Comment.AvatarBaseUrl
+	if rawAvatarBaseUrl, getErr := tools.GetStringFromRawData(rawData, "comment.avatar_base_url"); getErr == nil {
+		if anaErr := tools.AnalyzeAvatarBaseUrl(rawAvatarBaseUrl); anaErr != nil {
+			resp.BadRequest(ctx, anaErr.Error(), nil)
+			return
+		}
+		avatarBaseUrl = strings.TrimSpace(rawAvatarBaseUrl)
+	}
+
+	config.Comment = config.CommentConfig{
+		Moderation:    moderation,
+		Spam:          spamConf,
+		AvatarBaseUrl: avatarBaseUrl,
+	}
+
+	if upErr := adminservices.UpdateConfig(); upErr != nil {
+		resp.Err(ctx, "更新失败", upErr.Error())
+		return
+	}
+
+	resp.Ok(ctx, "更新成功", nil)
+}
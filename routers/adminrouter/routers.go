@@ -68,6 +68,12 @@ func Routers(e *gin.Engine) {
 		editGroup.POST("/update-or-add-blog", updateOrAddBlog)
 
 		editGroup.GET("/blog-data/:blog_id", getBlogData)
+
+		editGroup.GET("/revisions/:blog_id", getBlogRevisions)
+
+		editGroup.GET("/revisions/:blog_id/diff", diffBlogRevisions)
+
+		editGroup.POST("/revisions/:blog_id/:revision_id/restore", restoreBlogRevision)
 	}
 
 	{
@@ -122,6 +128,63 @@ func Routers(e *gin.Engine) {
 		settingGroup.PUT("/cache-index/config", updateCacheAndIndexConfig)
 
 		settingGroup.PUT("/cache-index/rebuild-index", rebuildIndex)
+
+		settingGroup.POST("/cache-index/snapshot", saveCacheSnapshot)
+
+		settingGroup.GET("/comment/config", getCommentConfig)
+
+		settingGroup.PUT("/comment/config", updateCommentConfig)
+	}
+
+	{
+		cacheGroup := adminGroup.Group("/cache")
+
+		if env.CurrentEnv == env.ProdEnv {
+			cacheGroup.Use(middleware.AnalyzeJWT())
+		}
+
+		cacheGroup.GET("/stats", getCacheStats)
+
+		cacheGroup.GET("/keys", scanCacheKeys)
+
+		cacheGroup.GET("/key", inspectCacheKey)
+
+		cacheGroup.DELETE("/key", deleteCacheKey)
+	}
+
+	{
+		emailGroup := adminGroup.Group("/emails")
+
+		if env.CurrentEnv == env.ProdEnv {
+			emailGroup.Use(middleware.AnalyzeJWT())
+		}
+
+		// 发件箱，查看发送状态并处理发送失败的邮件
+		emailGroup.GET("", getOutboxEmails)
+
+		emailGroup.PUT("/:email_id/retry", retryOutboxEmail)
+
+		emailGroup.PUT("/:email_id/cancel", cancelOutboxEmail)
+	}
+
+	{
+		emailTemplateGroup := adminGroup.Group("/email-templates")
+
+		if env.CurrentEnv == env.ProdEnv {
+			emailTemplateGroup.Use(middleware.AnalyzeJWT())
+		}
+
+		emailTemplateGroup.GET("", listEmailTemplates)
+
+		emailTemplateGroup.GET("/:name", getEmailTemplate)
+
+		// 使用示例数据预览，不保存
+		emailTemplateGroup.POST("/:name/preview", previewEmailTemplate)
+
+		emailTemplateGroup.PUT("/:name", updateEmailTemplate)
+
+		// 删除覆盖的模板，恢复使用内置模板
+		emailTemplateGroup.DELETE("/:name", resetEmailTemplate)
 	}
 
 	{
@@ -136,5 +199,12 @@ func Routers(e *gin.Engine) {
 		commentGroup.PUT("/:comment_id/content", updateCommentContent)
 
 		commentGroup.DELETE("/:comment_id", deleteCommentWithSubComments)
+
+		// 评论审核
+		commentGroup.GET("/pending", getPendingComments)
+
+		commentGroup.PUT("/approve", approveComments)
+
+		commentGroup.PUT("/reject", rejectComments)
 	}
 }
@@ -47,3 +47,8 @@ func TokenIsUnauthorized(ctx *gin.Context, msg string, data any) {
 func RedirectUrl(ctx *gin.Context, url string) {
 	ctx.Redirect(http.StatusFound, url)
 }
+
+// Forbidden 无权访问
+func Forbidden(ctx *gin.Context, msg string, data any) {
+	MakeResp(ctx, http.StatusForbidden, msg, data)
+}
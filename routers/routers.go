@@ -2,6 +2,8 @@ package routers
 
 import (
 	"github.com/gin-gonic/gin"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/routers/middleware"
 )
 
@@ -19,6 +21,7 @@ func IncludeOpts(opts ...Option) {
 func InitRouter() *gin.Engine {
 	// 创建一个没有任何中间件的路由
 	r := gin.New()
+	setTrustedProxies(r, config.Server.TrustedProxies)
 
 	// 添加自定义的中间件
 	r.Use(middleware.Logger(), middleware.RunTimeCors(), gin.Recovery())
@@ -30,3 +33,16 @@ func InitRouter() *gin.Engine {
 	options = make([]Option, 0)
 	return r
 }
+
+// setTrustedProxies 只信任配置的反向代理转发的客户端地址，未配置时使用连接的对端地址，
+// 避免客户端伪造 X-Forwarded-For 或 X-Real-IP 绕过按 IP 的评论限流。
+// 配置有误时不信任任何代理。
+func setTrustedProxies(r *gin.Engine, proxies []string) {
+	if len(proxies) == 0 {
+		proxies = nil
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		logger.Error("可信反向代理配置错误，不信任任何代理: %v", err)
+		_ = r.SetTrustedProxies(nil)
+	}
+}
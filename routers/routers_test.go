@@ -0,0 +1,64 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	config.LoadConfig()
+	_ = logger.InitLogger(context.Background())
+}
+
+// clientIp 返回服务端识别出的客户端地址，评论限流以该地址作为键
+func clientIp(t *testing.T, proxies []string, remoteAddr string, headers map[string]string) string {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setTrustedProxies(r, proxies)
+	r.GET("/ip", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.ClientIP())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("请求失败: %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestSetTrustedProxies(t *testing.T) {
+	spoofed := map[string]string{"X-Forwarded-For": "9.9.9.9", "X-Real-IP": "8.8.8.8"}
+
+	// 未配置可信代理时，伪造的请求头不会改变限流使用的客户端地址
+	for _, headers := range []map[string]string{nil, spoofed, {"X-Forwarded-For": "7.7.7.7"}} {
+		if ip := clientIp(t, nil, "1.2.3.4:5678", headers); ip != "1.2.3.4" {
+			t.Errorf("期望使用连接的对端地址，实际得到 %s, 请求头 %v", ip, headers)
+		}
+	}
+
+	// 请求来自可信代理时使用代理转发的客户端地址，否则忽略请求头
+	proxies := []string{"10.0.0.0/8"}
+	if ip := clientIp(t, proxies, "10.0.0.1:5678", spoofed); ip != "9.9.9.9" {
+		t.Errorf("期望使用可信代理转发的客户端地址，实际得到 %s", ip)
+	}
+	if ip := clientIp(t, proxies, "1.2.3.4:5678", spoofed); ip != "1.2.3.4" {
+		t.Errorf("不是可信代理时期望忽略请求头，实际得到 %s", ip)
+	}
+
+	// 配置错误时不信任任何代理
+	if ip := clientIp(t, []string{"not-an-ip"}, "10.0.0.1:5678", spoofed); ip != "10.0.0.1" {
+		t.Errorf("配置错误时期望不信任任何代理，实际得到 %s", ip)
+	}
+}
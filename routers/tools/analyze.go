@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/storage/ossstore/s3"
 	"strings"
 
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
@@ -160,6 +161,63 @@ func AnalyzeOssConfig(ossConfig *config.OssConfig) error {
 	return nil
 }
 
+// AnalyzeS3Config 解析 S3 兼容对象存储配置
+func AnalyzeS3Config(ossConfig *config.OssConfig) error {
+	store, err := s3.NewStore(context.Background(), ossConfig)
+	if err != nil {
+		return err
+	}
+
+	return store.CheckBucket(context.Background())
+}
+
+// AnalyzeSiteUrl 分析站点访问地址，允许为空，非空时必须是 http 或 https 的绝对地址
+func AnalyzeSiteUrl(siteUrl string) error {
+	siteUrl = strings.TrimSpace(siteUrl)
+	if siteUrl == "" {
+		return nil
+	}
+
+	u, err := url.Parse(siteUrl)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("站点地址 %v 必须以 http:// 或 https:// 开头", siteUrl)
+	}
+
+	return nil
+}
+
+// AnalyzeAvatarBaseUrl 分析评论者头像服务地址，允许为空，非空时必须是 http 或 https 的绝对地址
+func AnalyzeAvatarBaseUrl(baseUrl string) error {
+	baseUrl = strings.TrimSpace(baseUrl)
+	if baseUrl == "" {
+		return nil
+	}
+
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("头像服务地址 %v 必须以 http:// 或 https:// 开头", baseUrl)
+	}
+
+	return nil
+}
+
+// AnalyzeCommentModeration 分析评论审核模式
+func AnalyzeCommentModeration(moderation string) error {
+	switch moderation {
+	case config.ModerationAutoApprove, config.ModerationHoldAll, config.ModerationHoldFirstTime:
+		return nil
+	default:
+		return fmt.Errorf("评论审核模式 %v 无效，可选值: %s、%s、%s", moderation,
+			config.ModerationAutoApprove, config.ModerationHoldAll, config.ModerationHoldFirstTime)
+	}
+}
+
 func AnalyzeHostAddress(host string) error {
 	// 域名验证正则表达式
 	domainRegex := `^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`
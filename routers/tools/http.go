@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"net/http"
+	"sparrow_blog_server/pkg/config"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SiteUrl 获取配置的博客前台访问地址 server.site_url，未配置时返回空字符串
+// 不使用请求中的 Host、X-Forwarded-Proto 等可以伪造的请求头，避免缓存或 CDN 将伪造的链接返回给其他读者
+func SiteUrl() string {
+	return strings.TrimRight(strings.TrimSpace(config.Server.SiteUrl), "/")
+}
+
+// ApiUrl 获取配置的服务端对外访问地址 server.api_url，未配置时返回空字符串
+func ApiUrl() string {
+	return strings.TrimRight(strings.TrimSpace(config.Server.ApiUrl), "/")
+}
+
+// CheckNotModified 设置 ETag 与 Last-Modified 响应头，并判断客户端缓存是否仍然有效
+// 参数:
+//   - ctx: HTTP请求上下文
+//   - etag: 资源的实体标签，需要包含双引号
+//   - lastModified: 资源的最后修改时间，为零值时不设置
+//
+// 返回值:
+//   - bool: 客户端缓存有效时返回 true，此时已经写入 304 响应，调用方直接返回即可
+func CheckNotModified(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	ctx.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if inm := ctx.GetHeader("If-None-Match"); inm != "" {
+		// 存在 If-None-Match 时忽略 If-Modified-Since，GET 请求使用弱比较
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				notModified = true
+				break
+			}
+		}
+	} else if ims := ctx.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil && !lastModified.Truncate(time.Second).After(t) {
+			notModified = true
+		}
+	}
+
+	if notModified {
+		ctx.Status(http.StatusNotModified)
+	}
+	return notModified
+}
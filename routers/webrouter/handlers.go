@@ -1,17 +1,31 @@
 package webrouter
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"sparrow_blog_server/internal/model/dto"
 	"sparrow_blog_server/internal/repositories/commentrepo"
 	"sparrow_blog_server/internal/services/adminservices"
 	"sparrow_blog_server/internal/services/webservice"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/email"
+	"sparrow_blog_server/pkg/feed"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/pkg/sitemap"
+	"sparrow_blog_server/pkg/spam"
 	"sparrow_blog_server/routers/resp"
 	"sparrow_blog_server/routers/tools"
 	"sparrow_blog_server/searchengine"
+	"sparrow_blog_server/storage/ossstore"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,6 +61,9 @@ func redirectImgReq(ctx *gin.Context) {
 }
 
 // getBlogData 获取博客详细数据
+// RESTful API: GET /web/blog/:blog_id?render=html
+//
+// 查询参数 render=html 时额外返回服务端渲染的 HTML 与目录
 // @param ctx *gin.Context - Gin上下文
 // @return 无返回值，通过resp包响应数据
 func getBlogData(ctx *gin.Context) {
@@ -61,11 +78,71 @@ func getBlogData(ctx *gin.Context) {
 		return
 	}
 
-	// 获取成功，返回博客数据和预签名URL
-	resp.Ok(ctx, "获取成功", map[string]any{
+	data := map[string]any{
 		"blog_data":    blogData,
 		"pre_sign_url": preUrl,
-	})
+	}
+
+	// 需要时返回渲染后的 HTML 与目录
+	if ctx.Query("render") == "html" {
+		rendered, err := webservice.GetRenderedBlog(ctx, blogData.BlogId, blogData.BlogTitle, blogData.UpdateTime)
+		if err != nil {
+			resp.Err(ctx, "渲染博客内容失败", err.Error())
+			return
+		}
+		data["rendered"] = rendered
+	}
+
+	// 获取成功，返回博客数据和预签名URL
+	resp.Ok(ctx, "获取成功", data)
+}
+
+// maxLocalObjectSize 本地对象存储单次上传的最大字节数
+const maxLocalObjectSize = 32 << 20
+
+// getLocalObject 通过预签名 URL 下载本地对象存储中的对象
+// RESTful API: GET /web/object/*object_name?expires=&signature=
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回文件内容
+func getLocalObject(ctx *gin.Context) {
+	objectName := strings.TrimPrefix(ctx.Param("object_name"), "/")
+
+	path, err := webservice.VerifyLocalObjectRequest(ctx, ossstore.Get, objectName, "", ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		resp.Forbidden(ctx, "访问对象失败", err.Error())
+		return
+	}
+
+	ctx.File(path)
+}
+
+// putLocalObject 通过预签名 URL 向本地对象存储上传对象
+// RESTful API: PUT /web/object/*object_name?expires=&signature=
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应结果
+func putLocalObject(ctx *gin.Context) {
+	objectName := strings.TrimPrefix(ctx.Param("object_name"), "/")
+
+	_, err := webservice.VerifyLocalObjectRequest(ctx, ossstore.Put, objectName, ctx.ContentType(), ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		resp.Forbidden(ctx, "上传对象失败", err.Error())
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxLocalObjectSize))
+	if err != nil {
+		resp.BadRequest(ctx, "读取上传内容失败", err.Error())
+		return
+	}
+
+	if err := webservice.PutLocalObject(ctx, objectName, content); err != nil {
+		resp.Err(ctx, "上传对象失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "上传成功", nil)
 }
 
 // searchContent 搜索内容
@@ -152,6 +229,7 @@ func searchContent(ctx *gin.Context) {
 
 // getCommentsByBlogId 根据博客ID获取所有评论及子评论
 // RESTful API: GET /web/comment/:blog_id
+// 一次返回所有楼层，评论较多时使用 getCommentThreads 分页获取
 //
 // @param ctx *gin.Context - Gin上下文
 // @return 无返回值，通过resp包响应评论数据
@@ -174,6 +252,78 @@ func getCommentsByBlogId(ctx *gin.Context) {
 	resp.Ok(ctx, "获取评论成功", comments)
 }
 
+// getCommentThreads 分页获取博客的楼主评论，只返回每个楼层的回复数量
+// RESTful API: GET /web/comment/:blog_id/threads?sort=newest&cursor=&limit=10
+// 查询参数 sort 为排序方式（newest、oldest 或 most_replied，默认 newest），
+// cursor 为上一页返回的 next_cursor（首页为空），limit 为每页数量
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应楼主评论与下一页的游标
+func getCommentThreads(ctx *gin.Context) {
+	blogId := ctx.Param("blog_id")
+	if blogId == "" {
+		resp.BadRequest(ctx, "博客ID不能为空", nil)
+		return
+	}
+	limit, ok := commentPageLimit(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := webservice.GetCommentThreads(ctx, blogId, ctx.Query("sort"), ctx.Query("cursor"), limit)
+	if errors.Is(err, webservice.ErrInvalidCommentPage) {
+		resp.BadRequest(ctx, "请求参数错误", err.Error())
+		return
+	} else if err != nil {
+		resp.Err(ctx, "获取评论失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取评论成功", page)
+}
+
+// getCommentReplies 按发表时间正序分页获取楼层中的回复
+// RESTful API: GET /web/comment/replies/:comment_id?cursor=&limit=10
+// comment_id 为楼主评论ID，查询参数与 getCommentThreads 相同
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包响应回复与下一页的游标
+func getCommentReplies(ctx *gin.Context) {
+	commentId := ctx.Param("comment_id")
+	if commentId == "" {
+		resp.BadRequest(ctx, "评论ID不能为空", nil)
+		return
+	}
+	limit, ok := commentPageLimit(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := webservice.GetCommentReplies(ctx, commentId, ctx.Query("cursor"), limit)
+	if errors.Is(err, webservice.ErrCommentNotFound) {
+		resp.MakeResp(ctx, http.StatusNotFound, "评论不存在", err.Error())
+		return
+	} else if errors.Is(err, webservice.ErrInvalidCommentPage) {
+		resp.BadRequest(ctx, "请求参数错误", err.Error())
+		return
+	} else if err != nil {
+		resp.Err(ctx, "获取回复失败", err.Error())
+		return
+	}
+
+	resp.Ok(ctx, "获取回复成功", page)
+}
+
+// commentPageLimit 解析查询参数 limit，未指定时使用默认值，无效时响应 400 并返回 false
+func commentPageLimit(ctx *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(webservice.DefaultCommentPageSize)))
+	if err != nil || limit <= 0 || limit > webservice.MaxCommentPageSize {
+		resp.BadRequest(ctx, "请求参数错误", fmt.Sprintf("limit 必须在 1 到 %d 之间", webservice.MaxCommentPageSize))
+		return 0, false
+	}
+	return limit, true
+}
+
 // addComment 添加评论
 // RESTful API: POST /web/comment
 //
@@ -203,6 +353,17 @@ func addComment(ctx *gin.Context) {
 		return
 	}
 
+	// 校验评论者填写的昵称与个人网站
+	if err := webservice.NormalizeCommenterProfile(commentDto); err != nil {
+		resp.BadRequest(ctx, err.Error(), nil)
+		return
+	}
+
+	// 反垃圾与限流检查
+	if !checkCommentSpam(ctx, commentDto) {
+		return
+	}
+
 	// 调用webservice层处理评论添加
 	commentVo, err := webservice.AddComment(ctx, commentDto)
 	if err != nil {
@@ -220,11 +381,21 @@ func addComment(ctx *gin.Context) {
 		}
 
 		// 获取回复的原评论信息（如果是回复）
-		var originalContent, originalCommenterEmail string
-		if commentDto.ReplyToCommentId != "" {
-			if originalComment, err := commentrepo.FindCommentById(ctx.Copy(), commentDto.ReplyToCommentId); err == nil {
+		// 待审核的回复只通知博主，审核通过后再通知被回复的评论者
+		replyToCommentId := commentDto.ReplyToCommentId
+		if commentVo.Status == dto.CommentStatusPending {
+			replyToCommentId = ""
+		}
+		var originalContent, originalCommenterEmail, unsubscribeUrl string
+		if replyToCommentId != "" {
+			if originalComment, err := commentrepo.FindCommentById(ctx.Copy(), replyToCommentId); err == nil {
 				originalContent = originalComment.Content
-				originalCommenterEmail = originalComment.CommenterEmail
+				// 被回复的评论者订阅了本楼的回复时才通知
+				var subscribed bool
+				unsubscribeUrl, subscribed = webservice.GetReplyUnsubscribeUrl(ctx.Copy(), originalComment.CommenterEmail, commentVo.OriginPostId)
+				if subscribed {
+					originalCommenterEmail = originalComment.CommenterEmail
+				}
 			}
 		}
 
@@ -235,9 +406,10 @@ func addComment(ctx *gin.Context) {
 			blogData.BlogTitle,
 			commentDto.Content,
 			time.Now().Format("2006-01-02 15:04:05"),
-			commentDto.ReplyToCommentId,
+			replyToCommentId,
 			originalContent,
 			originalCommenterEmail,
+			unsubscribeUrl,
 		); err != nil {
 			// 邮件发送失败只记录日志，不影响主流程
 			// 这里可以添加日志记录
@@ -246,6 +418,10 @@ func addComment(ctx *gin.Context) {
 	}()
 
 	// 返回成功响应
+	if commentVo.Status == dto.CommentStatusPending {
+		resp.Ok(ctx, "评论已提交，等待审核", commentVo)
+		return
+	}
 	resp.Ok(ctx, "评论添加成功", commentVo)
 }
 
@@ -283,6 +459,17 @@ func replyComment(ctx *gin.Context) {
 		return
 	}
 
+	// 校验评论者填写的昵称与个人网站
+	if err := webservice.NormalizeCommenterProfile(commentDto); err != nil {
+		resp.BadRequest(ctx, err.Error(), nil)
+		return
+	}
+
+	// 反垃圾与限流检查
+	if !checkCommentSpam(ctx, commentDto) {
+		return
+	}
+
 	// 调用webservice层处理回复添加
 	commentVo, err := webservice.AddComment(ctx, commentDto)
 	if err != nil {
@@ -300,10 +487,22 @@ func replyComment(ctx *gin.Context) {
 		}
 
 		// 获取回复的原评论信息
-		var originalContent, originalCommenterEmail string
-		if originalComment, err := commentrepo.FindCommentById(ctx.Copy(), commentDto.ReplyToCommentId); err == nil {
-			originalContent = originalComment.Content
-			originalCommenterEmail = originalComment.CommenterEmail
+		// 待审核的回复只通知博主，审核通过后再通知被回复的评论者
+		replyToCommentId := commentDto.ReplyToCommentId
+		if commentVo.Status == dto.CommentStatusPending {
+			replyToCommentId = ""
+		}
+		var originalContent, originalCommenterEmail, unsubscribeUrl string
+		if replyToCommentId != "" {
+			if originalComment, err := commentrepo.FindCommentById(ctx.Copy(), replyToCommentId); err == nil {
+				originalContent = originalComment.Content
+				// 被回复的评论者订阅了本楼的回复时才通知
+				var subscribed bool
+				unsubscribeUrl, subscribed = webservice.GetReplyUnsubscribeUrl(ctx.Copy(), originalComment.CommenterEmail, commentVo.OriginPostId)
+				if subscribed {
+					originalCommenterEmail = originalComment.CommenterEmail
+				}
+			}
 		}
 
 		// 发送评论或回复通知邮件
@@ -313,9 +512,10 @@ func replyComment(ctx *gin.Context) {
 			blogData.BlogTitle,
 			commentDto.Content,
 			time.Now().Format("2006-01-02 15:04:05"),
-			commentDto.ReplyToCommentId,
+			replyToCommentId,
 			originalContent,
 			originalCommenterEmail,
+			unsubscribeUrl,
 		); err != nil {
 			// 邮件发送失败只记录日志，不影响主流程
 			// 这里可以添加日志记录
@@ -324,6 +524,10 @@ func replyComment(ctx *gin.Context) {
 	}()
 
 	// 返回成功响应
+	if commentVo.Status == dto.CommentStatusPending {
+		resp.Ok(ctx, "回复已提交，等待审核", commentVo)
+		return
+	}
 	resp.Ok(ctx, "回复添加成功", commentVo)
 }
 
@@ -343,3 +547,253 @@ func getLatestComments(ctx *gin.Context) {
 	// 返回成功响应
 	resp.Ok(ctx, "获取最新评论成功", comments)
 }
+
+// unsubscribePage 退订确认页与结果页，Token 不为空时展示以 POST 请求提交到当前地址的确认按钮
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>退订回复通知</title>
+</head>
+<body>
+<p>{{.Message}}</p>
+{{- if .Token}}
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">确认退订</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderUnsubscribePage 输出退订确认页或结果页
+// 链接中带有退订令牌，页面不缓存，也不在跳转时通过 Referer 泄露
+func renderUnsubscribePage(ctx *gin.Context, code int, message string, token string) {
+	var buf bytes.Buffer
+	if err := unsubscribePage.Execute(&buf, map[string]string{"Message": message, "Token": token}); err != nil {
+		resp.Err(ctx, "渲染退订页面失败", err.Error())
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Data(code, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// showUnsubscribePage 展示退订确认页，不修改订阅
+// RESTful API: GET /web/comment/unsubscribe?token=
+// 邮件安全扫描与链接预取会访问邮件中的链接，GET 请求只检查令牌并展示确认按钮，点击后以 POST 请求退订。
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回 HTML 页面
+func showUnsubscribePage(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		renderUnsubscribePage(ctx, http.StatusBadRequest, "退订链接缺少令牌", "")
+		return
+	}
+	if err := webservice.CheckUnsubscribeToken(token); err != nil {
+		renderUnsubscribePage(ctx, http.StatusBadRequest, "退订链接无效或已过期", "")
+		return
+	}
+
+	renderUnsubscribePage(ctx, http.StatusOK, "确认不再接收该楼层的评论回复通知吗？", token)
+}
+
+// unsubscribeComment 退订楼层的回复通知
+// RESTful API: POST /web/comment/unsubscribe?token=
+// 邮件客户端的一键退订（RFC 8058）以 POST 请求访问 List-Unsubscribe 中的链接，请求体为 List-Unsubscribe=One-Click，
+// 令牌在查询参数中；确认页提交的表单中同样带有令牌。重复退订返回成功。
+// 浏览器提交确认页时返回 HTML 结果页，其余请求返回 JSON。
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，通过resp包或 HTML 页面响应结果
+func unsubscribeComment(ctx *gin.Context) {
+	fromPage := ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+
+	token := ctx.Query("token")
+	if token == "" {
+		token = ctx.PostForm("token")
+	}
+	if token == "" {
+		if fromPage {
+			renderUnsubscribePage(ctx, http.StatusBadRequest, "退订链接缺少令牌", "")
+			return
+		}
+		resp.BadRequest(ctx, "退订令牌不能为空", nil)
+		return
+	}
+
+	if err := webservice.UnsubscribeByToken(ctx, token); err != nil {
+		if fromPage {
+			renderUnsubscribePage(ctx, http.StatusBadRequest, "退订失败，退订链接无效或已过期", "")
+			return
+		}
+		resp.BadRequest(ctx, "退订失败: "+err.Error(), nil)
+		return
+	}
+
+	if fromPage {
+		renderUnsubscribePage(ctx, http.StatusOK, "已退订该楼层的回复通知", "")
+		return
+	}
+	resp.Ok(ctx, "已退订该楼层的回复通知", nil)
+}
+
+// getRssFeed 获取 RSS 2.0 订阅源
+// RESTful API: GET /web/feed.xml?category=&tag=&content=full
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回订阅源内容
+func getRssFeed(ctx *gin.Context) {
+	serveFeed(ctx, feed.RSSContentType, (*feed.Feed).RSS)
+}
+
+// getAtomFeed 获取 Atom 1.0 订阅源
+// RESTful API: GET /web/atom.xml?category=&tag=&content=full
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回订阅源内容
+func getAtomFeed(ctx *gin.Context) {
+	serveFeed(ctx, feed.AtomContentType, (*feed.Feed).Atom)
+}
+
+// getJsonFeed 获取 JSON Feed 1.1 订阅源
+// RESTful API: GET /web/feed.json?category=&tag=&content=full
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回订阅源内容
+func getJsonFeed(ctx *gin.Context) {
+	serveFeed(ctx, feed.JSONContentType, (*feed.Feed).JSON)
+}
+
+// serveFeed 查询订阅源并按指定格式输出，客户端缓存有效时返回 304
+// 查询参数 category 与 tag 分别按分类 ID 与标签 ID 过滤，content=full 时输出全文
+// 链接只使用配置的 server.site_url 与 server.api_url，未配置时返回 404
+func serveFeed(ctx *gin.Context, contentType string, render func(*feed.Feed) ([]byte, error)) {
+	siteUrl, apiUrl := tools.SiteUrl(), tools.ApiUrl()
+	if siteUrl == "" || apiUrl == "" {
+		logger.Error("未配置 server.site_url 或 server.api_url，无法生成订阅源")
+		resp.MakeResp(ctx, http.StatusNotFound, "订阅源不存在", nil)
+		return
+	}
+
+	query := webservice.FeedQuery{
+		CategoryId:  ctx.Query("category"),
+		TagId:       ctx.Query("tag"),
+		FullContent: ctx.Query("content") == "full",
+	}
+
+	source, err := webservice.PrepareFeed(ctx, query, siteUrl, apiUrl)
+	if errors.Is(err, webservice.ErrFeedNotFound) {
+		resp.MakeResp(ctx, http.StatusNotFound, "订阅源不存在", err.Error())
+		return
+	} else if err != nil {
+		resp.Err(ctx, "获取订阅源失败", err.Error())
+		return
+	}
+
+	// 格式不同的订阅源 URL 不同，客户端会分别缓存，ETag 只需区分查询条件
+	if tools.CheckNotModified(ctx, source.ETag, source.LastModified) {
+		return
+	}
+
+	data, err := render(source.Build(ctx, ctx.Request.URL.RequestURI()))
+	if err != nil {
+		resp.Err(ctx, "生成订阅源失败", err.Error())
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// getSitemap 获取站点地图，地址过多时返回站点地图索引
+// RESTful API: GET /web/sitemap.xml
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回站点地图内容
+func getSitemap(ctx *gin.Context) {
+	serveSitemap(ctx, 0)
+}
+
+// getSitemapPage 获取拆分后的站点地图文件
+// RESTful API: GET /web/sitemap/:page.xml
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回站点地图内容
+func getSitemapPage(ctx *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(ctx.Param("page"), ".xml"))
+	if err != nil || page <= 0 {
+		resp.MakeResp(ctx, http.StatusNotFound, "站点地图不存在", nil)
+		return
+	}
+	serveSitemap(ctx, page)
+}
+
+// serveSitemap 输出站点地图的指定页，客户端缓存有效时返回 304
+// 地址只使用配置的 server.site_url 与 server.api_url，未配置时返回 404
+func serveSitemap(ctx *gin.Context, page int) {
+	siteUrl, apiUrl := tools.SiteUrl(), tools.ApiUrl()
+	if siteUrl == "" || apiUrl == "" {
+		logger.Error("未配置 server.site_url 或 server.api_url，无法生成站点地图")
+		resp.MakeResp(ctx, http.StatusNotFound, "站点地图不存在", nil)
+		return
+	}
+
+	doc, err := webservice.GetSitemap(ctx, siteUrl, apiUrl, page)
+	if errors.Is(err, webservice.ErrSitemapNotFound) {
+		resp.MakeResp(ctx, http.StatusNotFound, "站点地图不存在", err.Error())
+		return
+	} else if err != nil {
+		resp.Err(ctx, "获取站点地图失败", err.Error())
+		return
+	}
+
+	if tools.CheckNotModified(ctx, doc.ETag, doc.LastModified) {
+		return
+	}
+
+	data, err := doc.Render()
+	if err != nil {
+		resp.Err(ctx, "生成站点地图失败", err.Error())
+		return
+	}
+
+	ctx.Data(http.StatusOK, sitemap.ContentType, data)
+}
+
+// getRobots 获取 robots.txt
+// RESTful API: GET /web/robots.txt
+// 需要由博客前台或反向代理在前台站点的 /robots.txt 提供，搜索引擎才会接受站点地图中前台的地址
+//
+// @param ctx *gin.Context - Gin上下文
+// @return 无返回值，直接返回文本内容
+func getRobots(ctx *gin.Context) {
+	apiUrl := tools.ApiUrl()
+	if apiUrl == "" {
+		logger.Error("未配置 server.api_url，robots.txt 中不包含站点地图地址")
+	}
+	ctx.String(http.StatusOK, webservice.GetRobots(apiUrl))
+}
+
+// checkCommentSpam 检查评论是否为垃圾评论或提交过于频繁，被拒绝时已经写入错误响应
+func checkCommentSpam(ctx *gin.Context, commentDto *dto.CommentDto) bool {
+	rejection := webservice.CheckCommentSpam(ctx, &spam.Submission{
+		Ip:       ctx.ClientIP(),
+		Email:    commentDto.CommenterEmail,
+		Content:  commentDto.Content,
+		Honeypot: commentDto.Honeypot,
+	})
+	if rejection == nil {
+		return true
+	}
+
+	if rejection.RateLimited {
+		resp.MakeResp(ctx, http.StatusTooManyRequests, "评论提交过于频繁，请稍后再试", nil)
+	} else {
+		resp.BadRequest(ctx, "评论未通过内容检查", nil)
+	}
+	return false
+}
@@ -3,10 +3,23 @@ package webrouter
 import "github.com/gin-gonic/gin"
 
 func Router(e *gin.Engine) {
+	// 爬虫只会在站点根目录查找 robots.txt
+	e.GET("/robots.txt", getRobots)
+
 	webGroup := e.Group("/web")
 
 	webGroup.GET("/basic-data", getBasicData)
 
+	// 订阅源，支持 ?category=&tag= 过滤与 ?content=full 全文输出
+	webGroup.GET("/feed.xml", getRssFeed)
+	webGroup.GET("/atom.xml", getAtomFeed)
+	webGroup.GET("/feed.json", getJsonFeed)
+
+	// 站点地图与 robots.txt，地址超过上限时 sitemap.xml 为索引，各页通过 /sitemap/:page.xml 访问
+	webGroup.GET("/sitemap.xml", getSitemap)
+	webGroup.GET("/sitemap/:page", getSitemapPage)
+	webGroup.GET("/robots.txt", getRobots)
+
 	{
 		sysGroup := webGroup.Group("/sys")
 
@@ -31,7 +44,13 @@ func Router(e *gin.Engine) {
 		searchGroup.GET("/:content", searchContent)
 	}
 
+	{
+		objectGroup := webGroup.Group("/object")
 
+		// 本地对象存储的预签名下载与上传
+		objectGroup.GET("/*object_name", getLocalObject)
+		objectGroup.PUT("/*object_name", putLocalObject)
+	}
 
 	{
 		commentGroup := webGroup.Group("/comment")
@@ -39,6 +58,10 @@ func Router(e *gin.Engine) {
 		// 根据博客ID获取所有评论及子评论
 		commentGroup.GET("/:blog_id", getCommentsByBlogId)
 
+		// 分页获取博客的楼主评论，楼层中的回复按需分页加载
+		commentGroup.GET("/:blog_id/threads", getCommentThreads)
+		commentGroup.GET("/replies/:comment_id", getCommentReplies)
+
 		// 添加评论
 		commentGroup.POST("", addComment)
 
@@ -47,5 +70,10 @@ func Router(e *gin.Engine) {
 
 		// 获取最新的5条评论
 		commentGroup.GET("/latest", getLatestComments)
+
+		// 通知邮件中的退订链接，GET 只展示确认页，避免邮件安全扫描与预取请求误退订；
+		// POST 由确认页或邮件客户端的一键退订发起，执行退订
+		commentGroup.GET("/unsubscribe", showUnsubscribePage)
+		commentGroup.POST("/unsubscribe", unsubscribeComment)
 	}
 }
@@ -237,20 +237,24 @@ func createIndexSafely(indexPath string, indexMapping blevemapping.IndexMapping)
 	return nil, fmt.Errorf("创建索引失败，已尝试3次: %w", lastErr)
 }
 
-// getAllDocs 获取所有文章
+// getAllDocs 获取所有需要索引的文章
 func getAllDocs(ctx context.Context) ([]doc.Doc, error) {
 	blogDtos, err := blogrepo.FindAllBlogs(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
-	docs := make([]doc.Doc, len(blogDtos))
-	for i, blogDto := range blogDtos {
-		docs[i] = doc.Doc{
+	// 只索引显示状态的博客，隐藏的博客不应出现在搜索结果中
+	docs := make([]doc.Doc, 0, len(blogDtos))
+	for _, blogDto := range blogDtos {
+		if !blogDto.BlogState {
+			continue
+		}
+		docs = append(docs, doc.Doc{
 			ID:    blogDto.BlogId,
 			ImgId: blogDto.BlogImageId,
 			Title: blogDto.BlogTitle,
-		}
+		})
 	}
 
 	return docs, nil
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"sparrow_blog_server/cache"
 	"time"
 )
 
@@ -19,6 +20,25 @@ const BlogCacheKeyPrefix = "blog_cache_"
 
 const BlogReadCountKeyPrefix = "blog_read_count_"
 
+// CommentRateLimitKeyPrefix 评论限流令牌桶缓存 key 前缀
+const CommentRateLimitKeyPrefix = "comment_rate_limit_"
+
+// CacheStatsKeyPrefixes 缓存按前缀统计命中、未命中与淘汰次数的键前缀
+var CacheStatsKeyPrefixes = []string{
+	ImgCacheKeyPrefix,
+	BlogCacheKeyPrefix,
+	BlogReadCountKeyPrefix,
+	UserRevokedTokenKeyPre,
+	CommentRateLimitKeyPrefix,
+}
+
+// NoEvictKeyPrefixes 不参与缓存容量淘汰的键前缀
+// 已撤销的 token 被淘汰后，已注销的 token 会重新通过校验；验证码被淘汰会导致正在进行的验证失败。
+var NoEvictKeyPrefixes = []string{
+	VerificationCodeKey,
+	UserRevokedTokenKeyPre,
+}
+
 func BuildImgCacheKey(imgId string) string {
 	return ImgCacheKeyPrefix + imgId
 }
@@ -27,10 +47,15 @@ func BuildBlogCacheKey(blogId string) string {
 	return BlogCacheKeyPrefix + blogId
 }
 
-// BuildBlogReadCountKey 构建博客阅读数缓存 key，缓存 key 格式：blog_read_count_<blogId>_<date>
+// BuildBlogReadCountKey 构建博客阅读数缓存 key，缓存 key 格式：blog_read_count_<blogId>-<date>
 func BuildBlogReadCountKey(blogId string) string {
 	// 获取当前日期
 	year, month, day := time.Now().Date()
 	date := fmt.Sprintf("%d%02d%02d", year, month, day)
 	return fmt.Sprintf("%s%s-%s", BlogReadCountKeyPrefix, blogId, date)
 }
+
+// BuildBlogReadCountPattern 构建匹配博客所有日期阅读数缓存 key 的 Scan 模式
+func BuildBlogReadCountPattern(blogId string) string {
+	return BlogReadCountKeyPrefix + cache.QuotePattern(blogId) + "-*"
+}
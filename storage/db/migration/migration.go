@@ -0,0 +1,303 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"sparrow_blog_server/pkg/logger"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFS 内嵌的迁移脚本，文件名格式为 <版本号>_<名称>.<up|down>.sql
+//
+//go:embed sql/*.sql
+var migrationFS embed.FS
+
+// fileNamePattern 迁移脚本文件名格式
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+const createSchemaVersionTableSQL = `
+	CREATE TABLE IF NOT EXISTS SCHEMA_VERSION
+	(
+		version 		INTEGER 		PRIMARY KEY NOT NULL, 					-- 迁移版本号
+		name 			VARCHAR(100) 	NOT NULL, 								-- 迁移名称
+		checksum 		CHAR(64) 		NOT NULL, 								-- 升级脚本的 SHA256
+		applied_time 	TIMESTAMP 		NOT NULL DEFAULT CURRENT_TIMESTAMP 		-- 执行时间
+	); -- 数据库结构版本表
+`
+
+// ErrChecksumMismatch 已执行的迁移脚本被修改
+var ErrChecksumMismatch = errors.New("迁移脚本校验和不一致")
+
+// Migration 一次数据库结构迁移
+type Migration struct {
+	Version  uint   // 版本号，按从小到大的顺序执行
+	Name     string // 迁移名称
+	Up       string // 升级脚本
+	Down     string // 回滚脚本
+	Checksum string // 升级脚本的 SHA256
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Migration
+	Applied          bool      // 是否已执行
+	AppliedTime      time.Time // 执行时间
+	ChecksumMismatch bool      // 已执行的脚本与当前内嵌的脚本是否不一致
+}
+
+// schemaVersion SCHEMA_VERSION 表中的一条记录
+type schemaVersion struct {
+	Version     uint      `gorm:"column:version;primaryKey"`
+	Name        string    `gorm:"column:name"`
+	Checksum    string    `gorm:"column:checksum"`
+	AppliedTime time.Time `gorm:"column:applied_time"`
+}
+
+// TableName 返回 SCHEMA_VERSION 表名
+func (schemaVersion) TableName() string {
+	return "SCHEMA_VERSION"
+}
+
+// Load 读取内嵌的全部迁移，按版本号升序返回
+func Load() ([]Migration, error) {
+	return loadFrom(migrationFS, "sql")
+}
+
+// loadFrom 从指定的文件系统目录读取迁移脚本
+func loadFrom(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移脚本目录失败: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("迁移脚本版本号错误: %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移脚本 %s 失败: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: matches[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, m.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少升级或回滚脚本", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// GetStatus 获取每个迁移的执行状态，不会修改数据库
+func GetStatus(ctx context.Context, db *gorm.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return getStatus(ctx, db, migrations)
+}
+
+// Up 在一个事务中执行所有未执行的迁移
+// 参数:
+//   - ctx: 上下文对象
+//   - db: 数据库连接
+//
+// 返回值:
+//   - []Migration: 本次执行的迁移
+//   - error: 任意一个迁移失败时整体回滚并返回错误
+func Up(ctx context.Context, db *gorm.DB) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return up(ctx, db, migrations)
+}
+
+// Down 在一个事务中按版本号倒序回滚最近执行的 steps 个迁移
+// 参数:
+//   - ctx: 上下文对象
+//   - db: 数据库连接
+//   - steps: 回滚的迁移数量
+//
+// 返回值:
+//   - []Migration: 本次回滚的迁移
+//   - error: 任意一个迁移回滚失败时整体回滚并返回错误
+func Down(ctx context.Context, db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return down(ctx, db, migrations, steps)
+}
+
+func getStatus(ctx context.Context, db *gorm.DB, migrations []Migration) ([]Status, error) {
+	applied := make(map[uint]schemaVersion)
+	if tableExists(ctx, db, schemaVersion{}.TableName()) {
+		var records []schemaVersion
+		if err := db.WithContext(ctx).Order("version").Find(&records).Error; err != nil {
+			msg := fmt.Sprintf("查询数据库结构版本失败: %v", err)
+			logger.Error(msg)
+			return nil, errors.New(msg)
+		}
+		for _, r := range records {
+			applied[r.Version] = r
+		}
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		s := Status{Migration: m}
+		if r, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedTime = r.AppliedTime
+			s.ChecksumMismatch = r.Checksum != m.Checksum
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	// 数据库中存在但当前程序中没有的迁移，通常是降级了程序版本
+	if len(applied) > 0 {
+		unknown := make([]uint, 0, len(applied))
+		for version := range applied {
+			unknown = append(unknown, version)
+		}
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+		return nil, fmt.Errorf("数据库已执行的迁移版本 %v 在当前程序中不存在，请使用更新的程序版本", unknown)
+	}
+
+	return statuses, nil
+}
+
+func up(ctx context.Context, db *gorm.DB, migrations []Migration) ([]Migration, error) {
+	if err := db.WithContext(ctx).Exec(createSchemaVersionTableSQL).Error; err != nil {
+		msg := fmt.Sprintf("创建 SCHEMA_VERSION 表失败: %v", err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	statuses, err := getStatus(ctx, db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, s := range statuses {
+		if s.ChecksumMismatch {
+			return nil, fmt.Errorf("%w: 版本 %d (%s)", ErrChecksumMismatch, s.Version, s.Name)
+		}
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, m := range pending {
+			logger.Info("执行数据库迁移: %d_%s", m.Version, m.Name)
+			if err := tx.Exec(m.Up).Error; err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			record := schemaVersion{Version: m.Version, Name: m.Name, Checksum: m.Checksum, AppliedTime: time.Now()}
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("记录迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func down(ctx context.Context, db *gorm.DB, migrations []Migration, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("回滚数量必须大于 0")
+	}
+
+	statuses, err := getStatus(ctx, db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Migration
+	for i := len(statuses) - 1; i >= 0 && len(targets) < steps; i-- {
+		if statuses[i].Applied {
+			targets = append(targets, statuses[i].Migration)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, m := range targets {
+			logger.Info("回滚数据库迁移: %d_%s", m.Version, m.Name)
+			if err := tx.Exec(m.Down).Error; err != nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			if err := tx.Delete(&schemaVersion{}, "version = ?", m.Version).Error; err != nil {
+				return fmt.Errorf("删除迁移记录 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// tableExists 判断 SQLite 中是否存在指定的数据表
+func tableExists(ctx context.Context, db *gorm.DB, tableName string) bool {
+	var count int64
+	db.WithContext(ctx).Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&count)
+	return count > 0
+}
@@ -0,0 +1,166 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	_ = logger.InitLogger(context.Background())
+}
+
+func openTestDb(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func testMigrations(t *testing.T, files fstest.MapFS) []Migration {
+	migrations, err := loadFrom(files, "sql")
+	if err != nil {
+		t.Fatalf("读取迁移失败: %v", err)
+	}
+	return migrations
+}
+
+func TestMigration_Embedded(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDb(t)
+
+	applied, err := Up(ctx, db)
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if len(applied) == 0 || applied[0].Version != 1 {
+		t.Fatalf("应当从版本 1 开始执行: %v", applied)
+	}
+	for _, table := range []string{"BLOG", "BLOG_READ_COUNT", "CATEGORY", "TAG", "BLOG_TAG", "IMG", "COMMENT"} {
+		if !tableExists(ctx, db, table) {
+			t.Errorf("%s 表不存在", table)
+		}
+	}
+
+	applied, err = Up(ctx, db)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("重复执行不应再有迁移: %v, %v", applied, err)
+	}
+
+	statuses, err := GetStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("获取迁移状态失败: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.ChecksumMismatch {
+			t.Errorf("迁移 %d 状态错误: %+v", s.Version, s)
+		}
+	}
+}
+
+func TestMigration_UpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDb(t)
+
+	migrations := testMigrations(t, fstest.MapFS{
+		"sql/0001_a.up.sql":   {Data: []byte("CREATE TABLE A (id INTEGER);")},
+		"sql/0001_a.down.sql": {Data: []byte("DROP TABLE A;")},
+		"sql/0002_b.up.sql":   {Data: []byte("ALTER TABLE A ADD COLUMN name TEXT; CREATE INDEX IDX_A_NAME ON A (name);")},
+		"sql/0002_b.down.sql": {Data: []byte("DROP INDEX IDX_A_NAME; ALTER TABLE A DROP COLUMN name;")},
+	})
+
+	statuses, err := getStatus(ctx, db, migrations)
+	if err != nil || len(statuses) != 2 || statuses[0].Applied {
+		t.Fatalf("未执行时状态错误: %+v, %v", statuses, err)
+	}
+	if tableExists(ctx, db, "SCHEMA_VERSION") {
+		t.Fatal("查询状态不应创建 SCHEMA_VERSION 表")
+	}
+
+	if _, err := up(ctx, db, migrations); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if err := db.Exec("INSERT INTO A (id, name) VALUES (1, 'x')").Error; err != nil {
+		t.Fatalf("迁移后的表结构错误: %v", err)
+	}
+
+	reverted, err := down(ctx, db, migrations, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("回滚迁移失败: %v, %v", reverted, err)
+	}
+	if err := db.Exec("INSERT INTO A (id, name) VALUES (2, 'y')").Error; err == nil {
+		t.Fatal("回滚后 name 列不应存在")
+	}
+
+	if _, err := down(ctx, db, migrations, 5); err != nil {
+		t.Fatalf("回滚全部迁移失败: %v", err)
+	}
+	if tableExists(ctx, db, "A") {
+		t.Fatal("回滚全部迁移后 A 表不应存在")
+	}
+}
+
+func TestMigration_Rollback(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDb(t)
+
+	migrations := testMigrations(t, fstest.MapFS{
+		"sql/0001_a.up.sql":   {Data: []byte("CREATE TABLE A (id INTEGER);")},
+		"sql/0001_a.down.sql": {Data: []byte("DROP TABLE A;")},
+		"sql/0002_b.up.sql":   {Data: []byte("CREATE TABLE B (id INTEGER); THIS IS NOT SQL;")},
+		"sql/0002_b.down.sql": {Data: []byte("DROP TABLE B;")},
+	})
+
+	if _, err := up(ctx, db, migrations); err == nil {
+		t.Fatal("错误的迁移脚本应当执行失败")
+	}
+	if tableExists(ctx, db, "A") || tableExists(ctx, db, "B") {
+		t.Fatal("迁移失败后应当整体回滚")
+	}
+}
+
+func TestMigration_ChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDb(t)
+
+	migrations := testMigrations(t, fstest.MapFS{
+		"sql/0001_a.up.sql":   {Data: []byte("CREATE TABLE A (id INTEGER);")},
+		"sql/0001_a.down.sql": {Data: []byte("DROP TABLE A;")},
+	})
+	if _, err := up(ctx, db, migrations); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	modified := testMigrations(t, fstest.MapFS{
+		"sql/0001_a.up.sql":   {Data: []byte("CREATE TABLE A (id INTEGER, name TEXT);")},
+		"sql/0001_a.down.sql": {Data: []byte("DROP TABLE A;")},
+	})
+	if _, err := up(ctx, db, modified); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("修改已执行的迁移脚本应当报错: %v", err)
+	}
+}
+
+func TestMigration_LoadInvalid(t *testing.T) {
+	_, err := loadFrom(fstest.MapFS{
+		"sql/0001_a.up.sql": {Data: []byte("CREATE TABLE A (id INTEGER);")},
+	}, "sql")
+	if err == nil {
+		t.Fatal("缺少回滚脚本时应当报错")
+	}
+}
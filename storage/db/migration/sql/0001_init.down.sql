@@ -0,0 +1,9 @@
+-- 删除基础数据表
+
+DROP TABLE IF EXISTS COMMENT;
+DROP TABLE IF EXISTS IMG;
+DROP TABLE IF EXISTS BLOG_TAG;
+DROP TABLE IF EXISTS TAG;
+DROP TABLE IF EXISTS CATEGORY;
+DROP TABLE IF EXISTS BLOG_READ_COUNT;
+DROP TABLE IF EXISTS BLOG;
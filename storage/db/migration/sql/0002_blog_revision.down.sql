@@ -0,0 +1,4 @@
+-- 删除博客修订历史
+
+DROP INDEX IF EXISTS IDX_BLOG_REVISION_BLOG_ID;
+DROP TABLE IF EXISTS BLOG_REVISION;
@@ -0,0 +1,15 @@
+-- 博客修订历史
+
+CREATE TABLE IF NOT EXISTS BLOG_REVISION
+(
+    revision_id     VARCHAR(16)     PRIMARY KEY NOT NULL,                   -- 修订 ID
+    blog_id         VARCHAR(16)     NOT NULL,                               -- 博客 ID
+    blog_title      VARCHAR(50)     NOT NULL,                               -- 保存时的博客标题
+    blog_brief      VARCHAR(255)    NOT NULL DEFAULT '',                    -- 保存时的博客简介
+    blog_words_num  INTEGER         NOT NULL DEFAULT 0,                     -- 保存时的博客字数
+    content_hash    CHAR(64)        NOT NULL,                               -- 内容 SHA256
+    object_path     VARCHAR(255)    NOT NULL,                               -- 修订内容在对象存储中的路径
+    create_time     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP      -- 创建时间
+); -- 博客修订表
+
+CREATE INDEX IF NOT EXISTS IDX_BLOG_REVISION_BLOG_ID ON BLOG_REVISION (blog_id, create_time);
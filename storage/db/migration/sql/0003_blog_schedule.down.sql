@@ -0,0 +1,6 @@
+-- 删除博客定时发布与定时下线
+
+DROP INDEX IF EXISTS IDX_BLOG_UNPUBLISH_AT;
+DROP INDEX IF EXISTS IDX_BLOG_PUBLISH_AT;
+ALTER TABLE BLOG DROP COLUMN unpublish_at;
+ALTER TABLE BLOG DROP COLUMN publish_at;
@@ -0,0 +1,7 @@
+-- 博客定时发布与定时下线
+
+ALTER TABLE BLOG ADD COLUMN publish_at TIMESTAMP NULL;      -- 定时发布时间，到期后由调度器发布并清空
+ALTER TABLE BLOG ADD COLUMN unpublish_at TIMESTAMP NULL;    -- 定时下线时间，到期后由调度器下线并清空
+
+CREATE INDEX IF NOT EXISTS IDX_BLOG_PUBLISH_AT ON BLOG (publish_at);
+CREATE INDEX IF NOT EXISTS IDX_BLOG_UNPUBLISH_AT ON BLOG (unpublish_at);
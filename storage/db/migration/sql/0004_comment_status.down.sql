@@ -0,0 +1,5 @@
+-- 删除评论审核状态
+
+DROP INDEX IF EXISTS IDX_COMMENT_EMAIL_STATUS;
+DROP INDEX IF EXISTS IDX_COMMENT_STATUS;
+ALTER TABLE COMMENT DROP COLUMN comment_status;
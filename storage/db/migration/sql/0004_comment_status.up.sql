@@ -0,0 +1,6 @@
+-- 评论审核状态，已有评论视为已通过
+
+ALTER TABLE COMMENT ADD COLUMN comment_status VARCHAR(16) NOT NULL DEFAULT 'approved';   -- 审核状态: pending、approved、rejected
+
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_STATUS ON COMMENT (comment_status, create_time);
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_EMAIL_STATUS ON COMMENT (commenter_email, comment_status);
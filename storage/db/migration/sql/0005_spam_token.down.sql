@@ -0,0 +1,3 @@
+-- 删除评论反垃圾训练数据
+
+DROP TABLE IF EXISTS SPAM_TOKEN;
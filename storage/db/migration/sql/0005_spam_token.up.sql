@@ -0,0 +1,9 @@
+-- 评论反垃圾贝叶斯分类器的训练数据
+
+CREATE TABLE IF NOT EXISTS SPAM_TOKEN
+(
+    token           VARCHAR(64)     PRIMARY KEY NOT NULL,                   -- 分词
+    spam_count      INTEGER         NOT NULL DEFAULT 0,                     -- 出现在垃圾评论中的次数
+    ham_count       INTEGER         NOT NULL DEFAULT 0,                     -- 出现在正常评论中的次数
+    update_time     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP      -- 更新时间
+); -- 评论分词统计表
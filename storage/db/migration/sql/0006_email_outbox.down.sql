@@ -0,0 +1,3 @@
+-- 删除邮件发件箱
+
+DROP TABLE IF EXISTS EMAIL_OUTBOX;
@@ -0,0 +1,18 @@
+-- 邮件发件箱，邮件先写入发件箱，由后台发送并在失败时重试
+
+CREATE TABLE IF NOT EXISTS EMAIL_OUTBOX
+(
+    email_id        VARCHAR(16)     PRIMARY KEY NOT NULL,                   -- 邮件 ID
+    recipient       VARCHAR(255)    NOT NULL,                               -- 收件人邮箱
+    subject         VARCHAR(255)    NOT NULL,                               -- 邮件主题
+    content         TEXT            NOT NULL,                               -- 渲染后的 HTML 正文
+    email_status    VARCHAR(16)     NOT NULL DEFAULT 'pending',             -- 发送状态: pending、sending、sent、dead、cancelled
+    attempts        INTEGER         NOT NULL DEFAULT 0,                     -- 已尝试发送的次数
+    next_attempt_at TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,     -- 下次尝试发送的时间
+    last_error      TEXT            NOT NULL DEFAULT '',                    -- 最近一次发送失败的原因
+    sent_time       TIMESTAMP       NULL,                                   -- 发送成功的时间
+    create_time     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,     -- 创建时间
+    update_time     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP      -- 更新时间
+); -- 邮件发件箱表
+
+CREATE INDEX IF NOT EXISTS IDX_EMAIL_OUTBOX_STATUS ON EMAIL_OUTBOX (email_status, next_attempt_at);
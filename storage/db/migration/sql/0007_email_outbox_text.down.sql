@@ -0,0 +1,3 @@
+-- 删除发件箱的纯文本正文
+
+ALTER TABLE EMAIL_OUTBOX DROP COLUMN text_content;
@@ -0,0 +1,3 @@
+-- 发件箱保存纯文本正文，与 HTML 正文一起作为 multipart/alternative 发送
+
+ALTER TABLE EMAIL_OUTBOX ADD COLUMN text_content TEXT NOT NULL DEFAULT '';  -- 渲染后的纯文本正文，为空时只发送 HTML 正文
@@ -0,0 +1,5 @@
+-- 删除评论回复订阅与发件箱的退订链接
+
+ALTER TABLE EMAIL_OUTBOX DROP COLUMN unsubscribe_url;
+
+DROP TABLE IF EXISTS COMMENT_SUBSCRIPTION;
@@ -0,0 +1,15 @@
+-- 评论者按楼层订阅回复通知，发件箱保存退订链接用于 List-Unsubscribe 邮件头
+
+CREATE TABLE IF NOT EXISTS COMMENT_SUBSCRIPTION
+(
+    subscriber_email VARCHAR(255)   NOT NULL,                               -- 订阅者邮箱
+    thread_id        VARCHAR(16)    NOT NULL,                               -- 楼主评论 ID
+    blog_id          VARCHAR(16)    NOT NULL,                               -- 博客 ID
+    create_time      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,     -- 订阅时间
+    PRIMARY KEY (subscriber_email, thread_id)
+); -- 评论回复订阅表
+
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_SUBSCRIPTION_THREAD ON COMMENT_SUBSCRIPTION (thread_id);
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_SUBSCRIPTION_BLOG ON COMMENT_SUBSCRIPTION (blog_id);
+
+ALTER TABLE EMAIL_OUTBOX ADD COLUMN unsubscribe_url TEXT NOT NULL DEFAULT '';  -- 退订链接，为空时不设置 List-Unsubscribe 邮件头
@@ -0,0 +1,4 @@
+-- 删除评论者昵称与个人网站
+
+ALTER TABLE COMMENT DROP COLUMN commenter_website;
+ALTER TABLE COMMENT DROP COLUMN commenter_nickname;
@@ -0,0 +1,4 @@
+-- 评论者可以填写昵称与个人网站，前台展示昵称与头像而不是邮箱
+
+ALTER TABLE COMMENT ADD COLUMN commenter_nickname VARCHAR(50) NOT NULL DEFAULT '';   -- 评论者昵称，为空时前台展示打码的邮箱
+ALTER TABLE COMMENT ADD COLUMN commenter_website VARCHAR(255) NOT NULL DEFAULT '';   -- 评论者个人网站，只允许 http 与 https 地址
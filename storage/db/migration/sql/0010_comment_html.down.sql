@@ -0,0 +1,3 @@
+-- 删除评论渲染后的 HTML
+
+ALTER TABLE COMMENT DROP COLUMN comment_html;
@@ -0,0 +1,3 @@
+-- 保存评论渲染后的 HTML，前台展示经过清理的 HTML 而不是原始内容
+
+ALTER TABLE COMMENT ADD COLUMN comment_html TEXT NOT NULL DEFAULT '';   -- 评论内容渲染后的 HTML，为空时在读取时渲染
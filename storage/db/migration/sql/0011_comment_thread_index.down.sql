@@ -0,0 +1,4 @@
+-- 删除评论分页查询索引
+
+DROP INDEX IF EXISTS IDX_COMMENT_ORIGIN_POST;
+DROP INDEX IF EXISTS IDX_COMMENT_BLOG_THREAD;
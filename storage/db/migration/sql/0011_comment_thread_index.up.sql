@@ -0,0 +1,7 @@
+-- 楼主评论与楼层回复的分页查询索引
+
+-- 楼主评论的 original_poster_id 统一为空字符串，分页查询时可以直接使用索引
+UPDATE COMMENT SET original_poster_id = '' WHERE original_poster_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_BLOG_THREAD ON COMMENT (blog_id, original_poster_id, create_time);   -- 按博客分页查询楼主评论
+CREATE INDEX IF NOT EXISTS IDX_COMMENT_ORIGIN_POST ON COMMENT (original_poster_id, create_time);            -- 分页查询楼层回复与统计回复数量
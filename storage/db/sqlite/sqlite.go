@@ -7,13 +7,35 @@ import (
 	"sparrow_blog_server/pkg/filetool"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/storage/db/dblogger"
-	"sparrow_blog_server/storage/db/sqlscript"
+	"sparrow_blog_server/storage/db/migration"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// ConnectSqlite 连接 Sqlite 数据库并执行未完成的数据库迁移
 func ConnectSqlite(ctx context.Context) (*gorm.DB, error) {
+	db, err := OpenSqlite(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 执行数据库结构迁移
+	applied, err := migration.Up(ctx, db)
+	if err != nil {
+		handleError("数据库迁移失败", err)
+	}
+	for _, m := range applied {
+		logger.Info("数据库迁移完成: %d_%s", m.Version, m.Name)
+	}
+
+	logger.Info("Sqlite 数据库连接成功")
+
+	return db, nil
+}
+
+// OpenSqlite 仅打开 Sqlite 数据库连接，不执行数据库迁移
+func OpenSqlite(ctx context.Context) (*gorm.DB, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -39,67 +61,9 @@ func ConnectSqlite(ctx context.Context) (*gorm.DB, error) {
 		handleError("Sqlite 数据库连接失败", err)
 	}
 
-	// 创建相关基础数据表
-	if !tableExists(db, "BLOG") {
-		err = db.Exec(sqlscript.CreateBlogTableSQL).Error
-		if err != nil {
-			handleError("创建 BLOG 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "BLOG_READ_COUNT") {
-		err = db.Exec(sqlscript.CreateBlogReadCountTableSQL).Error
-		if err != nil {
-			handleError("创建 BLOG_READ_COUNT 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "CATEGORY") {
-		err = db.Exec(sqlscript.CreateCategoryTableSQL).Error
-		if err != nil {
-			handleError("创建 CATEGORY 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "TAG") {
-		err = db.Exec(sqlscript.CreateTagTableSQL).Error
-		if err != nil {
-			handleError("创建 TAG 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "BLOG_TAG") {
-		err = db.Exec(sqlscript.CreateBlogTagTableSQL).Error
-		if err != nil {
-			handleError("创建 BLOG_TAG 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "IMG") {
-		err = db.Exec(sqlscript.CreateImgTableSQL).Error
-		if err != nil {
-			handleError("创建 IMG 表失败", err)
-		}
-	}
-
-	if !tableExists(db, "COMMENT") {
-		err = db.Exec(sqlscript.CreateCommentTableSQL).Error
-		if err != nil {
-			handleError("创建 COMMENT 表失败", err)
-		}
-	}
-
-	logger.Info("Sqlite 数据库连接成功")
-
 	return db, nil
 }
 
-func tableExists(db *gorm.DB, tableName string) bool {
-	var count int
-	db.Raw("SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ?", tableName).Scan(&count)
-	return count > 0
-}
-
 func handleError(msg string, err error) {
 	logger.Error(msg + ": " + err.Error())
 	panic(msg + ": " + err.Error())
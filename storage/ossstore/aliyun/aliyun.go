@@ -1,13 +1,34 @@
 package aliyun
 
 import (
+	"bytes"
 	"context"
-	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
-	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	"errors"
+	"fmt"
+	"io"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage/ossstore"
+	"time"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
 )
 
+// Store 阿里云 OSS 对象存储后端
+type Store struct {
+	client *oss.Client // 阿里云 OSS 客户端
+}
+
+// NewStore 创建阿里云 OSS 对象存储后端
+func NewStore(ctx context.Context) (*Store, error) {
+	client, err := ConnectOss(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: client}, nil
+}
+
 // ConnectOss 连接 Oss
 func ConnectOss(ctx context.Context) (*oss.Client, error) {
 	select {
@@ -36,11 +57,254 @@ func ConnectOss(ctx context.Context) (*oss.Client, error) {
 	if err != nil {
 		// 如果获取 Bucket 信息失败，记录错误日志
 		logger.Error("获取 bucket 信息失败: %v", err)
+	} else {
+		// 记录获取 Bucket 信息的响应状态码
+		logger.Info("获取 bucket 信息: %v\n", result.StatusCode)
 	}
 
-	// 记录获取 Bucket 信息的响应状态码
-	logger.Info("获取 bucket 信息: %v\n", result.StatusCode)
-
 	// 返回创建的 Oss 客户端
 	return ossClient, nil
 }
+
+// DeleteObject 删除对象
+// - ctx 上下文对象，用于控制请求的截止时间、取消信号等
+// - oldName 要删除的对象名称
+func (s *Store) DeleteObject(ctx context.Context, oldName string) error {
+	// 构建删除对象的请求
+	deleteRequest := &oss.DeleteObjectRequest{
+		Bucket: oss.Ptr(config.Oss.Bucket), // 存储空间名称
+		Key:    oss.Ptr(oldName),           // 要删除的对象名称
+	}
+	// 执行删除对象的操作
+	deleteResult, err := s.client.DeleteObject(ctx, deleteRequest)
+	if err != nil {
+		msg := fmt.Sprintf("删除 OssClient 对象失败 %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("删除源对象成功: %#v", deleteResult)
+
+	return nil
+}
+
+// RenameObject 重命名对象
+// - ctx 上下文对象，用于控制请求的截止时间、取消信号等
+// - oldPath 原对象路径
+// - newPath 新对象路径
+func (s *Store) RenameObject(ctx context.Context, oldPath, newPath string) error {
+	// 创建文件拷贝器
+	c := s.client.NewCopier()
+
+	// 构建拷贝对象的请求
+	copyRequest := &oss.CopyObjectRequest{
+		Bucket:       oss.Ptr(config.Oss.Bucket), // 目标存储空间名称
+		Key:          oss.Ptr(newPath),           // 目标对象名称
+		SourceBucket: oss.Ptr(config.Oss.Bucket), // 源存储空间名称
+		SourceKey:    oss.Ptr(oldPath),           // 源对象名称
+		StorageClass: oss.StorageClassStandard,   // 指定存储类型为归档类型
+	}
+	// 执行拷贝对象的操作
+	result, err := c.Copy(ctx, copyRequest)
+	if err != nil {
+		// 记录错误信息，并返回自定义错误信息
+		msg := fmt.Sprintf("拷贝 OssClient 对象失败 %v", err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("拷贝对象成功: %#v", result)
+
+	// 构建删除对象的请求
+	deleteRequest := &oss.DeleteObjectRequest{
+		Bucket: oss.Ptr(config.Oss.Bucket), // 存储空间名称
+		Key:    oss.Ptr(oldPath),           // 要删除的对象名称
+	}
+	// 执行删除对象的操作
+	deleteResult, err := s.client.DeleteObject(ctx, deleteRequest)
+	if err != nil {
+		// 记录错误信息，并返回自定义错误信息
+		msg := fmt.Sprintf("删除 (%v) 对象失败 %v", oldPath, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("删除源对象成功: %#v", deleteResult)
+
+	return nil
+}
+
+// PutContentToOss 上传内容
+// - ctx 上下文对象，用于控制请求的截止时间、取消信号等
+// - content 要上传的内容
+// - objectName 上传到 OssClient 的对象名称
+func (s *Store) PutContentToOss(ctx context.Context, content []byte, objectName string) error {
+
+	// 创建上传器
+	request := &oss.PutObjectRequest{
+		Bucket: oss.Ptr(config.Oss.Bucket), // 指定上传的 Bucket 名称，使用 config 中的配置
+		Key:    oss.Ptr(objectName),        // 指定上传的对象名称
+		Body:   bytes.NewReader(content),   // 将内容转换为 Reader，作为上传的内容
+	}
+
+	// 使用上下文 ctx 开启上传请求
+	result, err := s.client.PutObject(ctx, request)
+
+	// 上传文件失败
+	if err != nil {
+		msg := fmt.Sprintf("上传 (%v) 失败, 错误信息: %v", objectName, err)
+		logger.Error(msg)
+		// 返回自定义错误信息，避免暴露敏感信息
+		return errors.New(msg)
+	}
+
+	// 记录成功信息
+	logger.Info("上传 (%v) 文件成功: %#v", objectName, result.Status)
+	return nil
+}
+
+// IsExist 检查指定的对象是否存在于存储桶中。
+// 参数:
+//   - ctx: 上下文对象，用于控制请求的生命周期和取消操作。
+//   - objectName: 要检查的对象名称。
+//
+// 返回值:
+//   - bool: 如果对象存在，则返回 true；否则返回 false。
+//   - error: 如果在检查过程中发生错误，则返回错误信息；否则返回 nil。
+func (s *Store) IsExist(ctx context.Context, objectName string) (bool, error) {
+	// 调用 OSS 客户端的 IsObjectExist 方法检查对象是否存在。
+	result, err := s.client.IsObjectExist(ctx, config.Oss.Bucket, objectName)
+	if err != nil {
+		// 如果检查失败，记录错误日志并返回封装后的错误信息。
+		msg := fmt.Sprintf("判断 (%v) 对象是否存在失败 %v", objectName, err)
+		logger.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	return result, nil
+}
+
+// GetContentFromOss 下载内容
+// - ctx 上下文对象，用于控制请求的截止时间、取消信号等
+// - objectName 下载的对象名称
+// 返回下载的内容和错误信息
+func (s *Store) GetContentFromOss(ctx context.Context, objectName string) ([]byte, error) {
+	// 使用上下文 ctx 开启上传请求
+	result, err := s.client.GetObject(ctx, &oss.GetObjectRequest{
+		Bucket: oss.Ptr(config.Oss.Bucket), // 指定下载的 Bucket 名称，使用 config 中的配置
+		Key:    oss.Ptr(objectName),        // 指定下载的对象名称
+	})
+	if err != nil {
+		msg := fmt.Sprintf("获取 (%v) 文件失败 %v", objectName, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Error("OssClient 关闭 Reader 失败: " + err.Error())
+		}
+	}(result.Body)
+
+	// 读取文件内容
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		msg := fmt.Sprintf("读取 objectName 文件失败: %s, 错误信息: %v", objectName, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	return data, nil
+}
+
+// ListOssDirFiles 列举指定目录下的所有对象
+func (s *Store) ListOssDirFiles(ctx context.Context, dir string) ([]string, error) {
+	// 创建列出对象的请求
+	request := &oss.ListObjectsV2Request{
+		Bucket: oss.Ptr(config.Oss.Bucket),
+		Prefix: oss.Ptr(dir), // 列举指定目录下的所有对象
+	}
+
+	// 创建分页器
+	p := s.client.NewListObjectsV2Paginator(request)
+
+	// 初始化结果数组
+	var results []string
+
+	// 初始化页码计数器
+	var i int
+	logger.Info("开始列举 (%v) 下的对象", dir)
+	// 遍历分页器中的每一页
+	for p.HasNext() {
+		i++
+
+		// 获取下一页的数据
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			logger.Error("获取第 %v 页数据失败: %v", i, err)
+			return nil, err
+		}
+
+		// 打印该页中的每个对象的信息
+		for _, obj := range page.Contents {
+			results = append(results, oss.ToString(obj.Key))
+		}
+	}
+
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// 从第一个截取是因为第一个是目录名称
+	return results[1:], nil
+}
+
+// GenPreSignUrl 生成一个预签名的 URL，用于访问或上传指定的对象。
+// 参数:
+//   - ctx: 上下文对象，用于控制请求的生命周期和取消操作。
+//   - objectName: 对象的名称（通常是文件名），用于标识存储桶中的具体对象。
+//   - method: 操作方法，支持 "Get" 或 "Put"，分别表示获取对象和上传对象。
+//
+// 返回值:
+//   - *ossstore.PresignResult: 包含预签名 URL 和相关元数据的结果对象。
+//   - error: 如果发生错误，返回具体的错误信息。
+func (s *Store) GenPreSignUrl(ctx context.Context, objectName, fileType, method string, duration time.Duration) (*ossstore.PresignResult, error) {
+	// 根据传入的方法构造对应的请求体
+	var request oss.RequestCommonInterface
+	switch method {
+	case ossstore.Get:
+		// 构造获取对象的请求
+		request = &oss.GetObjectRequest{
+			Bucket: oss.Ptr(config.Oss.Bucket),
+			Key:    oss.Ptr(objectName),
+		}
+	case ossstore.Put:
+		// 构造上传对象的请求
+		header, ok := ossstore.ContentTypeOf(fileType)
+		if !ok {
+			return nil, errors.New("不支持的文件类型")
+		}
+		request = &oss.PutObjectRequest{
+			Bucket:      oss.Ptr(config.Oss.Bucket),
+			Key:         oss.Ptr(objectName),
+			ContentType: oss.Ptr(header),
+		}
+	default:
+		// 如果传入的方法不被支持，返回错误
+		return nil, errors.New("不支持的方法")
+	}
+
+	// 调用 OSS 客户端生成预签名 URL
+	result, err := s.client.Presign(ctx, request, oss.PresignExpires(duration))
+
+	if err != nil {
+		// 如果生成预签名 URL 失败，记录错误日志并返回错误信息
+		msg := fmt.Sprintf("生成预签名 URL 失败: %v", err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	// 返回生成的预签名结果
+	return &ossstore.PresignResult{
+		Method:        result.Method,
+		URL:           result.URL,
+		Expiration:    result.Expiration,
+		SignedHeaders: result.SignedHeaders,
+	}, nil
+}
@@ -33,3 +33,13 @@ func GenOssSavePath(name string, fileType string) string {
 		return ""
 	}
 }
+
+// GenRevisionDir 用于生成博客修订内容的保存目录
+func GenRevisionDir(blogId string) string {
+	return fmt.Sprintf("%srevisions/%s/", config.Oss.BlogOssPath, blogId)
+}
+
+// GenRevisionSavePath 用于生成博客修订内容的保存路径，修订内容按哈希命名
+func GenRevisionSavePath(blogId string, contentHash string) string {
+	return fmt.Sprintf("%s%s.md", GenRevisionDir(blogId), contentHash)
+}
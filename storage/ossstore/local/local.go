@@ -0,0 +1,344 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage/ossstore"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoutePath 本地对象存储对外提供访问的路由前缀
+const RoutePath = "/web/object"
+
+var (
+	ErrInvalidObjectName = errors.New("非法的对象名称")
+	ErrInvalidSignature  = errors.New("签名无效")
+	ErrUrlExpired        = errors.New("链接已过期")
+)
+
+// Store 本地文件系统对象存储后端，对象以文件的形式保存在 root 目录下
+type Store struct {
+	root    string // 对象存储根目录
+	baseUrl string // 生成预签名 URL 时使用的服务访问地址
+	secret  []byte // 预签名 URL 的签名密钥
+}
+
+// NewStore 创建本地文件系统对象存储后端
+// 参数:
+//   - root: 对象存储根目录，不存在时会自动创建
+//   - baseUrl: 服务对外访问地址，例如 http://localhost:8080
+//   - secret: 预签名 URL 的签名密钥
+//
+// 返回值:
+//   - *Store: 本地对象存储后端
+//   - error: 创建根目录失败时返回错误
+func NewStore(root, baseUrl string, secret []byte) (*Store, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("本地对象存储签名密钥不能为空")
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		msg := fmt.Sprintf("创建本地对象存储目录 (%v) 失败: %v", root, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return &Store{
+		root:    root,
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+		secret:  secret,
+	}, nil
+}
+
+// ObjectPath 将对象名称转换为磁盘上的文件路径，拒绝越出根目录的对象名称
+func (s *Store) ObjectPath(objectName string) (string, error) {
+	name := strings.TrimLeft(objectName, "/")
+	if name == "" || strings.Contains(name, "\\") {
+		return "", ErrInvalidObjectName
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." || seg == "." {
+			return "", ErrInvalidObjectName
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean(name))), nil
+}
+
+// DeleteObject 删除对象，对象不存在时视为删除成功
+func (s *Store) DeleteObject(ctx context.Context, objectName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p, err := s.ObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		msg := fmt.Sprintf("删除 (%v) 对象失败 %v", objectName, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("删除对象成功: %v", objectName)
+
+	return nil
+}
+
+// RenameObject 重命名对象
+func (s *Store) RenameObject(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	src, err := s.ObjectPath(oldPath)
+	if err != nil {
+		return err
+	}
+	dst, err := s.ObjectPath(newPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		msg := fmt.Sprintf("创建目录 (%v) 失败 %v", filepath.Dir(dst), err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		msg := fmt.Sprintf("重命名 (%v) 为 (%v) 失败 %v", oldPath, newPath, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("重命名对象成功: %v -> %v", oldPath, newPath)
+
+	return nil
+}
+
+// PutContentToOss 上传内容，先写入临时文件再原子替换，避免读到写了一半的对象
+func (s *Store) PutContentToOss(ctx context.Context, content []byte, objectName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p, err := s.ObjectPath(objectName)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p, content); err != nil {
+		msg := fmt.Sprintf("上传 (%v) 失败, 错误信息: %v", objectName, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("上传 (%v) 文件成功", objectName)
+
+	return nil
+}
+
+// IsExist 检查指定的对象是否存在
+func (s *Store) IsExist(ctx context.Context, objectName string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	p, err := s.ObjectPath(objectName)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		msg := fmt.Sprintf("判断 (%v) 对象是否存在失败 %v", objectName, err)
+		logger.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	return !info.IsDir(), nil
+}
+
+// GetContentFromOss 下载内容
+func (s *Store) GetContentFromOss(ctx context.Context, objectName string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p, err := s.ObjectPath(objectName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		msg := fmt.Sprintf("获取 (%v) 文件失败 %v", objectName, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return data, nil
+}
+
+// ListOssDirFiles 列举指定目录下的所有对象，返回的对象名称使用 / 作为分隔符
+func (s *Store) ListOssDirFiles(ctx context.Context, dir string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	start := s.root
+	if strings.Trim(dir, "/") != "" {
+		p, err := s.ObjectPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		start = p
+	}
+
+	logger.Info("开始列举 (%v) 下的对象", dir)
+	var results []string
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		results = append(results, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		msg := fmt.Sprintf("列举 (%v) 下的对象失败 %v", dir, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return results, nil
+}
+
+// GenPreSignUrl 生成一个指向本地对象路由的预签名 URL
+// 参数:
+//   - ctx: 上下文对象
+//   - objectName: 对象名称
+//   - fileType: 文件类型，上传时用于确定 Content-Type
+//   - method: 操作方法，支持 GET 与 PUT
+//   - duration: 链接有效期
+//
+// 返回值:
+//   - *ossstore.PresignResult: 预签名结果
+//   - error: 参数不合法时返回错误
+func (s *Store) GenPreSignUrl(ctx context.Context, objectName, fileType, method string, duration time.Duration) (*ossstore.PresignResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := s.ObjectPath(objectName); err != nil {
+		return nil, err
+	}
+
+	var contentType string
+	signedHeaders := map[string]string{}
+	switch method {
+	case ossstore.Get:
+	case ossstore.Put:
+		header, ok := ossstore.ContentTypeOf(fileType)
+		if !ok {
+			return nil, errors.New("不支持的文件类型")
+		}
+		contentType = header
+		signedHeaders["Content-Type"] = header
+	default:
+		return nil, errors.New("不支持的方法")
+	}
+
+	expiration := time.Now().Add(duration)
+	expires := strconv.FormatInt(expiration.Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(method, objectName, expires, contentType))
+
+	return &ossstore.PresignResult{
+		Method:        method,
+		URL:           fmt.Sprintf("%s%s/%s?%s", s.baseUrl, RoutePath, escapeObjectName(objectName), query.Encode()),
+		Expiration:    expiration,
+		SignedHeaders: signedHeaders,
+	}, nil
+}
+
+// Verify 校验预签名请求，通过后返回对象在磁盘上的路径
+// 参数:
+//   - method: 实际的请求方法
+//   - objectName: 请求的对象名称
+//   - contentType: 实际的请求 Content-Type，仅 PUT 请求参与校验
+//   - expires: URL 中携带的过期时间戳
+//   - signature: URL 中携带的签名
+//
+// 返回值:
+//   - string: 对象在磁盘上的路径
+//   - error: 签名无效或链接过期时返回错误
+func (s *Store) Verify(method, objectName, contentType, expires, signature string) (string, error) {
+	p, err := s.ObjectPath(objectName)
+	if err != nil {
+		return "", err
+	}
+	if method != ossstore.Put {
+		contentType = ""
+	}
+	expected := s.sign(method, objectName, expires, contentType)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", ErrInvalidSignature
+	}
+	ts, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > ts {
+		return "", ErrUrlExpired
+	}
+
+	return p, nil
+}
+
+// sign 计算预签名 URL 的签名
+func (s *Store) sign(method, objectName, expires, contentType string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{method, strings.TrimLeft(objectName, "/"), expires, contentType}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escapeObjectName 对对象名称的每一段进行 URL 转义，保留路径分隔符
+func escapeObjectName(objectName string) string {
+	segs := strings.Split(strings.TrimLeft(objectName, "/"), "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segs, "/")
+}
+
+// writeFileAtomic 将内容写入同目录下的临时文件后重命名为目标文件
+func writeFileAtomic(p string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+
+	return os.Rename(tmpName, p)
+}
@@ -0,0 +1,121 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage/ossstore"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	// 加载配置文件
+	config.LoadConfig()
+	// 初始化 Logger 组件
+	_ = logger.InitLogger(context.Background())
+}
+
+func newTestStore(t *testing.T) *Store {
+	store, err := NewStore(t.TempDir(), "http://localhost:8080/", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("创建本地对象存储失败: %v", err)
+	}
+	return store
+}
+
+func TestLocalStore_Basic(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	if err := store.PutContentToOss(ctx, []byte("# 标题"), "blogs/测试.md"); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+
+	exist, err := store.IsExist(ctx, "blogs/测试.md")
+	if err != nil || !exist {
+		t.Fatalf("对象应当存在: %v, %v", exist, err)
+	}
+
+	data, err := store.GetContentFromOss(ctx, "blogs/测试.md")
+	if err != nil || string(data) != "# 标题" {
+		t.Fatalf("读取内容不一致: %q, %v", data, err)
+	}
+
+	if err := store.RenameObject(ctx, "blogs/测试.md", "blogs/新标题.md"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if exist, _ := store.IsExist(ctx, "blogs/测试.md"); exist {
+		t.Fatal("重命名后原对象不应存在")
+	}
+
+	files, err := store.ListOssDirFiles(ctx, "blogs/")
+	if err != nil || len(files) != 1 || files[0] != "blogs/新标题.md" {
+		t.Fatalf("列举结果错误: %v, %v", files, err)
+	}
+
+	if err := store.DeleteObject(ctx, "blogs/新标题.md"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := store.DeleteObject(ctx, "blogs/新标题.md"); err != nil {
+		t.Fatalf("重复删除不应报错: %v", err)
+	}
+}
+
+func TestLocalStore_InvalidObjectName(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, name := range []string{"", "../etc/passwd", "blogs/../../x", "a\\b"} {
+		if _, err := store.ObjectPath(name); !errors.Is(err, ErrInvalidObjectName) {
+			t.Errorf("对象名称 %q 应当被拒绝", name)
+		}
+	}
+}
+
+func TestLocalStore_PreSignUrl(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	presign, err := store.GenPreSignUrl(ctx, "images/图 1.webp", ossstore.Webp, ossstore.Put, time.Minute)
+	if err != nil {
+		t.Fatalf("生成预签名URL失败: %v", err)
+	}
+	if presign.SignedHeaders["Content-Type"] != ossstore.WebpHeader {
+		t.Fatalf("签名请求头错误: %v", presign.SignedHeaders)
+	}
+
+	u, err := url.Parse(presign.URL)
+	if err != nil {
+		t.Fatalf("解析URL失败: %v", err)
+	}
+	if !strings.HasPrefix(u.Path, RoutePath+"/") {
+		t.Fatalf("URL 路径错误: %v", u.Path)
+	}
+	objectName := strings.TrimPrefix(u.Path, RoutePath+"/")
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	if _, err := store.Verify(ossstore.Put, objectName, ossstore.WebpHeader, expires, signature); err != nil {
+		t.Fatalf("校验应当通过: %v", err)
+	}
+	if _, err := store.Verify(ossstore.Get, objectName, "", expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("上传签名不能用于下载: %v", err)
+	}
+	if _, err := store.Verify(ossstore.Put, objectName, ossstore.MarkdownHeader, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Content-Type 不一致时校验应当失败: %v", err)
+	}
+	if _, err := store.Verify(ossstore.Put, "images/other.webp", ossstore.WebpHeader, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("对象名称被篡改时校验应当失败: %v", err)
+	}
+
+	expired, err := store.GenPreSignUrl(ctx, "images/a.webp", ossstore.Webp, ossstore.Get, -time.Minute)
+	if err != nil {
+		t.Fatalf("生成预签名URL失败: %v", err)
+	}
+	u, _ = url.Parse(expired.URL)
+	if _, err := store.Verify(ossstore.Get, "images/a.webp", "", u.Query().Get("expires"), u.Query().Get("signature")); !errors.Is(err, ErrUrlExpired) {
+		t.Fatalf("过期链接校验应当失败: %v", err)
+	}
+}
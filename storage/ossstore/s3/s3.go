@@ -0,0 +1,406 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sparrow_blog_server/pkg/config"
+	"sparrow_blog_server/pkg/logger"
+	"sparrow_blog_server/storage/ossstore"
+	"strings"
+	"time"
+)
+
+// maxPresignDuration S3 预签名 URL 的最长有效期
+const maxPresignDuration = 7 * 24 * time.Hour
+
+// Store S3 兼容协议的对象存储后端，适用于 AWS S3、MinIO、Cloudflare R2 等
+type Store struct {
+	endpoint     *url.URL     // 服务访问地址
+	bucket       string       // 存储空间名称
+	usePathStyle bool         // 是否使用路径风格访问
+	signer       *signer      // 请求签名器
+	client       *http.Client // HTTP 客户端
+	now          func() time.Time
+}
+
+// errorResponse S3 返回的错误信息
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// listBucketResult ListObjectsV2 的返回结果
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// NewStore 创建 S3 兼容对象存储后端
+// 参数:
+//   - ctx: 上下文对象
+//   - ossConfig: 对象存储配置，Endpoint 需要包含协议，例如 http://127.0.0.1:9000
+//
+// 返回值:
+//   - *Store: S3 对象存储后端
+//   - error: 配置不合法时返回错误
+func NewStore(ctx context.Context, ossConfig *config.OssConfig) (*Store, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	endpoint := strings.TrimSpace(ossConfig.Endpoint)
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("S3 endpoint 格式错误: %v", ossConfig.Endpoint)
+	}
+	if ossConfig.Bucket == "" {
+		return nil, errors.New("S3 存储空间名称不能为空")
+	}
+	region := ossConfig.Region
+	if region == "" {
+		// MinIO、R2 等服务对地域不敏感，使用 S3 的默认地域
+		region = "us-east-1"
+	}
+
+	return &Store{
+		endpoint:     u,
+		bucket:       ossConfig.Bucket,
+		usePathStyle: ossConfig.UsePathStyle,
+		signer: &signer{
+			accessKeyId:     ossConfig.AccessKeyId,
+			accessKeySecret: ossConfig.AccessKeySecret,
+			region:          region,
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// CheckBucket 检查存储空间是否可以访问
+func (s *Store) CheckBucket(ctx context.Context) error {
+	res, err := s.do(ctx, http.MethodHead, "", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("获取 bucket 信息失败: %v", res.StatusCode)
+	}
+	return nil
+}
+
+// DeleteObject 删除对象
+func (s *Store) DeleteObject(ctx context.Context, objectName string) error {
+	if err := s.deleteObject(ctx, objectName); err != nil {
+		msg := fmt.Sprintf("删除 (%v) 对象失败 %v", objectName, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("删除源对象成功: %v", objectName)
+
+	return nil
+}
+
+// RenameObject 重命名对象，S3 不支持重命名，通过拷贝后删除源对象实现
+func (s *Store) RenameObject(ctx context.Context, oldPath, newPath string) error {
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", "/"+s.bucket+"/"+uriEncode(oldPath, false))
+
+	res, err := s.do(ctx, http.MethodPut, newPath, nil, header, nil)
+	if err == nil {
+		err = checkCopyResponse(res)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("拷贝 (%v) 对象失败 %v", oldPath, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("拷贝对象成功: %v -> %v", oldPath, newPath)
+
+	if err := s.deleteObject(ctx, oldPath); err != nil {
+		msg := fmt.Sprintf("删除 (%v) 对象失败 %v", oldPath, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("删除源对象成功: %v", oldPath)
+
+	return nil
+}
+
+// PutContentToOss 上传内容
+func (s *Store) PutContentToOss(ctx context.Context, content []byte, objectName string) error {
+	res, err := s.do(ctx, http.MethodPut, objectName, nil, nil, content)
+	if err == nil {
+		err = checkResponse(res, http.StatusOK)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("上传 (%v) 失败, 错误信息: %v", objectName, err)
+		logger.Error(msg)
+		return errors.New(msg)
+	}
+	logger.Info("上传 (%v) 文件成功", objectName)
+
+	return nil
+}
+
+// IsExist 检查指定的对象是否存在
+func (s *Store) IsExist(ctx context.Context, objectName string) (bool, error) {
+	res, err := s.do(ctx, http.MethodHead, objectName, nil, nil, nil)
+	if err != nil {
+		msg := fmt.Sprintf("判断 (%v) 对象是否存在失败 %v", objectName, err)
+		logger.Error(msg)
+		return false, errors.New(msg)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		msg := fmt.Sprintf("判断 (%v) 对象是否存在失败 %v", objectName, res.StatusCode)
+		logger.Error(msg)
+		return false, errors.New(msg)
+	}
+}
+
+// GetContentFromOss 下载内容
+func (s *Store) GetContentFromOss(ctx context.Context, objectName string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, objectName, nil, nil, nil)
+	if err != nil {
+		msg := fmt.Sprintf("获取 (%v) 文件失败 %v", objectName, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("获取 (%v) 文件失败 %v", objectName, parseError(res))
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		msg := fmt.Sprintf("读取 objectName 文件失败: %s, 错误信息: %v", objectName, err)
+		logger.Error(msg)
+		return nil, errors.New(msg)
+	}
+	return data, nil
+}
+
+// ListOssDirFiles 列举指定目录下的所有对象，不包含目录占位对象本身
+func (s *Store) ListOssDirFiles(ctx context.Context, dir string) ([]string, error) {
+	var results []string
+	var token string
+
+	logger.Info("开始列举 (%v) 下的对象", dir)
+	for i := 1; ; i++ {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", dir)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		page, err := s.listObjects(ctx, query)
+		if err != nil {
+			logger.Error("获取第 %v 页数据失败: %v", i, err)
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			if obj.Key == dir {
+				continue
+			}
+			results = append(results, obj.Key)
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	return results, nil
+}
+
+// GenPreSignUrl 生成一个预签名的 URL，用于访问或上传指定的对象
+// 参数:
+//   - ctx: 上下文对象
+//   - objectName: 对象名称
+//   - fileType: 文件类型，上传时用于确定 Content-Type
+//   - method: 操作方法，支持 GET 与 PUT
+//   - duration: 链接有效期，最长 7 天
+//
+// 返回值:
+//   - *ossstore.PresignResult: 预签名结果
+//   - error: 参数不合法时返回错误
+func (s *Store) GenPreSignUrl(ctx context.Context, objectName, fileType, method string, duration time.Duration) (*ossstore.PresignResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	signedHeaders := map[string]string{}
+	switch method {
+	case ossstore.Get:
+	case ossstore.Put:
+		header, ok := ossstore.ContentTypeOf(fileType)
+		if !ok {
+			return nil, errors.New("不支持的文件类型")
+		}
+		signedHeaders["Content-Type"] = header
+	default:
+		return nil, errors.New("不支持的方法")
+	}
+	if duration > maxPresignDuration {
+		duration = maxPresignDuration
+	}
+
+	headers := map[string]string{}
+	for k, v := range signedHeaders {
+		headers[strings.ToLower(k)] = v
+	}
+	now := s.now()
+
+	return &ossstore.PresignResult{
+		Method:        method,
+		URL:           s.signer.presign(method, s.objectUrl(objectName, nil), headers, now, duration),
+		Expiration:    now.Add(duration),
+		SignedHeaders: signedHeaders,
+	}, nil
+}
+
+// objectUrl 构造对象的访问地址，objectName 为空时表示存储空间本身
+func (s *Store) objectUrl(objectName string, query url.Values) *url.URL {
+	u := *s.endpoint
+	basePath := strings.TrimRight(u.Path, "/")
+	escapedKey := uriEncode(objectName, false)
+
+	if s.usePathStyle {
+		u.Path = basePath + "/" + s.bucket
+		u.RawPath = basePath + "/" + uriEncode(s.bucket, true)
+		if objectName != "" {
+			u.Path += "/" + objectName
+			u.RawPath += "/" + escapedKey
+		}
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = basePath + "/" + objectName
+		u.RawPath = basePath + "/" + escapedKey
+	}
+
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+// do 构造并发送签名后的请求
+func (s *Store) do(ctx context.Context, method, objectName string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := s.objectUrl(objectName, query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	s.signer.signRequest(req, payloadHash, s.now())
+
+	return s.client.Do(req)
+}
+
+// deleteObject 删除对象，对象不存在时 S3 同样返回成功
+func (s *Store) deleteObject(ctx context.Context, objectName string) error {
+	res, err := s.do(ctx, http.MethodDelete, objectName, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return checkResponse(res, http.StatusNoContent, http.StatusOK)
+}
+
+// listObjects 列举一页对象
+func (s *Store) listObjects(ctx context.Context, query url.Values) (*listBucketResult, error) {
+	res, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, parseError(res)
+	}
+
+	var result listBucketResult
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析列举结果失败: %w", err)
+	}
+	return &result, nil
+}
+
+// checkResponse 检查响应状态码并关闭响应体
+func checkResponse(res *http.Response, expected ...int) error {
+	defer res.Body.Close()
+	for _, code := range expected {
+		if res.StatusCode == code {
+			_, _ = io.Copy(io.Discard, res.Body)
+			return nil
+		}
+	}
+	return parseError(res)
+}
+
+// checkCopyResponse 检查拷贝对象的响应，S3 可能在返回 200 的同时在响应体中携带错误
+func checkCopyResponse(res *http.Response) error {
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return parseError(res)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	var e errorResponse
+	if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+		return fmt.Errorf("%s: %s", e.Code, e.Message)
+	}
+	return nil
+}
+
+// parseError 从响应中解析 S3 的错误信息
+func parseError(res *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+
+	var e errorResponse
+	if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+		return fmt.Errorf("%d %s: %s", res.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+}
@@ -0,0 +1,50 @@
+package ossstore
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AliyunProvider = "aliyun" // 阿里云 OSS
+	S3Provider     = "s3"     // S3 兼容协议，如 AWS S3、MinIO、Cloudflare R2
+	LocalProvider  = "local"  // 本地文件系统
+)
+
+// PresignResult 预签名结果，屏蔽不同对象存储后端之间的差异
+type PresignResult struct {
+	Method        string            // 请求方法
+	URL           string            // 预签名 URL
+	Expiration    time.Time         // 过期时间
+	SignedHeaders map[string]string // 参与签名的请求头，上传时客户端需要携带
+}
+
+// ObjectStore 对象存储后端接口，所有存储后端都需要实现该接口
+type ObjectStore interface {
+	// DeleteObject 删除对象
+	DeleteObject(ctx context.Context, objectName string) error
+	// RenameObject 重命名对象
+	RenameObject(ctx context.Context, oldPath, newPath string) error
+	// PutContentToOss 上传内容
+	PutContentToOss(ctx context.Context, content []byte, objectName string) error
+	// IsExist 检查对象是否存在
+	IsExist(ctx context.Context, objectName string) (bool, error)
+	// GetContentFromOss 下载内容
+	GetContentFromOss(ctx context.Context, objectName string) ([]byte, error)
+	// ListOssDirFiles 列举指定目录下的所有对象，不包含目录本身
+	ListOssDirFiles(ctx context.Context, dir string) ([]string, error)
+	// GenPreSignUrl 生成用于访问或上传对象的预签名 URL
+	GenPreSignUrl(ctx context.Context, objectName, fileType, method string, duration time.Duration) (*PresignResult, error)
+}
+
+// ContentTypeOf 根据文件类型获取上传时使用的 Content-Type
+func ContentTypeOf(fileType string) (string, bool) {
+	switch fileType {
+	case MarkDown:
+		return MarkdownHeader, true
+	case Webp:
+		return WebpHeader, true
+	default:
+		return "", false
+	}
+}
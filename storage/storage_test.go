@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sparrow_blog_server/cache"
 	"sparrow_blog_server/pkg/config"
 	"sparrow_blog_server/pkg/logger"
 	"sparrow_blog_server/storage/ossstore"
@@ -62,3 +63,53 @@ func TestStorage_PreSignUrl_Put(t *testing.T) {
 
 	fmt.Println(url)
 }
+
+func TestNoEvictKeyPrefixes(t *testing.T) {
+	ctx := context.Background()
+	oldCache := config.Cache
+	config.Cache.Aof.Enable = false
+	config.Cache.Eviction = config.EvictionConfig{MaxEntries: 10, Policy: config.EvictionLRU}
+	defer func() { config.Cache = oldCache }()
+
+	c, err := cache.NewCache(ctx)
+	if err != nil {
+		t.Fatalf("创建缓存失败: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	c.SetNoEvictPrefixes(NoEvictKeyPrefixes...)
+
+	revoked := UserRevokedTokenKeyPre + "logged_out_token"
+	_ = c.SetWithExpired(ctx, revoked, true, time.Hour)
+	_ = c.SetWithExpired(ctx, VerificationCodeKey, "code", 5*time.Minute)
+
+	// 大量来自不同 IP 的限流键造成淘汰压力
+	for i := 0; i < 1000; i++ {
+		_ = c.SetWithExpired(ctx, fmt.Sprintf("%s10.0.%d.%d", CommentRateLimitKeyPrefix, i/256, i%256), "bucket", time.Hour)
+	}
+
+	for _, key := range []string{revoked, VerificationCodeKey} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("期望 %s 不被淘汰，实际得到 %v", key, err)
+		}
+	}
+}
+
+func TestPresignKey(t *testing.T) {
+	if key := presignKey(""); key != nil {
+		t.Errorf("未配置 token_key 时期望返回 nil，实际得到 %x", key)
+	}
+
+	key := presignKey("token-key")
+	if len(key) != 32 {
+		t.Fatalf("期望派生 32 字节密钥，实际得到 %d 字节", len(key))
+	}
+	if string(key) == "token-key" {
+		t.Error("预签名密钥不应与 token_key 相同")
+	}
+	if string(presignKey("token-key")) != string(key) {
+		t.Error("相同的 token_key 应派生出相同的密钥，否则重启后链接失效")
+	}
+	if string(presignKey("other-key")) == string(key) {
+		t.Error("不同的 token_key 应派生出不同的密钥")
+	}
+}